@@ -26,6 +26,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/jobmgrsvc"
 	"github.com/uber/peloton/.gen/thrift/aurora/api/auroraschedulermanagerserver"
 	"github.com/uber/peloton/.gen/thrift/aurora/api/readonlyschedulerserver"
+	"github.com/uber/peloton/.gen/thrift/aurora/bridge/aurorabridgeschedulerserver"
 	"github.com/uber/peloton/pkg/aurorabridge/cache"
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 
@@ -257,6 +258,7 @@ func main() {
 	}
 
 	authOutboundMiddleware := outbound.NewAuthOutboundMiddleware(securityClient)
+	correlationOutboundMiddleware := outbound.NewCorrelationOutboundMiddleware()
 
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonAuroraBridge,
@@ -271,9 +273,9 @@ func main() {
 			Oneway: yarpc.OnewayInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
-			Unary:  authOutboundMiddleware,
-			Stream: authOutboundMiddleware,
-			Oneway: authOutboundMiddleware,
+			Unary:  yarpc.UnaryOutboundMiddleware(authOutboundMiddleware, correlationOutboundMiddleware),
+			Stream: yarpc.StreamOutboundMiddleware(authOutboundMiddleware, correlationOutboundMiddleware),
+			Oneway: yarpc.OnewayOutboundMiddleware(authOutboundMiddleware, correlationOutboundMiddleware),
 		},
 	})
 
@@ -345,6 +347,7 @@ func main() {
 
 	dispatcher.Register(auroraschedulermanagerserver.New(handler))
 	dispatcher.Register(readonlyschedulerserver.New(handler))
+	dispatcher.Register(aurorabridgeschedulerserver.New(handler))
 
 	if err := candidate.Start(); err != nil {
 		log.Fatalf("Unable to start leader candidate: %v", err)
@@ -37,6 +37,7 @@ import (
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr"
 	bin_packing "github.com/uber/peloton/pkg/hostmgr/binpacking"
+	hostmgr_config "github.com/uber/peloton/pkg/hostmgr/config"
 	"github.com/uber/peloton/pkg/hostmgr/goalstate"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	"github.com/uber/peloton/pkg/hostmgr/host/drainer"
@@ -625,6 +626,11 @@ func main() {
 		bin_packing.Init(nil, nil)
 	}
 
+	if cfg.HostManager.FeatureFlags.Enabled(
+		hostmgr_config.DefragBinPackingFeatureFlag) {
+		cfg.HostManager.BinPacking = bin_packing.DeFrag
+	}
+
 	log.WithField("ranker_name", cfg.HostManager.BinPacking).
 		Info("Bin packing is enabled")
 	defaultRanker := bin_packing.GetRankerByName(cfg.HostManager.BinPacking)
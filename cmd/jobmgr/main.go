@@ -565,6 +565,22 @@ func main() {
 		[]cached.JobTaskListener{watchsvc.NewWatchListener(watchProcessor)},
 	)
 
+	// Register terminal task cache eviction
+	if ttl := cfg.JobManager.TerminalTaskCacheTTL; ttl > 0 {
+		if err := backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "TerminalTaskCacheEviction",
+				Func: func(_ *atomic.Bool) {
+					jobFactory.EvictTerminalTasks(ttl)
+				},
+				Period: ttl,
+			},
+		); err != nil {
+			log.WithError(err).
+				Fatal("fail to register terminal task cache eviction")
+		}
+	}
+
 	// Register WorkflowProgressCheck
 	workflowCheck := &progress.WorkflowProgressCheck{
 		JobFactory: jobFactory,
@@ -718,6 +734,7 @@ func main() {
 
 	podsvc.InitV1AlphaPodServiceHandler(
 		dispatcher,
+		rootScope,
 		store,
 		store,
 		store,
@@ -728,6 +745,7 @@ func main() {
 		logmanager.NewLogManager(&http.Client{Timeout: _httpClientTimeout}),
 		*mesosAgentWorkDir,
 		hostsvc.NewInternalHostServiceYARPCClient(dispatcher.ClientConfig(common.PelotonHostManager)),
+		cfg.JobManager.PodSvcCfg,
 	)
 
 	volumesvc.InitServiceHandler(
@@ -253,6 +253,7 @@ func main() {
 	if *taskType != "" {
 		overridePlacementStrategy(*taskType, &cfg)
 	}
+	applyBinPackFeatureFlag(&cfg)
 
 	if *taskDequeueLimit != 0 {
 		cfg.Placement.TaskDequeueLimit = *taskDequeueLimit
@@ -483,6 +484,22 @@ func initPlacementStrategy(cfg config.Config) plugins.Strategy {
 	return strategy
 }
 
+// applyBinPackFeatureFlag makes the batch strategy take precedence over the
+// configured strategy when the bin pack feature flag is enabled. It returns
+// true if the configured strategy was overridden.
+func applyBinPackFeatureFlag(cfg *config.Config) bool {
+	if !cfg.Placement.FeatureFlags.Enabled(config.BinPackFeatureFlag) ||
+		cfg.Placement.Strategy == config.Batch {
+		return false
+	}
+
+	log.WithField("feature_flag", config.BinPackFeatureFlag).
+		WithField("strategy", cfg.Placement.Strategy).
+		Warn("Bin pack feature flag overrides the placement strategy with batch")
+	cfg.Placement.Strategy = config.Batch
+	return true
+}
+
 // overrides the strategy based on the task type supplied at runtime.
 func overridePlacementStrategy(taskType string, cfg *config.Config) {
 	tt, ok := resmgr.TaskType_value[taskType]
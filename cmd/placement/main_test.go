@@ -17,7 +17,10 @@ package main
 import (
 	"testing"
 
+	commonconfig "github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/plugins/batch"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -71,3 +74,87 @@ func TestOverridePlacementStrategy(t *testing.T) {
 			test.name)
 	}
 }
+
+func TestApplyBinPackFeatureFlag(t *testing.T) {
+	tt := []struct {
+		name           string
+		strategy       config.PlacementStrategy
+		flag           bool
+		wantStrategy   config.PlacementStrategy
+		wantOverridden bool
+	}{
+		{
+			name:         "flag disabled keeps mimir strategy",
+			strategy:     config.Mimir,
+			wantStrategy: config.Mimir,
+		},
+		{
+			name:         "flag enabled with batch strategy agrees",
+			strategy:     config.Batch,
+			flag:         true,
+			wantStrategy: config.Batch,
+		},
+		{
+			name:           "flag enabled overrides mimir strategy",
+			strategy:       config.Mimir,
+			flag:           true,
+			wantStrategy:   config.Batch,
+			wantOverridden: true,
+		},
+	}
+
+	for _, test := range tt {
+		cfg := &config.Config{
+			Placement: config.PlacementConfig{
+				Strategy: test.strategy,
+				FeatureFlags: commonconfig.FeatureFlags{
+					config.BinPackFeatureFlag: test.flag,
+				},
+			},
+		}
+		assert.Equal(
+			t,
+			test.wantOverridden,
+			applyBinPackFeatureFlag(cfg),
+			test.name)
+		assert.Equal(
+			t,
+			test.wantStrategy,
+			cfg.Placement.Strategy,
+			test.name)
+	}
+}
+
+func TestInitPlacementStrategy(t *testing.T) {
+	cfg := config.Config{
+		Placement: config.PlacementConfig{
+			Strategy: config.Mimir,
+		},
+	}
+	assert.IsType(t,
+		mimir.New(nil, &cfg.Placement),
+		initPlacementStrategy(cfg))
+
+	cfg.Placement.Strategy = config.Batch
+	assert.IsType(t,
+		batch.New(&cfg.Placement),
+		initPlacementStrategy(cfg))
+}
+
+func TestInitPlacementStrategyWithBinPackFeatureFlag(t *testing.T) {
+	for _, taskType := range []string{"STATELESS", "BATCH"} {
+		cfg := config.Config{
+			Placement: config.PlacementConfig{
+				FeatureFlags: commonconfig.FeatureFlags{
+					config.BinPackFeatureFlag: true,
+				},
+			},
+		}
+		overridePlacementStrategy(taskType, &cfg)
+		applyBinPackFeatureFlag(&cfg)
+		assert.IsType(t,
+			batch.New(&cfg.Placement),
+			initPlacementStrategy(cfg),
+			taskType)
+	}
+}
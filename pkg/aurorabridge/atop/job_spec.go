@@ -28,11 +28,34 @@ import (
 	"github.com/uber/peloton/pkg/aurorabridge/label"
 )
 
+// _defaultMaxUnavailableInstancesPercent is the percent of instances which
+// may be unavailable if not configured by SLADefaults.
+const _defaultMaxUnavailableInstancesPercent = 10
+
+// SLADefaults defines the SLA of new job specs, which is applied only
+// when the Aurora job update request leaves the fields unset.
+type SLADefaults struct {
+	// MaxUnavailableInstancesPercent is the percent of instances which may
+	// be unavailable during maintenance and/or load redistribution. At
+	// least one instance is always allowed to be unavailable.
+	MaxUnavailableInstancesPercent float64 `yaml:"max_unavailable_instances_percent"`
+
+	// Preemptible sets the preemptibility of jobs whose task config
+	// specifies neither a tier nor the production flag.
+	Preemptible bool `yaml:"preemptible"`
+
+	// MaxRunsToRetain is the number of runs of each instance whose pod
+	// events are kept. The pod events of older runs are trimmed
+	// automatically. If 0, the pod events of all runs are kept.
+	MaxRunsToRetain uint32 `yaml:"max_runs_to_retain"`
+}
+
 // NewJobSpecFromJobUpdateRequest creates a new JobSpec.
 func NewJobSpecFromJobUpdateRequest(
 	r *api.JobUpdateRequest,
 	respoolID *peloton.ResourcePoolID,
 	c config.ThermosExecutorConfig,
+	d SLADefaults,
 ) (*stateless.JobSpec, error) {
 
 	if !r.IsSetTaskConfig() {
@@ -53,6 +76,11 @@ func NewJobSpecFromJobUpdateRequest(
 		common.BridgeJobLabel,
 	}
 
+	// Propagate the Aurora metadata to the job labels as well, using the
+	// same prefixed keys as the pod labels so that metadata entries never
+	// collide with the job key labels above.
+	l = append(l, label.NewAuroraMetadataLabels(r.GetTaskConfig().GetMetadata())...)
+
 	return &stateless.JobSpec{
 		Revision:      nil, // Unused.
 		Name:          NewJobName(r.GetTaskConfig().GetJob()),
@@ -62,7 +90,7 @@ func NewJobSpecFromJobUpdateRequest(
 		Description:   "",  // Unused.
 		Labels:        l,
 		InstanceCount: uint32(r.GetInstanceCount()),
-		Sla:           newSLASpec(r.GetTaskConfig(), getMaxUnavailableInstances(r)),
+		Sla:           newSLASpec(r.GetTaskConfig(), getMaxUnavailableInstances(r, d), d),
 		DefaultSpec:   p,
 		InstanceSpec:  nil, // TODO(codyg): Pinned instance support.
 		RespoolId:     respoolID,
@@ -70,17 +98,36 @@ func NewJobSpecFromJobUpdateRequest(
 }
 
 // getMaxUnavailableInstances calculates MaximumUnavailableInstances based on
-// JobUpdateRequest. For jobs with instance_count<10, maxUnavailableInstances
-// is set to 1.
-func getMaxUnavailableInstances(r *api.JobUpdateRequest) uint32 {
+// JobUpdateRequest, as a percent of instance_count which defaults to 10%.
+// maxUnavailableInstances is at least 1.
+func getMaxUnavailableInstances(r *api.JobUpdateRequest, d SLADefaults) uint32 {
+	percent := d.MaxUnavailableInstancesPercent
+	if percent <= 0 {
+		percent = _defaultMaxUnavailableInstancesPercent
+	}
 	instanceCount := float64(r.GetInstanceCount())
-	return uint32(math.Max(0.1*instanceCount, 1.0))
+	return uint32(math.Max(percent/100*instanceCount, 1.0))
 }
 
-func newSLASpec(t *api.TaskConfig, maxUnavailableInstances uint32) *stateless.SlaSpec {
+func newSLASpec(
+	t *api.TaskConfig,
+	maxUnavailableInstances uint32,
+	d SLADefaults,
+) *stateless.SlaSpec {
 	preemptible := false
 	revocable := false
 
+	// Tier set by the request takes precedence over the production flag,
+	// which takes precedence over the default. Production jobs may not be
+	// preempted, while non-production jobs may.
+	switch {
+	case t.IsSetTier():
+	case t.IsSetProduction():
+		preemptible = !t.GetProduction()
+	default:
+		preemptible = d.Preemptible
+	}
+
 	switch t.GetTier() {
 	case common.Preemptible:
 		preemptible = false
@@ -101,5 +148,6 @@ func newSLASpec(t *api.TaskConfig, maxUnavailableInstances uint32) *stateless.Sl
 		Preemptible:                 preemptible,
 		Revocable:                   revocable,
 		MaximumUnavailableInstances: maxUnavailableInstances,
+		MaxRunsToRetain:             d.MaxRunsToRetain,
 	}
 }
@@ -17,7 +17,11 @@ package atop
 import (
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
+	"github.com/uber/peloton/pkg/aurorabridge/common"
+	"github.com/uber/peloton/pkg/aurorabridge/label"
+	"github.com/uber/peloton/pkg/common/config"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/thriftrw/ptr"
@@ -63,8 +67,159 @@ func TestGetMaxUnavailableInstances(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			maxUnavailableInstances := getMaxUnavailableInstances(tt.req)
+			maxUnavailableInstances := getMaxUnavailableInstances(tt.req, SLADefaults{})
 			assert.Equal(t, tt.expect, maxUnavailableInstances)
 		})
 	}
 }
+
+// TestGetMaxUnavailableInstancesConfiguredPercent tests that the configured
+// percent of max unavailable instances is used.
+func TestGetMaxUnavailableInstancesConfiguredPercent(t *testing.T) {
+	d := SLADefaults{MaxUnavailableInstancesPercent: 25}
+
+	assert.Equal(t, uint32(5), getMaxUnavailableInstances(
+		&api.JobUpdateRequest{InstanceCount: ptr.Int32(20)}, d))
+	assert.Equal(t, uint32(1), getMaxUnavailableInstances(
+		&api.JobUpdateRequest{InstanceCount: ptr.Int32(2)}, d))
+}
+
+// TestNewSLASpecDefaults tests that the default preemptibility is applied
+// only when the task config does not set a tier.
+func TestNewSLASpecDefaults(t *testing.T) {
+	d := SLADefaults{Preemptible: true}
+
+	testCases := []struct {
+		name                string
+		tier                *string
+		expectedPreemptible bool
+		expectedRevocable   bool
+	}{
+		{
+			name:                "tier not set",
+			tier:                nil,
+			expectedPreemptible: true,
+		},
+		{
+			name:                "preemptible tier",
+			tier:                ptr.String(common.Preemptible),
+			expectedPreemptible: false,
+		},
+		{
+			name:                "preferred tier",
+			tier:                ptr.String(common.Preferred),
+			expectedPreemptible: false,
+		},
+		{
+			name:                "revocable tier",
+			tier:                ptr.String(common.Revocable),
+			expectedPreemptible: true,
+			expectedRevocable:   true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSLASpec(&api.TaskConfig{Tier: tt.tier}, 3, d)
+			assert.Equal(t, tt.expectedPreemptible, s.GetPreemptible())
+			assert.Equal(t, tt.expectedRevocable, s.GetRevocable())
+			assert.Equal(t, uint32(3), s.GetMaximumUnavailableInstances())
+		})
+	}
+
+	// Without configured defaults jobs are not preemptible.
+	s := newSLASpec(&api.TaskConfig{}, 1, SLADefaults{})
+	assert.False(t, s.GetPreemptible())
+	assert.Equal(t, uint32(0), s.GetMaxRunsToRetain())
+}
+
+// TestNewSLASpecProduction tests that the production flag of the task
+// config maps to the preemptibility of the job when no tier is set.
+func TestNewSLASpecProduction(t *testing.T) {
+	testCases := []struct {
+		name                string
+		production          *bool
+		tier                *string
+		defaults            SLADefaults
+		expectedPreemptible bool
+	}{
+		{
+			name:                "production",
+			production:          ptr.Bool(true),
+			defaults:            SLADefaults{Preemptible: true},
+			expectedPreemptible: false,
+		},
+		{
+			name:                "non-production",
+			production:          ptr.Bool(false),
+			expectedPreemptible: true,
+		},
+		{
+			name:                "tier takes precedence",
+			production:          ptr.Bool(false),
+			tier:                ptr.String(common.Preferred),
+			expectedPreemptible: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSLASpec(&api.TaskConfig{
+				Production: tt.production,
+				Tier:       tt.tier,
+			}, 1, tt.defaults)
+			assert.Equal(t, tt.expectedPreemptible, s.GetPreemptible())
+			assert.False(t, s.GetRevocable())
+		})
+	}
+}
+
+// TestNewSLASpecMaxRunsToRetain tests that the configured run history
+// retention is applied to new jobs.
+func TestNewSLASpecMaxRunsToRetain(t *testing.T) {
+	s := newSLASpec(&api.TaskConfig{}, 1, SLADefaults{MaxRunsToRetain: 10})
+	assert.Equal(t, uint32(10), s.GetMaxRunsToRetain())
+}
+
+// TestNewJobSpecMetadataLabels tests that the Aurora metadata of the task
+// config is added to the labels of the job and of its pods, without
+// colliding with the job key labels.
+func TestNewJobSpecMetadataLabels(t *testing.T) {
+	md := []*api.Metadata{
+		{
+			Key:   ptr.String("routing"),
+			Value: ptr.String("canary"),
+		},
+		{
+			Key:   ptr.String("aurora_job_key_role"),
+			Value: ptr.String("other-role"),
+		},
+	}
+	r := &api.JobUpdateRequest{
+		TaskConfig: &api.TaskConfig{
+			Job: &api.JobKey{
+				Role:        ptr.String("role"),
+				Environment: ptr.String("env"),
+				Name:        ptr.String("name"),
+			},
+			Metadata: md,
+		},
+		InstanceCount: ptr.Int32(1),
+	}
+
+	s, err := NewJobSpecFromJobUpdateRequest(
+		r,
+		&peloton.ResourcePoolID{Value: "respool"},
+		config.ThermosExecutorConfig{},
+		SLADefaults{},
+	)
+	assert.NoError(t, err)
+
+	assert.Contains(t, s.GetLabels(), label.NewAuroraJobKeyRole("role"))
+	for _, l := range label.NewAuroraMetadataLabels(md) {
+		assert.Contains(t, s.GetLabels(), l)
+		assert.Contains(t, s.GetDefaultSpec().GetLabels(), l)
+	}
+	assert.Equal(t, md, label.ParseAuroraMetadata(s.GetLabels()))
+	assert.Equal(t, md, label.ParseAuroraMetadata(s.GetDefaultSpec().GetLabels()))
+}
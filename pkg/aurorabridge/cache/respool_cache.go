@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+)
+
+// RespoolKey identifies the jobs which are placed in the same resource
+// pool.
+type RespoolKey struct {
+	Role string
+	Tier string
+	GPU  bool
+}
+
+// respoolEntry is a single entry inside RespoolCache.
+type respoolEntry struct {
+	id *peloton.ResourcePoolID
+	// expiry is the time after which the resource pool is resolved again.
+	expiry time.Time
+}
+
+// RespoolCache keeps the resource pools resolved for jobs, keyed by the
+// role and tier of the job, so that a resource pool is not resolved for
+// every request. The entries expire after the ttl, so that changes of the
+// resource pools are eventually picked up.
+type RespoolCache struct {
+	sync.Mutex
+
+	ttl     time.Duration
+	entries map[RespoolKey]respoolEntry
+}
+
+// NewRespoolCache creates a RespoolCache keeping resource pools for ttl.
+func NewRespoolCache(ttl time.Duration) *RespoolCache {
+	return &RespoolCache{
+		ttl:     ttl,
+		entries: make(map[RespoolKey]respoolEntry),
+	}
+}
+
+// Get returns the resource pool resolved for key, if it was resolved
+// within the ttl.
+func (c *RespoolCache) Get(
+	key RespoolKey,
+	now time.Time,
+) (*peloton.ResourcePoolID, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiry) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return &peloton.ResourcePoolID{Value: entry.id.GetValue()}, true
+}
+
+// Add records the resource pool resolved for key.
+func (c *RespoolCache) Add(
+	key RespoolKey,
+	id *peloton.ResourcePoolID,
+	now time.Time,
+) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries[key] = respoolEntry{
+		id:     &peloton.ResourcePoolID{Value: id.GetValue()},
+		expiry: now.Add(c.ttl),
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRespoolCache tests that resource pools are only found within the ttl.
+func TestRespoolCache(t *testing.T) {
+	c := NewRespoolCache(time.Minute)
+	now := time.Now()
+	key := RespoolKey{Role: "role", Tier: "preemptible"}
+
+	_, ok := c.Get(key, now)
+	assert.False(t, ok)
+
+	id := &peloton.ResourcePoolID{Value: "respool-1"}
+	c.Add(key, id, now)
+
+	r, ok := c.Get(key, now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, id, r)
+
+	// Resource pools are cached separately for each tier.
+	_, ok = c.Get(RespoolKey{Role: "role", Tier: "revocable"}, now)
+	assert.False(t, ok)
+
+	// The resource pool expires after the ttl, and is refreshed.
+	_, ok = c.Get(key, now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Empty(t, c.entries)
+
+	refreshed := &peloton.ResourcePoolID{Value: "respool-2"}
+	c.Add(key, refreshed, now.Add(time.Minute))
+	r, ok = c.Get(key, now.Add(time.Minute+time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, refreshed, r)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+)
+
+// tokenEntry is a single entry inside UpdateTokenCache.
+type tokenEntry struct {
+	key    string
+	result *api.Result
+	// expiry is the time after which the token is no longer deduplicated.
+	expiry time.Time
+}
+
+// UpdateTokenCache keeps the results of recent StartJobUpdate requests
+// keyed by their idempotency token, so that a retried request can be
+// answered with the original result instead of starting another update.
+// The cache is bounded, the oldest tokens are evicted once it is full.
+type UpdateTokenCache struct {
+	sync.Mutex
+
+	size    int
+	window  time.Duration
+	entries map[string]*list.Element
+	// order keeps the entries from the oldest to the newest.
+	order *list.List
+}
+
+// NewUpdateTokenCache creates an UpdateTokenCache holding up to size tokens
+// for the given window.
+func NewUpdateTokenCache(size int, window time.Duration) *UpdateTokenCache {
+	return &UpdateTokenCache{
+		size:    size,
+		window:  window,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the result recorded for the token key, if the token was
+// seen within the window.
+func (c *UpdateTokenCache) Get(key string, now time.Time) (*api.Result, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*tokenEntry)
+	if !now.Before(entry.expiry) {
+		c.remove(e)
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// Add records the result of the request with the token key.
+func (c *UpdateTokenCache) Add(key string, result *api.Result, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+
+	c.entries[key] = c.order.PushBack(&tokenEntry{
+		key:    key,
+		result: result,
+		expiry: now.Add(c.window),
+	})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Front())
+	}
+}
+
+func (c *UpdateTokenCache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*tokenEntry).key)
+}
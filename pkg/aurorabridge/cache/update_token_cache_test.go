@@ -0,0 +1,73 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+func newTestResult(id string) *api.Result {
+	return &api.Result{
+		StartJobUpdateResult: &api.StartJobUpdateResult{
+			Key: &api.JobUpdateKey{ID: ptr.String(id)},
+		},
+	}
+}
+
+// TestUpdateTokenCache tests that tokens are only found within the window.
+func TestUpdateTokenCache(t *testing.T) {
+	c := NewUpdateTokenCache(10, time.Minute)
+	now := time.Now()
+
+	_, ok := c.Get("token-1", now)
+	assert.False(t, ok)
+
+	result := newTestResult("update-1")
+	c.Add("token-1", result, now)
+
+	r, ok := c.Get("token-1", now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, result, r)
+
+	// The token expires after the window.
+	_, ok = c.Get("token-1", now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Empty(t, c.entries)
+}
+
+// TestUpdateTokenCacheEviction tests that the oldest tokens are evicted
+// once the cache is full.
+func TestUpdateTokenCacheEviction(t *testing.T) {
+	c := NewUpdateTokenCache(2, time.Minute)
+	now := time.Now()
+
+	c.Add("token-1", newTestResult("update-1"), now)
+	c.Add("token-2", newTestResult("update-2"), now)
+	c.Add("token-3", newTestResult("update-3"), now)
+
+	_, ok := c.Get("token-1", now)
+	assert.False(t, ok)
+	_, ok = c.Get("token-2", now)
+	assert.True(t, ok)
+	_, ok = c.Get("token-3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.order.Len())
+}
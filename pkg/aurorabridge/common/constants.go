@@ -46,6 +46,20 @@ var BridgePodLabel = &peloton.Label{
 // a forced PodSpec change.
 const BridgeUpdateLabelKey = "aurora_bridge_update"
 
+// IdempotencyTokenKey is the key of the Aurora job update metadata which
+// carries the idempotency token of a StartJobUpdate request.
+const IdempotencyTokenKey = "idempotency_token"
+
+// ForceUpdateKey is the key of the Aurora job update metadata which, when
+// set to "true", lets StartJobUpdate replace an in-progress update whose
+// status is not overridable.
+const ForceUpdateKey = "force_update"
+
+// AllowVolumeOrphanKey is the key of the Aurora job update metadata which,
+// when set to "true", lets StartJobUpdate remove instances which hold a
+// persistent volume.
+const AllowVolumeOrphanKey = "allow_volume_orphan"
+
 // AuroraGpuResourceKey is the label set to indicate the number
 // of GPUs to be allocated to the task.
 const AuroraGpuResourceKey = "udeploy_num_gpus"
@@ -15,13 +15,25 @@
 package aurorabridge
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
 
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/common"
 	"github.com/uber/peloton/pkg/common/config"
 )
 
+// _defaultUpdateActionMaxRetries is the number of times a workflow action
+// is retried on a job version conflict if not configured.
+const _defaultUpdateActionMaxRetries = 3
+
+// _defaultMaxInstancesPerJob is the maximum instance count accepted by
+// StartJobUpdate if not configured.
+const _defaultMaxInstancesPerJob = 100000
+
 // ServiceHandlerConfig defines ServiceHandler configuration.
 type ServiceHandlerConfig struct {
 	GetJobUpdateWorkers           int `yaml:"get_job_update_workers"`
@@ -60,12 +72,80 @@ type ServiceHandlerConfig struct {
 	// UpdatesLimit specifies the limit on number of updates to include per job
 	UpdatesLimit uint32 `yaml:"updates_limit"`
 
+	// UpdateDetailsInstanceEventsMax specifies the maximum number of
+	// instance update events returned per update by GetJobUpdateDetails.
+	// The most recent events are retained.
+	UpdateDetailsInstanceEventsMax int `yaml:"update_details_instance_events_max"`
+
+	// UpdateDetailsSummaryOnly omits instance update events from
+	// GetJobUpdateDetails response if set to true.
+	UpdateDetailsSummaryOnly bool `yaml:"update_details_summary_only"`
+
 	// ThemrosExecutor is config used to generate mesos CommandInfo / ExecutorInfo
 	// for Thermos executor
 	ThermosExecutor config.ThermosExecutorConfig `yaml:"thermos_executor"`
 
 	// Enable Peloton inplace update
 	EnableInPlace bool `yaml:"enable-inplace-update"`
+
+	// JobSLADefaults specifies the SLA of jobs created by bridge, which is
+	// used only when the Aurora job update request leaves it unset.
+	JobSLADefaults atop.SLADefaults `yaml:"job_sla_defaults"`
+
+	// UpdateActionMaxRetries specifies the number of times a pause, resume
+	// or abort workflow action is retried when it fails because the job
+	// version changed after it was fetched. Defaults to 3 retries if not
+	// set, 0 disables the retries.
+	UpdateActionMaxRetries *int `yaml:"update_action_max_retries"`
+
+	// KillOrphanedInstancesOnAbort stops the instances beyond the current
+	// instance count of the job after AbortJobUpdate aborts the update,
+	// e.g. instances left running by an aborted scale down update.
+	KillOrphanedInstancesOnAbort bool `yaml:"kill_orphaned_instances_on_abort"`
+
+	// MaxInstancesPerJob specifies the maximum instance count accepted
+	// by StartJobUpdate, so that a mistyped instance count is rejected
+	// before building a huge job spec. Defaults to 100000 instances if
+	// not set, 0 means there is no limit.
+	MaxInstancesPerJob *uint32 `yaml:"max_instances_per_job"`
+
+	// StartJobUpdateDedupWindow specifies for how long a StartJobUpdate
+	// request is deduplicated, i.e. a request with the same idempotency
+	// token for the same job returns the result of the original request
+	// instead of starting another update. A zero value disables it.
+	StartJobUpdateDedupWindow time.Duration `yaml:"start_job_update_dedup_window"`
+
+	// StartJobUpdateDedupCacheSize specifies the maximum number of
+	// idempotency tokens remembered for deduplication.
+	StartJobUpdateDedupCacheSize int `yaml:"start_job_update_dedup_cache_size"`
+
+	// CheckRespoolCapacity specifies whether a StartJobUpdate request is
+	// rejected when the resources it adds to the job exceed the
+	// unallocated reservation of the resource pool. Disabled by default.
+	CheckRespoolCapacity bool `yaml:"check_respool_capacity"`
+
+	// RespoolCacheTTL specifies for how long the resource pool resolved
+	// for the role and tier of a job is reused by later requests, before
+	// it is resolved again. A zero value disables caching.
+	RespoolCacheTTL time.Duration `yaml:"respool_cache_ttl"`
+
+	// StartJobUpdateOverridableStatuses lists the Aurora statuses, e.g.
+	// ROLL_FORWARD_PAUSED, of an in-progress update which StartJobUpdate
+	// may replace. A request for a job whose update is in any other
+	// in-progress status is rejected unless it carries the force update
+	// metadata. Updates which completed can always be replaced. If left
+	// empty, StartJobUpdate replaces in-progress updates regardless.
+	StartJobUpdateOverridableStatuses []string `yaml:"start_job_update_overridable_statuses"`
+
+	// CreateJobConflictMaxRetries specifies the number of times
+	// StartJobUpdate retries creating a job after CreateJob reports that
+	// the job already exists, but the job cannot be resolved by its name,
+	// e.g. while the job is being deleted. A zero value disables retries.
+	CreateJobConflictMaxRetries int `yaml:"create_job_conflict_max_retries"`
+
+	// CreateJobConflictRetryInterval specifies the delay before the first
+	// retry of a conflicting CreateJob, which doubles for every retry.
+	CreateJobConflictRetryInterval time.Duration `yaml:"create_job_conflict_retry_interval"`
 }
 
 func (c *ServiceHandlerConfig) normalize() {
@@ -114,6 +194,33 @@ func (c *ServiceHandlerConfig) normalize() {
 	if c.UpdatesLimit == 0 {
 		c.UpdatesLimit = 10
 	}
+	if c.UpdateDetailsInstanceEventsMax == 0 {
+		c.UpdateDetailsInstanceEventsMax = 1000
+	}
+	if c.StartJobUpdateDedupCacheSize == 0 {
+		c.StartJobUpdateDedupCacheSize = 1000
+	}
+	if c.CreateJobConflictRetryInterval == 0 {
+		c.CreateJobConflictRetryInterval = 100 * time.Millisecond
+	}
+}
+
+// updateActionMaxRetries returns the number of times a workflow action is
+// retried on a job version conflict.
+func (c *ServiceHandlerConfig) updateActionMaxRetries() int {
+	if c.UpdateActionMaxRetries == nil {
+		return _defaultUpdateActionMaxRetries
+	}
+	return *c.UpdateActionMaxRetries
+}
+
+// maxInstancesPerJob returns the maximum instance count accepted by
+// StartJobUpdate, 0 meaning there is no limit.
+func (c *ServiceHandlerConfig) maxInstancesPerJob() uint32 {
+	if c.MaxInstancesPerJob == nil {
+		return _defaultMaxInstancesPerJob
+	}
+	return *c.MaxInstancesPerJob
 }
 
 func (c *ServiceHandlerConfig) getTasksWithoutConfigsWorkers(size int) int {
@@ -130,9 +237,32 @@ func (c *ServiceHandlerConfig) validate() error {
 	if err := c.ThermosExecutor.Validate(); err != nil {
 		return err
 	}
+	if _, err := c.overridableUpdateStatuses(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// overridableUpdateStatuses parses StartJobUpdateOverridableStatuses into
+// a set of Aurora job update statuses. It returns nil if none is set.
+func (c *ServiceHandlerConfig) overridableUpdateStatuses() (
+	*common.JobUpdateStatusSet, error) {
+	if len(c.StartJobUpdateOverridableStatuses) == 0 {
+		return nil, nil
+	}
+
+	var statuses []api.JobUpdateStatus
+	for _, name := range c.StartJobUpdateOverridableStatuses {
+		var s api.JobUpdateStatus
+		if err := s.UnmarshalText([]byte(name)); err != nil {
+			return nil, fmt.Errorf(
+				"invalid start_job_update_overridable_statuses: %s", err)
+		}
+		statuses = append(statuses, s)
+	}
+	return common.NewJobUpdateStatusSet(statuses...), nil
+}
+
 // RespoolLoaderConfig defines RespoolLoader configuration.
 type RespoolLoaderConfig struct {
 	RetryInterval      time.Duration      `yaml:"retry_interval"`
@@ -0,0 +1,40 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"context"
+
+	"github.com/uber/peloton/pkg/middleware/outbound"
+
+	"github.com/pborman/uuid"
+	"go.uber.org/yarpc"
+)
+
+// _correlationIDDetailPrefix prefixes the response detail carrying the
+// correlation id of a request.
+const _correlationIDDetailPrefix = "correlation id: "
+
+// withCorrelationID returns a copy of ctx carrying the correlation id of
+// the inbound request, along with the id. The id is taken from the
+// request headers if the caller set one, and generated otherwise. The id
+// is sent along with the requests made to Peloton using the context.
+func withCorrelationID(ctx context.Context) (context.Context, string) {
+	id := yarpc.CallFromContext(ctx).Header(outbound.CorrelationIDHeader)
+	if id == "" {
+		id = uuid.New()
+	}
+	return outbound.WithCorrelationID(ctx, id), id
+}
@@ -34,7 +34,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
-	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/common/util/podname"
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/atomic"
@@ -367,7 +367,7 @@ func (e *eventPublisher) getTaskStateChange(
 		context.Background(),
 		rpcTimeout)
 	defer cancelFunc()
-	jobID, _, err := util.ParseTaskID(podName.GetValue())
+	jobID, _, err := podname.ParsePodName(podName)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to parse pod name to derive jobID")
 	}
@@ -23,12 +23,12 @@ import (
 	"sync"
 	"time"
 
-	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
 	"github.com/uber/peloton/.gen/peloton/private/jobmgrsvc"
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
 
@@ -41,11 +41,13 @@ import (
 	"github.com/uber/peloton/pkg/common/concurrency"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	"github.com/uber/peloton/pkg/common/util/podname"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
 	"go.uber.org/thriftrw/ptr"
 	"go.uber.org/yarpc/yarpcerrors"
 )
@@ -76,6 +78,13 @@ type ServiceHandler struct {
 	respoolLoader RespoolLoader
 	random        common.Random
 	jobIdCache    cache.JobIDCache
+
+	updateTokenCache *cache.UpdateTokenCache
+	respoolCache     *cache.RespoolCache
+
+	// overridableUpdateStatuses is nil if StartJobUpdate replaces
+	// in-progress updates regardless of their status.
+	overridableUpdateStatuses *common.JobUpdateStatusSet
 }
 
 // NewServiceHandler creates a new ServiceHandler.
@@ -94,6 +103,10 @@ func NewServiceHandler(
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
+	overridableUpdateStatuses, err := config.overridableUpdateStatuses()
+	if err != nil {
+		return nil, err
+	}
 
 	return &ServiceHandler{
 		config:        config,
@@ -104,6 +117,11 @@ func NewServiceHandler(
 		respoolLoader: respoolLoader,
 		random:        random,
 		jobIdCache:    jobIdCache,
+		updateTokenCache: cache.NewUpdateTokenCache(
+			config.StartJobUpdateDedupCacheSize,
+			config.StartJobUpdateDedupWindow),
+		respoolCache:              cache.NewRespoolCache(config.RespoolCacheTTL),
+		overridableUpdateStatuses: overridableUpdateStatuses,
 	}, nil
 }
 
@@ -112,10 +130,11 @@ func (h *ServiceHandler) GetJobSummary(
 	ctx context.Context,
 	role *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getJobSummary(ctx, role)
-	resp := newResponse(result, err, "getJobSummary")
+	resp := h.newResponse(ctx, result, err, "getJobSummary")
 
 	defer func() {
 		h.metrics.
@@ -130,6 +149,7 @@ func (h *ServiceHandler) GetJobSummary(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"role": role,
 				},
@@ -140,6 +160,7 @@ func (h *ServiceHandler) GetJobSummary(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"role": role,
 			},
@@ -154,30 +175,13 @@ func (h *ServiceHandler) getJobSummary(
 	ctx context.Context,
 	role *string,
 ) (*api.Result, *auroraError) {
-	var jobIDs []*peloton.JobID
-	var err error
-
-	if role != nil && *role != "" {
-		jobIDs, err = h.getJobIDsFromRoleCache(ctx, *role)
-	} else {
-		jobIDs, err = h.queryJobIDs(ctx, "", "", "")
-	}
-
-	if err != nil {
-		return nil, auroraErrorf("get job ids from role: %s", err)
-	}
-
-	var inputs []interface{}
-	for _, j := range jobIDs {
-		inputs = append(inputs, j)
-	}
-
 	f := func(ctx context.Context, input interface{}) (interface{}, error) {
 		jobID := input.(*peloton.JobID)
 		jobInfo, err := h.getJobInfo(ctx, jobID)
 		if err != nil {
 			if yarpcerrors.IsNotFound(err) {
-				return nil, nil
+				// Skipped by mapJobIDsFromRole.
+				return nil, err
 			}
 			return nil, fmt.Errorf("get job info for job id %q: %s",
 				jobID.GetValue(), err)
@@ -208,13 +212,13 @@ func (h *ServiceHandler) getJobSummary(
 		return s, nil
 	}
 
-	outputs, err := concurrency.Map(
+	outputs, aerr := h.mapJobIDsFromRole(
 		ctx,
+		role,
 		concurrency.MapperFunc(f),
-		inputs,
 		h.config.GetJobSummaryWorkers)
-	if err != nil {
-		return nil, auroraErrorf(err.Error())
+	if aerr != nil {
+		return nil, aerr
 	}
 
 	summaries := []*api.JobSummary{}
@@ -242,10 +246,11 @@ func (h *ServiceHandler) GetTasksWithoutConfigs(
 	ctx context.Context,
 	query *api.TaskQuery,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getTasksWithoutConfigs(ctx, query)
-	resp := newResponse(result, err, "getTasksWithoutConfigs")
+	resp := h.newResponse(ctx, result, err, "getTasksWithoutConfigs")
 
 	defer func() {
 		h.metrics.
@@ -260,6 +265,7 @@ func (h *ServiceHandler) GetTasksWithoutConfigs(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"query": query,
 				},
@@ -270,6 +276,7 @@ func (h *ServiceHandler) GetTasksWithoutConfigs(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"query": query,
 			},
@@ -289,14 +296,14 @@ func (h *ServiceHandler) getTasksWithoutConfigs(
 	for s := range query.GetStatuses() {
 		p, err := atop.NewPodState(s)
 		if err != nil {
-			return nil, auroraErrorf("new pod state: %s", err)
+			return nil, auroraErrorFrom(err, "new pod state")
 		}
 		podStates = append(podStates, p)
 	}
 
 	jobIDs, err := h.getJobIDsFromTaskQuery(ctx, query)
 	if err != nil {
-		return nil, auroraErrorf("get job ids from task query: %s", err)
+		return nil, auroraErrorFrom(err, "get job ids from task query")
 	}
 
 	// concurrency.Map in nested setup leaks goroutines, thus using
@@ -354,7 +361,8 @@ func (h *ServiceHandler) getTasksWithoutConfigs(
 	tasks := []*api.ScheduledTask{}
 	for _, o := range mapOutputs {
 		if o.err != nil {
-			return nil, auroraErrorf(o.err.Error())
+			return nil, auroraErrorf(o.err.Error()).
+				code(toAuroraResponseCode(o.err))
 		}
 		if o.ts == nil {
 			continue
@@ -427,12 +435,12 @@ func (h *ServiceHandler) getScheduledTasks(
 		podID := p.GetStatus().GetPodId()
 		podName := podSpec.GetPodName()
 
-		runID, err := util.ParseRunID(podID.GetValue())
+		_, _, runID, err := podname.ParsePodID(podID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse pod id: %s", err)
 		}
 
-		_, instanceID, err := util.ParseTaskID(podName.GetValue())
+		_, instanceID, err := podname.ParsePodName(podName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse pod name: %s", err)
 		}
@@ -446,11 +454,7 @@ func (h *ServiceHandler) getScheduledTasks(
 				break
 			}
 
-			newPodID := &peloton.PodID{
-				Value: util.CreateMesosTaskID(&v0peloton.JobID{
-					Value: jobID.GetValue(),
-				}, instanceID, newRunID).GetValue(),
-			}
+			newPodID := podname.FormatPodID(jobID.GetValue(), instanceID, newRunID)
 
 			taskInput := &getScheduledTaskInput{
 				podName:    podName,
@@ -592,10 +596,11 @@ func (h *ServiceHandler) getScheduledTasks(
 func (h *ServiceHandler) GetConfigSummary(
 	ctx context.Context,
 	job *api.JobKey) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getConfigSummary(ctx, job)
-	resp := newResponse(result, err, "getConfigSummary")
+	resp := h.newResponse(ctx, result, err, "getConfigSummary")
 
 	defer func() {
 		h.metrics.
@@ -610,6 +615,7 @@ func (h *ServiceHandler) GetConfigSummary(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"job": job,
 				},
@@ -620,6 +626,7 @@ func (h *ServiceHandler) GetConfigSummary(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job": job,
 			},
@@ -638,12 +645,12 @@ func (h *ServiceHandler) getConfigSummary(
 		ctx,
 		jobKey)
 	if err != nil {
-		return nil, auroraErrorf("unable to get jobID from jobKey: %s", err)
+		return nil, auroraErrorFrom(err, "unable to get jobID from jobKey")
 	}
 
 	jobSummary, err := h.getJobInfoSummary(ctx, jobID)
 	if err != nil {
-		return nil, auroraErrorf("unable to get jobSummary from jobID: %s", err)
+		return nil, auroraErrorFrom(err, "unable to get jobSummary from jobID")
 	}
 
 	podInfos, err := h.queryPods(
@@ -651,14 +658,14 @@ func (h *ServiceHandler) getConfigSummary(
 		jobID,
 		jobSummary.GetInstanceCount())
 	if err != nil {
-		return nil, auroraErrorf("unable to query pods using jobID: %s", err)
+		return nil, auroraErrorFrom(err, "unable to query pods using jobID")
 	}
 
 	configSummary, err := ptoa.NewConfigSummary(
 		jobSummary,
 		podInfos)
 	if err != nil {
-		return nil, auroraErrorf("unable to get config summary from podInfos: %s", err)
+		return nil, auroraErrorFrom(err, "unable to get config summary from podInfos")
 	}
 
 	return &api.Result{
@@ -673,10 +680,11 @@ func (h *ServiceHandler) GetJobs(
 	ctx context.Context,
 	ownerRole *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getJobs(ctx, ownerRole)
-	resp := newResponse(result, err, "getJobs")
+	resp := h.newResponse(ctx, result, err, "getJobs")
 
 	defer func() {
 		h.metrics.
@@ -691,6 +699,7 @@ func (h *ServiceHandler) GetJobs(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"ownerRole": ownerRole,
 				},
@@ -701,6 +710,7 @@ func (h *ServiceHandler) GetJobs(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"ownerRole": ownerRole,
 			},
@@ -715,30 +725,13 @@ func (h *ServiceHandler) getJobs(
 	ctx context.Context,
 	ownerRole *string,
 ) (*api.Result, *auroraError) {
-	var jobIDs []*peloton.JobID
-	var err error
-
-	if ownerRole != nil && *ownerRole != "" {
-		jobIDs, err = h.getJobIDsFromRoleCache(ctx, *ownerRole)
-	} else {
-		jobIDs, err = h.queryJobIDs(ctx, "", "", "")
-	}
-
-	if err != nil {
-		return nil, auroraErrorf("get job ids from role: %s", err)
-	}
-
-	var inputs []interface{}
-	for _, j := range jobIDs {
-		inputs = append(inputs, j)
-	}
-
 	f := func(ctx context.Context, input interface{}) (interface{}, error) {
 		jobID := input.(*peloton.JobID)
 		jobInfo, err := h.getJobInfo(ctx, jobID)
 		if err != nil {
 			if yarpcerrors.IsNotFound(err) {
-				return nil, nil
+				// Skipped by mapJobIDsFromRole.
+				return nil, err
 			}
 			return nil, fmt.Errorf("get job info for job id %q: %s",
 				jobID.GetValue(), err)
@@ -769,13 +762,13 @@ func (h *ServiceHandler) getJobs(
 		return c, nil
 	}
 
-	outputs, err := concurrency.Map(
+	outputs, aerr := h.mapJobIDsFromRole(
 		ctx,
+		ownerRole,
 		concurrency.MapperFunc(f),
-		inputs,
 		h.config.GetJobsWorkers)
-	if err != nil {
-		return nil, auroraErrorf(err.Error())
+	if aerr != nil {
+		return nil, aerr
 	}
 
 	configs := []*api.JobConfiguration{}
@@ -798,15 +791,99 @@ func (h *ServiceHandler) getJobs(
 	}, nil
 }
 
+// ListJobs lists the jobs of a role. Unlike GetJobs, the returned
+// JobConfigurations are built from the job summaries returned by a
+// paginated QueryJobs, and therefore do not include TaskConfig.
+func (h *ServiceHandler) ListJobs(
+	ctx context.Context,
+	role *string,
+) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
+
+	startTime := time.Now()
+	result, err := h.listJobs(ctx, role)
+	resp := h.newResponse(ctx, result, err, "listJobs")
+
+	defer func() {
+		h.metrics.
+			Procedures[ProcedureListJobs].
+			ResponseCodes[resp.GetResponseCode()].
+			Calls.Inc(1)
+
+		h.metrics.
+			Procedures[ProcedureListJobs].
+			ResponseCodes[resp.GetResponseCode()].
+			CallLatency.Record(time.Since(startTime))
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"correlation_id": cid,
+				"params": log.Fields{
+					"role": role,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ListJobs error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"correlation_id": cid,
+			"params": log.Fields{
+				"role": role,
+			},
+			"result": result,
+		}).Debug("ListJobs success")
+	}()
+
+	return resp, nil
+}
+
+func (h *ServiceHandler) listJobs(
+	ctx context.Context,
+	role *string,
+) (*api.Result, *auroraError) {
+	if role == nil || *role == "" {
+		return nil, auroraErrorf("role is required").
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	summaries, err := h.queryJobSummaries(ctx, *role)
+	if err != nil {
+		return nil, auroraErrorFrom(err, "query jobs")
+	}
+
+	configs := []*api.JobConfiguration{}
+	for _, s := range summaries {
+		// If the job is stopped, skip it from the response
+		if s.GetInstanceCount() == 0 {
+			continue
+		}
+
+		c, err := ptoa.NewJobConfigurationFromSummary(s)
+		if err != nil {
+			return nil, auroraErrorFrom(err, "new job configuration")
+		}
+		configs = append(configs, c)
+	}
+
+	return &api.Result{
+		GetJobsResult: &api.GetJobsResult{
+			Configs: configs,
+		},
+	}, nil
+}
+
 // GetJobUpdateSummaries gets job update summaries.
 func (h *ServiceHandler) GetJobUpdateSummaries(
 	ctx context.Context,
 	query *api.JobUpdateQuery,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getJobUpdateSummaries(ctx, query)
-	resp := newResponse(result, err, "getJobUpdateSummaries")
+	resp := h.newResponse(ctx, result, err, "getJobUpdateSummaries")
 
 	defer func() {
 		h.metrics.
@@ -821,6 +898,7 @@ func (h *ServiceHandler) GetJobUpdateSummaries(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"query": query,
 				},
@@ -831,6 +909,7 @@ func (h *ServiceHandler) GetJobUpdateSummaries(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"query": query,
 			},
@@ -848,7 +927,7 @@ func (h *ServiceHandler) getJobUpdateSummaries(
 
 	details, err := h.queryJobUpdates(ctx, query, false /* includeInstanceEvents */)
 	if err != nil {
-		return nil, auroraErrorf("query job updates: %s", err)
+		return nil, auroraErrorFrom(err, "query job updates")
 	}
 	summaries := []*api.JobUpdateSummary{}
 	for _, d := range details {
@@ -869,10 +948,11 @@ func (h *ServiceHandler) GetJobUpdateDetails(
 	key *api.JobUpdateKey,
 	query *api.JobUpdateQuery,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getJobUpdateDetails(ctx, key, query)
-	resp := newResponse(result, err, "getJobUpdateDetails")
+	resp := h.newResponse(ctx, result, err, "getJobUpdateDetails")
 
 	defer func() {
 		h.metrics.
@@ -887,6 +967,7 @@ func (h *ServiceHandler) GetJobUpdateDetails(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"key":   key,
 					"query": query,
@@ -898,6 +979,7 @@ func (h *ServiceHandler) GetJobUpdateDetails(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"key":   key,
 				"query": query,
@@ -918,13 +1000,21 @@ func (h *ServiceHandler) getJobUpdateDetails(
 	if key.IsSetJob() {
 		query.JobKey = key.GetJob()
 	}
-	details, err := h.queryJobUpdates(ctx, query, true /* includeInstanceEvents */)
+	details, err := h.queryJobUpdates(
+		ctx, query, !h.config.UpdateDetailsSummaryOnly /* includeInstanceEvents */)
 	if err != nil {
-		return nil, auroraErrorf("query job updates: %s", err)
+		return nil, auroraErrorFrom(err, "query job updates")
 	}
 	if details == nil {
 		details = []*api.JobUpdateDetails{}
 	}
+	for _, d := range details {
+		// Instance events are sorted by ascending timestamp, keep the
+		// most recent ones.
+		if n := len(d.InstanceEvents); n > h.config.UpdateDetailsInstanceEventsMax {
+			d.InstanceEvents = d.InstanceEvents[n-h.config.UpdateDetailsInstanceEventsMax:]
+		}
+	}
 	return &api.Result{
 		GetJobUpdateDetailsResult: &api.GetJobUpdateDetailsResult{
 			DetailsList: details,
@@ -938,10 +1028,11 @@ func (h *ServiceHandler) getJobUpdateDetails(
 func (h *ServiceHandler) GetJobUpdateDiff(
 	ctx context.Context,
 	request *api.JobUpdateRequest) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.getJobUpdateDiff(ctx, request)
-	resp := newResponse(result, err, "getJobUpdateDiff")
+	resp := h.newResponse(ctx, result, err, "getJobUpdateDiff")
 
 	defer func() {
 		h.metrics.
@@ -956,6 +1047,7 @@ func (h *ServiceHandler) GetJobUpdateDiff(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"request": request,
 				},
@@ -966,6 +1058,7 @@ func (h *ServiceHandler) GetJobUpdateDiff(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"request": request,
 			},
@@ -981,18 +1074,18 @@ func (h *ServiceHandler) getJobUpdateDiff(
 	request *api.JobUpdateRequest,
 ) (*api.Result, *auroraError) {
 
-	respoolID, err := h.respoolLoader.Load(
-		ctx,
-		label.IsGpuConfig(
-			request.GetTaskConfig().GetMetadata(),
-			request.GetTaskConfig().GetResources(),
-		),
-	)
+	respoolID, err := h.loadRespool(ctx, request.GetTaskConfig())
 	if err != nil {
-		return nil, auroraErrorf("load respool: %s", err)
+		return nil, auroraErrorFrom(err, "load respool")
 	}
 
 	newJobResult := func() (*api.Result, *auroraError) {
+		// Nothing is added to a new job scaled to zero instances.
+		if request.GetInstanceCount() <= 0 {
+			return &api.Result{
+				GetJobUpdateDiffResult: &api.GetJobUpdateDiffResult{},
+			}, nil
+		}
 		last := max(0, request.GetInstanceCount()-1)
 		return &api.Result{
 			GetJobUpdateDiffResult: &api.GetJobUpdateDiffResult{
@@ -1013,7 +1106,7 @@ func (h *ServiceHandler) getJobUpdateDiff(
 			// so construct the diff manually in this case.
 			return newJobResult()
 		}
-		return nil, auroraErrorf("get job id: %s", err)
+		return nil, auroraErrorFrom(err, "get job id")
 	}
 
 	jobSummary, err := h.getJobInfoSummary(ctx, jobID)
@@ -1023,16 +1116,28 @@ func (h *ServiceHandler) getJobUpdateDiff(
 			// so construct the diff manually in this case.
 			return newJobResult()
 		}
-		return nil, auroraErrorf("get job summary: %s", err)
+		return nil, auroraErrorFrom(err, "get job summary")
+	}
+
+	// Scaling a job to zero instances tears it down, so all the current
+	// instances are removed regardless of the task config.
+	if request.GetInstanceCount() <= 0 {
+		result := &api.GetJobUpdateDiffResult{}
+		if n := jobSummary.GetInstanceCount(); n > 0 {
+			result.Remove = ptoa.NewConfigGroupWithoutTaskConfig(
+				[]*pod.InstanceIDRange{{From: 0, To: n - 1}})
+		}
+		return &api.Result{GetJobUpdateDiffResult: result}, nil
 	}
 
 	jobSpec, err := atop.NewJobSpecFromJobUpdateRequest(
 		request,
 		respoolID,
 		h.config.ThermosExecutor,
+		h.config.JobSLADefaults,
 	)
 	if err != nil {
-		return nil, auroraErrorf("new job spec: %s", err)
+		return nil, auroraErrorFrom(err, "new job spec")
 	}
 
 	resp, err := h.jobClient.GetReplaceJobDiff(
@@ -1043,7 +1148,7 @@ func (h *ServiceHandler) getJobUpdateDiff(
 			Spec:    jobSpec,
 		})
 	if err != nil {
-		return nil, auroraErrorf("get replace job diff: %s", err)
+		return nil, auroraErrorFrom(err, "get replace job diff")
 	}
 
 	return &api.Result{GetJobUpdateDiffResult: &api.GetJobUpdateDiffResult{
@@ -1054,10 +1159,13 @@ func (h *ServiceHandler) getJobUpdateDiff(
 	}}, nil
 }
 
-// GetTierConfigs is a no-op. It is only used to determine liveness of the scheduler.
+// GetTierConfigs returns the tiers supported by the cluster, mapped from
+// Peloton's preemptible and revocable capabilities. Aurora clients use it
+// to validate job submissions, and to determine liveness of the scheduler.
 func (h *ServiceHandler) GetTierConfigs(
 	ctx context.Context,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result := &api.Result{
@@ -1088,7 +1196,7 @@ func (h *ServiceHandler) GetTierConfigs(
 			},
 		},
 	}
-	resp := newResponse(result, nil)
+	resp := h.newResponse(ctx, result, nil)
 
 	defer func() {
 		h.metrics.
@@ -1102,7 +1210,8 @@ func (h *ServiceHandler) GetTierConfigs(
 			CallLatency.Record(time.Since(startTime))
 
 		log.WithFields(log.Fields{
-			"result": result,
+			"correlation_id": cid,
+			"result":         result,
 		}).Debug("GetTierConfigs success")
 	}()
 
@@ -1116,10 +1225,11 @@ func (h *ServiceHandler) KillTasks(
 	instances map[int32]struct{},
 	message *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.killTasks(ctx, job, instances, message)
-	resp := newResponse(result, err, "killTasks")
+	resp := h.newResponse(ctx, result, err, "killTasks")
 
 	defer func() {
 		h.metrics.
@@ -1140,6 +1250,7 @@ func (h *ServiceHandler) KillTasks(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"job":       job,
 					"instances": instancesStr,
@@ -1152,6 +1263,7 @@ func (h *ServiceHandler) KillTasks(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job":       job,
 				"instances": instancesStr,
@@ -1171,11 +1283,11 @@ func (h *ServiceHandler) killTasks(
 
 	id, err := h.getJobID(ctx, job)
 	if err != nil {
-		return nil, auroraErrorf("get job id: %s", err)
+		return nil, auroraErrorFrom(err, "get job id")
 	}
 	summary, err := h.getJobInfoSummary(ctx, id)
 	if err != nil {
-		return nil, auroraErrorf("get job info summary: %s", err)
+		return nil, auroraErrorFrom(err, "get job info summary")
 	}
 
 	stopAll := false
@@ -1201,11 +1313,11 @@ func (h *ServiceHandler) killTasks(
 			Version: summary.GetStatus().GetVersion(),
 		}
 		if _, err := h.jobClient.StopJob(ctx, req); err != nil {
-			return nil, auroraErrorf("stop job: %s", err)
+			return nil, auroraErrorFrom(err, "stop job")
 		}
 	} else {
 		if err := h.stopPodsConcurrently(ctx, id, instances); err != nil {
-			return nil, auroraErrorf("stop pods in parallel: %s", err)
+			return nil, auroraErrorFrom(err, "stop pods in parallel")
 		}
 	}
 	return dummyResult(), nil
@@ -1224,9 +1336,8 @@ func (h *ServiceHandler) stopPodsConcurrently(
 
 	f := func(ctx context.Context, input interface{}) (interface{}, error) {
 		instanceID := input.(int32)
-		name := util.CreatePelotonTaskID(id.GetValue(), uint32(instanceID))
 		req := &podsvc.StopPodRequest{
-			PodName: &peloton.PodName{Value: name},
+			PodName: podname.FormatPodName(id.GetValue(), uint32(instanceID)),
 		}
 
 		resp, err := h.podClient.StopPod(ctx, req)
@@ -1269,10 +1380,11 @@ func (h *ServiceHandler) StartJobUpdate(
 	request *api.JobUpdateRequest,
 	message *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.startJobUpdate(ctx, request, message)
-	resp := newResponse(result, err, "startJobUpdate")
+	resp := h.newResponse(ctx, result, err, "startJobUpdate")
 
 	defer func() {
 		updateService := request.GetTaskConfig().GetJob().GetRole()
@@ -1313,6 +1425,7 @@ func (h *ServiceHandler) StartJobUpdate(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"request": request,
 					"message": message,
@@ -1324,6 +1437,7 @@ func (h *ServiceHandler) StartJobUpdate(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"message": message,
 				"request": request, // TODO (varung): remove post PRR or as necessary
@@ -1342,10 +1456,11 @@ func (h *ServiceHandler) PauseJobUpdate(
 	key *api.JobUpdateKey,
 	message *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.pauseJobUpdate(ctx, key, message)
-	resp := newResponse(result, err, "pauseJobUpdate")
+	resp := h.newResponse(ctx, result, err, "pauseJobUpdate")
 
 	defer func() {
 		h.metrics.
@@ -1360,6 +1475,7 @@ func (h *ServiceHandler) PauseJobUpdate(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"key":     key,
 					"message": message,
@@ -1371,6 +1487,7 @@ func (h *ServiceHandler) PauseJobUpdate(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job":       key.GetJob(),
 				"update_id": key.GetID(),
@@ -1390,19 +1507,24 @@ func (h *ServiceHandler) pauseJobUpdate(
 
 	id, err := h.getJobID(ctx, key.GetJob())
 	if err != nil {
-		return nil, auroraErrorf("get job id: %s", err)
+		return nil, auroraErrorFrom(err, "get job id")
 	}
-	v, aerr := h.matchJobUpdateID(ctx, id, key.GetID())
+	aerr := h.retryOnVersionConflict(
+		ctx,
+		id,
+		key.GetID(),
+		func(v *peloton.EntityVersion) error {
+			req := &statelesssvc.PauseJobWorkflowRequest{
+				JobId:   id,
+				Version: v,
+			}
+			_, err := h.jobClient.PauseJobWorkflow(ctx, req)
+			return err
+		},
+		"pause job workflow")
 	if aerr != nil {
 		return nil, aerr
 	}
-	req := &statelesssvc.PauseJobWorkflowRequest{
-		JobId:   id,
-		Version: v,
-	}
-	if _, err := h.jobClient.PauseJobWorkflow(ctx, req); err != nil {
-		return nil, auroraErrorf("pause job workflow: %s", err)
-	}
 	return dummyResult(), nil
 }
 
@@ -1412,10 +1534,11 @@ func (h *ServiceHandler) ResumeJobUpdate(
 	key *api.JobUpdateKey,
 	message *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.resumeJobUpdate(ctx, key, message)
-	resp := newResponse(result, err, "resumeJobUpdate")
+	resp := h.newResponse(ctx, result, err, "resumeJobUpdate")
 
 	defer func() {
 		h.metrics.
@@ -1430,6 +1553,7 @@ func (h *ServiceHandler) ResumeJobUpdate(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"key":     key,
 					"message": message,
@@ -1441,6 +1565,7 @@ func (h *ServiceHandler) ResumeJobUpdate(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job":       key.GetJob(),
 				"update_id": key.GetID(),
@@ -1460,22 +1585,26 @@ func (h *ServiceHandler) resumeJobUpdate(
 
 	id, err := h.getJobID(ctx, key.GetJob())
 	if err != nil {
-		return nil, auroraErrorf("get job id: %s", err)
+		return nil, auroraErrorFrom(err, "get job id")
 	}
 
-	v, aerr := h.matchJobUpdateID(ctx, id, key.GetID())
+	aerr := h.retryOnVersionConflict(
+		ctx,
+		id,
+		key.GetID(),
+		func(v *peloton.EntityVersion) error {
+			req := &statelesssvc.ResumeJobWorkflowRequest{
+				JobId:   id,
+				Version: v,
+			}
+			_, err := h.jobClient.ResumeJobWorkflow(ctx, req)
+			return err
+		},
+		"resume job workflow")
 	if aerr != nil {
 		return nil, aerr
 	}
 
-	req := &statelesssvc.ResumeJobWorkflowRequest{
-		JobId:   id,
-		Version: v,
-	}
-	if _, err := h.jobClient.ResumeJobWorkflow(ctx, req); err != nil {
-		return nil, auroraErrorf("resume job workflow: %s", err)
-	}
-
 	return dummyResult(), nil
 }
 
@@ -1485,10 +1614,11 @@ func (h *ServiceHandler) AbortJobUpdate(
 	key *api.JobUpdateKey,
 	message *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.abortJobUpdate(ctx, key, message)
-	resp := newResponse(result, err, "abortJobUpdate")
+	resp := h.newResponse(ctx, result, err, "abortJobUpdate")
 
 	defer func() {
 		h.metrics.
@@ -1503,6 +1633,7 @@ func (h *ServiceHandler) AbortJobUpdate(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"key":     key,
 					"message": message,
@@ -1514,6 +1645,7 @@ func (h *ServiceHandler) AbortJobUpdate(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job":       key.GetJob(),
 				"update_id": key.GetID(),
@@ -1533,35 +1665,89 @@ func (h *ServiceHandler) abortJobUpdate(
 
 	id, err := h.getJobID(ctx, key.GetJob())
 	if err != nil {
-		return nil, auroraErrorf("get job id: %s", err)
+		return nil, auroraErrorFrom(err, "get job id")
 	}
 
-	v, aerr := h.matchJobUpdateID(ctx, id, key.GetID())
+	aerr := h.retryOnVersionConflict(
+		ctx,
+		id,
+		key.GetID(),
+		func(v *peloton.EntityVersion) error {
+			req := &statelesssvc.AbortJobWorkflowRequest{
+				JobId:   id,
+				Version: v,
+			}
+			_, err := h.jobClient.AbortJobWorkflow(ctx, req)
+			return err
+		},
+		"abort job workflow")
 	if aerr != nil {
 		return nil, aerr
 	}
 
-	req := &statelesssvc.AbortJobWorkflowRequest{
-		JobId:   id,
-		Version: v,
-	}
-	if _, err := h.jobClient.AbortJobWorkflow(ctx, req); err != nil {
-		return nil, auroraErrorf("abort job workflow: %s", err)
+	if h.config.KillOrphanedInstancesOnAbort {
+		if err := h.stopOrphanedInstances(ctx, id); err != nil {
+			return nil, auroraErrorFrom(err, "stop orphaned instances")
+		}
 	}
 
 	return dummyResult(), nil
 }
 
+// stopOrphanedInstances stops the non-terminal pods of the job whose
+// instance id is beyond the current instance count of the job.
+func (h *ServiceHandler) stopOrphanedInstances(
+	ctx context.Context,
+	id *peloton.JobID,
+) error {
+	summary, err := h.getJobInfoSummary(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get job info summary: %s", err)
+	}
+
+	pods, err := h.listPods(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list pods: %s", err)
+	}
+
+	orphaned := make(map[int32]struct{})
+	for _, p := range pods {
+		if util.IsPelotonPodStateTerminal(p.GetStatus().GetState()) {
+			continue
+		}
+		_, instanceID, err := podname.ParsePodName(p.GetPodName())
+		if err != nil {
+			return fmt.Errorf("parse pod name: %s", err)
+		}
+		if instanceID >= summary.GetInstanceCount() {
+			orphaned[int32(instanceID)] = struct{}{}
+		}
+	}
+
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"job_id":         id.GetValue(),
+		"instance_count": summary.GetInstanceCount(),
+		"orphaned":       len(orphaned),
+	}).Info("stopping orphaned instances after abort")
+
+	return h.stopPodsConcurrently(ctx, id, orphaned)
+}
+
 // RollbackJobUpdate rollbacks the specified active job update to the initial state.
 func (h *ServiceHandler) RollbackJobUpdate(
 	ctx context.Context,
 	key *api.JobUpdateKey,
 	message *string,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.rollbackJobUpdate(ctx, key, message)
-	resp := newResponse(result, err, "rollbackJobUpdate")
+	resp := h.newResponse(ctx, result, err, "rollbackJobUpdate")
 
 	defer func() {
 		h.metrics.
@@ -1576,6 +1762,7 @@ func (h *ServiceHandler) RollbackJobUpdate(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"key":     key,
 					"message": message,
@@ -1587,6 +1774,7 @@ func (h *ServiceHandler) RollbackJobUpdate(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job":       key.GetJob(),
 				"update_id": key.GetID(),
@@ -1614,17 +1802,17 @@ func (h *ServiceHandler) rollbackJobUpdate(
 
 	id, err := h.getJobID(ctx, key.GetJob())
 	if err != nil {
-		return nil, auroraErrorf("get job id: %s", err)
+		return nil, auroraErrorFrom(err, "get job id")
 	}
 
 	j, jobSpec, w, err := h.getJobAndWorkflow(ctx, id)
 	if err != nil {
-		return nil, auroraErrorf("get job: %s", err)
+		return nil, auroraErrorFrom(err, "get job")
 	}
 
 	d, err := opaquedata.Deserialize(w.GetOpaqueData())
 	if err != nil {
-		return nil, auroraErrorf("deserialize opaque data: %s", err)
+		return nil, auroraErrorFrom(err, "deserialize opaque data")
 	}
 
 	if d.UpdateID != key.GetID() {
@@ -1635,7 +1823,7 @@ func (h *ServiceHandler) rollbackJobUpdate(
 
 	status, err := ptoa.NewJobUpdateStatus(w.GetStatus().GetState(), d)
 	if err != nil {
-		return nil, auroraErrorf("new job update status: %s", err)
+		return nil, auroraErrorFrom(err, "new job update status")
 	}
 	if !_validRollbackStatuses.Has(status) {
 		return nil, auroraErrorf(
@@ -1646,13 +1834,13 @@ func (h *ServiceHandler) rollbackJobUpdate(
 	d.AppendUpdateAction(opaquedata.Rollback)
 	od, err := d.Serialize()
 	if err != nil {
-		return nil, auroraErrorf("serialize opaque data: %s", err)
+		return nil, auroraErrorFrom(err, "serialize opaque data")
 	}
 
 	prevVersion := w.GetStatus().GetPrevVersion()
 	prevConfigVersion, _, _, err := versionutil.ParseJobEntityVersion(prevVersion)
 	if err != nil {
-		return nil, auroraErrorf("parse previous job entity version: %s", err)
+		return nil, auroraErrorFrom(err, "parse previous job entity version")
 	}
 
 	var prevSpec *stateless.JobSpec
@@ -1666,7 +1854,7 @@ func (h *ServiceHandler) rollbackJobUpdate(
 	} else {
 		prevJob, err := h.getFullJobInfoByVersion(ctx, id, prevVersion)
 		if err != nil {
-			return nil, auroraErrorf("get previous job: %s", err)
+			return nil, auroraErrorFrom(err, "get previous job")
 		}
 
 		prevSpec = prevJob.GetSpec()
@@ -1688,7 +1876,7 @@ func (h *ServiceHandler) rollbackJobUpdate(
 		OpaqueData: od,
 	}
 	if _, err := h.jobClient.ReplaceJob(ctx, req); err != nil {
-		return nil, auroraErrorf("replace job: %s", err)
+		return nil, auroraErrorFrom(err, "replace job")
 	}
 	return dummyResult(), nil
 }
@@ -1700,10 +1888,11 @@ func (h *ServiceHandler) PulseJobUpdate(
 	ctx context.Context,
 	key *api.JobUpdateKey,
 ) (*api.Response, error) {
+	ctx, cid := withCorrelationID(ctx)
 
 	startTime := time.Now()
 	result, err := h.pulseJobUpdate(ctx, key)
-	resp := newResponse(result, err, "pulseJobUpdate")
+	resp := h.newResponse(ctx, result, err, "pulseJobUpdate")
 
 	defer func() {
 		h.metrics.
@@ -1718,6 +1907,7 @@ func (h *ServiceHandler) PulseJobUpdate(
 
 		if err != nil {
 			log.WithFields(log.Fields{
+				"correlation_id": cid,
 				"params": log.Fields{
 					"key": key,
 				},
@@ -1728,6 +1918,7 @@ func (h *ServiceHandler) PulseJobUpdate(
 		}
 
 		log.WithFields(log.Fields{
+			"correlation_id": cid,
 			"params": log.Fields{
 				"job":       key.GetJob(),
 				"update_id": key.GetID(),
@@ -1753,23 +1944,19 @@ func (h *ServiceHandler) pulseJobUpdate(
 
 	id, err := h.getJobID(ctx, key.GetJob())
 	if err != nil {
-		aerr := auroraErrorf("get job id: %s", err)
-		if yarpcerrors.IsNotFound(err) {
-			// Unknown update.
-			// TODO(codyg): We should support some form of update ID.
-			aerr.code(api.ResponseCodeInvalidRequest)
-		}
-		return nil, aerr
+		// An unknown update maps to an invalid request.
+		// TODO(codyg): We should support some form of update ID.
+		return nil, auroraErrorFrom(err, "get job id")
 	}
 
 	j, _, w, err := h.getJobAndWorkflow(ctx, id)
 	if err != nil {
-		return nil, auroraErrorf("get job status: %s", err)
+		return nil, auroraErrorFrom(err, "get job status")
 	}
 
 	d, err := opaquedata.Deserialize(w.GetOpaqueData())
 	if err != nil {
-		return nil, auroraErrorf("deserialize opaque data: %s", err)
+		return nil, auroraErrorFrom(err, "deserialize opaque data")
 	}
 
 	if d.UpdateID != key.GetID() {
@@ -1779,7 +1966,7 @@ func (h *ServiceHandler) pulseJobUpdate(
 
 	status, err := ptoa.NewJobUpdateStatus(w.GetStatus().GetState(), d)
 	if err != nil {
-		return nil, auroraErrorf("new job update status: %s", err)
+		return nil, auroraErrorFrom(err, "new job update status")
 	}
 
 	// Only resume if we're in a valid status. Else, pulseJobUpdate is
@@ -1788,7 +1975,7 @@ func (h *ServiceHandler) pulseJobUpdate(
 		d.AppendUpdateAction(opaquedata.Pulse)
 		od, err := d.Serialize()
 		if err != nil {
-			return nil, auroraErrorf("serialize opaque data: %s", err)
+			return nil, auroraErrorFrom(err, "serialize opaque data")
 		}
 
 		req := &statelesssvc.ResumeJobWorkflowRequest{
@@ -1797,7 +1984,7 @@ func (h *ServiceHandler) pulseJobUpdate(
 			OpaqueData: od,
 		}
 		if _, err := h.jobClient.ResumeJobWorkflow(ctx, req); err != nil {
-			return nil, auroraErrorf("resume job workflow: %s", err)
+			return nil, auroraErrorFrom(err, "resume job workflow")
 		}
 	}
 
@@ -2103,6 +2290,42 @@ func (h *ServiceHandler) queryJobCache(
 	return resp.GetResult(), nil
 }
 
+// queryJobSummaries pages through QueryJobs and returns the summaries of
+// all bridge jobs belonging to role.
+func (h *ServiceHandler) queryJobSummaries(
+	ctx context.Context,
+	role string,
+) ([]*stateless.JobSummary, error) {
+	labels := append(
+		label.BuildPartialAuroraJobKeyLabels(role, "", ""),
+		common.BridgeJobLabel,
+	)
+
+	var summaries []*stateless.JobSummary
+	var offset uint32
+	for {
+		resp, err := h.jobClient.QueryJobs(ctx, &statelesssvc.QueryJobsRequest{
+			Spec: &stateless.QuerySpec{
+				Pagination: &query.PaginationSpec{
+					Offset: offset,
+					Limit:  h.config.QueryJobsLimit,
+				},
+				Labels: labels,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, resp.GetRecords()...)
+
+		offset += uint32(len(resp.GetRecords()))
+		if len(resp.GetRecords()) == 0 ||
+			offset >= resp.GetPagination().GetTotal() {
+			return summaries, nil
+		}
+	}
+}
+
 // getJobIDsFromTaskQuery queries peloton job ids based on aurora TaskQuery.
 // Note that it will not throw error when no job is found. The current
 // behavior for querying:
@@ -2151,18 +2374,19 @@ func (h *ServiceHandler) getJobIDsFromTaskQuery(
 }
 
 // getJobIDsFromRoleCache queries peloton job ids based on aurora JobKey role.
-// It will first look at the internal job id cache first,
+// It will first look at the internal job id cache first, and returns
+// whether the job ids are served from the cache.
 func (h *ServiceHandler) getJobIDsFromRoleCache(
 	ctx context.Context,
 	role string,
-) ([]*peloton.JobID, error) {
+) ([]*peloton.JobID, bool, error) {
 	if ids := h.jobIdCache.GetJobIDs(role); len(ids) > 0 {
-		return ids, nil
+		return ids, true, nil
 	}
 
 	jobCache, err := h.queryJobCache(ctx, role, "", "")
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	h.jobIdCache.PopulateFromJobCache(role, jobCache)
@@ -2171,7 +2395,71 @@ func (h *ServiceHandler) getJobIDsFromRoleCache(
 	for _, cache := range jobCache {
 		jobIDs = append(jobIDs, cache.GetJobId())
 	}
-	return jobIDs, nil
+	return jobIDs, false, nil
+}
+
+// mapJobIDsFromRole queries peloton job ids based on aurora JobKey role,
+// or all job ids if role is not set, and maps them using f. Outputs of
+// jobs for which f returns a not-found error are nil.
+//
+// Job ids served from the job id cache may be stale, e.g. if jobs were
+// deleted and recreated while jobmgr failed over to a new leader. If any
+// of them is not found, the cache entries of the role are invalidated
+// and the job ids are queried and mapped once more.
+func (h *ServiceHandler) mapJobIDsFromRole(
+	ctx context.Context,
+	role *string,
+	f concurrency.MapperFunc,
+	workers int,
+) ([]interface{}, *auroraError) {
+	for retry := false; ; retry = true {
+		var jobIDs []*peloton.JobID
+		var cached bool
+		var err error
+
+		if role != nil && *role != "" {
+			jobIDs, cached, err = h.getJobIDsFromRoleCache(ctx, *role)
+		} else {
+			jobIDs, err = h.queryJobIDs(ctx, "", "", "")
+		}
+
+		if err != nil {
+			return nil, auroraErrorFrom(err, "get job ids from role")
+		}
+
+		var inputs []interface{}
+		for _, j := range jobIDs {
+			inputs = append(inputs, j)
+		}
+
+		notFound := atomic.NewBool(false)
+		mapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+			output, err := f(ctx, input)
+			if yarpcerrors.IsNotFound(err) {
+				notFound.Store(true)
+				return nil, nil
+			}
+			return output, err
+		}
+
+		outputs, err := concurrency.Map(
+			ctx,
+			concurrency.MapperFunc(mapper),
+			inputs,
+			workers)
+		if err != nil {
+			return nil, auroraErrorf(err.Error()).
+				code(toAuroraResponseCode(err))
+		}
+
+		if !cached || !notFound.Load() || retry {
+			return outputs, nil
+		}
+
+		log.WithField("role", *role).
+			Info("Job id cache is stale, invalidating it")
+		h.jobIdCache.Invalidate(*role)
+	}
 }
 
 // matchJobUpdateID matches a jobID workflow against updateID. Returns the entity
@@ -2185,11 +2473,11 @@ func (h *ServiceHandler) matchJobUpdateID(
 
 	j, _, w, err := h.getJobAndWorkflow(ctx, jobID)
 	if err != nil {
-		return nil, auroraErrorf("get job status: %s", err)
+		return nil, auroraErrorFrom(err, "get job status")
 	}
 	d, err := opaquedata.Deserialize(w.GetOpaqueData())
 	if err != nil {
-		return nil, auroraErrorf("deserialize opaque data: %s", err)
+		return nil, auroraErrorFrom(err, "deserialize opaque data")
 	}
 	if d.UpdateID != updateID {
 		return nil, auroraErrorf("update id does not match current update").
@@ -2198,6 +2486,41 @@ func (h *ServiceHandler) matchJobUpdateID(
 	return j.GetVersion(), nil
 }
 
+// retryOnVersionConflict looks up the entity version of the workflow matching
+// updateID and invokes action with it. Since the job version may change
+// between the lookup and the workflow action, an ABORTED error returned by
+// action causes the version to be re-fetched and the action to be re-issued,
+// up to UpdateActionMaxRetries times.
+func (h *ServiceHandler) retryOnVersionConflict(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	updateID string,
+	action func(v *peloton.EntityVersion) error,
+	desc string,
+) *auroraError {
+	var err error
+	for i := 0; i <= h.config.updateActionMaxRetries(); i++ {
+		v, aerr := h.matchJobUpdateID(ctx, jobID, updateID)
+		if aerr != nil {
+			return aerr
+		}
+		err = action(v)
+		if err == nil {
+			return nil
+		}
+		if !yarpcerrors.IsAborted(err) {
+			break
+		}
+		log.WithFields(log.Fields{
+			"job_id":    jobID.GetValue(),
+			"update_id": updateID,
+			"version":   v.GetValue(),
+			"attempt":   i + 1,
+		}).WithError(err).Info("job version changed, retrying " + desc)
+	}
+	return auroraErrorFrom(err, desc)
+}
+
 // getJobInfo calls jobmgr to get JobInfo based on JobID.
 func (h *ServiceHandler) getJobInfo(
 	ctx context.Context,
@@ -2267,17 +2590,14 @@ func (h *ServiceHandler) queryPods(
 
 	var inputs []interface{}
 	for i := uint32(0); i < instanceCount; i++ {
-		inputs = append(inputs, fmt.Sprintf("%s-%d", jobID.GetValue(), i))
+		inputs = append(inputs, podname.FormatPodName(jobID.GetValue(), i))
 	}
 
 	workers := h.config.getTasksWithoutConfigsWorkers(len(inputs))
 
 	f := func(ctx context.Context, input interface{}) (interface{}, error) {
-		podName := input.(string)
 		req := &podsvc.GetPodRequest{
-			PodName: &peloton.PodName{
-				Value: podName,
-			},
+			PodName:    input.(*peloton.PodName),
 			StatusOnly: false,
 			Limit:      1,
 		}
@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+	"github.com/uber/peloton/.gen/thrift/aurora/bridge"
+
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// BulkStartJobUpdate starts the updates of a set of related jobs as a
+// unit. All requests are validated before any update is started, and if
+// starting an update fails, the updates started before it are aborted on a
+// best-effort basis. The result of each request is returned in the order
+// of the requests, along with an overall response aggregating all failures.
+func (h *ServiceHandler) BulkStartJobUpdate(
+	ctx context.Context,
+	requests []*api.JobUpdateRequest,
+	message *string,
+) (*bridge.BulkStartJobUpdateResponse, error) {
+	ctx, cid := withCorrelationID(ctx)
+
+	startTime := time.Now()
+	results, err := h.bulkStartJobUpdate(ctx, requests, message)
+	resp := &bridge.BulkStartJobUpdateResponse{
+		Response: h.newResponse(ctx, nil, err, "bulkStartJobUpdate"),
+		Results:  results,
+	}
+
+	defer func() {
+		h.metrics.
+			Procedures[ProcedureBulkStartJobUpdate].
+			ResponseCodes[resp.GetResponse().GetResponseCode()].
+			Calls.Inc(1)
+
+		h.metrics.
+			Procedures[ProcedureBulkStartJobUpdate].
+			ResponseCodes[resp.GetResponse().GetResponseCode()].
+			CallLatency.Record(time.Since(startTime))
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"correlation_id": cid,
+				"params": log.Fields{
+					"jobs":    len(requests),
+					"message": message,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("BulkStartJobUpdate error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"correlation_id": cid,
+			"params": log.Fields{
+				"jobs":    len(requests),
+				"message": message,
+			},
+		}).Info("BulkStartJobUpdate success")
+	}()
+
+	return resp, nil
+}
+
+func (h *ServiceHandler) bulkStartJobUpdate(
+	ctx context.Context,
+	requests []*api.JobUpdateRequest,
+	message *string,
+) ([]*bridge.BulkJobUpdateResult, *auroraError) {
+	results := make([]*bridge.BulkJobUpdateResult, len(requests))
+	for i, request := range requests {
+		results[i] = &bridge.BulkJobUpdateResult{
+			Job:     request.GetTaskConfig().GetJob(),
+			Aborted: ptr.Bool(false),
+		}
+	}
+
+	if err := h.validateBulkJobUpdateRequests(ctx, requests, results); err != nil {
+		return results, auroraErrorf("validate job updates: %s", err).
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	var started []*bridge.BulkJobUpdateResult
+	for i, request := range requests {
+		result, aerr := h.startJobUpdate(ctx, request, message)
+		results[i].Response = h.newResponse(ctx, result, aerr, "startJobUpdate")
+		if aerr == nil {
+			started = append(started, results[i])
+			continue
+		}
+
+		err := fmt.Errorf("start job update of %s: %s",
+			atop.NewJobName(results[i].GetJob()), aerr.msg)
+		err = multierr.Append(err, h.abortBulkJobUpdates(ctx, started, message))
+		return results, auroraErrorf(err.Error()).code(aerr.responseCode)
+	}
+
+	return results, nil
+}
+
+// validateBulkJobUpdateRequests validates all requests of a
+// BulkStartJobUpdate call, setting the response of the invalid ones.
+func (h *ServiceHandler) validateBulkJobUpdateRequests(
+	ctx context.Context,
+	requests []*api.JobUpdateRequest,
+	results []*bridge.BulkJobUpdateResult,
+) error {
+	var errs error
+	jobs := make(map[string]struct{})
+	for i, request := range requests {
+		name := atop.NewJobName(results[i].GetJob())
+		aerr := h.validateJobUpdateRequest(request)
+		if _, ok := jobs[name]; ok && aerr == nil {
+			aerr = auroraErrorf("duplicate update of job %s", name).
+				code(api.ResponseCodeInvalidRequest)
+		}
+		jobs[name] = struct{}{}
+
+		if aerr != nil {
+			results[i].Response = h.newResponse(ctx, nil, aerr, "startJobUpdate")
+			errs = multierr.Append(errs,
+				fmt.Errorf("invalid job update of %s: %s", name, aerr.msg))
+		}
+	}
+	return errs
+}
+
+// abortBulkJobUpdates aborts the updates started by a BulkStartJobUpdate
+// call, in reverse order of starting them. It returns the aggregated errors
+// of the updates which failed to be aborted.
+func (h *ServiceHandler) abortBulkJobUpdates(
+	ctx context.Context,
+	started []*bridge.BulkJobUpdateResult,
+	message *string,
+) error {
+	var errs error
+	for i := len(started) - 1; i >= 0; i-- {
+		key := started[i].GetResponse().GetResult().GetStartJobUpdateResult().GetKey()
+		if _, aerr := h.abortJobUpdate(ctx, key, message); aerr != nil {
+			errs = multierr.Append(errs, fmt.Errorf(
+				"abort job update of %s: %s",
+				atop.NewJobName(started[i].GetJob()), aerr.msg))
+			continue
+		}
+		started[i].Aborted = ptr.Bool(true)
+	}
+	return errs
+}
@@ -16,10 +16,16 @@ package aurorabridge
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
@@ -28,15 +34,21 @@ import (
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
 
 	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/cache"
 	"github.com/uber/peloton/pkg/aurorabridge/common"
 	"github.com/uber/peloton/pkg/aurorabridge/label"
 	"github.com/uber/peloton/pkg/aurorabridge/opaquedata"
+	"github.com/uber/peloton/pkg/aurorabridge/ptoa"
+	pelotoncommon "github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/concurrency"
 	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
+	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	"github.com/uber/peloton/pkg/common/util/podname"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"go.uber.org/thriftrw/ptr"
 	"go.uber.org/yarpc/yarpcerrors"
 )
@@ -48,15 +60,89 @@ func (h *ServiceHandler) startJobUpdate(
 	message *string,
 ) (*api.Result, *auroraError) {
 
-	respoolID, err := h.respoolLoader.Load(
-		ctx,
-		label.IsGpuConfig(
-			request.GetTaskConfig().GetMetadata(),
-			request.GetTaskConfig().GetResources(),
-		),
-	)
+	if aerr := h.validateJobUpdateRequest(request); aerr != nil {
+		return nil, aerr
+	}
+
+	if h.config.StartJobUpdateDedupWindow == 0 {
+		return h.createOrReplaceJob(ctx, request, message)
+	}
+
+	token, err := newUpdateToken(request, message)
+	if err != nil {
+		return nil, auroraErrorFrom(err, "new update token")
+	}
+	if result, ok := h.updateTokenCache.Get(token, time.Now()); ok {
+		log.WithFields(log.Fields{
+			"job_key": request.GetTaskConfig().GetJob(),
+			"update":  result.GetStartJobUpdateResult().GetKey(),
+		}).Info("Deduplicated retried StartJobUpdate request")
+		return result, nil
+	}
+
+	result, aerr := h.createOrReplaceJob(ctx, request, message)
+	if aerr != nil {
+		return nil, aerr
+	}
+	h.updateTokenCache.Add(token, result, time.Now())
+	return result, nil
+}
+
+// validateJobUpdateRequest returns an INVALID_REQUEST error if the
+// StartJobUpdate request cannot be applied.
+func (h *ServiceHandler) validateJobUpdateRequest(
+	request *api.JobUpdateRequest,
+) *auroraError {
+	if err := validateUpdateInstances(request); err != nil {
+		return auroraErrorFrom(err, "invalid update instances").
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	if max := h.config.maxInstancesPerJob(); max > 0 &&
+		uint32(request.GetInstanceCount()) > max {
+		return auroraErrorf(
+			"instance count %d exceeds maximum of %d instances per job",
+			request.GetInstanceCount(), max).
+			code(api.ResponseCodeInvalidRequest)
+	}
+	return nil
+}
+
+// newUpdateToken returns the idempotency token of a StartJobUpdate request
+// scoped to the job of the request. The token is taken from the update
+// metadata if the client set one, otherwise it is a hash of the request.
+func newUpdateToken(
+	request *api.JobUpdateRequest,
+	message *string,
+) (string, error) {
+	jobName := atop.NewJobName(request.GetTaskConfig().GetJob())
+	for _, m := range request.GetMetadata() {
+		if m.GetKey() == common.IdempotencyTokenKey && m.GetValue() != "" {
+			return jobName + "/" + m.GetValue(), nil
+		}
+	}
+
+	b, err := json.Marshal(struct {
+		Request *api.JobUpdateRequest `json:"request"`
+		Message *string               `json:"message"`
+	}{request, message})
 	if err != nil {
-		return nil, auroraErrorf("load respool: %s", err)
+		return "", fmt.Errorf("json marshal: %s", err)
+	}
+	sum := sha256.Sum256(b)
+	return jobName + "/" + hex.EncodeToString(sum[:]), nil
+}
+
+// createOrReplaceJob creates the job of a StartJobUpdate request if it
+// does not exist, and replaces the job spec otherwise.
+func (h *ServiceHandler) createOrReplaceJob(
+	ctx context.Context,
+	request *api.JobUpdateRequest,
+	message *string,
+) (*api.Result, *auroraError) {
+	respoolID, err := h.loadRespool(ctx, request.GetTaskConfig())
+	if err != nil {
+		return nil, auroraErrorFrom(err, "load respool")
 	}
 
 	jobKey := request.GetTaskConfig().GetJob()
@@ -65,15 +151,16 @@ func (h *ServiceHandler) startJobUpdate(
 		request,
 		respoolID,
 		h.config.ThermosExecutor,
+		h.config.JobSLADefaults,
 	)
 	if err != nil {
-		return nil, auroraErrorf("new job spec: %s", err)
+		return nil, auroraErrorFrom(err, "new job spec")
 	}
 
 	d := opaquedata.NewDataFromJobUpdateRequest(request, message)
 	od, err := d.Serialize()
 	if err != nil {
-		return nil, auroraErrorf("serialize opaque data: %s", err)
+		return nil, auroraErrorFrom(err, "serialize opaque data")
 	}
 
 	createReq := &statelesssvc.CreateJobRequest{
@@ -96,7 +183,15 @@ func (h *ServiceHandler) startJobUpdate(
 	id, err := h.getJobID(ctx, jobKey)
 	if err != nil {
 		if !yarpcerrors.IsNotFound(err) {
-			return nil, auroraErrorf("get job id: %s", err)
+			return nil, auroraErrorFrom(err, "get job id")
+		}
+
+		if err := validateUpdateInstanceRanges(request, nil); err != nil {
+			return nil, auroraErrorFrom(err, "invalid update instances")
+		}
+
+		if aerr := h.checkRespoolCapacity(ctx, respoolID, nil, jobSpec); aerr != nil {
+			return nil, aerr
 		}
 
 		// Invalidate job_id cache for the particular role after createJob()
@@ -104,18 +199,31 @@ func (h *ServiceHandler) startJobUpdate(
 		defer h.jobIdCache.Invalidate(jobKey.GetRole())
 
 		// Job does not exist, create the job.
-		if aerr := h.createJob(ctx, createReq); aerr != nil {
+		existingID, aerr := h.createJob(ctx, createReq, jobKey)
+		if aerr != nil {
 			return nil, aerr
 		}
+		if existingID == nil {
+			return updateResult, nil
+		}
 
-		return updateResult, nil
+		// Job was created in the meantime, update the job instead.
+		id = existingID
 	}
 
 	// Job exists in job_name_to_id table
 	v, err := h.getCurrentJobVersion(ctx, id)
 	if err != nil {
 		if !yarpcerrors.IsNotFound(err) {
-			return nil, auroraErrorf("get current job version: %s", err)
+			return nil, auroraErrorFrom(err, "get current job version")
+		}
+
+		if err := validateUpdateInstanceRanges(request, nil); err != nil {
+			return nil, auroraErrorFrom(err, "invalid update instances")
+		}
+
+		if aerr := h.checkRespoolCapacity(ctx, respoolID, nil, jobSpec); aerr != nil {
+			return nil, aerr
 		}
 
 		// Invalidate job_id cache for the particular role after createJob()
@@ -124,18 +232,35 @@ func (h *ServiceHandler) startJobUpdate(
 
 		// Job was present in job_name_to_id table, but did not exist,
 		// create the job.
-		if aerr := h.createJob(ctx, createReq); aerr != nil {
+		existingID, aerr := h.createJob(ctx, createReq, jobKey)
+		if aerr != nil {
 			return nil, aerr
 		}
+		if existingID == nil {
+			return updateResult, nil
+		}
+
+		// Job was created in the meantime, update the job instead.
+		id = existingID
+		v, err = h.getCurrentJobVersion(ctx, id)
+		if err != nil {
+			return nil, auroraErrorFrom(err, "get current job version")
+		}
+	}
 
-		return updateResult, nil
+	if aerr := h.checkUpdateOverride(ctx, request, id); aerr != nil {
+		return nil, aerr
 	}
 
 	// Job exists in job_name_to_id table and the job id is present,
 	// update the job.
 	updateJobSpec, err := h.createJobSpecForUpdate(ctx, request, id, jobSpec)
 	if err != nil {
-		return nil, auroraErrorf("create job spec for update: %s", err)
+		return nil, auroraErrorFrom(err, "create job spec for update")
+	}
+
+	if aerr := h.checkRespoolCapacity(ctx, respoolID, id, updateJobSpec); aerr != nil {
+		return nil, aerr
 	}
 
 	replaceReq := &statelesssvc.ReplaceJobRequest{
@@ -152,6 +277,236 @@ func (h *ServiceHandler) startJobUpdate(
 	return updateResult, nil
 }
 
+// _completedUpdateStatuses enumerates the statuses of updates which are no
+// longer in progress, and hence can always be replaced by StartJobUpdate.
+var _completedUpdateStatuses = common.NewJobUpdateStatusSet(
+	api.JobUpdateStatusRolledForward,
+	api.JobUpdateStatusRolledBack,
+	api.JobUpdateStatusAborted,
+	api.JobUpdateStatusError,
+	api.JobUpdateStatusFailed,
+)
+
+// checkUpdateOverride returns an INVALID_REQUEST error if the job has an
+// update in progress whose status is not overridable, unless the request
+// forces the update. The check is skipped if no overridable statuses are
+// configured.
+func (h *ServiceHandler) checkUpdateOverride(
+	ctx context.Context,
+	request *api.JobUpdateRequest,
+	id *peloton.JobID,
+) *auroraError {
+	if h.overridableUpdateStatuses == nil || isForceUpdate(request) {
+		return nil
+	}
+
+	_, _, w, err := h.getJobAndWorkflow(ctx, id)
+	if err != nil {
+		return auroraErrorFrom(err, "get job")
+	}
+	if w.GetStatus() == nil {
+		return nil
+	}
+
+	d, err := opaquedata.Deserialize(w.GetOpaqueData())
+	if err != nil {
+		return auroraErrorFrom(err, "deserialize opaque data")
+	}
+	status, err := ptoa.NewJobUpdateStatus(w.GetStatus().GetState(), d)
+	if err != nil {
+		return auroraErrorFrom(err, "new job update status")
+	}
+
+	if _completedUpdateStatuses.Has(status) ||
+		h.overridableUpdateStatuses.Has(status) {
+		return nil
+	}
+	return auroraErrorf(
+		"job has an update in progress with status %s, which can only be "+
+			"overridden with %s metadata, overridable statuses: %s",
+		status, common.ForceUpdateKey, h.overridableUpdateStatuses).
+		code(api.ResponseCodeInvalidRequest)
+}
+
+// isForceUpdate returns whether the update metadata of the request forces
+// an in-progress update to be replaced.
+func isForceUpdate(request *api.JobUpdateRequest) bool {
+	for _, m := range request.GetMetadata() {
+		if m.GetKey() == common.ForceUpdateKey && m.GetValue() == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadRespool returns the resource pool of the job of the task config.
+// The resource pool resolved for the role and tier of the job is reused
+// until RespoolCacheTTL expires, after which it is resolved again. Failed
+// resolutions are not cached, so the next request resolves it again.
+func (h *ServiceHandler) loadRespool(
+	ctx context.Context,
+	taskConfig *api.TaskConfig,
+) (*peloton.ResourcePoolID, error) {
+	isGpu := label.IsGpuConfig(
+		taskConfig.GetMetadata(),
+		taskConfig.GetResources(),
+	)
+	if h.config.RespoolCacheTTL == 0 {
+		return h.respoolLoader.Load(ctx, isGpu)
+	}
+
+	key := cache.RespoolKey{
+		Role: taskConfig.GetJob().GetRole(),
+		Tier: taskConfig.GetTier(),
+		GPU:  isGpu,
+	}
+	if id, ok := h.respoolCache.Get(key, time.Now()); ok {
+		h.metrics.RespoolCacheHit.Inc(1)
+		return id, nil
+	}
+	h.metrics.RespoolCacheMiss.Inc(1)
+
+	id, err := h.respoolLoader.Load(ctx, isGpu)
+	if err != nil {
+		return nil, err
+	}
+	h.respoolCache.Add(key, id, time.Now())
+	return id, nil
+}
+
+// checkRespoolCapacity returns an INVALID_REQUEST error if CheckRespoolCapacity
+// is enabled and the resources added by the job spec exceed the available
+// reservation of the resource pool, i.e. the reservation which is not
+// allocated yet. For an existing job, id is set and only the resources
+// exceeding the current spec of the job are added, as the resources of the
+// current spec are already part of the allocation. The check is skipped if
+// the resource pool or the current job spec cannot be fetched.
+func (h *ServiceHandler) checkRespoolCapacity(
+	ctx context.Context,
+	respoolID *peloton.ResourcePoolID,
+	id *peloton.JobID,
+	jobSpec *stateless.JobSpec,
+) *auroraError {
+	if !h.config.CheckRespoolCapacity {
+		return nil
+	}
+
+	info, err := h.respoolLoader.Get(ctx, respoolID)
+	if err != nil {
+		log.WithField("respool_id", respoolID.GetValue()).
+			WithError(err).
+			Warn("Skipped resource pool capacity check")
+		return nil
+	}
+
+	var current *stateless.JobSpec
+	if id != nil {
+		_, current, _, err = h.getJobAndWorkflow(ctx, id)
+		if err != nil {
+			log.WithField("job_id", id.GetValue()).
+				WithError(err).
+				Warn("Skipped resource pool capacity check")
+			return nil
+		}
+	}
+
+	allocation := make(map[string]float64)
+	for _, u := range info.GetUsage() {
+		allocation[u.GetKind()] = u.GetAllocation()
+	}
+
+	demand := getJobResourceDemand(jobSpec)
+	currentDemand := getJobResourceDemand(current)
+	for _, r := range info.GetConfig().GetResources() {
+		available := r.GetReservation() - allocation[r.GetKind()]
+		d := demand[r.GetKind()] - currentDemand[r.GetKind()]
+		if d > 0 && d > available {
+			return auroraErrorf(
+				"job requires %.2f additional %s, exceeding the available "+
+					"reservation %.2f of resource pool %s",
+				d, r.GetKind(), available, info.GetPath().GetValue()).
+				code(api.ResponseCodeInvalidRequest)
+		}
+	}
+	return nil
+}
+
+// getJobResourceDemand returns the resources required by all instances of
+// the job, keyed by resource kind. Instances with an instance spec require
+// the resources of their instance spec instead of the default spec.
+func getJobResourceDemand(jobSpec *stateless.JobSpec) map[string]float64 {
+	demand := make(map[string]float64)
+	defaults := jobSpec.GetInstanceCount()
+	for i, spec := range jobSpec.GetInstanceSpec() {
+		if i >= jobSpec.GetInstanceCount() || len(spec.GetContainers()) == 0 {
+			continue
+		}
+		addPodResourceDemand(demand, spec, 1)
+		defaults--
+	}
+	addPodResourceDemand(demand, jobSpec.GetDefaultSpec(), float64(defaults))
+	return demand
+}
+
+// addPodResourceDemand adds the resources required by the given number of
+// pods of the pod spec to demand.
+func addPodResourceDemand(
+	demand map[string]float64,
+	spec *pod.PodSpec,
+	pods float64,
+) {
+	for _, c := range spec.GetContainers() {
+		r := c.GetResource()
+		demand[pelotoncommon.CPU] += r.GetCpuLimit() * pods
+		demand[pelotoncommon.MEMORY] += r.GetMemLimitMb() * pods
+		demand[pelotoncommon.DISK] += r.GetDiskLimitMb() * pods
+		demand[pelotoncommon.GPU] += r.GetGpuLimit() * pods
+	}
+}
+
+// validateUpdateInstances checks that the instance count of the update and
+// the instance ranges in UpdateOnlyTheseInstances field are well formed.
+func validateUpdateInstances(req *api.JobUpdateRequest) error {
+	instances := req.GetInstanceCount()
+	if instances < 0 {
+		return fmt.Errorf("negative instance count %d", instances)
+	}
+
+	for _, r := range req.GetSettings().GetUpdateOnlyTheseInstances() {
+		if r.GetFirst() < 0 || r.GetFirst() > r.GetLast() {
+			return fmt.Errorf(
+				"invalid instance range [%d, %d]", r.GetFirst(), r.GetLast())
+		}
+	}
+	return nil
+}
+
+// validateUpdateInstanceRanges returns an InvalidArgument error if an
+// instance range in UpdateOnlyTheseInstances field names an instance which
+// neither exists nor is added by the update. A scale-down may pin the
+// instances it removes, so the ranges are checked against the larger of
+// the current and the new instance count.
+func validateUpdateInstanceRanges(
+	req *api.JobUpdateRequest,
+	podStates map[uint32]*podStateSpec,
+) error {
+	instances := uint32(req.GetInstanceCount())
+	for i := range podStates {
+		if i >= instances {
+			instances = i + 1
+		}
+	}
+
+	for _, r := range req.GetSettings().GetUpdateOnlyTheseInstances() {
+		if uint32(r.GetLast()) >= instances {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"instance range [%d, %d] exceeds instance count %d",
+				r.GetFirst(), r.GetLast(), instances)
+		}
+	}
+	return nil
+}
+
 // createJobSpecForUpdate generates JobSpec which supports pinned instances.
 func (h *ServiceHandler) createJobSpecForUpdate(
 	ctx context.Context,
@@ -159,7 +514,6 @@ func (h *ServiceHandler) createJobSpecForUpdate(
 	jobID *peloton.JobID,
 	genJobSpec *stateless.JobSpec,
 ) (*stateless.JobSpec, error) {
-	genPodSpec := genJobSpec.GetDefaultSpec()
 	instances := req.GetInstanceCount()
 
 	// Get current pod state and spec for the job
@@ -168,6 +522,20 @@ func (h *ServiceHandler) createJobSpecForUpdate(
 		return nil, errors.Wrap(err, "get current pods")
 	}
 
+	if err := validateUpdateInstanceRanges(req, podStates); err != nil {
+		return nil, err
+	}
+
+	if err := validateVolumeOrphans(req, podStates); err != nil {
+		return nil, err
+	}
+
+	// Aurora task configs do not carry persistent volumes, so take the
+	// volume from the current pods to keep it attached to the same
+	// instance ids.
+	genJobSpec = carryPersistentVolume(genJobSpec, podStates)
+	genPodSpec := genJobSpec.GetDefaultSpec()
+
 	// Get a map of instances that are in terminal states
 	terminalInstances := getTerminalInstances(req, podStates)
 
@@ -194,7 +562,106 @@ func (h *ServiceHandler) createJobSpecForUpdate(
 		specChangedInstances,
 	)
 
-	return newJobSpec, nil
+	return preservePersistentVolumes(newJobSpec, instances, podStates), nil
+}
+
+// validateVolumeOrphans returns an InvalidArgument error if the update
+// removes instances holding a persistent volume, unless the update metadata
+// explicitly allows it.
+func validateVolumeOrphans(
+	req *api.JobUpdateRequest,
+	podStates map[uint32]*podStateSpec,
+) error {
+	for _, m := range req.GetMetadata() {
+		if m.GetKey() == common.AllowVolumeOrphanKey && m.GetValue() == "true" {
+			return nil
+		}
+	}
+
+	var orphaned []uint32
+	for i, s := range podStates {
+		if i >= uint32(req.GetInstanceCount()) && s.podSpec.GetVolume() != nil {
+			orphaned = append(orphaned, i)
+		}
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+	sort.Slice(orphaned, func(i, j int) bool { return orphaned[i] < orphaned[j] })
+	return yarpcerrors.InvalidArgumentErrorf(
+		"removing instances %v orphans their persistent volumes, "+
+			"set %s metadata to allow it",
+		orphaned, common.AllowVolumeOrphanKey)
+}
+
+// carryPersistentVolume returns a copy of the generated job spec whose
+// default spec holds the persistent volume of the lowest existing instance
+// which has one. The generated job spec is returned as is if it already
+// has a volume or no current pod has one.
+func carryPersistentVolume(
+	genJobSpec *stateless.JobSpec,
+	podStates map[uint32]*podStateSpec,
+) *stateless.JobSpec {
+	if genJobSpec.GetDefaultSpec().GetVolume() != nil {
+		return genJobSpec
+	}
+
+	var volume *pod.PersistentVolumeSpec
+	lowest := uint32(math.MaxUint32)
+	for i, s := range podStates {
+		if v := s.podSpec.GetVolume(); v != nil && i < lowest {
+			volume = v
+			lowest = i
+		}
+	}
+	if volume == nil {
+		return genJobSpec
+	}
+
+	newJobSpec := proto.Clone(genJobSpec).(*stateless.JobSpec)
+	if newJobSpec.DefaultSpec == nil {
+		newJobSpec.DefaultSpec = &pod.PodSpec{}
+	}
+	newJobSpec.DefaultSpec.Volume = volume
+	return newJobSpec
+}
+
+// preservePersistentVolumes overrides the instance spec of every kept
+// instance whose persistent volume would otherwise change, so that each
+// instance id stays attached to its current volume across the update.
+func preservePersistentVolumes(
+	jobSpec *stateless.JobSpec,
+	instances int32,
+	podStates map[uint32]*podStateSpec,
+) *stateless.JobSpec {
+	newJobSpec := jobSpec
+	for i := uint32(0); i < uint32(instances); i++ {
+		s, ok := podStates[i]
+		if !ok || s.podSpec.GetVolume() == nil {
+			continue
+		}
+
+		instanceSpec := newJobSpec.GetInstanceSpec()[i]
+		merged := taskconfig.MergePodSpec(newJobSpec.GetDefaultSpec(), instanceSpec)
+		if proto.Equal(merged.GetVolume(), s.podSpec.GetVolume()) {
+			continue
+		}
+
+		if newJobSpec == jobSpec {
+			newJobSpec = proto.Clone(jobSpec).(*stateless.JobSpec)
+			if newJobSpec.InstanceSpec == nil {
+				newJobSpec.InstanceSpec = make(map[uint32]*pod.PodSpec)
+			}
+		}
+		if instanceSpec == nil {
+			instanceSpec = getInstanceSpecLabelOnly(newJobSpec.GetDefaultSpec())
+		} else {
+			instanceSpec = proto.Clone(instanceSpec).(*pod.PodSpec)
+		}
+		instanceSpec.Volume = s.podSpec.GetVolume()
+		newJobSpec.InstanceSpec[i] = instanceSpec
+	}
+	return newJobSpec
 }
 
 // createJobSpecForUpdateInternal is implementation of createJobSpecForUpdate,
@@ -445,34 +912,97 @@ func (h *ServiceHandler) getSpecChangedInstances(
 }
 
 // createJob calls CreateJob API using the input CreateJobRequest.
+//
+// If the job already exists, e.g. a retried request whose previous attempt
+// created the job, the job id is resolved again using jobKey and returned,
+// such that the caller can replace the existing job instead. A nil job id
+// is returned if the job is created.
+//
+// If the job cannot be resolved either, e.g. it is racing with the deletion
+// of the job, creating the job is retried up to CreateJobConflictMaxRetries
+// times with an exponential backoff.
 func (h *ServiceHandler) createJob(
 	ctx context.Context,
 	req *statelesssvc.CreateJobRequest,
-) *auroraError {
-	if _, err := h.jobClient.CreateJob(ctx, req); err != nil {
-		if yarpcerrors.IsAlreadyExists(err) {
-			return auroraErrorf(
-				"create job: %s", err).
-				code(api.ResponseCodeInvalidRequest)
+	jobKey *api.JobKey,
+) (*peloton.JobID, *auroraError) {
+	var err error
+	delay := h.config.CreateJobConflictRetryInterval
+	for i := 0; i <= h.config.CreateJobConflictMaxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, auroraErrorf("create job: %s", ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		if _, err = h.jobClient.CreateJob(ctx, req); err == nil {
+			return nil, nil
+		}
+		if !yarpcerrors.IsAlreadyExists(err) {
+			return nil, auroraErrorFrom(err, "create job")
+		}
+
+		id, idErr := h.getJobID(ctx, jobKey)
+		if idErr == nil {
+			return id, nil
 		}
-		return auroraErrorf("create job: %s", err)
+		log.WithFields(log.Fields{
+			"job_key": jobKey,
+			"attempt": i + 1,
+		}).WithError(idErr).Info("job already exists but cannot be resolved")
 	}
-	return nil
+	return nil, auroraErrorFrom(err, "create job")
 }
 
 // replaceJob calls ReplaceJob API using the input ReplaceJobRequest.
+// The job version returned by ReplaceJob is verified to descend from the
+// version the request was made against.
 func (h *ServiceHandler) replaceJob(
 	ctx context.Context,
 	req *statelesssvc.ReplaceJobRequest,
 ) *auroraError {
-	if _, err := h.jobClient.ReplaceJob(ctx, req); err != nil {
-		if yarpcerrors.IsAborted(err) {
-			// Upgrade conflict.
-			return auroraErrorf(
-				"replace job: %s", err).
-				code(api.ResponseCodeInvalidRequest)
-		}
-		return auroraErrorf("replace job: %s", err)
+	resp, err := h.jobClient.ReplaceJob(ctx, req)
+	if err != nil {
+		// An upgrade conflict maps to an invalid request.
+		return auroraErrorFrom(err, "replace job")
+	}
+
+	// Older jobmgr versions do not return the new job version.
+	if resp.GetVersion().GetValue() == "" {
+		return nil
+	}
+	if err := checkVersionLineage(req.GetVersion(), resp.GetVersion()); err != nil {
+		return auroraErrorf("replace job: %s", err).
+			code(api.ResponseCodeInvalidRequest)
+	}
+	return nil
+}
+
+// checkVersionLineage returns an error if the job entity version newv does
+// not descend from prevv, i.e. if the job was changed by someone else
+// between reading prevv and the change which produced newv. A descendant
+// keeps the desired state version, and does not move the configuration
+// or workflow version backwards.
+func checkVersionLineage(prevv, newv *peloton.EntityVersion) error {
+	prevConfig, prevDesiredState, prevWorkflow, err :=
+		versionutil.ParseJobEntityVersion(prevv)
+	if err != nil {
+		return fmt.Errorf("parse version %q: %s", prevv.GetValue(), err)
+	}
+	newConfig, newDesiredState, newWorkflow, err :=
+		versionutil.ParseJobEntityVersion(newv)
+	if err != nil {
+		return fmt.Errorf("parse version %q: %s", newv.GetValue(), err)
+	}
+	if newConfig < prevConfig ||
+		newDesiredState != prevDesiredState ||
+		newWorkflow < prevWorkflow {
+		return fmt.Errorf(
+			"job version %q does not descend from %q",
+			newv.GetValue(), prevv.GetValue())
 	}
 	return nil
 }
@@ -510,7 +1040,7 @@ func (h *ServiceHandler) getCurrentPods(
 	// avoid expensive db reads when dealing with jobs with large instance
 	// count.
 	for _, p := range pods {
-		_, instanceID, err := util.ParseTaskID(p.GetPodName().GetValue())
+		_, instanceID, err := podname.ParsePodName(p.GetPodName())
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to parse pod name")
 		}
@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	jobmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc/mocks"
@@ -29,6 +32,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	podmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
 	"github.com/uber/peloton/.gen/peloton/private/jobmgrsvc"
 	jobmgrmocks "github.com/uber/peloton/.gen/peloton/private/jobmgrsvc/mocks"
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
@@ -37,13 +41,17 @@ import (
 	aurorabridgemocks "github.com/uber/peloton/pkg/aurorabridge/mocks"
 
 	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/cache"
 	"github.com/uber/peloton/pkg/aurorabridge/common"
 	"github.com/uber/peloton/pkg/aurorabridge/fixture"
 	"github.com/uber/peloton/pkg/aurorabridge/label"
 	"github.com/uber/peloton/pkg/aurorabridge/mockutil"
 	"github.com/uber/peloton/pkg/aurorabridge/opaquedata"
+	pelotoncommon "github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/middleware/outbound"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -519,6 +527,69 @@ func (suite *ServiceHandlerTestSuite) TestGetJobsSkipNotFoundJobs() {
 	suite.Len(resp.GetResult().GetGetJobsResult().GetConfigs(), jobs-len(jobsNotFound))
 }
 
+// TestGetJobsStaleJobIDCache tests GetJobs endpoint when a job id served
+// from the job id cache is not found, GetJobs should invalidate the cache
+// for the role and query the job ids once more.
+func (suite *ServiceHandlerTestSuite) TestGetJobsStaleJobIDCache() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	role := "role1"
+	jobKey := fixture.AuroraJobKey()
+	staleJobID := fixture.PelotonJobID()
+	jobID := fixture.PelotonJobID()
+	podName := &peloton.PodName{Value: jobID.GetValue() + "-0"}
+
+	mdLabel := label.NewAuroraMetadataLabels(fixture.AuroraMetadata())
+	jkLabel := label.NewAuroraJobKey(jobKey)
+
+	ql := append(
+		label.BuildPartialAuroraJobKeyLabels(role, "", ""),
+		common.BridgeJobLabel,
+	)
+
+	gomock.InOrder(
+		suite.jobIdCache.EXPECT().
+			GetJobIDs(role).
+			Return([]*peloton.JobID{staleJobID}),
+		suite.jobClient.EXPECT().
+			GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+				SummaryOnly: false,
+				JobId:       staleJobID,
+			}).
+			Return(nil, yarpcerrors.NotFoundErrorf("job id not found")),
+		suite.jobIdCache.EXPECT().Invalidate(role),
+		suite.jobIdCache.EXPECT().GetJobIDs(role).Return(nil),
+	)
+
+	jobCache := suite.expectQueryJobsWithLabels(
+		ql, []*peloton.JobID{jobID}, jobKey)
+	suite.jobIdCache.EXPECT().PopulateFromJobCache(role, jobCache)
+
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+			SummaryOnly: false,
+			JobId:       jobID,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			JobInfo: &stateless.JobInfo{
+				Spec: &stateless.JobSpec{
+					Name:          atop.NewJobName(jobKey),
+					InstanceCount: 1,
+					DefaultSpec: &pod.PodSpec{
+						PodName:    podName,
+						Labels:     append([]*peloton.Label{jkLabel}, mdLabel...),
+						Containers: []*pod.ContainerSpec{{}},
+					},
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.GetJobs(suite.ctx, &role)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	suite.Len(resp.GetResult().GetGetJobsResult().GetConfigs(), 1)
+}
+
 // TestGetJobsFailure tests GetJobs endpoint when some jobs have errors
 // returned by Peloton GetJob API, GetJobs should error out and not returning
 // any results.
@@ -589,6 +660,95 @@ func (suite *ServiceHandlerTestSuite) TestGetJobsFailure() {
 	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
 }
 
+// TestListJobs tests ListJobs returns summaries of all jobs in a role
+// from a single paginated QueryJobs, without calling GetJob per job.
+func (suite *ServiceHandlerTestSuite) TestListJobs() {
+	testScope := tally.NewTestScope("", nil)
+	suite.handler.metrics = NewMetrics(testScope)
+
+	role := "role1"
+	jobKey1 := &api.JobKey{
+		Role:        ptr.String(role),
+		Environment: ptr.String("prod"),
+		Name:        ptr.String("job1"),
+	}
+	jobKey2 := &api.JobKey{
+		Role:        ptr.String(role),
+		Environment: ptr.String("prod"),
+		Name:        ptr.String("job2"),
+	}
+
+	ql := append(
+		label.BuildPartialAuroraJobKeyLabels(role, "", ""),
+		common.BridgeJobLabel,
+	)
+
+	suite.jobClient.EXPECT().
+		QueryJobs(gomock.Any(), &statelesssvc.QueryJobsRequest{
+			Spec: &stateless.QuerySpec{
+				Pagination: &query.PaginationSpec{
+					Limit: suite.config.QueryJobsLimit,
+				},
+				Labels: ql,
+			},
+		}).
+		Return(&statelesssvc.QueryJobsResponse{
+			Records: []*stateless.JobSummary{
+				{
+					JobId:         fixture.PelotonJobID(),
+					Name:          atop.NewJobName(jobKey1),
+					Owner:         "owner1",
+					InstanceCount: 3,
+				},
+				{
+					JobId:         fixture.PelotonJobID(),
+					Name:          atop.NewJobName(jobKey2),
+					Owner:         "owner2",
+					InstanceCount: 5,
+				},
+			},
+			Pagination: &query.Pagination{
+				Limit: suite.config.QueryJobsLimit,
+				Total: 2,
+			},
+		}, nil)
+
+	resp, err := suite.handler.ListJobs(suite.ctx, &role)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	configs := resp.GetResult().GetGetJobsResult().GetConfigs()
+	suite.Len(configs, 2)
+	suite.Equal(jobKey1, configs[0].GetKey())
+	suite.Equal("owner1", configs[0].GetOwner().GetUser())
+	suite.Equal(int32(3), configs[0].GetInstanceCount())
+	suite.Nil(configs[0].GetTaskConfig())
+	suite.Equal(jobKey2, configs[1].GetKey())
+	suite.Equal("owner2", configs[1].GetOwner().GetUser())
+	suite.Equal(int32(5), configs[1].GetInstanceCount())
+
+	counters := testScope.Snapshot().Counters()
+	suite.Equal(int64(1), counters["calls+procedure="+ProcedureListJobs+
+		",responsecode=ok,updateservice="].Value())
+}
+
+// TestListJobsUnknownRole tests ListJobs returns an empty result when
+// no job belongs to the role.
+func (suite *ServiceHandlerTestSuite) TestListJobsUnknownRole() {
+	role := "unknown"
+
+	suite.jobClient.EXPECT().
+		QueryJobs(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.QueryJobsResponse{
+			Pagination: &query.Pagination{},
+		}, nil)
+
+	resp, err := suite.handler.ListJobs(suite.ctx, &role)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	suite.Empty(resp.GetResult().GetGetJobsResult().GetConfigs())
+}
+
 // Tests get job update diff
 func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDiff() {
 	defer goleak.VerifyNoLeaks(suite.T())
@@ -603,6 +763,7 @@ func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDiff() {
 		jobUpdateRequest,
 		respoolID,
 		suite.config.ThermosExecutor,
+		suite.config.JobSLADefaults,
 	)
 
 	addedInstancesIDRange := []*pod.InstanceIDRange{
@@ -670,6 +831,7 @@ func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDiffFailure() {
 		jobUpdateRequest,
 		respoolID,
 		suite.config.ThermosExecutor,
+		suite.config.JobSLADefaults,
 	)
 
 	suite.respoolLoader.EXPECT().Load(gomock.Any(), true).Return(respoolID, nil)
@@ -725,34 +887,137 @@ func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDiff_JobNotFound() {
 	suite.Empty(result.GetUnchanged())
 }
 
+// Ensures GetJobUpdateDiff reports all current instances as removed when
+// a job is scaled to zero instances.
+func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDiff_ScaleToZero() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	jobUpdateRequest := fixture.AuroraJobUpdateRequest()
+	jobUpdateRequest.InstanceCount = ptr.Int32(0)
+	jobID := fixture.PelotonJobID()
+	jobKey := jobUpdateRequest.GetTaskConfig().GetJob()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(jobKey, jobID)
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+			SummaryOnly: true,
+			JobId:       jobID,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			Summary: &stateless.JobSummary{
+				InstanceCount: 5,
+				Status: &stateless.JobStatus{
+					Version: fixture.PelotonEntityVersion(),
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.GetJobUpdateDiff(
+		suite.ctx,
+		jobUpdateRequest,
+	)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetGetJobUpdateDiffResult()
+	suite.Equal([]*api.Range{{
+		First: ptr.Int32(0),
+		Last:  ptr.Int32(4),
+	}}, result.GetRemove()[0].GetInstances())
+	suite.Empty(result.GetAdd())
+	suite.Empty(result.GetUpdate())
+	suite.Empty(result.GetUnchanged())
+}
+
+// Ensures GetJobUpdateDiff reports an empty diff for a job which does not
+// exist and is scaled to zero instances.
+func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDiff_JobNotFoundScaleToZero() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	k := fixture.AuroraJobKey()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: atop.NewJobName(k),
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+
+	resp, err := suite.handler.GetJobUpdateDiff(
+		suite.ctx,
+		&api.JobUpdateRequest{
+			TaskConfig:    &api.TaskConfig{Job: k},
+			InstanceCount: ptr.Int32(0),
+		})
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetGetJobUpdateDiffResult()
+	suite.Empty(result.GetAdd())
+	suite.Empty(result.GetUpdate())
+	suite.Empty(result.GetRemove())
+	suite.Empty(result.GetUnchanged())
+}
+
 func (suite *ServiceHandlerTestSuite) TestGetTierConfigs() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
 	resp, err := suite.handler.GetTierConfigs(suite.ctx)
 	suite.NoError(err)
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetGetTierConfigResult()
+	suite.Equal(common.Preemptible, result.GetDefaultTierName())
+
+	tiers := make(map[string]map[string]string)
+	for _, t := range result.GetTiers() {
+		tiers[t.GetName()] = t.GetSettings()
+	}
+	suite.Len(tiers, 3)
+	suite.Equal(map[string]string{
+		common.Preemptible: "true",
+		common.Revocable:   "false",
+	}, tiers[common.Preemptible])
+	suite.Equal(map[string]string{
+		common.Preemptible: "false",
+		common.Revocable:   "false",
+	}, tiers[common.Preferred])
+	suite.Equal(map[string]string{
+		common.Preemptible: "true",
+		common.Revocable:   "true",
+	}, tiers[common.Revocable])
 }
 
-// Ensures StartJobUpdate creates jobs which don't exist.
-func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobSuccess() {
+// Ensures StartJobUpdate sends a correlation id along with the downstream
+// CreateJob call, and returns the same id in the response details.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_CorrelationID() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
 	respoolID := fixture.PelotonResourcePoolID()
 	req := fixture.AuroraJobUpdateRequest()
 	k := req.GetTaskConfig().GetJob()
-	name := atop.NewJobName(k)
 
 	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
 
 	suite.jobClient.EXPECT().
-		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
-			JobName: name,
-		}).
+		GetJobIDFromJobName(gomock.Any(), gomock.Any()).
 		Return(nil, yarpcerrors.NotFoundErrorf(""))
 
+	var createID string
 	suite.jobClient.EXPECT().
 		CreateJob(gomock.Any(), gomock.Any()).
-		Return(&statelesssvc.CreateJobResponse{}, nil)
+		DoAndReturn(func(
+			ctx context.Context,
+			_ *statelesssvc.CreateJobRequest,
+		) (*statelesssvc.CreateJobResponse, error) {
+			createID = outbound.CorrelationIDFromContext(ctx)
+			return &statelesssvc.CreateJobResponse{}, nil
+		})
 
 	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
 
@@ -760,27 +1025,22 @@ func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobSuccess() {
 	suite.NoError(err)
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
 
-	result := resp.GetResult().GetStartJobUpdateResult()
-	suite.Equal(k, result.GetKey().GetJob())
+	suite.NotEmpty(createID)
+	suite.Equal(
+		_correlationIDDetailPrefix+createID,
+		resp.GetDetails()[0].GetMessage())
 }
 
-// Ensures StartJobUpdate returns an INVALID_REQUEST error if there is a conflict
-// when trying to create a job which doesn't exist.
-func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobConflict() {
+// Ensures StartJobUpdate creates jobs which don't exist.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobSuccess() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
 	respoolID := fixture.PelotonResourcePoolID()
 	req := fixture.AuroraJobUpdateRequest()
-	labels := []*api.Metadata{
-		{
-			Key:   ptr.String(common.AuroraGpuResourceKey),
-			Value: ptr.String("2"),
-		},
-	}
-	req.TaskConfig.Metadata = labels
-	name := atop.NewJobName(req.GetTaskConfig().GetJob())
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
 
-	suite.respoolLoader.EXPECT().Load(gomock.Any(), true).Return(respoolID, nil)
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
 
 	suite.jobClient.EXPECT().
 		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
@@ -790,95 +1050,971 @@ func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobConflict() {
 
 	suite.jobClient.EXPECT().
 		CreateJob(gomock.Any(), gomock.Any()).
-		Return(nil, yarpcerrors.AlreadyExistsErrorf(""))
+		Return(&statelesssvc.CreateJobResponse{}, nil)
 
-	suite.jobIdCache.EXPECT().Invalidate(req.GetTaskConfig().GetJob().GetRole())
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
 
 	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
 	suite.NoError(err)
-	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetStartJobUpdateResult()
+	suite.Equal(k, result.GetKey().GetJob())
 }
 
-// Ensures StartJobUpdate replaces jobs which already exist with no pulse.
-func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobNoPulseSuccess() {
+// Ensures the configured job SLA defaults are applied to a new job whose
+// request leaves them unset, and the request takes precedence otherwise.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobSLADefaults() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
-	respoolID := fixture.PelotonResourcePoolID()
-	req := fixture.AuroraJobUpdateRequest()
-	k := req.GetTaskConfig().GetJob()
-	curv := fixture.PelotonEntityVersion()
-	id := fixture.PelotonJobID()
+	suite.handler.config.JobSLADefaults = atop.SLADefaults{
+		MaxUnavailableInstancesPercent: 50,
+		Preemptible:                    true,
+	}
 
-	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	testCases := []struct {
+		name                string
+		tier                *string
+		expectedPreemptible bool
+	}{
+		{"tier not set", nil, true},
+		{"tier set", ptr.String(common.Preferred), false},
+	}
 
-	suite.expectGetJobIDFromJobName(k, id)
+	for _, tt := range testCases {
+		respoolID := fixture.PelotonResourcePoolID()
+		req := fixture.AuroraJobUpdateRequest()
+		req.InstanceCount = ptr.Int32(10)
+		req.TaskConfig.Tier = tt.tier
+		k := req.GetTaskConfig().GetJob()
+		name := atop.NewJobName(k)
 
-	suite.expectGetJobVersion(id, curv)
+		suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
 
-	suite.expectListPods(id, []*pod.PodSummary{})
+		suite.jobClient.EXPECT().
+			GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+				JobName: name,
+			}).
+			Return(nil, yarpcerrors.NotFoundErrorf(""))
 
-	suite.jobClient.EXPECT().
-		ReplaceJob(
-			gomock.Any(),
-			mockutil.MatchReplaceJobRequestUpdateActions(nil)).
-		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+		suite.jobClient.EXPECT().
+			CreateJob(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, r *statelesssvc.CreateJobRequest) {
+				sla := r.GetSpec().GetSla()
+				suite.Equal(tt.expectedPreemptible, sla.GetPreemptible(), tt.name)
+				suite.Equal(uint32(5), sla.GetMaximumUnavailableInstances(), tt.name)
+			}).
+			Return(&statelesssvc.CreateJobResponse{}, nil)
 
-	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
-	suite.NoError(err)
-	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+		suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
 
-	result := resp.GetResult().GetStartJobUpdateResult()
-	suite.Equal(k, result.GetKey().GetJob())
+		resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+		suite.NoError(err)
+		suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	}
 }
 
-// Ensures StartJobUpdate replaces jobs which already exist with pulse.
-func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobWithPulseSuccess() {
+// Ensures StartJobUpdate accepts a request whose instance ranges are
+// consistent with the instance count.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ConsistentInstanceRanges() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
 	respoolID := fixture.PelotonResourcePoolID()
-	req := &api.JobUpdateRequest{
-		TaskConfig: fixture.AuroraTaskConfig(),
-		Settings: &api.JobUpdateSettings{
-			BlockIfNoPulsesAfterMs: ptr.Int32(1000),
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(3)
+	req.Settings = &api.JobUpdateSettings{
+		UpdateOnlyTheseInstances: []*api.Range{
+			{First: ptr.Int32(0), Last: ptr.Int32(0)},
+			{First: ptr.Int32(1), Last: ptr.Int32(2)},
 		},
 	}
 	k := req.GetTaskConfig().GetJob()
-	curv := fixture.PelotonEntityVersion()
-	id := fixture.PelotonJobID()
+	name := atop.NewJobName(k)
 
 	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
 
-	suite.expectGetJobIDFromJobName(k, id)
-
-	suite.expectGetJobVersion(id, curv)
-
-	suite.expectListPods(id, []*pod.PodSummary{})
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
 
 	suite.jobClient.EXPECT().
-		ReplaceJob(
-			gomock.Any(),
-			mockutil.MatchReplaceJobRequestUpdateActions([]opaquedata.UpdateAction{
-				opaquedata.StartPulsed,
-			})).
-		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
 
 	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
 	suite.NoError(err)
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
-
-	result := resp.GetResult().GetStartJobUpdateResult()
-	suite.Equal(k, result.GetKey().GetJob())
 }
 
-// Ensures StartJobUpdate returns an INVALID_REQUEST error if there is a conflict
-// when trying to replace a job which has changed version.
-func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobConflict() {
+// Ensures StartJobUpdate returns an INVALID_REQUEST error if the instance
+// ranges contradict the instance count.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_InconsistentInstanceRanges() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
 	respoolID := fixture.PelotonResourcePoolID()
 	req := fixture.AuroraJobUpdateRequest()
-	k := req.GetTaskConfig().GetJob()
-	curv := fixture.PelotonEntityVersion()
+	req.InstanceCount = ptr.Int32(2)
+	req.Settings = &api.JobUpdateSettings{
+		UpdateOnlyTheseInstances: []*api.Range{
+			{First: ptr.Int32(1), Last: ptr.Int32(3)},
+		},
+	}
+	name := atop.NewJobName(req.GetTaskConfig().GetJob())
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate accepts a request whose instance count is within
+// the configured maximum.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_WithinMaxInstances() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	max := uint32(10)
+	suite.handler.config.MaxInstancesPerJob = &max
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(10)
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error if the instance
+// count exceeds the configured maximum.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ExceedsMaxInstances() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	max := uint32(10)
+	suite.handler.config.MaxInstancesPerJob = &max
+
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(11)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+	details := resp.GetDetails()
+	suite.Contains(details[len(details)-1].GetMessage(), "exceeds maximum")
+}
+
+// Ensures StartJobUpdate rejects a request with a huge instance count by
+// default, before making any call to Peloton.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ExceedsDefaultMaxInstances() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.Nil(suite.handler.config.MaxInstancesPerJob)
+
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(1000000)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+	details := resp.GetDetails()
+	suite.Contains(details[len(details)-1].GetMessage(),
+		"instance count 1000000 exceeds maximum of 100000 instances per job")
+}
+
+// Ensures StartJobUpdate accepts a request just under the default maximum
+// instance count.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_UnderDefaultMaxInstances() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.Nil(suite.handler.config.MaxInstancesPerJob)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(_defaultMaxInstancesPerJob - 1)
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *statelesssvc.CreateJobRequest) {
+			suite.Equal(uint32(_defaultMaxInstancesPerJob-1),
+				req.GetSpec().GetInstanceCount())
+		}).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate does not cap the instance count if the maximum is
+// explicitly set to 0.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NoMaxInstances() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	max := uint32(0)
+	suite.handler.config.MaxInstancesPerJob = &max
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(_defaultMaxInstancesPerJob + 1)
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate creates the job if the resource pool capacity check
+// is enabled and the job fits into the available reservation of the pool.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_WithinRespoolCapacity() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.CheckRespoolCapacity = true
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(10)
+	req.TaskConfig.Resources = []*api.Resource{{NumCpus: ptr.Float64(2)}}
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	suite.respoolLoader.EXPECT().
+		Get(gomock.Any(), respoolID).
+		Return(newRespoolInfo(30, 5), nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error without creating
+// the job if the job exceeds the available reservation of the resource pool.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ExceedsRespoolCapacity() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.CheckRespoolCapacity = true
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(10)
+	req.TaskConfig.Resources = []*api.Resource{{NumCpus: ptr.Float64(2)}}
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	suite.respoolLoader.EXPECT().
+		Get(gomock.Any(), respoolID).
+		Return(newRespoolInfo(30, 15), nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+	details := resp.GetDetails()
+	suite.Contains(details[len(details)-1].GetMessage(), "available reservation")
+}
+
+// Ensures StartJobUpdate replaces a running job close to the reservation of
+// its resource pool if the resources added by the update fit into the
+// available reservation, since the current resources of the job are already
+// allocated.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_RunningJobWithinRespoolCapacity() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.CheckRespoolCapacity = true
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(10)
+	req.TaskConfig.Resources = []*api.Resource{{NumCpus: ptr.Float64(2.5)}}
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	// The job currently runs 10 instances of 2 cpus, out of the 25
+	// allocated cpus of the pool, and the update adds 5 cpus.
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	suite.respoolLoader.EXPECT().
+		Get(gomock.Any(), respoolID).
+		Return(newRespoolInfo(30, 25), nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+	suite.expectGetJobVersion(id, curv)
+	suite.expectListPods(id, []*pod.PodSummary{})
+	suite.expectGetJobSpec(id, newJobSpecWithCPU(10, 2))
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error without replacing
+// a running job if the resources added by the update exceed the available
+// reservation of the resource pool.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_RunningJobExceedsRespoolCapacity() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.CheckRespoolCapacity = true
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.InstanceCount = ptr.Int32(10)
+	req.TaskConfig.Resources = []*api.Resource{{NumCpus: ptr.Float64(3)}}
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	suite.respoolLoader.EXPECT().
+		Get(gomock.Any(), respoolID).
+		Return(newRespoolInfo(30, 25), nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+	suite.expectGetJobVersion(id, curv)
+	suite.expectListPods(id, []*pod.PodSummary{})
+	suite.expectGetJobSpec(id, newJobSpecWithCPU(10, 2))
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+	details := resp.GetDetails()
+	suite.Contains(details[len(details)-1].GetMessage(), "available reservation")
+}
+
+// expectGetJobSpec sets up expect for GetJob API returning the given spec
+// of the job.
+func (suite *ServiceHandlerTestSuite) expectGetJobSpec(
+	id *peloton.JobID,
+	spec *stateless.JobSpec,
+) {
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{JobId: id}).
+		Return(&statelesssvc.GetJobResponse{
+			JobInfo: &stateless.JobInfo{Spec: spec},
+		}, nil)
+}
+
+// newJobSpecWithCPU returns the spec of a job whose instances each require
+// the given cpus.
+func newJobSpecWithCPU(instances uint32, cpus float64) *stateless.JobSpec {
+	return &stateless.JobSpec{
+		InstanceCount: instances,
+		DefaultSpec: &pod.PodSpec{
+			Containers: []*pod.ContainerSpec{
+				{Resource: &pod.ResourceSpec{CpuLimit: cpus}},
+			},
+		},
+	}
+}
+
+// newRespoolInfo returns the info of a resource pool with the given cpu
+// reservation and allocation.
+func newRespoolInfo(reservation, allocation float64) *respool.ResourcePoolInfo {
+	return &respool.ResourcePoolInfo{
+		Config: &respool.ResourcePoolConfig{
+			Resources: []*respool.ResourceConfig{
+				{Kind: "cpu", Reservation: reservation, Limit: reservation},
+			},
+		},
+		Usage: []*respool.ResourceUsage{
+			{Kind: "cpu", Allocation: allocation},
+		},
+		Path: &respool.ResourcePoolPath{Value: "/AuroraBridge"},
+	}
+}
+
+// Ensures a retried identical StartJobUpdate request returns the result of
+// the original request without creating the job again.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_DedupRetriedRequest() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.StartJobUpdateDedupWindow = time.Minute
+	suite.handler.updateTokenCache = cache.NewUpdateTokenCache(10, time.Minute)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	updateKey := resp.GetResult().GetStartJobUpdateResult().GetKey()
+
+	resp, err = suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	suite.Equal(updateKey, resp.GetResult().GetStartJobUpdateResult().GetKey())
+}
+
+// Ensures StartJobUpdate requests carrying the same idempotency token are
+// deduplicated, while a request with another token starts a new update.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_DedupIdempotencyToken() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.StartJobUpdateDedupWindow = time.Minute
+	suite.handler.updateTokenCache = cache.NewUpdateTokenCache(10, time.Minute)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	newRequest := func(token string) *api.JobUpdateRequest {
+		req := fixture.AuroraJobUpdateRequest()
+		req.Metadata = append(req.Metadata, &api.Metadata{
+			Key:   ptr.String(common.IdempotencyTokenKey),
+			Value: ptr.String(token),
+		})
+		return req
+	}
+	req := newRequest("token-1")
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	updateKey := resp.GetResult().GetStartJobUpdateResult().GetKey()
+
+	// The retry carries the same token even though the message differs.
+	resp, err = suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("retry"))
+	suite.NoError(err)
+	suite.Equal(updateKey, resp.GetResult().GetStartJobUpdateResult().GetKey())
+
+	// A request with another token is not deduplicated.
+	other := newRequest("token-2")
+	otherName := atop.NewJobName(other.GetTaskConfig().GetJob())
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: otherName,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf(""))
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.CreateJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(other.GetTaskConfig().GetJob().GetRole())
+
+	resp, err = suite.handler.StartJobUpdate(suite.ctx, other, ptr.String("some message"))
+	suite.NoError(err)
+	suite.NotEqual(updateKey, resp.GetResult().GetStartJobUpdateResult().GetKey())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error if there is a conflict
+// when trying to create a job which doesn't exist, and the job cannot be
+// resolved afterwards.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobConflict() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	labels := []*api.Metadata{
+		{
+			Key:   ptr.String(common.AuroraGpuResourceKey),
+			Value: ptr.String("2"),
+		},
+	}
+	req.TaskConfig.Metadata = labels
+	name := atop.NewJobName(req.GetTaskConfig().GetJob())
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), true).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("")).
+		Times(2)
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.AlreadyExistsErrorf(""))
+
+	suite.jobIdCache.EXPECT().Invalidate(req.GetTaskConfig().GetJob().GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate retries creating a job if CreateJob reports a
+// conflict which resolves on retry, e.g. when racing with a job deletion.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobConflictRetrySuccess() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.CreateJobConflictMaxRetries = 2
+	suite.handler.config.CreateJobConflictRetryInterval = time.Millisecond
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	name := atop.NewJobName(req.GetTaskConfig().GetJob())
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("")).
+		Times(2)
+
+	gomock.InOrder(
+		suite.jobClient.EXPECT().
+			CreateJob(gomock.Any(), gomock.Any()).
+			Return(nil, yarpcerrors.AlreadyExistsErrorf("")),
+		suite.jobClient.EXPECT().
+			CreateJob(gomock.Any(), gomock.Any()).
+			Return(&statelesssvc.CreateJobResponse{}, nil),
+	)
+
+	suite.jobIdCache.EXPECT().Invalidate(req.GetTaskConfig().GetJob().GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error if the conflict
+// creating a job persists after all the retries.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobConflictRetryExhausted() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.CreateJobConflictMaxRetries = 2
+	suite.handler.config.CreateJobConflictRetryInterval = time.Millisecond
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	name := atop.NewJobName(req.GetTaskConfig().GetJob())
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.jobClient.EXPECT().
+		GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+			JobName: name,
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("")).
+		Times(4)
+
+	suite.jobClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.AlreadyExistsErrorf("")).
+		Times(3)
+
+	suite.jobIdCache.EXPECT().Invalidate(req.GetTaskConfig().GetJob().GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate replaces the job if CreateJob reports the job
+// already exists, e.g. when a previous attempt of the request created it.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobAlreadyExistsReplaceJob() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	name := atop.NewJobName(k)
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	gomock.InOrder(
+		suite.jobClient.EXPECT().
+			GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+				JobName: name,
+			}).
+			Return(nil, yarpcerrors.NotFoundErrorf("")),
+
+		suite.jobClient.EXPECT().
+			CreateJob(gomock.Any(), gomock.Any()).
+			Return(nil, yarpcerrors.AlreadyExistsErrorf("")),
+
+		suite.jobClient.EXPECT().
+			GetJobIDFromJobName(gomock.Any(), &statelesssvc.GetJobIDFromJobNameRequest{
+				JobName: name,
+			}).
+			Return(&statelesssvc.GetJobIDFromJobNameResponse{
+				JobId: []*peloton.JobID{id},
+			}, nil),
+	)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(
+			gomock.Any(),
+			mockutil.MatchReplaceJobRequestUpdateActions(nil)).
+		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+
+	suite.jobIdCache.EXPECT().Invalidate(k.GetRole())
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetStartJobUpdateResult()
+	suite.Equal(k, result.GetKey().GetJob())
+}
+
+// Ensures StartJobUpdate replaces jobs which already exist with no pulse.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobNoPulseSuccess() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(
+			gomock.Any(),
+			mockutil.MatchReplaceJobRequestUpdateActions(nil)).
+		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetStartJobUpdateResult()
+	suite.Equal(k, result.GetKey().GetJob())
+}
+
+// Ensures StartJobUpdate replaces jobs which already exist with pulse.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobWithPulseSuccess() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := &api.JobUpdateRequest{
+		TaskConfig: fixture.AuroraTaskConfig(),
+		Settings: &api.JobUpdateSettings{
+			BlockIfNoPulsesAfterMs: ptr.Int32(1000),
+		},
+	}
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(
+			gomock.Any(),
+			mockutil.MatchReplaceJobRequestUpdateActions([]opaquedata.UpdateAction{
+				opaquedata.StartPulsed,
+			})).
+		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetStartJobUpdateResult()
+	suite.Equal(k, result.GetKey().GetJob())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error if there is a conflict
+// when trying to replace a job which has changed version.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobConflict() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.AbortedErrorf(""))
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures the INVALID_REQUEST response of StartJobUpdate on a replace
+// conflict is counted by its response code.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobConflictMetrics() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	testScope := tally.NewTestScope("", nil)
+	suite.handler.metrics = NewMetrics(testScope)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.AbortedErrorf(""))
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+
+	counters := testScope.Snapshot().Counters()
+	counter := fmt.Sprintf(
+		"calls+procedure=%s,responsecode=invalid-request,updateservice=%s",
+		ProcedureStartJobUpdate, k.GetRole())
+	suite.Equal(int64(1), counters[counter].Value())
+}
+
+// Ensures StartJobUpdate succeeds when the job version returned by
+// ReplaceJob descends from the version the job was replaced at.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobVersionDescends() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion(3, 1, 5)
+	newv := fixture.PelotonEntityVersion(4, 1, 6)
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.ReplaceJobResponse{Version: newv}, nil)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate returns an INVALID_REQUEST error if the job version
+// returned by ReplaceJob does not descend from the version the job was
+// replaced at, e.g. because the job was changed concurrently.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobVersionLineageBreak() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion(3, 1, 5)
+	newv := fixture.PelotonEntityVersion(4, 2, 6)
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	suite.jobClient.EXPECT().
+		ReplaceJob(gomock.Any(), gomock.Any()).
+		Return(&statelesssvc.ReplaceJobResponse{Version: newv}, nil)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate rejects replacing a job whose update is rolling
+// forward if the status is not overridable.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_UpdateInProgress() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.overridableUpdateStatuses = common.NewJobUpdateStatusSet(
+		api.JobUpdateStatusRollForwardPaused,
+	)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.expectGetJobVersion(id, curv)
+
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+			JobId: id,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			JobInfo: &stateless.JobInfo{
+				Status: &stateless.JobStatus{
+					Version: curv,
+				},
+			},
+			WorkflowInfo: &stateless.WorkflowInfo{
+				Status: &stateless.WorkflowStatus{
+					State: stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures StartJobUpdate replaces a job whose update is in progress if the
+// request forces the update.
+func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ForceUpdateInProgress() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.overridableUpdateStatuses = common.NewJobUpdateStatusSet(
+		api.JobUpdateStatusRollForwardPaused,
+	)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	req := fixture.AuroraJobUpdateRequest()
+	req.Metadata = append(req.Metadata, &api.Metadata{
+		Key:   ptr.String(common.ForceUpdateKey),
+		Value: ptr.String("true"),
+	})
+	k := req.GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
 	id := fixture.PelotonJobID()
 
 	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
@@ -891,11 +2027,182 @@ func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_ReplaceJobConflict() {
 
 	suite.jobClient.EXPECT().
 		ReplaceJob(gomock.Any(), gomock.Any()).
-		Return(nil, yarpcerrors.AbortedErrorf(""))
+		Return(&statelesssvc.ReplaceJobResponse{}, nil)
 
 	resp, err := suite.handler.StartJobUpdate(suite.ctx, req, ptr.String("some message"))
 	suite.NoError(err)
-	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures the resource pool resolved for the role and tier of a job is
+// served from the cache by later resolutions of the same role and tier.
+func (suite *ServiceHandlerTestSuite) TestLoadRespool_Cached() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.RespoolCacheTTL = time.Minute
+	suite.handler.respoolCache = cache.NewRespoolCache(time.Minute)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	taskConfig := fixture.AuroraTaskConfig()
+	taskConfig.Tier = ptr.String(common.Preemptible)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	for i := 0; i < 2; i++ {
+		id, err := suite.handler.loadRespool(suite.ctx, taskConfig)
+		suite.NoError(err)
+		suite.Equal(respoolID.GetValue(), id.GetValue())
+	}
+
+	// A different tier is resolved separately.
+	otherTier := fixture.AuroraTaskConfig()
+	otherTier.Job = taskConfig.GetJob()
+	otherTier.Tier = ptr.String(common.Revocable)
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+
+	_, err := suite.handler.loadRespool(suite.ctx, otherTier)
+	suite.NoError(err)
+}
+
+// Ensures a failed resource pool resolution is not cached, and that the
+// resolutions served from the cache are counted as hits.
+func (suite *ServiceHandlerTestSuite) TestLoadRespool_ErrorNotCached() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	scope := tally.NewTestScope("", nil)
+	suite.handler.metrics = NewMetrics(scope)
+	suite.handler.config.RespoolCacheTTL = time.Minute
+	suite.handler.respoolCache = cache.NewRespoolCache(time.Minute)
+
+	respoolID := fixture.PelotonResourcePoolID()
+	taskConfig := fixture.AuroraTaskConfig()
+
+	gomock.InOrder(
+		suite.respoolLoader.EXPECT().
+			Load(gomock.Any(), false).
+			Return(nil, errors.New("some error")),
+		suite.respoolLoader.EXPECT().
+			Load(gomock.Any(), false).
+			Return(respoolID, nil),
+	)
+
+	_, err := suite.handler.loadRespool(suite.ctx, taskConfig)
+	suite.Error(err)
+
+	for i := 0; i < 2; i++ {
+		id, err := suite.handler.loadRespool(suite.ctx, taskConfig)
+		suite.NoError(err)
+		suite.Equal(respoolID.GetValue(), id.GetValue())
+	}
+
+	counters := scope.Snapshot().Counters()
+	suite.Equal(int64(1), counters["respool_cache.hit+"].Value())
+	suite.Equal(int64(2), counters["respool_cache.miss+"].Value())
+}
+
+// Ensures BulkStartJobUpdate aborts the updates started before a failed
+// one, does not start the later ones, and returns an aggregated error.
+func (suite *ServiceHandlerTestSuite) TestBulkStartJobUpdate_RollbackOnFailure() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	respoolID := fixture.PelotonResourcePoolID()
+	reqs := []*api.JobUpdateRequest{
+		fixture.AuroraJobUpdateRequest(),
+		fixture.AuroraJobUpdateRequest(),
+		fixture.AuroraJobUpdateRequest(),
+	}
+	k := reqs[0].GetTaskConfig().GetJob()
+	curv := fixture.PelotonEntityVersion()
+	v := fixture.PelotonEntityVersion()
+	id := fixture.PelotonJobID()
+
+	// The first update replaces an existing job.
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	suite.expectGetJobIDFromJobName(k, id)
+	suite.expectGetJobVersion(id, curv)
+	suite.expectListPods(id, []*pod.PodSummary{})
+
+	var od *peloton.OpaqueData
+	suite.jobClient.EXPECT().
+		ReplaceJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *statelesssvc.ReplaceJobRequest) {
+			od = req.GetOpaqueData()
+		}).
+		Return(&statelesssvc.ReplaceJobResponse{}, nil)
+
+	// The second update fails.
+	suite.respoolLoader.EXPECT().
+		Load(gomock.Any(), false).
+		Return(nil, errors.New("some error"))
+
+	// The first update is aborted.
+	suite.expectGetJobIDFromJobName(k, id)
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{JobId: id}).
+		DoAndReturn(func(context.Context, *statelesssvc.GetJobRequest) (
+			*statelesssvc.GetJobResponse, error) {
+			return &statelesssvc.GetJobResponse{
+				JobInfo: &stateless.JobInfo{
+					Status: &stateless.JobStatus{Version: v},
+				},
+				WorkflowInfo: &stateless.WorkflowInfo{OpaqueData: od},
+			}, nil
+		})
+	suite.jobClient.EXPECT().
+		AbortJobWorkflow(gomock.Any(), &statelesssvc.AbortJobWorkflowRequest{
+			JobId:   id,
+			Version: v,
+		}).
+		Return(nil, nil)
+
+	resp, err := suite.handler.BulkStartJobUpdate(
+		suite.ctx, reqs, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponse().GetResponseCode())
+	details := resp.GetResponse().GetDetails()
+	suite.Contains(details[len(details)-1].GetMessage(),
+		atop.NewJobName(reqs[1].GetTaskConfig().GetJob()))
+
+	results := resp.GetResults()
+	suite.Len(results, 3)
+	suite.Equal(k, results[0].GetJob())
+	suite.Equal(api.ResponseCodeOk, results[0].GetResponse().GetResponseCode())
+	suite.True(results[0].GetAborted())
+	suite.Equal(api.ResponseCodeError, results[1].GetResponse().GetResponseCode())
+	suite.False(results[1].GetAborted())
+	suite.Nil(results[2].GetResponse())
+	suite.False(results[2].GetAborted())
+}
+
+// Ensures BulkStartJobUpdate starts no update if any request is invalid.
+func (suite *ServiceHandlerTestSuite) TestBulkStartJobUpdate_InvalidRequest() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	invalid := fixture.AuroraJobUpdateRequest()
+	invalid.InstanceCount = ptr.Int32(-1)
+	duplicate := fixture.AuroraJobUpdateRequest()
+	reqs := []*api.JobUpdateRequest{
+		fixture.AuroraJobUpdateRequest(),
+		invalid,
+		duplicate,
+		duplicate,
+	}
+
+	resp, err := suite.handler.BulkStartJobUpdate(
+		suite.ctx, reqs, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest,
+		resp.GetResponse().GetResponseCode())
+
+	results := resp.GetResults()
+	suite.Len(results, 4)
+	suite.Nil(results[0].GetResponse())
+	suite.Equal(api.ResponseCodeInvalidRequest,
+		results[1].GetResponse().GetResponseCode())
+	suite.Nil(results[2].GetResponse())
+	suite.Equal(api.ResponseCodeInvalidRequest,
+		results[3].GetResponse().GetResponseCode())
 }
 
 // Ensures PauseJobUpdate successfully maps to PauseJobWorkflow.
@@ -922,6 +2229,93 @@ func (suite *ServiceHandlerTestSuite) TestPauseJobUpdate_Success() {
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
 }
 
+// Ensures PauseJobUpdate re-fetches the job version and retries
+// PauseJobWorkflow when the first attempt fails due to a version conflict.
+func (suite *ServiceHandlerTestSuite) TestPauseJobUpdate_RetryOnVersionConflict() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	k := fixture.AuroraJobUpdateKey()
+	id := fixture.PelotonJobID()
+	v1 := fixture.PelotonEntityVersion(1)
+	v2 := fixture.PelotonEntityVersion(2)
+
+	suite.expectGetJobIDFromJobName(k.GetJob(), id)
+
+	gomock.InOrder(
+		suite.expectGetJobAndWorkflow(id, k.GetID(), v1),
+		suite.jobClient.EXPECT().
+			PauseJobWorkflow(gomock.Any(), &statelesssvc.PauseJobWorkflowRequest{
+				JobId:   id,
+				Version: v1,
+			}).
+			Return(nil, yarpcerrors.AbortedErrorf("version mismatch")),
+		suite.expectGetJobAndWorkflow(id, k.GetID(), v2),
+		suite.jobClient.EXPECT().
+			PauseJobWorkflow(gomock.Any(), &statelesssvc.PauseJobWorkflowRequest{
+				JobId:   id,
+				Version: v2,
+			}).
+			Return(nil, nil),
+	)
+
+	resp, err := suite.handler.PauseJobUpdate(suite.ctx, k, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures PauseJobUpdate does not retry PauseJobWorkflow on a version
+// conflict if the retries are disabled.
+func (suite *ServiceHandlerTestSuite) TestPauseJobUpdate_RetriesDisabled() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	retries := 0
+	suite.handler.config.UpdateActionMaxRetries = &retries
+
+	k := fixture.AuroraJobUpdateKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+
+	suite.expectGetJobIDFromJobName(k.GetJob(), id)
+
+	suite.expectGetJobAndWorkflow(id, k.GetID(), v)
+
+	suite.jobClient.EXPECT().
+		PauseJobWorkflow(gomock.Any(), &statelesssvc.PauseJobWorkflowRequest{
+			JobId:   id,
+			Version: v,
+		}).
+		Return(nil, yarpcerrors.AbortedErrorf("version mismatch"))
+
+	resp, err := suite.handler.PauseJobUpdate(suite.ctx, k, ptr.String("some message"))
+	suite.NoError(err)
+	suite.NotEqual(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures PauseJobUpdate does not retry PauseJobWorkflow on errors other
+// than a version conflict.
+func (suite *ServiceHandlerTestSuite) TestPauseJobUpdate_NoRetryOnOtherError() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	k := fixture.AuroraJobUpdateKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+
+	suite.expectGetJobIDFromJobName(k.GetJob(), id)
+
+	suite.expectGetJobAndWorkflow(id, k.GetID(), v)
+
+	suite.jobClient.EXPECT().
+		PauseJobWorkflow(gomock.Any(), &statelesssvc.PauseJobWorkflowRequest{
+			JobId:   id,
+			Version: v,
+		}).
+		Return(nil, yarpcerrors.InternalErrorf("some error"))
+
+	resp, err := suite.handler.PauseJobUpdate(suite.ctx, k, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
 // Ensures PauseJobUpdate returns INVALID_REQUEST if update id does not match workflow.
 func (suite *ServiceHandlerTestSuite) TestPauseJobUpdate_InvalidUpdateID() {
 	defer goleak.VerifyNoLeaks(suite.T())
@@ -958,31 +2352,125 @@ func (suite *ServiceHandlerTestSuite) TestResumeJobUpdate_Success() {
 		}).
 		Return(nil, nil)
 
-	resp, err := suite.handler.ResumeJobUpdate(suite.ctx, k, ptr.String("some message"))
+	resp, err := suite.handler.ResumeJobUpdate(suite.ctx, k, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures ResumeJobUpdate returns INVALID_REQUEST if update id does not match workflow.
+func (suite *ServiceHandlerTestSuite) TestResumeJobUpdate_InvalidUpdateID() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	k := fixture.AuroraJobUpdateKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+
+	suite.expectGetJobIDFromJobName(k.GetJob(), id)
+
+	suite.expectGetJobAndWorkflow(id, "some other id", v)
+
+	resp, err := suite.handler.ResumeJobUpdate(suite.ctx, k, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures AbortJobUpdate successfully maps to AbortJobWorkflow.
+func (suite *ServiceHandlerTestSuite) TestAbortJobUpdate_Success() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	k := fixture.AuroraJobUpdateKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+
+	suite.expectGetJobIDFromJobName(k.GetJob(), id)
+
+	suite.expectGetJobAndWorkflow(id, k.GetID(), v)
+
+	suite.jobClient.EXPECT().
+		AbortJobWorkflow(gomock.Any(), &statelesssvc.AbortJobWorkflowRequest{
+			JobId:   id,
+			Version: v,
+		}).
+		Return(nil, nil)
+
+	resp, err := suite.handler.AbortJobUpdate(suite.ctx, k, ptr.String("some message"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures AbortJobUpdate stops the running instances beyond the instance
+// count of the job if KillOrphanedInstancesOnAbort is set.
+func (suite *ServiceHandlerTestSuite) TestAbortJobUpdate_KillOrphanedInstances() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.KillOrphanedInstancesOnAbort = true
+
+	k := fixture.AuroraJobUpdateKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+
+	suite.expectGetJobIDFromJobName(k.GetJob(), id)
+
+	suite.expectGetJobAndWorkflow(id, k.GetID(), v)
+
+	suite.jobClient.EXPECT().
+		AbortJobWorkflow(gomock.Any(), &statelesssvc.AbortJobWorkflowRequest{
+			JobId:   id,
+			Version: v,
+		}).
+		Return(nil, nil)
+
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+			SummaryOnly: true,
+			JobId:       id,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			Summary: &stateless.JobSummary{
+				InstanceCount: 3,
+				Status: &stateless.JobStatus{
+					Version: v,
+				},
+			},
+		}, nil)
+
+	// Instances 3 and 4 are orphaned, instance 5 is already terminal.
+	var pods []*pod.PodSummary
+	for i := 0; i < 6; i++ {
+		state := pod.PodState_POD_STATE_RUNNING
+		if i == 5 {
+			state = pod.PodState_POD_STATE_KILLED
+		}
+		pods = append(pods, &pod.PodSummary{
+			PodName: &peloton.PodName{
+				Value: util.CreatePelotonTaskID(id.GetValue(), uint32(i)),
+			},
+			Status: &pod.PodStatus{State: state},
+		})
+	}
+	suite.expectListPods(id, pods)
+
+	for _, i := range []uint32{3, 4} {
+		suite.podClient.EXPECT().
+			StopPod(gomock.Any(), &podsvc.StopPodRequest{
+				PodName: &peloton.PodName{
+					Value: util.CreatePelotonTaskID(id.GetValue(), i),
+				},
+			}).
+			Return(&podsvc.StopPodResponse{}, nil)
+	}
+
+	resp, err := suite.handler.AbortJobUpdate(suite.ctx, k, ptr.String("some message"))
 	suite.NoError(err)
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
 }
 
-// Ensures ResumeJobUpdate returns INVALID_REQUEST if update id does not match workflow.
-func (suite *ServiceHandlerTestSuite) TestResumeJobUpdate_InvalidUpdateID() {
+// Ensures AbortJobUpdate returns an error if stopping orphaned instances
+// fails after the update is aborted.
+func (suite *ServiceHandlerTestSuite) TestAbortJobUpdate_KillOrphanedInstancesError() {
 	defer goleak.VerifyNoLeaks(suite.T())
 
-	k := fixture.AuroraJobUpdateKey()
-	id := fixture.PelotonJobID()
-	v := fixture.PelotonEntityVersion()
-
-	suite.expectGetJobIDFromJobName(k.GetJob(), id)
-
-	suite.expectGetJobAndWorkflow(id, "some other id", v)
-
-	resp, err := suite.handler.ResumeJobUpdate(suite.ctx, k, ptr.String("some message"))
-	suite.NoError(err)
-	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
-}
-
-// Ensures AbortJobUpdate successfully maps to AbortJobWorkflow.
-func (suite *ServiceHandlerTestSuite) TestAbortJobUpdate_Success() {
-	defer goleak.VerifyNoLeaks(suite.T())
+	suite.handler.config.KillOrphanedInstancesOnAbort = true
 
 	k := fixture.AuroraJobUpdateKey()
 	id := fixture.PelotonJobID()
@@ -999,9 +2487,16 @@ func (suite *ServiceHandlerTestSuite) TestAbortJobUpdate_Success() {
 		}).
 		Return(nil, nil)
 
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+			SummaryOnly: true,
+			JobId:       id,
+		}).
+		Return(nil, errors.New("some error"))
+
 	resp, err := suite.handler.AbortJobUpdate(suite.ctx, k, ptr.String("some message"))
 	suite.NoError(err)
-	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
 }
 
 // Ensures AbortJobUpdate returns INVALID_REQUEST if update id does not match workflow.
@@ -1198,12 +2693,12 @@ func (suite *ServiceHandlerTestSuite) expectGetJobAndWorkflow(
 	jobID *peloton.JobID,
 	updateID string,
 	v *peloton.EntityVersion,
-) {
+) *gomock.Call {
 	d := &opaquedata.Data{UpdateID: updateID}
 	od, err := d.Serialize()
 	suite.NoError(err)
 
-	suite.jobClient.EXPECT().
+	return suite.jobClient.EXPECT().
 		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
 			JobId: jobID,
 		}).
@@ -2124,6 +3619,58 @@ func (suite *ServiceHandlerTestSuite) TestKillTasks_Success() {
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
 }
 
+// Ensures that KillTasks never stops more pods concurrently than the
+// configured number of StopPod workers.
+func (suite *ServiceHandlerTestSuite) TestKillTasks_BoundedConcurrency() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	workers := 5
+	suite.handler.config.StopPodWorkers = workers
+
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+	instances := fixture.AuroraInstanceSet(500, 1000)
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.jobClient.EXPECT().
+		GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+			JobId:       id,
+			SummaryOnly: true,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			Summary: &stateless.JobSummary{
+				InstanceCount: 1000,
+			},
+		}, nil)
+
+	var inFlight, maxInFlight int32
+	suite.podClient.EXPECT().
+		StopPod(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ *podsvc.StopPodRequest,
+		) (*podsvc.StopPodResponse, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max ||
+					atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return &podsvc.StopPodResponse{}, nil
+		}).
+		Times(len(instances))
+
+	resp, err := suite.handler.KillTasks(suite.ctx, k, instances, nil)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	suite.True(atomic.LoadInt32(&maxInFlight) <= int32(workers))
+}
+
 func pickN(m map[int32]struct{}, n int) map[int32]struct{} {
 	p := make(map[int32]struct{})
 	for i := range m {
@@ -2887,6 +4434,102 @@ func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDetails_FilterNonUpdateWor
 	suite.Len(result, 2)
 }
 
+// Ensures that GetJobUpdateDetails caps the number of instance update events,
+// retaining the most recent ones.
+func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDetails_InstanceEventsCap() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.UpdateDetailsInstanceEventsMax = 3
+
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	w := fixture.PelotonWorkflowInfo("2019-01-01T00:00:00Z")
+	for i := uint32(0); i < 3; i++ {
+		w.InstanceEvents = append(
+			w.InstanceEvents,
+			&stateless.WorkflowInfoInstanceWorkflowEvents{
+				InstanceId: i,
+				Events: []*stateless.WorkflowEvent{
+					{
+						State:     stateless.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+						Timestamp: fmt.Sprintf("2019-01-01T00:0%d:30Z", i),
+					},
+					{
+						State:     stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+						Timestamp: fmt.Sprintf("2019-01-01T00:0%d:00Z", i),
+					},
+				},
+			})
+	}
+
+	suite.jobClient.EXPECT().
+		ListJobWorkflows(gomock.Any(), &statelesssvc.ListJobWorkflowsRequest{
+			JobId:               id,
+			InstanceEvents:      true,
+			UpdatesLimit:        suite.config.UpdatesLimit,
+			InstanceEventsLimit: suite.config.InstanceEventsLimit,
+		}).
+		Return(&statelesssvc.ListJobWorkflowsResponse{
+			WorkflowInfos: []*stateless.WorkflowInfo{w},
+		}, nil)
+
+	resp, err := suite.handler.GetJobUpdateDetails(
+		suite.ctx, nil, &api.JobUpdateQuery{JobKey: k})
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetGetJobUpdateDetailsResult().GetDetailsList()
+	suite.Len(result, 1)
+
+	events := result[0].GetInstanceEvents()
+	suite.Len(events, 3)
+	// Only the most recent events are retained, i.e. instance 1 finishing
+	// the update, followed by instance 2 starting and finishing the update.
+	suite.Equal(int32(1), events[0].GetInstanceId())
+	suite.Equal(api.JobUpdateActionInstanceUpdated, events[0].GetAction())
+	suite.Equal(int32(2), events[1].GetInstanceId())
+	suite.Equal(api.JobUpdateActionInstanceUpdating, events[1].GetAction())
+	suite.Equal(int32(2), events[2].GetInstanceId())
+	suite.Equal(api.JobUpdateActionInstanceUpdated, events[2].GetAction())
+}
+
+// Ensures that GetJobUpdateDetails does not query nor return instance
+// update events if summary only is set.
+func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDetails_SummaryOnly() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	suite.handler.config.UpdateDetailsSummaryOnly = true
+
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.jobClient.EXPECT().
+		ListJobWorkflows(gomock.Any(), &statelesssvc.ListJobWorkflowsRequest{
+			JobId:               id,
+			InstanceEvents:      false,
+			UpdatesLimit:        suite.config.UpdatesLimit,
+			InstanceEventsLimit: suite.config.InstanceEventsLimit,
+		}).
+		Return(&statelesssvc.ListJobWorkflowsResponse{
+			WorkflowInfos: []*stateless.WorkflowInfo{fixture.PelotonWorkflowInfo("")},
+		}, nil)
+
+	resp, err := suite.handler.GetJobUpdateDetails(
+		suite.ctx, nil, &api.JobUpdateQuery{JobKey: k})
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetGetJobUpdateDetailsResult().GetDetailsList()
+	suite.Len(result, 1)
+	suite.NotNil(result[0].GetUpdate().GetSummary())
+	suite.Empty(result[0].GetInstanceEvents())
+}
+
 // expectListPods sets up expect for ListPods API based on input JobID
 // and a list of PodSummary.
 func (suite *ServiceHandlerTestSuite) expectListPods(
@@ -3276,6 +4919,79 @@ func (suite *ServiceHandlerTestSuite) TestCreateJobSpecForUpdate_NoPinned() {
 	suite.Equal(spec, newSpec)
 }
 
+// TestCreateJobSpecForUpdate_ScaleDownPinned tests createJobSpecForUpdate()
+// util function accepts a scale-down update which pins the instances it
+// removes, and rejects one which pins instances that do not exist.
+func (suite *ServiceHandlerTestSuite) TestCreateJobSpecForUpdate_ScaleDownPinned() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	id := fixture.PelotonJobID()
+	entityVersion := &peloton.EntityVersion{Value: "1-0-0"}
+	podSpec := &pod.PodSpec{
+		Labels: []*peloton.Label{
+			{
+				Key:   "label-key",
+				Value: "label-value",
+			},
+		},
+	}
+
+	var pods []*pod.PodSummary
+	for i := uint32(0); i < 3; i++ {
+		pods = append(pods, &pod.PodSummary{
+			PodName: &peloton.PodName{
+				Value: util.CreatePelotonTaskID(id.GetValue(), i),
+			},
+			Status: &pod.PodStatus{
+				State:   pod.PodState_POD_STATE_RUNNING,
+				Version: entityVersion,
+			},
+		})
+	}
+
+	for _, tc := range []struct {
+		last    int32
+		wantErr bool
+	}{
+		{last: 2, wantErr: false},
+		{last: 3, wantErr: true},
+	} {
+		req := fixture.AuroraJobUpdateRequest()
+		req.InstanceCount = ptr.Int32(1)
+		req.Settings = &api.JobUpdateSettings{
+			UpdateOnlyTheseInstances: []*api.Range{
+				{First: ptr.Int32(1), Last: ptr.Int32(tc.last)},
+			},
+		}
+		spec := &stateless.JobSpec{
+			Name:        atop.NewJobName(req.GetTaskConfig().GetJob()),
+			DefaultSpec: podSpec,
+		}
+
+		suite.expectListPods(id, pods)
+		suite.jobClient.EXPECT().
+			GetJob(gomock.Any(), &statelesssvc.GetJobRequest{
+				JobId:   id,
+				Version: entityVersion,
+			}).
+			Return(&statelesssvc.GetJobResponse{
+				JobInfo: &stateless.JobInfo{
+					Spec: &stateless.JobSpec{
+						DefaultSpec: podSpec,
+					},
+				},
+			}, nil)
+
+		_, err := suite.handler.createJobSpecForUpdate(suite.ctx, req, id, spec)
+		if tc.wantErr {
+			suite.Error(err)
+			suite.True(yarpcerrors.IsInvalidArgument(err))
+		} else {
+			suite.NoError(err)
+		}
+	}
+}
+
 // TestCreateJobSpecForUpdate_WithPinned tests createJobSpecForUpdate()
 // util function update request with pinned instances.
 func (suite *ServiceHandlerTestSuite) TestCreateJobSpecForUpdate_WithPinned() {
@@ -4190,6 +5906,93 @@ func (suite *ServiceHandlerTestSuite) TestCreateJobSpecForUpdateInternal_KeepSpe
 	}, newJobSpec)
 }
 
+// TestCreateJobSpecForUpdateInternal_ScaleOnly tests
+// createJobSpecForUpdateInternal returns job spec which leaves the running
+// instances untouched if the update only changes the instance count, and
+// still starts the instances which are in terminal state.
+func (suite *ServiceHandlerTestSuite) TestCreateJobSpecForUpdateInternal_ScaleOnly() {
+	defer goleak.VerifyNoLeaks(suite.T())
+
+	instances := int32(5)
+	podSpec := &pod.PodSpec{
+		Labels: []*peloton.Label{
+			{Key: "k1", Value: "v1"},
+		},
+	}
+	jobSpec := &stateless.JobSpec{
+		DefaultSpec: podSpec,
+	}
+	podStates := map[uint32]*podStateSpec{
+		0: {
+			state: pod.PodState_POD_STATE_RUNNING,
+			podSpec: &pod.PodSpec{
+				Labels: []*peloton.Label{
+					{Key: "k1", Value: "v1"},
+				},
+			},
+		},
+		1: {
+			state: pod.PodState_POD_STATE_RUNNING,
+			podSpec: &pod.PodSpec{
+				Labels: []*peloton.Label{
+					{Key: "k1", Value: "v1"},
+					{Key: common.BridgeUpdateLabelKey, Value: "1"},
+				},
+			},
+		},
+		2: {
+			state: pod.PodState_POD_STATE_KILLED,
+			podSpec: &pod.PodSpec{
+				Labels: []*peloton.Label{
+					{Key: "k1", Value: "v1"},
+					{Key: common.BridgeUpdateLabelKey, Value: "1"},
+				},
+			},
+		},
+	}
+	terminalInstances := map[uint32]struct{}{
+		2: {},
+	}
+	updateInstances := map[uint32]struct{}{
+		0: {}, 1: {}, 2: {}, 3: {}, 4: {},
+	}
+	specChangeInstances := map[uint32]struct{}{
+		3: {}, 4: {},
+	}
+
+	newJobSpec := suite.handler.createJobSpecForUpdateInternal(
+		instances,
+		jobSpec,
+		podStates,
+		terminalInstances,
+		updateInstances,
+		specChangeInstances,
+	)
+	suite.Equal(&stateless.JobSpec{
+		DefaultSpec: podSpec,
+		InstanceSpec: map[uint32]*pod.PodSpec{
+			// instance 0 keeps the same spec as default spec
+			// instance 1 keeps the original "bridge update label", so
+			// that it is not restarted
+			1: {
+				Labels: []*peloton.Label{
+					{Key: "k1", Value: "v1"},
+					{Key: common.BridgeUpdateLabelKey, Value: "1"},
+				},
+			},
+			// instance 2 gets a new "bridge update label", so that it is
+			// started
+			2: {
+				Labels: []*peloton.Label{
+					{Key: "k1", Value: "v1"},
+					{Key: common.BridgeUpdateLabelKey, Value: _randomUUID},
+				},
+			},
+			// instance 3 and 4 are added instances using default spec
+		},
+	}, newJobSpec)
+}
+
 // TestGetInstanceSpecLabelOnly check getInstanceSpecLabelOnly extracts
 // instance spec correctly.
 func TestGetInstanceSpecLabelOnly(t *testing.T) {
@@ -4298,6 +6101,79 @@ func TestGetTerminalInstances(t *testing.T) {
 	}, ti)
 }
 
+// TestPreservePersistentVolumes checks that a replace keeps every
+// instance attached to its current persistent volume.
+func TestPreservePersistentVolumes(t *testing.T) {
+	defer goleak.VerifyNoLeaks(t)
+
+	volA := &pod.PersistentVolumeSpec{ContainerPath: "/data", SizeMb: 100}
+	volB := &pod.PersistentVolumeSpec{ContainerPath: "/data", SizeMb: 200}
+	labels := []*peloton.Label{{Key: "k1", Value: "v1"}}
+
+	genJobSpec := &stateless.JobSpec{
+		DefaultSpec: &pod.PodSpec{Labels: labels, Revocable: true},
+	}
+	podStates := map[uint32]*podStateSpec{
+		0: {podSpec: &pod.PodSpec{Labels: labels, Volume: volA}},
+		1: {podSpec: &pod.PodSpec{Labels: labels, Volume: volA}},
+		2: {podSpec: &pod.PodSpec{Labels: labels, Volume: volB}},
+	}
+
+	carried := carryPersistentVolume(genJobSpec, podStates)
+	assert.Equal(t, volA, carried.GetDefaultSpec().GetVolume())
+	// generated spec is not modified
+	assert.Nil(t, genJobSpec.GetDefaultSpec().GetVolume())
+
+	newJobSpec := preservePersistentVolumes(carried, 4, podStates)
+	for i := uint32(0); i < 3; i++ {
+		merged := taskconfig.MergePodSpec(
+			newJobSpec.GetDefaultSpec(), newJobSpec.GetInstanceSpec()[i])
+		assert.Equal(t, podStates[i].podSpec.GetVolume(), merged.GetVolume())
+		assert.True(t, merged.GetRevocable())
+		assert.Equal(t, labels, merged.GetLabels())
+	}
+	assert.Len(t, newJobSpec.GetInstanceSpec(), 1)
+	// added instance uses the default spec
+	assert.Nil(t, newJobSpec.GetInstanceSpec()[3])
+	// input spec is not modified
+	assert.Nil(t, carried.GetInstanceSpec())
+
+	// nothing to preserve without volumes
+	noVolume := map[uint32]*podStateSpec{
+		0: {podSpec: &pod.PodSpec{Labels: labels}},
+	}
+	assert.Equal(t, genJobSpec, carryPersistentVolume(genJobSpec, noVolume))
+	assert.Equal(t, genJobSpec, preservePersistentVolumes(genJobSpec, 1, noVolume))
+}
+
+// TestValidateVolumeOrphans checks that removing instances holding a
+// persistent volume requires explicit opt-in.
+func TestValidateVolumeOrphans(t *testing.T) {
+	defer goleak.VerifyNoLeaks(t)
+
+	vol := &pod.PersistentVolumeSpec{ContainerPath: "/data", SizeMb: 100}
+	podStates := map[uint32]*podStateSpec{
+		0: {podSpec: &pod.PodSpec{Volume: vol}},
+		1: {podSpec: &pod.PodSpec{Volume: vol}},
+		2: {podSpec: &pod.PodSpec{}},
+	}
+
+	// removing an instance without volume is fine
+	req := &api.JobUpdateRequest{InstanceCount: ptr.Int32(2)}
+	assert.NoError(t, validateVolumeOrphans(req, podStates))
+
+	// removing an instance with volume is rejected
+	req = &api.JobUpdateRequest{InstanceCount: ptr.Int32(1)}
+	err := validateVolumeOrphans(req, podStates)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	// unless explicitly allowed
+	req.Metadata = []*api.Metadata{
+		{Key: ptr.String(common.AllowVolumeOrphanKey), Value: ptr.String("true")},
+	}
+	assert.NoError(t, validateVolumeOrphans(req, podStates))
+}
+
 // TestGetUpdateInstances_AllInstances checks when UpdateOnlyTheseInstances
 // is not set and new pod spec is different from all currently running pod
 // specs, getUpdateInstances should return all the instance ids.
@@ -4466,3 +6342,20 @@ func TestGetPodRunsLimit(t *testing.T) {
 		})
 	}
 }
+
+// TestGetJobResourceDemand checks getJobResourceDemand takes the resources
+// of the instances with an instance spec from their instance spec.
+func TestGetJobResourceDemand(t *testing.T) {
+	spec := newJobSpecWithCPU(4, 2)
+	spec.InstanceSpec = map[uint32]*pod.PodSpec{
+		1: newJobSpecWithCPU(1, 5).GetDefaultSpec(),
+		// Labels only, the instance keeps the default resources.
+		2: {Labels: []*peloton.Label{{Key: "k", Value: "v"}}},
+		// Out of the instance count.
+		7: newJobSpecWithCPU(1, 100).GetDefaultSpec(),
+	}
+
+	demand := getJobResourceDemand(spec)
+	assert.Equal(t, float64(11), demand[pelotoncommon.CPU])
+	assert.Empty(t, getJobResourceDemand(nil))
+}
@@ -21,6 +21,7 @@ import (
 
 const (
 	ProcedureAbortJobUpdate         = "auroraschedulermanager__abortjobupdate"
+	ProcedureBulkStartJobUpdate     = "aurorabridgescheduler__bulkstartjobupdate"
 	ProcedureGetConfigSummary       = "readonlyscheduler__getconfigsummary"
 	ProcedureGetJobSummary          = "readonlyscheduler__getjobsummary"
 	ProcedureGetJobUpdateDetails    = "readonlyscheduler__getjobupdatedetails"
@@ -30,6 +31,7 @@ const (
 	ProcedureGetTasksWithoutConfigs = "readonlyscheduler__gettaskswithoutconfigs"
 	ProcedureGetTierConfigs         = "readonlyscheduler__gettierconfigs"
 	ProcedureKillTasks              = "auroraschedulermanager__killtasks"
+	ProcedureListJobs               = "aurorabridgescheduler__listjobs"
 	ProcedurePauseJobUpdate         = "auroraschedulermanager__pausejobupdate"
 	ProcedurePulseJobUpdate         = "auroraschedulermanager__pulsejobupdate"
 	ProcedureResumeJobUpdate        = "auroraschedulermanager__resumejobupdate"
@@ -48,6 +50,7 @@ const (
 
 var _procedures = []string{
 	ProcedureAbortJobUpdate,
+	ProcedureBulkStartJobUpdate,
 	ProcedureGetConfigSummary,
 	ProcedureGetJobSummary,
 	ProcedureGetJobUpdateDetails,
@@ -57,6 +60,7 @@ var _procedures = []string{
 	ProcedureGetTasksWithoutConfigs,
 	ProcedureGetTierConfigs,
 	ProcedureKillTasks,
+	ProcedureListJobs,
 	ProcedurePauseJobUpdate,
 	ProcedurePulseJobUpdate,
 	ProcedureResumeJobUpdate,
@@ -87,24 +91,28 @@ type PerProcedureMetrics struct {
 // Metrics is the struct containing all metrics relevant for aurora api parrity
 type Metrics struct {
 	Procedures map[string]*PerProcedureMetrics
+
+	// Counters of resource pool resolutions served from the cache and
+	// resolved by the resource pool loader.
+	RespoolCacheHit  tally.Counter
+	RespoolCacheMiss tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
 // initialized and rooted at the given tally.Scope
 func NewMetrics(scope tally.Scope) *Metrics {
+	respoolCacheScope := scope.SubScope("respool_cache")
 	m := &Metrics{
-		Procedures: map[string]*PerProcedureMetrics{},
+		Procedures:       map[string]*PerProcedureMetrics{},
+		RespoolCacheHit:  respoolCacheScope.Counter("hit"),
+		RespoolCacheMiss: respoolCacheScope.Counter("miss"),
 	}
 	for _, procedure := range _procedures {
 		responseCodes := make(map[api.ResponseCode]*PerResponseCodeMetrics)
 		for _, responseCode := range api.ResponseCode_Values() {
-			responseCodeText, exists := _responseCodeToText[responseCode]
-			if !exists {
-				responseCodeText = "unknown-error"
-			}
 			tag := map[string]string{
 				TagProcedure:    procedure,
-				TagResponseCode: responseCodeText,
+				TagResponseCode: responseCodeText(responseCode),
 				// Fill empty string here so that prometheus won't panic
 				// when the number of tags is changed inside subscope
 				TagService: "",
@@ -122,3 +130,11 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	}
 	return m
 }
+
+// responseCodeText returns the metric tag value of the response code.
+func responseCodeText(responseCode api.ResponseCode) string {
+	if text, ok := _responseCodeToText[responseCode]; ok {
+		return text
+	}
+	return "unknown-error"
+}
@@ -113,6 +113,24 @@ func NewJobConfiguration(
 	}, nil
 }
 
+// NewJobConfigurationFromSummary creates a JobConfiguration carrying only
+// the key, owner and instance count of the job, without a TaskConfig.
+// It is used when listing jobs so the full job spec need not be fetched.
+func NewJobConfigurationFromSummary(
+	jobSummary *stateless.JobSummary,
+) (*api.JobConfiguration, error) {
+	jobKey, err := NewJobKey(jobSummary.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.JobConfiguration{
+		Key:           jobKey,
+		Owner:         NewIdentity(jobSummary.GetOwner()),
+		InstanceCount: ptr.Int32(int32(jobSummary.GetInstanceCount())),
+	}, nil
+}
+
 // newJobStats creates a JobStats object.
 // Reference:
 // https://github.com/apache/aurora/blob/master/src/main/java/org/apache/aurora/scheduler/base/Jobs.java#L54
@@ -47,7 +47,9 @@ func NewJobUpdateDetails(
 	workflowEvents := workflow.GetEvents()
 	updateEvents := []*api.JobUpdateEvent{}
 	for i := range workflowEvents {
-		// Assuming peloton workflow events are sorted in descending order
+		// Peloton workflow events are usually sorted in descending order,
+		// walk them backwards so that events with the same timestamp keep
+		// their order after sorting.
 		pe := workflowEvents[len(workflowEvents)-1-i]
 		ae, err := NewJobUpdateEvent(pe, d)
 		if err != nil {
@@ -55,6 +57,10 @@ func NewJobUpdateDetails(
 		}
 		updateEvents = append(updateEvents, ae)
 	}
+	// Workflow events may arrive out of order, the history must be in
+	// ascending timestamp order.
+	sort.Stable(JobUpdateEventsByTimestamp(updateEvents))
+	updateEvents = collapseJobUpdateEvents(updateEvents)
 
 	jobUpdateInstructions, err := NewJobUpdateInstructions(
 		prevWorkflow,
@@ -91,6 +97,23 @@ func NewJobUpdateDetails(
 	}, nil
 }
 
+// collapseJobUpdateEvents removes the events with the same status as
+// the event preceding them, e.g. peloton INITIALIZED and ROLLING_FORWARD
+// events are both ROLLING_FORWARD in aurora, so that the events only
+// contain status transitions.
+func collapseJobUpdateEvents(
+	events []*api.JobUpdateEvent) []*api.JobUpdateEvent {
+	result := []*api.JobUpdateEvent{}
+	for _, e := range events {
+		if len(result) > 0 &&
+			result[len(result)-1].GetStatus() == e.GetStatus() {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
 var _rollbackAndTerminalStatuses = common.NewJobUpdateStatusSet(
 	api.JobUpdateStatusRollingBack,
 	api.JobUpdateStatusRollBackPaused,
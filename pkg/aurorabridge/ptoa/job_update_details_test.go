@@ -185,14 +185,65 @@ func TestNewJobUpdateDetailsUpdateEvents(t *testing.T) {
 			TimestampMs: ptr.Int64(1552003740000),
 			Message:     ptr.String("job-update-message"),
 		},
-		{
-			Status:      api.JobUpdateStatusRollingForward.Ptr(),
-			TimestampMs: ptr.Int64(1552003800000),
-			Message:     ptr.String("job-update-message"),
-		},
 		{
 			Status:      api.JobUpdateStatusRolledForward.Ptr(),
 			TimestampMs: ptr.Int64(1552003860000),
 		},
 	}, d.GetUpdateEvents())
 }
+
+// TestNewJobUpdateDetailsUpdateEventsOutOfOrder tests that shuffled
+// workflow events are returned as an ordered history of status
+// transitions.
+func TestNewJobUpdateDetailsUpdateEventsOutOfOrder(t *testing.T) {
+	k := fixture.AuroraJobKey()
+	w := fixture.PelotonWorkflowInfo("")
+	w.Events = []*stateless.WorkflowEvent{
+		{
+			Timestamp: "2019-03-08T00:12:00Z",
+			State:     stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+		},
+		{
+			Timestamp: "2019-03-08T00:09:00Z",
+			State:     stateless.WorkflowState_WORKFLOW_STATE_INITIALIZED,
+		},
+		{
+			Timestamp: "2019-03-08T00:14:00Z",
+			State:     stateless.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		},
+		{
+			Timestamp: "2019-03-08T00:11:00Z",
+			State:     stateless.WorkflowState_WORKFLOW_STATE_PAUSED,
+		},
+		{
+			Timestamp: "2019-03-08T00:10:00Z",
+			State:     stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+		},
+		{
+			Timestamp: "2019-03-08T00:13:00Z",
+			State:     stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+		},
+	}
+
+	d, err := NewJobUpdateDetails(k, nil, w)
+	require.NoError(t, err)
+
+	var statuses []api.JobUpdateStatus
+	var timestamps []int64
+	for _, e := range d.GetUpdateEvents() {
+		statuses = append(statuses, e.GetStatus())
+		timestamps = append(timestamps, e.GetTimestampMs())
+	}
+	require.Equal(t, []api.JobUpdateStatus{
+		api.JobUpdateStatusRollingForward,
+		api.JobUpdateStatusRollForwardPaused,
+		api.JobUpdateStatusRollingForward,
+		api.JobUpdateStatusRolledForward,
+	}, statuses)
+	require.Equal(t, []int64{
+		1552003740000,
+		1552003860000,
+		1552003920000,
+		1552004040000,
+	}, timestamps)
+}
@@ -67,3 +67,12 @@ func (s JobInstanceUpdateEventsByTimestamp) Swap(i, j int) { s[i], s[j] = s[j],
 func (s JobInstanceUpdateEventsByTimestamp) Less(i, j int) bool {
 	return s[i].GetTimestampMs() < s[j].GetTimestampMs()
 }
+
+// JobUpdateEventsByTimestamp sorts job update events by timestamp.
+type JobUpdateEventsByTimestamp []*api.JobUpdateEvent
+
+func (s JobUpdateEventsByTimestamp) Len() int      { return len(s) }
+func (s JobUpdateEventsByTimestamp) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s JobUpdateEventsByTimestamp) Less(i, j int) bool {
+	return s[i].GetTimestampMs() < s[j].GetTimestampMs()
+}
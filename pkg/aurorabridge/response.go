@@ -15,10 +15,15 @@
 package aurorabridge
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
+	"github.com/uber/peloton/pkg/middleware/outbound"
+
+	"github.com/pkg/errors"
 	"go.uber.org/thriftrw/ptr"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // auroraError is a utility for building Aurora errors.
@@ -39,6 +44,42 @@ func (e *auroraError) code(c api.ResponseCode) *auroraError {
 	return e
 }
 
+// auroraErrorFrom returns an Aurora error for err, prefixed with the
+// description of the failed operation. The response code is derived from
+// the yarpc error code of err, see toAuroraResponseCode.
+func auroraErrorFrom(err error, format string, args ...interface{}) *auroraError {
+	return auroraErrorf("%s: %s", fmt.Sprintf(format, args...), err).
+		code(toAuroraResponseCode(err))
+}
+
+// toAuroraResponseCode maps the yarpc error code of an error returned by
+// the Peloton services to the closest Aurora response code. Errors without
+// a yarpc error code map to ResponseCodeError.
+func toAuroraResponseCode(err error) api.ResponseCode {
+	err = errors.Cause(err)
+	if !yarpcerrors.IsStatus(err) {
+		return api.ResponseCodeError
+	}
+
+	switch yarpcerrors.FromError(err).Code() {
+	case yarpcerrors.CodeNotFound,
+		yarpcerrors.CodeAlreadyExists,
+		yarpcerrors.CodeInvalidArgument,
+		yarpcerrors.CodeFailedPrecondition,
+		yarpcerrors.CodeAborted:
+		// The request cannot succeed against the current state of the
+		// job, retrying it as is does not help. This includes conflicts
+		// with a concurrent change of the job, e.g. a replace of a job
+		// whose version changed, which Aurora clients expect as an
+		// invalid request.
+		return api.ResponseCodeInvalidRequest
+	case yarpcerrors.CodeUnavailable:
+		return api.ResponseCodeErrorTransient
+	default:
+		return api.ResponseCodeError
+	}
+}
+
 // newResponse is a convenience wrapper for converting a result and error into
 // a Response. r is ignored on non-nil errs, but extraDetails are always added
 // regardless of err.
@@ -56,6 +97,21 @@ func newResponse(r *api.Result, err *auroraError, extraDetails ...string) *api.R
 	}
 }
 
+// newResponse wraps the newResponse function, and adds the correlation id
+// of ctx as the first detail of the response.
+func (h *ServiceHandler) newResponse(
+	ctx context.Context,
+	r *api.Result,
+	err *auroraError,
+	extraDetails ...string,
+) *api.Response {
+	if id := outbound.CorrelationIDFromContext(ctx); id != "" {
+		extraDetails = append(
+			[]string{_correlationIDDetailPrefix + id}, extraDetails...)
+	}
+	return newResponse(r, err, extraDetails...)
+}
+
 func newResponseDetails(messages ...string) []*api.ResponseDetail {
 	var ds []*api.ResponseDetail
 	for _, m := range messages {
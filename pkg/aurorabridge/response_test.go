@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// TestToAuroraResponseCode tests the mapping of errors returned by the
+// Peloton services to Aurora response codes.
+func TestToAuroraResponseCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		code api.ResponseCode
+	}{
+		{"not found", yarpcerrors.NotFoundErrorf("job not found"), api.ResponseCodeInvalidRequest},
+		{"already exists", yarpcerrors.AlreadyExistsErrorf("job exists"), api.ResponseCodeInvalidRequest},
+		{"invalid argument", yarpcerrors.InvalidArgumentErrorf("bad spec"), api.ResponseCodeInvalidRequest},
+		{"failed precondition", yarpcerrors.FailedPreconditionErrorf("bad state"), api.ResponseCodeInvalidRequest},
+		{"aborted", yarpcerrors.AbortedErrorf("version mismatch"), api.ResponseCodeInvalidRequest},
+		{"unavailable", yarpcerrors.UnavailableErrorf("not leader"), api.ResponseCodeErrorTransient},
+		{"internal", yarpcerrors.InternalErrorf("some error"), api.ResponseCodeError},
+		{"not a yarpc error", errors.New("some error"), api.ResponseCodeError},
+		{"wrapped", pkgerrors.Wrap(yarpcerrors.NotFoundErrorf(""), "get job"), api.ResponseCodeInvalidRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, toAuroraResponseCode(tc.err))
+		})
+	}
+}
+
+// TestAuroraErrorFrom tests that the Aurora error built for an error
+// carries the mapped response code and a message prefixed with the failed
+// operation.
+func TestAuroraErrorFrom(t *testing.T) {
+	err := yarpcerrors.UnavailableErrorf("not leader")
+	aerr := auroraErrorFrom(err, "get job %s", "foo")
+	assert.Equal(t, api.ResponseCodeErrorTransient, aerr.responseCode)
+	assert.Equal(t, "get job foo: "+err.Error(), aerr.msg)
+
+	resp := newResponse(nil, aerr)
+	assert.Equal(t, api.ResponseCodeErrorTransient, resp.GetResponseCode())
+}
@@ -33,6 +33,9 @@ import (
 // exist, it boostraps one with provided defaults.
 type RespoolLoader interface {
 	Load(context.Context, bool) (*v1peloton.ResourcePoolID, error)
+
+	// Get returns the info, including the resource usage, of a resource pool.
+	Get(context.Context, *v1peloton.ResourcePoolID) (*respool.ResourcePoolInfo, error)
 }
 
 type respoolLoader struct {
@@ -97,6 +100,29 @@ func (l *respoolLoader) load(
 	return &v1peloton.ResourcePoolID{Value: respoolID.GetValue()}, nil
 }
 
+// Get returns the info, including the resource usage, of a resource pool.
+func (l *respoolLoader) Get(
+	ctx context.Context,
+	id *v1peloton.ResourcePoolID,
+) (*respool.ResourcePoolInfo, error) {
+
+	req := &respool.GetRequest{
+		Id: &v0peloton.ResourcePoolID{Value: id.GetValue()},
+	}
+	resp, err := l.client.GetResourcePool(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rerr := resp.GetError()
+	if rerr != nil {
+		if rerr.GetNotFound() != nil {
+			return nil, yarpcerrors.NotFoundErrorf(rerr.String())
+		}
+		return nil, yarpcerrors.UnknownErrorf(rerr.String())
+	}
+	return resp.GetPoolinfo(), nil
+}
+
 func (l *respoolLoader) bootstrapRespool(
 	ctx context.Context,
 	respoolPath string,
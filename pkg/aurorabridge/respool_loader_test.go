@@ -27,6 +27,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	v1peloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 )
 
 type RespoolLoaderTestSuite struct {
@@ -235,3 +236,34 @@ func (suite *RespoolLoaderTestSuite) TestBoostrapperRespoolError() {
 	_, err := suite.loader.Load(suite.ctx, false)
 	suite.Error(err)
 }
+
+func (suite *RespoolLoaderTestSuite) TestGetPool() {
+	id := &peloton.ResourcePoolID{Value: "bridge-id"}
+	info := &respool.ResourcePoolInfo{
+		Id:   id,
+		Path: &respool.ResourcePoolPath{Value: suite.config.RespoolPath},
+	}
+
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: id}).
+		Return(&respool.GetResponse{Poolinfo: info}, nil)
+
+	result, err := suite.loader.Get(suite.ctx, &v1peloton.ResourcePoolID{Value: id.GetValue()})
+	suite.NoError(err)
+	suite.Equal(info, result)
+}
+
+func (suite *RespoolLoaderTestSuite) TestGetPoolNotFound() {
+	id := &peloton.ResourcePoolID{Value: "bridge-id"}
+
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: id}).
+		Return(&respool.GetResponse{
+			Error: &respool.GetResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{Id: id},
+			},
+		}, nil)
+
+	_, err := suite.loader.Get(suite.ctx, &v1peloton.ResourcePoolID{Value: id.GetValue()})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2019 Uber Technologies, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Peloton specific extensions of the Aurora scheduler API served by
+// aurorabridge. Kept apart from api.thrift, which is a copy of the Aurora
+// API.
+
+include "./api.thrift"
+
+namespace py gen.peloton.aurorabridge
+
+/** The outcome of starting the update of one job in a bulkStartJobUpdate call. */
+struct BulkJobUpdateResult {
+  /** The key of the job of the update. */
+  1: optional api.JobKey job
+  /**
+   * The response of starting the update of the job. Unset if the update was
+   * not started because another one failed.
+   */
+  2: optional api.Response response
+  /** Whether the update was started, and then aborted because another one failed. */
+  3: optional bool aborted
+}
+
+struct BulkStartJobUpdateResponse {
+  /** The overall response, which is OK only if all the updates were started. */
+  1: optional api.Response response
+  /** The result of each request, in the order of the requests. */
+  2: optional list<BulkJobUpdateResult> results
+}
+
+service AuroraBridgeScheduler {
+  /**
+   * Starts the updates of a set of related jobs as a unit. All requests are
+   * validated before any update is started, and if starting an update fails,
+   * the updates started before it are aborted on a best-effort basis.
+   */
+  BulkStartJobUpdateResponse bulkStartJobUpdate(
+      /** The descriptions of how to change the jobs, at most one per job. */
+      1: list<api.JobUpdateRequest> requests,
+      /** A user-specified message to include with the induced job update state changes. */
+      2: string message)
+
+  /**
+   * Lists the jobs of a role. Unlike getJobs, the job configurations are
+   * built from the job summaries and do not include the task config.
+   */
+  api.Response listJobs(
+      /** The role of the jobs to list. */
+      1: string role)
+}
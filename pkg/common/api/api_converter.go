@@ -535,6 +535,7 @@ func ConvertSLAConfigToSLASpec(slaConfig *job.SlaConfig) *stateless.SlaSpec {
 		Preemptible:                 slaConfig.GetPreemptible(),
 		Revocable:                   slaConfig.GetRevocable(),
 		MaximumUnavailableInstances: slaConfig.GetMaximumUnavailableInstances(),
+		MaxRunsToRetain:             slaConfig.GetMaxRunsToRetain(),
 	}
 }
 
@@ -545,6 +546,7 @@ func ConvertSLASpecToSLAConfig(slaSpec *stateless.SlaSpec) *job.SlaConfig {
 		Preemptible:                 slaSpec.GetPreemptible(),
 		Revocable:                   slaSpec.GetRevocable(),
 		MaximumUnavailableInstances: slaSpec.GetMaximumUnavailableInstances(),
+		MaxRunsToRetain:             slaSpec.GetMaxRunsToRetain(),
 	}
 }
 
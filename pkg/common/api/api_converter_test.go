@@ -1157,8 +1157,9 @@ func (suite *apiConverterTestSuite) TestConvertJobSpecToJobConfig() {
 		},
 		InstanceCount: 10,
 		Sla: &stateless.SlaSpec{
-			Preemptible: true,
-			Revocable:   true,
+			Preemptible:     true,
+			Revocable:       true,
+			MaxRunsToRetain: 5,
 		},
 		DefaultSpec: &pod.PodSpec{
 			PodName: &v1alphapeloton.PodName{
@@ -1204,6 +1205,7 @@ func (suite *apiConverterTestSuite) TestConvertJobSpecToJobConfig() {
 	suite.Equal(jobSpec.GetInstanceCount(), jobConfig.GetInstanceCount())
 	suite.Equal(jobSpec.GetSla().GetPreemptible(), jobConfig.GetSLA().GetPreemptible())
 	suite.Equal(jobSpec.GetSla().GetRevocable(), jobConfig.GetSLA().GetRevocable())
+	suite.Equal(jobSpec.GetSla().GetMaxRunsToRetain(), jobConfig.GetSLA().GetMaxRunsToRetain())
 	suite.Equal(jobSpec.GetDefaultSpec().GetContainers()[0].GetName(), jobConfig.GetDefaultConfig().GetName())
 	suite.Equal(jobSpec.GetDefaultSpec().GetContainers()[0].GetCommand().GetValue(), jobConfig.GetDefaultConfig().GetCommand().GetValue())
 	suite.Equal(jobSpec.GetDefaultSpec().GetController(), jobConfig.GetDefaultConfig().GetController())
@@ -0,0 +1,25 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// FeatureFlags holds the on/off state of named features, which allows new
+// code paths to be rolled out gradually through configuration.
+type FeatureFlags map[string]bool
+
+// Enabled returns true if the named feature is turned on.
+// Unknown features are turned off.
+func (f FeatureFlags) Enabled(name string) bool {
+	return f[name]
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestFeatureFlagsEnabled(t *testing.T) {
+	var flags FeatureFlags
+	assert.False(t, flags.Enabled("flag1"))
+
+	err := yaml.Unmarshal([]byte("flag1: true\nflag2: false\n"), &flags)
+	assert.NoError(t, err)
+	assert.True(t, flags.Enabled("flag1"))
+	assert.False(t, flags.Enabled("flag2"))
+	assert.False(t, flags.Enabled("unknown"))
+}
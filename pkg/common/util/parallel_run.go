@@ -16,6 +16,7 @@ package util
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -34,12 +35,86 @@ const (
 
 type singleTask func(id uint32) error
 
+// InstanceResult is the outcome of running a task on an instance.
+type InstanceResult struct {
+	// InstanceID is the instance the task was run on.
+	InstanceID uint32
+	// Duration is how long the task took on the instance.
+	Duration time.Duration
+	// Err is the error returned by the task, if any.
+	Err error
+}
+
 // RunInParallel runs go routines which will perform action on
 // given list of instances
 func RunInParallel(identifier string, idList []uint32, task singleTask) error {
+	return runBatchesInParallel(
+		identifier,
+		uint32(len(idList)),
+		func(k uint32) uint32 { return idList[k] },
+		task)
+}
+
+// RunInParallelWithResults runs go routines which will perform action on
+// given list of instances like RunInParallel, and also returns the result
+// of every instance the action was run on, ordered by instance ID. Since
+// a batch stops at its first failed instance, the instances after it in
+// the batch have no result.
+func RunInParallelWithResults(
+	identifier string,
+	idList []uint32,
+	task singleTask) ([]InstanceResult, error) {
+	var lock sync.Mutex
+	results := make([]InstanceResult, 0, len(idList))
+	timedTask := func(id uint32) error {
+		start := time.Now()
+		err := task(id)
+		result := InstanceResult{
+			InstanceID: id,
+			Duration:   time.Since(start),
+			Err:        err,
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		results = append(results, result)
+		return err
+	}
+
+	err := RunInParallel(identifier, idList, timedTask)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].InstanceID < results[j].InstanceID
+	})
+	return results, err
+}
+
+// RunRangeInParallel runs go routines which will perform action on
+// the instances in the range [start, end), without building the
+// list of instances in the range.
+func RunRangeInParallel(
+	identifier string,
+	start uint32,
+	end uint32,
+	task singleTask) error {
+	if end <= start {
+		return nil
+	}
+	return runBatchesInParallel(
+		identifier,
+		end-start,
+		func(k uint32) uint32 { return start + k },
+		task)
+}
+
+// runBatchesInParallel runs the task on nTasks instances in parallel
+// batches, where idAt returns the k-th instance to run the task on.
+func runBatchesInParallel(
+	identifier string,
+	nTasks uint32,
+	idAt func(k uint32) uint32,
+	task singleTask) error {
 	var transientError int32
 
-	nTasks := uint32(len(idList))
 	// indicates if the task operation hit a transient error
 	transientError = 0
 
@@ -79,7 +154,7 @@ func RunInParallel(identifier string, idList []uint32, task singleTask) error {
 		go func() {
 			defer wg.Done()
 			for k := updateStart; k < updateEnd; k++ {
-				instance := idList[k]
+				instance := idAt(k)
 				err := task(instance)
 				if err != nil {
 					log.WithError(err).
@@ -17,6 +17,7 @@ package util
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
@@ -70,3 +71,105 @@ func (suite *TaskTestSuite) TestRunInParallelFail() {
 	err := RunInParallel(uuid.NewRandom().String(), instances, worker)
 	suite.True(yarpcerrors.IsAborted(err))
 }
+
+// TestRunInParallelWithResults tests that the per-instance results are
+// recorded and ordered by instance.
+func (suite *TaskTestSuite) TestRunInParallelWithResults() {
+	instances := []uint32{4, 2, 0, 3, 1}
+
+	// worker should take longer for the instances with larger IDs.
+	worker := func(id uint32) error {
+		time.Sleep(time.Duration(id) * time.Millisecond)
+		return nil
+	}
+
+	results, err := RunInParallelWithResults(
+		uuid.NewRandom().String(), instances, worker)
+	suite.NoError(err)
+	suite.Len(results, len(instances))
+
+	for i, result := range results {
+		suite.Equal(uint32(i), result.InstanceID)
+		suite.True(result.Duration >= time.Duration(i)*time.Millisecond)
+		suite.NoError(result.Err)
+	}
+}
+
+// TestRunInParallelWithResultsFail tests that the failure of an instance
+// is recorded in its result.
+func (suite *TaskTestSuite) TestRunInParallelWithResultsFail() {
+	worker := func(id uint32) error {
+		return yarpcerrors.InternalErrorf("test error")
+	}
+
+	results, err := RunInParallelWithResults(
+		uuid.NewRandom().String(), []uint32{0}, worker)
+	suite.Error(err)
+	suite.Len(results, 1)
+	suite.Equal(uint32(0), results[0].InstanceID)
+	suite.Error(results[0].Err)
+}
+
+// TestRunRangeInParallel tests that running an action on a range of
+// instances gives the same result as running it on the list of instances
+// in the range.
+func (suite *TaskTestSuite) TestRunRangeInParallel() {
+	ranges := []struct {
+		start uint32
+		end   uint32
+	}{
+		{0, 0},
+		{5, 3},
+		{0, 5},
+		{10, 11},
+		{7, 2500},
+	}
+
+	for _, r := range ranges {
+		var instances []uint32
+		for i := r.start; i < r.end; i++ {
+			instances = append(instances, i)
+		}
+
+		var lock sync.Mutex
+		visitedList := make(map[uint32]int)
+		visitedRange := make(map[uint32]int)
+		worker := func(visited map[uint32]int) singleTask {
+			return func(id uint32) error {
+				lock.Lock()
+				defer lock.Unlock()
+				visited[id]++
+				return nil
+			}
+		}
+
+		suite.NoError(RunInParallel(
+			uuid.NewRandom().String(), instances, worker(visitedList)))
+		suite.NoError(RunRangeInParallel(
+			uuid.NewRandom().String(), r.start, r.end, worker(visitedRange)))
+		suite.Equal(visitedList, visitedRange)
+		suite.Len(visitedRange, len(instances))
+	}
+}
+
+// TestRunRangeInParallelFail tests failure scenario for running actions
+// on a range of instances in parallel.
+func (suite *TaskTestSuite) TestRunRangeInParallelFail() {
+	worker := func(id uint32) error {
+		if id == 12 {
+			return yarpcerrors.AbortedErrorf("test error")
+		}
+		return nil
+	}
+	err := RunRangeInParallel(uuid.NewRandom().String(), 10, 15, worker)
+	suite.True(yarpcerrors.IsAborted(err))
+
+	worker = func(id uint32) error {
+		if id == 12 {
+			return yarpcerrors.InternalErrorf("test error")
+		}
+		return nil
+	}
+	err = RunRangeInParallel(uuid.NewRandom().String(), 10, 15, worker)
+	suite.True(yarpcerrors.IsInternal(err))
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podname
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/pborman/uuid"
+)
+
+// A pod name is formatted as <job uuid>-<instance id>, and a pod id as
+// <job uuid>-<instance id>-<run id>.
+
+// InvalidFormatError is returned when a pod name or a pod id does not
+// follow the expected format.
+type InvalidFormatError struct {
+	// Kind is either "pod name" or "pod id".
+	Kind string
+	// Value is the malformed value.
+	Value string
+	// Reason describes why the value is malformed.
+	Reason string
+}
+
+func (e *InvalidFormatError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Value, e.Reason)
+}
+
+// IsInvalidFormat returns true if err is an InvalidFormatError.
+func IsInvalidFormat(err error) bool {
+	_, ok := err.(*InvalidFormatError)
+	return ok
+}
+
+// FormatPodName builds the pod name from the job id and instance id.
+func FormatPodName(jobID string, instanceID uint32) *v1alphapeloton.PodName {
+	return &v1alphapeloton.PodName{
+		Value: jobID + "-" + strconv.FormatUint(uint64(instanceID), 10),
+	}
+}
+
+// FormatPodID builds the pod id from the job id, instance id and run id.
+func FormatPodID(
+	jobID string,
+	instanceID uint32,
+	runID uint64,
+) *v1alphapeloton.PodID {
+	return &v1alphapeloton.PodID{
+		Value: FormatPodName(jobID, instanceID).GetValue() +
+			"-" + strconv.FormatUint(runID, 10),
+	}
+}
+
+// ParsePodName parses the job id and instance id from the pod name.
+func ParsePodName(
+	podName *v1alphapeloton.PodName,
+) (jobID string, instanceID uint32, err error) {
+	return parse("pod name", podName.GetValue(), podName.GetValue())
+}
+
+// ParsePodID parses the job id, instance id and run id from the pod id.
+func ParsePodID(
+	podID *v1alphapeloton.PodID,
+) (jobID string, instanceID uint32, runID uint64, err error) {
+	value := podID.GetValue()
+	pos := strings.LastIndex(value, "-")
+	if pos == -1 {
+		return "", 0, 0, &InvalidFormatError{
+			Kind:   "pod id",
+			Value:  value,
+			Reason: "missing run id",
+		}
+	}
+
+	runID, err = strconv.ParseUint(value[pos+1:], 10, 64)
+	if err != nil {
+		return "", 0, 0, &InvalidFormatError{
+			Kind:   "pod id",
+			Value:  value,
+			Reason: "run id is not a number",
+		}
+	}
+
+	jobID, instanceID, err = parse("pod id", value, value[:pos])
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return jobID, instanceID, runID, nil
+}
+
+// parse parses the job id and instance id from name, reporting errors
+// against value of the given kind.
+func parse(kind, value, name string) (string, uint32, error) {
+	pos := strings.LastIndex(name, "-")
+	if pos == -1 {
+		return "", 0, &InvalidFormatError{
+			Kind:   kind,
+			Value:  value,
+			Reason: "missing instance id",
+		}
+	}
+
+	jobID := name[:pos]
+	if uuid.Parse(jobID) == nil {
+		return "", 0, &InvalidFormatError{
+			Kind:   kind,
+			Value:  value,
+			Reason: "job id is not a uuid",
+		}
+	}
+
+	instanceID, err := strconv.ParseUint(name[pos+1:], 10, 32)
+	if err != nil {
+		return "", 0, &InvalidFormatError{
+			Kind:   kind,
+			Value:  value,
+			Reason: "instance id is not a number",
+		}
+	}
+	return jobID, uint32(instanceID), nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podname
+
+import (
+	"testing"
+
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFormatAndParsePodName tests that a formatted pod name is parsed back
+// into its job id and instance id.
+func TestFormatAndParsePodName(t *testing.T) {
+	jobID := uuid.New()
+
+	podName := FormatPodName(jobID, 12)
+	assert.Equal(t, jobID+"-12", podName.GetValue())
+
+	parsedJobID, instanceID, err := ParsePodName(podName)
+	assert.NoError(t, err)
+	assert.Equal(t, jobID, parsedJobID)
+	assert.Equal(t, uint32(12), instanceID)
+}
+
+// TestFormatAndParsePodID tests that a formatted pod id is parsed back
+// into its job id, instance id and run id.
+func TestFormatAndParsePodID(t *testing.T) {
+	jobID := uuid.New()
+
+	podID := FormatPodID(jobID, 3, 7)
+	assert.Equal(t, jobID+"-3-7", podID.GetValue())
+
+	parsedJobID, instanceID, runID, err := ParsePodID(podID)
+	assert.NoError(t, err)
+	assert.Equal(t, jobID, parsedJobID)
+	assert.Equal(t, uint32(3), instanceID)
+	assert.Equal(t, uint64(7), runID)
+}
+
+// TestParsePodNameInvalid tests that malformed pod names are rejected
+// with an InvalidFormatError.
+func TestParsePodNameInvalid(t *testing.T) {
+	jobID := uuid.New()
+
+	testCases := []struct {
+		name    string
+		podName string
+	}{
+		{"empty", ""},
+		{"no instance id", jobID},
+		{"job id not a uuid", "invalid-name"},
+		{"truncated job id", jobID[:len(jobID)-1] + "-1"},
+		{"instance id not a number", jobID + "-a"},
+		{"negative instance id", jobID + "--1"},
+		{"instance id overflow", jobID + "-4294967296"},
+		{"pod id", jobID + "-1-1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParsePodName(&v1alphapeloton.PodName{Value: tt.podName})
+			assert.Error(t, err)
+			assert.True(t, IsInvalidFormat(err))
+		})
+	}
+}
+
+// TestParsePodIDInvalid tests that malformed pod ids are rejected
+// with an InvalidFormatError.
+func TestParsePodIDInvalid(t *testing.T) {
+	jobID := uuid.New()
+
+	testCases := []struct {
+		name  string
+		podID string
+	}{
+		{"empty", ""},
+		{"no run id", jobID + "-1"},
+		{"run id not a number", jobID + "-1-a"},
+		{"legacy uuid run id", jobID + "-1-" + uuid.New()},
+		{"job id not a uuid", "job-1-1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := ParsePodID(&v1alphapeloton.PodID{Value: tt.podID})
+			assert.Error(t, err)
+			assert.True(t, IsInvalidFormat(err))
+		})
+	}
+}
@@ -122,6 +122,13 @@ func ConvertTaskToResMgrTask(
 		Revocable:         taskInfo.GetConfig().GetRevocable(),
 		DesiredHost:       taskInfo.GetRuntime().GetDesiredHost(),
 		PlacementStrategy: jobConfig.GetPlacementStrategy(),
+		RespoolID:         jobConfig.GetRespoolID(),
+		HostPool:          taskInfo.GetConfig().GetHostPool(),
+		SoftConstraint:    taskInfo.GetConfig().GetSoftConstraint(),
+		AllowedHosts:      taskInfo.GetConfig().GetAllowedHosts(),
+		DeniedHosts:       taskInfo.GetConfig().GetDeniedHosts(),
+
+		AntiAffinityJobLabels: taskInfo.GetConfig().GetAntiAffinityJobLabels(),
 	}
 
 	taskState := taskInfo.GetRuntime().GetState()
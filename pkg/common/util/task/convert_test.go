@@ -127,6 +127,7 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 	jobConfig := &job.JobConfig{
 		SLA:               &job.SlaConfig{},
 		PlacementStrategy: job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
+		RespoolID:         &peloton.ResourcePoolID{Value: uuid.New()},
 	}
 	for _, taskInfo := range taskInfos {
 		rmTask := ConvertTaskToResMgrTask(taskInfo, jobConfig)
@@ -144,9 +145,48 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 			t,
 			job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
 			rmTask.GetPlacementStrategy())
+		assert.Equal(t, jobConfig.GetRespoolID(), rmTask.GetRespoolID())
 	}
 }
 
+// TestConvertTaskToResMgrTaskPlacementConfig tests that the placement
+// configuration of the task config is copied to the resmgr task.
+func TestConvertTaskToResMgrTaskPlacementConfig(t *testing.T) {
+	softConstraint := &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:        task.LabelConstraint_HOST,
+			Condition:   task.LabelConstraint_CONDITION_EQUAL,
+			Label:       &peloton.Label{Key: "storage", Value: "ssd"},
+			Requirement: 1,
+		},
+	}
+	antiAffinityJobLabel := &peloton.Label{Key: "service", Value: "cache"}
+	taskInfo := &task.TaskInfo{
+		InstanceId: 0,
+		JobId:      &peloton.JobID{Value: uuid.New()},
+		Config: &task.TaskConfig{
+			HostPool:              "pool",
+			SoftConstraint:        softConstraint,
+			AllowedHosts:          []string{"host1", "host2"},
+			DeniedHosts:           []string{"host3"},
+			AntiAffinityJobLabels: []*peloton.Label{antiAffinityJobLabel},
+		},
+		Runtime: &task.RuntimeInfo{
+			State: task.TaskState_INITIALIZED,
+		},
+	}
+
+	rmTask := ConvertTaskToResMgrTask(taskInfo, &job.JobConfig{})
+	assert.Equal(t, "pool", rmTask.GetHostPool())
+	assert.Equal(t, softConstraint, rmTask.GetSoftConstraint())
+	assert.Equal(t, []string{"host1", "host2"}, rmTask.GetAllowedHosts())
+	assert.Equal(t, []string{"host3"}, rmTask.GetDeniedHosts())
+	assert.Equal(t,
+		[]*peloton.Label{antiAffinityJobLabel},
+		rmTask.GetAntiAffinityJobLabels())
+}
+
 func TestConvertToResMgrGangs(t *testing.T) {
 	jobConfig := &job.JobConfig{
 		SLA: &job.SlaConfig{
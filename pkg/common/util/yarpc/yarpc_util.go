@@ -3,6 +3,8 @@ package yarpc
 import (
 	"context"
 
+	"github.com/uber/peloton/pkg/common/util/podname"
+
 	"github.com/pkg/errors"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/yarpcerrors"
@@ -27,10 +29,13 @@ func ConvertToYARPCError(err error) error {
 	}
 
 	// if the cause of the error is yarpc error, retain the
-	// error code. Otherwise, use internal error code.
+	// error code. Malformed pod names and pod ids are invalid
+	// arguments. Otherwise, use internal error code.
 	statusCode := yarpcerrors.CodeInternal
 	if yarpcerrors.IsStatus(errors.Cause(err)) {
 		statusCode = errors.Cause(err).(*yarpcerrors.Status).Code()
+	} else if podname.IsInvalidFormat(errors.Cause(err)) {
+		statusCode = yarpcerrors.CodeInvalidArgument
 	}
 	return yarpcerrors.Newf(statusCode, err.Error())
 }
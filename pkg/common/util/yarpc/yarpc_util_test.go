@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/uber/peloton/pkg/common/util/podname"
+
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/yarpc/api/encoding"
@@ -42,3 +44,9 @@ func TestConvertToYARPCErrorForNonYARPCError(t *testing.T) {
 	err := ConvertToYARPCError(errors.New("test error"))
 	assert.True(t, yarpcerrors.IsInternal(err))
 }
+
+func TestConvertToYARPCErrorForInvalidPodName(t *testing.T) {
+	_, _, err := podname.ParsePodName(nil)
+	err = ConvertToYARPCError(errors.Wrap(err, "test message"))
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+}
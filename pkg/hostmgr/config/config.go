@@ -17,11 +17,19 @@ package config
 import (
 	"time"
 
+	commonconfig "github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/hostmgr/goalstate"
 	"github.com/uber/peloton/pkg/hostmgr/reconcile"
 	"github.com/uber/peloton/pkg/hostmgr/watchevent"
 )
 
+const (
+	// DefragBinPackingFeatureFlag is the feature flag which makes host
+	// manager rank hosts using the defrag bin packing ranker, overriding
+	// the configured ranker.
+	DefragBinPackingFeatureFlag = "hostmgr_defrag_binpacking"
+)
+
 // Config is Host Manager specific configuration
 type Config struct {
 	// HTTP port which hostmgr is listening on
@@ -96,4 +104,8 @@ type Config struct {
 
 	// GoalState configuration
 	GoalState goalstate.Config `yaml:"goal_state"`
+
+	// FeatureFlags toggles features of host manager which are being
+	// rolled out gradually.
+	FeatureFlags commonconfig.FeatureFlags `yaml:"feature_flags"`
 }
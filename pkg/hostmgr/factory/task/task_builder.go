@@ -596,12 +596,16 @@ func (tb *Builder) extractScalarResources(
 		if minimum.Empty() {
 			continue
 		}
-		rs := util.CreateMesosScalarResources(map[string]float64{
+		values := map[string]float64{
 			common.MesosCPU:  minimum.CPU,
 			common.MesosMem:  minimum.Mem,
 			common.MesosDisk: minimum.Disk,
 			common.MesosGPU:  minimum.GPU,
-		}, role)
+		}
+		for name, value := range minimum.Named {
+			values[name] = value
+		}
+		rs := util.CreateMesosScalarResources(values, role)
 
 		launchResources = append(launchResources, rs...)
 
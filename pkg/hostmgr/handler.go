@@ -53,6 +53,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/watchevent"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -566,7 +567,7 @@ func (h *ServiceHandler) AcquireHostOffers(
 		pHostOffer := hostsvc.HostOffer{
 			Hostname:   hostname,
 			AgentId:    offers[0].GetAgentId(),
-			Attributes: offers[0].GetAttributes(),
+			Attributes: h.hostOfferAttributes(hostname, offers[0].GetAttributes()),
 			Resources:  resources,
 			Id:         &peloton.HostOfferID{Value: hostOffer.ID},
 		}
@@ -581,6 +582,31 @@ func (h *ServiceHandler) AcquireHostOffers(
 	return response, nil
 }
 
+// hostOfferAttributes returns the attributes of a host offer, which are
+// the agent attributes along with the host pool of the host if host pools
+// are enabled, so that placement can verify the pool of the host.
+func (h *ServiceHandler) hostOfferAttributes(
+	hostname string,
+	attributes []*mesos.Attribute,
+) []*mesos.Attribute {
+	if h.hostPoolManager == nil {
+		return attributes
+	}
+
+	pool, err := h.hostPoolManager.GetPoolByHostname(hostname)
+	if err != nil {
+		return attributes
+	}
+
+	result := make([]*mesos.Attribute, 0, len(attributes)+1)
+	result = append(result, attributes...)
+	return append(result, &mesos.Attribute{
+		Name: proto.String(common.HostPoolKey),
+		Type: mesos.Value_TEXT.Enum(),
+		Text: &mesos.Value_Text{Value: proto.String(pool.ID())},
+	})
+}
+
 // GetHosts implements InternalHostService.GetHosts.
 // This function gets the hosts based on resource requirements
 // and constraints passed in the request through hostsvc.HostFilter
@@ -15,6 +15,7 @@
 package hostmgr
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -250,6 +251,67 @@ func (suite *RecoveryTestSuite) TestStart() {
 	suite.NoError(err)
 }
 
+// TestStartRecoversTasksOnMultipleHosts tests that recovery rebuilds the
+// host to task map from the running tasks in the task store.
+func (suite *RecoveryTestSuite) TestStartRecoversTasksOnMultipleHosts() {
+	hostnames := []string{"host1", "host1", "host2"}
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	jobConfig := &job.JobConfig{
+		RespoolID:     &peloton.ResourcePoolID{Value: uuid.NewRandom().String()},
+		InstanceCount: uint32(len(hostnames)),
+	}
+
+	taskInfos := make(map[uint32]*task.TaskInfo)
+	for i, hostname := range hostnames {
+		taskID := fmt.Sprintf("%s-%d-1", jobID.GetValue(), i)
+		taskInfos[uint32(i)] = &task.TaskInfo{
+			InstanceId: uint32(i),
+			Runtime: &task.RuntimeInfo{
+				Host:        hostname,
+				MesosTaskId: &mesos.TaskID{Value: &taskID},
+				State:       task.TaskState_RUNNING,
+				GoalState:   task.TaskState_RUNNING,
+			},
+		}
+	}
+
+	suite.activeJobsOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*peloton.JobID{jobID}, nil)
+
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), jobID).
+		Return(&job.RuntimeInfo{
+			State:     job.JobState_RUNNING,
+			GoalState: job.JobState_SUCCEEDED,
+		}, nil)
+
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), jobID, gomock.Any()).
+		Return(jobConfig, &models.ConfigAddOn{}, nil)
+
+	suite.mockTaskStore.EXPECT().
+		GetTasksForJobByRange(gomock.Any(), jobID, gomock.Any()).
+		Return(taskInfos, nil)
+
+	suite.hostcache.EXPECT().RecoverPodInfoOnHost(
+		gomock.Any(),
+		gomock.Any(),
+		gomock.Any(),
+		gomock.Any(),
+	).Times(len(hostnames))
+
+	suite.NoError(suite.recoveryHandler.Start())
+
+	pool := offer.GetEventHandler().GetOfferPool()
+	summary, err := pool.GetHostSummary("host1")
+	suite.NoError(err)
+	suite.Equal(2, len(summary.GetTasks()))
+	summary, err = pool.GetHostSummary("host2")
+	suite.NoError(err)
+	suite.Equal(1, len(summary.GetTasks()))
+}
+
 func (suite *RecoveryTestSuite) TestStartDBRecoveryFailure() {
 	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
 	jobConfig := &job.JobConfig{
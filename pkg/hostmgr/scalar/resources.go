@@ -17,6 +17,8 @@ package scalar
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"sync"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -33,6 +35,10 @@ type Resources struct {
 	Mem  float64
 	Disk float64
 	GPU  float64
+
+	// Named holds scalar resources other than the ones above, such as
+	// FPGAs, keyed by Mesos resource name. It is nil if there are none.
+	Named map[string]float64
 }
 
 // a safe less than or equal to comparator which takes epsilon into consideration.
@@ -82,10 +88,18 @@ func (r Resources) HasGPU() bool {
 // Contains determines whether current Resources is large enough to contain
 // the other one.
 func (r Resources) Contains(other Resources) bool {
-	return lessThanOrEqual(other.CPU, r.CPU) &&
-		lessThanOrEqual(other.Mem, r.Mem) &&
-		lessThanOrEqual(other.Disk, r.Disk) &&
-		lessThanOrEqual(other.GPU, r.GPU)
+	if !lessThanOrEqual(other.CPU, r.CPU) ||
+		!lessThanOrEqual(other.Mem, r.Mem) ||
+		!lessThanOrEqual(other.Disk, r.Disk) ||
+		!lessThanOrEqual(other.GPU, r.GPU) {
+		return false
+	}
+	for name, value := range other.Named {
+		if !lessThanOrEqual(value, r.Named[name]) {
+			return false
+		}
+	}
+	return true
 }
 
 // Compare method compares current Resources with the other one, return
@@ -117,36 +131,117 @@ func (r Resources) Compare(other Resources, cmpLess bool) bool {
 	if other.Disk > 0 && lessThan(r.Disk, other.Disk) != cmpLess {
 		return false
 	}
+	for name, value := range other.Named {
+		if value > 0 && lessThan(r.Named[name], value) != cmpLess {
+			return false
+		}
+	}
 	return true
 }
 
 // Add atomically add another scalar resources onto current one.
 func (r Resources) Add(other Resources) Resources {
 	return Resources{
-		CPU:  r.CPU + other.CPU,
-		Mem:  r.Mem + other.Mem,
-		Disk: r.Disk + other.Disk,
-		GPU:  r.GPU + other.GPU,
+		CPU:   r.CPU + other.CPU,
+		Mem:   r.Mem + other.Mem,
+		Disk:  r.Disk + other.Disk,
+		GPU:   r.GPU + other.GPU,
+		Named: combineNamed(r.Named, other.Named, 1),
 	}
 }
 
 // TrySubtract attempts to subtract another scalar resources from current one
-// , but returns false if other has more resources.
+// , but returns false if other has more resources. Amounts within epsilon of
+// each other are treated as sufficient, and positive leftovers smaller than
+// epsilon are rounded down to zero so floating point drift does not pile up.
+// Negative leftovers are kept, so a series of subtractions can never
+// over-commit by more than epsilon in total.
 func (r Resources) TrySubtract(other Resources) (Resources, bool) {
 	if !r.Contains(other) {
 		return Resources{}, false
 	}
-	return r.Subtract(other), true
+	result := r.Subtract(other)
+	result.CPU = roundRemainder(result.CPU)
+	result.Mem = roundRemainder(result.Mem)
+	result.Disk = roundRemainder(result.Disk)
+	result.GPU = roundRemainder(result.GPU)
+	for name, value := range result.Named {
+		result.Named[name] = roundRemainder(value)
+	}
+	return result, true
+}
+
+// WeightedFit scores how well the needed resources fit into the resources
+// r, as the sum over the cpu, mem, disk and gpu dimensions of the fraction
+// of r taken by the need, multiplied by the weight of the dimension. A
+// lower score is a better fit, which leaves more of the heavily weighted
+// resources available. The score is +Inf if the need does not fit.
+func (r Resources) WeightedFit(need, weights Resources) float64 {
+	if !r.Contains(need) {
+		return math.Inf(1)
+	}
+	return weightedFraction(need.CPU, r.CPU, weights.CPU) +
+		weightedFraction(need.Mem, r.Mem, weights.Mem) +
+		weightedFraction(need.Disk, r.Disk, weights.Disk) +
+		weightedFraction(need.GPU, r.GPU, weights.GPU)
+}
+
+// weightedFraction returns the weighted fraction of the available amount
+// of a resource taken by the needed amount.
+func weightedFraction(need, available, weight float64) float64 {
+	if need <= 0 || weight == 0 {
+		return 0
+	}
+	if available <= need {
+		return weight
+	}
+	return weight * need / available
+}
+
+// roundRemainder rounds a positive amount smaller than epsilon down to zero.
+func roundRemainder(v float64) float64 {
+	if v > 0 && v < util.ResourceEpsilon {
+		return 0
+	}
+	return v
 }
 
 // Subtract another scalar resources from current one and return a new copy of result.
 func (r Resources) Subtract(other Resources) Resources {
 	return Resources{
-		CPU:  r.CPU - other.CPU,
-		Mem:  r.Mem - other.Mem,
-		Disk: r.Disk - other.Disk,
-		GPU:  r.GPU - other.GPU,
+		CPU:   r.CPU - other.CPU,
+		Mem:   r.Mem - other.Mem,
+		Disk:  r.Disk - other.Disk,
+		GPU:   r.GPU - other.GPU,
+		Named: combineNamed(r.Named, other.Named, -1),
+	}
+}
+
+// combineNamed returns a new map holding the named resources of r1 plus
+// sign times the named resources of r2. It returns nil if both are empty,
+// and never modifies r1 or r2.
+func combineNamed(r1, r2 map[string]float64, sign float64) map[string]float64 {
+	if len(r1) == 0 && len(r2) == 0 {
+		return nil
+	}
+	result := make(map[string]float64, len(r1)+len(r2))
+	for name, value := range r1 {
+		result[name] = value
+	}
+	for name, value := range r2 {
+		result[name] += sign * value
+	}
+	return result
+}
+
+// namedKeys returns the names of the named resources in sorted order.
+func (r Resources) namedKeys() []string {
+	names := make([]string, 0, len(r.Named))
+	for name := range r.Named {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
 
 // NonEmptyFields returns corresponding Mesos resource names for fields which are not empty.
@@ -164,6 +259,11 @@ func (r Resources) NonEmptyFields() []string {
 	if math.Abs(r.GPU) > util.ResourceEpsilon {
 		nonEmptyFields = append(nonEmptyFields, "gpus")
 	}
+	for _, name := range r.namedKeys() {
+		if math.Abs(r.Named[name]) > util.ResourceEpsilon {
+			nonEmptyFields = append(nonEmptyFields, name)
+		}
+	}
 
 	return nonEmptyFields
 }
@@ -175,8 +275,13 @@ func (r Resources) Empty() bool {
 
 // String returns a formatted string for scalar resources
 func (r Resources) String() string {
-	return fmt.Sprintf("CPU:%.2f MEM:%.2f DISK:%.2f GPU:%.2f",
+	var b strings.Builder
+	fmt.Fprintf(&b, "CPU:%.2f MEM:%.2f DISK:%.2f GPU:%.2f",
 		r.GetCPU(), r.GetMem(), r.GetDisk(), r.GetGPU())
+	for _, name := range r.namedKeys() {
+		fmt.Fprintf(&b, " %s:%.2f", name, r.Named[name])
+	}
+	return b.String()
 }
 
 // HasResourceType validates requested resource type is present agent resource type.
@@ -219,6 +324,12 @@ func FromResourceConfig(rc *task.ResourceConfig) (r Resources) {
 	r.Mem = rc.GetMemLimitMb()
 	r.Disk = rc.GetDiskLimitMb()
 	r.GPU = rc.GetGpuLimit()
+	if len(rc.GetScalarResources()) > 0 {
+		r.Named = make(map[string]float64, len(rc.GetScalarResources()))
+		for name, value := range rc.GetScalarResources() {
+			r.Named[name] = value
+		}
+	}
 	return r
 }
 
@@ -281,6 +392,11 @@ func FromMesosResource(resource *mesos.Resource) (r Resources) {
 		r.Disk += value
 	case "gpus":
 		r.GPU += value
+	default:
+		// Resources without a scalar value, such as ports, are skipped.
+		if name != "" && resource.GetScalar() != nil {
+			r.Named = map[string]float64{name: value}
+		}
 	}
 	return r
 }
@@ -351,6 +467,14 @@ func Minimum(r1, r2 Resources) (m Resources) {
 	m.Mem = math.Min(r1.Mem, r2.Mem)
 	m.Disk = math.Min(r1.Disk, r2.Disk)
 	m.GPU = math.Min(r1.GPU, r2.GPU)
+	for name, value := range r1.Named {
+		if other, ok := r2.Named[name]; ok {
+			if m.Named == nil {
+				m.Named = make(map[string]float64)
+			}
+			m.Named[name] = math.Min(value, other)
+		}
+	}
 	return m
 }
 
@@ -15,6 +15,7 @@
 package scalar
 
 import (
+	"math"
 	"strconv"
 	"testing"
 
@@ -352,6 +353,106 @@ func TestTrySubtract(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestWeightedFit(t *testing.T) {
+	need := createResource(2, 0, 1000, 0)
+	cpuTight := createResource(4, 0, 10000, 100)
+	memTight := createResource(20, 0, 2000, 100)
+
+	equal := createResource(1, 1, 1, 1)
+	assert.InDelta(t, 0.6, cpuTight.WeightedFit(need, equal), _zeroDelta)
+	assert.InDelta(t, 0.6, memTight.WeightedFit(need, equal), _zeroDelta)
+
+	memWeighted := createResource(1, 1, 4, 1)
+	assert.InDelta(t, 0.9, cpuTight.WeightedFit(need, memWeighted), _zeroDelta)
+	assert.InDelta(t, 2.1, memTight.WeightedFit(need, memWeighted), _zeroDelta)
+
+	// a need taking all of a resource takes its full weight
+	assert.InDelta(t, 1.5, need.WeightedFit(need, createResource(1, 0, 0.5, 0)),
+		_zeroDelta)
+
+	// a need which does not fit has the worst score
+	assert.True(t, math.IsInf(need.WeightedFit(cpuTight, equal), 1))
+}
+
+func TestTrySubtractNamed(t *testing.T) {
+	host := Resources{
+		CPU:   2.0,
+		Named: map[string]float64{"fpgas": 2.0},
+	}
+	task := Resources{
+		CPU:   1.0,
+		Named: map[string]float64{"fpgas": 1.5},
+	}
+
+	res, ok := host.TrySubtract(task)
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, res.CPU, _zeroDelta)
+	assert.InDelta(t, 0.5, res.Named["fpgas"], _zeroDelta)
+
+	// host is not modified
+	assert.InDelta(t, 2.0, host.Named["fpgas"], _zeroDelta)
+
+	// not enough of the named resource left
+	_, ok = res.TrySubtract(task)
+	assert.False(t, ok)
+
+	// host lacks the named resource
+	_, ok = Resources{CPU: 2.0}.TrySubtract(task)
+	assert.False(t, ok)
+
+	// resources without named resources do not carry an empty map
+	res, ok = Resources{CPU: 2.0}.TrySubtract(Resources{CPU: 1.0})
+	assert.True(t, ok)
+	assert.Nil(t, res.Named)
+}
+
+func TestTrySubtractFractionalPacking(t *testing.T) {
+	offer := Resources{CPU: 1.0, Mem: 1.0}
+	task := Resources{CPU: 0.1, Mem: 0.1}
+
+	// tasks summing to exactly the offer all fit, despite float drift
+	left := offer
+	for i := 0; i < 10; i++ {
+		var ok bool
+		left, ok = left.TrySubtract(task)
+		assert.True(t, ok, "task %d does not fit", i)
+	}
+	assert.Equal(t, 0.0, left.CPU)
+	assert.Equal(t, 0.0, left.Mem)
+
+	// nothing more fits
+	_, ok := left.TrySubtract(task)
+	assert.False(t, ok)
+
+	// an amount within epsilon fits once, but the deficit is carried so
+	// the total over-commit stays within epsilon
+	tiny := Resources{CPU: 0.0005}
+	left, ok = left.TrySubtract(tiny)
+	assert.True(t, ok)
+	assert.InDelta(t, -0.0005, left.CPU, _zeroDelta)
+	_, ok = left.TrySubtract(tiny)
+	assert.False(t, ok)
+}
+
+func TestFromMesosResourcesNamed(t *testing.T) {
+	rs := []*mesos.Resource{
+		util.NewMesosResourceBuilder().WithName("cpus").WithValue(1.0).Build(),
+		util.NewMesosResourceBuilder().WithName("fpgas").WithValue(2.0).Build(),
+		util.NewMesosResourceBuilder().WithName("fpgas").WithValue(1.0).Build(),
+	}
+
+	result := FromMesosResources(rs)
+	assert.InDelta(t, 1.0, result.CPU, _zeroDelta)
+	assert.InDelta(t, 3.0, result.Named["fpgas"], _zeroDelta)
+	assert.Equal(t, []string{"cpus", "fpgas"}, result.NonEmptyFields())
+
+	result = FromResourceConfig(&task.ResourceConfig{
+		CpuLimit:        1.0,
+		ScalarResources: map[string]float64{"fpgas": 1.0},
+	})
+	assert.InDelta(t, 1.0, result.Named["fpgas"], _zeroDelta)
+}
+
 func TestFromOfferMap(t *testing.T) {
 	rs := []*mesos.Resource{
 		util.NewMesosResourceBuilder().WithName("cpus").WithValue(1.0).Build(),
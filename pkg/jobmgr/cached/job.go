@@ -675,6 +675,22 @@ func (j *job) RemoveTask(id uint32) {
 	delete(j.tasks, id)
 }
 
+// evictTerminalTasks evicts the runtimes of the tasks which have been
+// terminal for the idle period from the cache, and returns the number of
+// evicted tasks.
+func (j *job) evictTerminalTasks(idle time.Duration, now time.Time) int {
+	j.RLock()
+	defer j.RUnlock()
+
+	var evicted int
+	for _, t := range j.tasks {
+		if t.evictIfTerminal(idle, now) {
+			evicted++
+		}
+	}
+	return evicted
+}
+
 func (j *job) GetAllTasks() map[uint32]Task {
 	j.RLock()
 	defer j.RUnlock()
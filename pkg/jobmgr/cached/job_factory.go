@@ -52,6 +52,11 @@ type JobFactory interface {
 	// GetAllJobs returns the list of all jobs in cache.
 	GetAllJobs() map[string]Job
 
+	// EvictTerminalTasks evicts the runtimes of the tasks which have been
+	// terminal for the idle period from the cache. They are read from the
+	// DB again when needed.
+	EvictTerminalTasks(idle time.Duration)
+
 	// Start emitting metrics.
 	Start()
 
@@ -166,6 +171,22 @@ func (f *jobFactory) GetAllJobs() map[string]Job {
 	return jobMap
 }
 
+func (f *jobFactory) EvictTerminalTasks(idle time.Duration) {
+	f.RLock()
+	jobs := make([]*job, 0, len(f.jobs))
+	for _, j := range f.jobs {
+		jobs = append(jobs, j)
+	}
+	f.RUnlock()
+
+	var evicted int
+	now := time.Now()
+	for _, j := range jobs {
+		evicted += j.evictTerminalTasks(idle, now)
+	}
+	f.mtx.scope.Counter("terminal_tasks_evicted").Inc(int64(evicted))
+}
+
 // Start the job factory, starts emitting metrics.
 func (f *jobFactory) Start() {
 	f.Lock()
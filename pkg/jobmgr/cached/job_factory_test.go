@@ -90,6 +90,57 @@ func TestStartStop(t *testing.T) {
 	assert.Nil(t, f.GetJob(jobID))
 }
 
+// TestEvictTerminalTasks tests that only the tasks which have been terminal
+// for the idle period are evicted from the cache.
+func TestEvictTerminalTasks(t *testing.T) {
+	testScope := tally.NewTestScope("", nil)
+	f := &jobFactory{
+		jobs:    map[string]*job{},
+		mtx:     NewMetrics(testScope),
+		running: true,
+	}
+
+	idleSince := uint64(time.Now().Add(-time.Hour).UnixNano())
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	taskInfos := map[uint32]*pbtask.TaskInfo{
+		0: {
+			Runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_RUNNING,
+				GoalState: pbtask.TaskState_RUNNING,
+				Revision:  &peloton.ChangeLog{Version: 1, UpdatedAt: idleSince},
+			},
+		},
+		1: {
+			Runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_SUCCEEDED,
+				GoalState: pbtask.TaskState_SUCCEEDED,
+				Revision:  &peloton.ChangeLog{Version: 1, UpdatedAt: idleSince},
+			},
+		},
+		2: {
+			Runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_KILLED,
+				GoalState: pbtask.TaskState_KILLED,
+				Revision: &peloton.ChangeLog{
+					Version:   1,
+					UpdatedAt: uint64(time.Now().UnixNano()),
+				},
+			},
+		},
+	}
+	j := f.AddJob(jobID)
+	j.ReplaceTasks(taskInfos, false)
+
+	f.EvictTerminalTasks(time.Minute)
+
+	assert.NotNil(t, j.GetTask(0).GetCacheRuntime())
+	assert.Nil(t, j.GetTask(1).GetCacheRuntime())
+	assert.Equal(t, pbtask.TaskState_SUCCEEDED, j.GetTask(1).CurrentState().State)
+	assert.NotNil(t, j.GetTask(2).GetCacheRuntime())
+	assert.Equal(t, int64(1),
+		testScope.Snapshot().Counters()["terminal_tasks_evicted+"].Value())
+}
+
 // TestPublishMetrics tests publishing metrics from the job factory.
 func TestPublishMetrics(t *testing.T) {
 	testScope := tally.NewTestScope("", nil)
@@ -132,6 +132,10 @@ type task struct {
 
 	runtime *pbtask.RuntimeInfo // task runtime information
 
+	// evicted holds the state of a terminal task whose runtime has been
+	// evicted from the cache, and is only used while runtime is nil.
+	evicted *pbtask.RuntimeInfo
+
 	config *taskConfigCache // task configuration information
 
 	initializedAt time.Time // Task intialization timestamp
@@ -295,9 +299,53 @@ func (t *task) updateConfig(ctx context.Context, configVersion uint64) error {
 // It should be called with the write task lock held.
 func (t *task) cleanTaskCache() {
 	t.runtime = nil
+	t.evicted = nil
 	t.config = nil
 }
 
+// evictIfTerminal evicts the runtime and config of the task from the cache
+// if the task is terminal and its runtime has not changed for the idle
+// period. The states of the task are retained, and the runtime is read from
+// the DB again when it is needed. It returns true if the task is evicted.
+func (t *task) evictIfTerminal(idle time.Duration, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.runtime == nil ||
+		!util.IsPelotonStateTerminal(t.runtime.GetState()) {
+		return false
+	}
+
+	updatedAt := time.Unix(0, int64(t.runtime.GetRevision().GetUpdatedAt()))
+	if now.Sub(updatedAt) < idle {
+		return false
+	}
+
+	evicted := &pbtask.RuntimeInfo{
+		State:                t.runtime.GetState(),
+		GoalState:            t.runtime.GetGoalState(),
+		Healthy:              t.runtime.GetHealthy(),
+		ConfigVersion:        t.runtime.GetConfigVersion(),
+		DesiredConfigVersion: t.runtime.GetDesiredConfigVersion(),
+		MesosTaskId:          t.runtime.GetMesosTaskId(),
+		DesiredMesosTaskId:   t.runtime.GetDesiredMesosTaskId(),
+		TerminationStatus:    t.runtime.GetTerminationStatus(),
+	}
+	t.cleanTaskCache()
+	t.evicted = evicted
+	return true
+}
+
+// stateRuntime returns the runtime which the states of the task are read
+// from, which is the evicted state if the runtime is evicted from the cache.
+// At least a read lock should be held before invoking the API.
+func (t *task) stateRuntime() *pbtask.RuntimeInfo {
+	if t.runtime == nil {
+		return t.evicted
+	}
+	return t.runtime
+}
+
 // createTask creates the task runtime in DB and cache
 func (t *task) createTask(ctx context.Context, runtime *pbtask.RuntimeInfo, owner string) error {
 	var runtimeCopy *pbtask.RuntimeInfo
@@ -698,10 +746,11 @@ func (t *task) CurrentState() TaskStateVector {
 	t.RLock()
 	defer t.RUnlock()
 
+	runtime := t.stateRuntime()
 	return TaskStateVector{
-		State:         t.runtime.GetState(),
-		ConfigVersion: t.runtime.GetConfigVersion(),
-		MesosTaskID:   t.runtime.GetMesosTaskId(),
+		State:         runtime.GetState(),
+		ConfigVersion: runtime.GetConfigVersion(),
+		MesosTaskID:   runtime.GetMesosTaskId(),
 	}
 }
 
@@ -709,10 +758,11 @@ func (t *task) GoalState() TaskStateVector {
 	t.RLock()
 	defer t.RUnlock()
 
+	runtime := t.stateRuntime()
 	return TaskStateVector{
-		State:         t.runtime.GetGoalState(),
-		ConfigVersion: t.runtime.GetDesiredConfigVersion(),
-		MesosTaskID:   t.runtime.GetDesiredMesosTaskId(),
+		State:         runtime.GetGoalState(),
+		ConfigVersion: runtime.GetDesiredConfigVersion(),
+		MesosTaskID:   runtime.GetDesiredMesosTaskId(),
 	}
 }
 
@@ -720,10 +770,11 @@ func (t *task) StateSummary() TaskStateSummary {
 	t.RLock()
 	defer t.RUnlock()
 
+	runtime := t.stateRuntime()
 	return TaskStateSummary{
-		CurrentState: t.runtime.GetState(),
-		GoalState:    t.runtime.GetGoalState(),
-		HealthState:  t.runtime.GetHealthy(),
+		CurrentState: runtime.GetState(),
+		GoalState:    runtime.GetGoalState(),
+		HealthState:  runtime.GetHealthy(),
 	}
 }
 
@@ -731,7 +782,7 @@ func (t *task) TerminationStatus() *pbtask.TerminationStatus {
 	t.RLock()
 	defer t.RUnlock()
 
-	return t.runtime.GetTerminationStatus()
+	return t.stateRuntime().GetTerminationStatus()
 }
 
 func (t *task) logStateTransitionMetrics(runtime *pbtask.RuntimeInfo) {
@@ -1191,3 +1191,53 @@ func (suite *taskTestSuite) TestStateSummary() {
 	suite.Equal(runtime.GetGoalState(), stateSummary.GoalState)
 	suite.Equal(runtime.GetHealthy(), stateSummary.HealthState)
 }
+
+// TestEvictIfTerminal tests that the runtime of a task which has been
+// terminal for the idle period is evicted from the cache while its states
+// are retained, and that the runtime is then read from the DB
+func (suite *taskTestSuite) TestEvictIfTerminal() {
+	now := time.Now()
+	runtime := &pbtask.RuntimeInfo{
+		State:                pbtask.TaskState_KILLED,
+		GoalState:            pbtask.TaskState_KILLED,
+		ConfigVersion:        2,
+		DesiredConfigVersion: 2,
+		Revision: &peloton.ChangeLog{
+			Version:   3,
+			UpdatedAt: uint64(now.Add(-time.Hour).UnixNano()),
+		},
+	}
+	suite.task.runtime = runtime
+
+	// not idle for long enough
+	suite.False(suite.task.evictIfTerminal(2*time.Hour, now))
+	suite.NotNil(suite.task.GetCacheRuntime())
+
+	suite.True(suite.task.evictIfTerminal(time.Minute, now))
+	suite.Nil(suite.task.GetCacheRuntime())
+	suite.Equal(pbtask.TaskState_KILLED, suite.task.CurrentState().State)
+	suite.Equal(uint64(2), suite.task.CurrentState().ConfigVersion)
+	suite.Equal(pbtask.TaskState_KILLED, suite.task.GoalState().State)
+
+	suite.taskStore.EXPECT().
+		GetTaskRuntime(gomock.Any(), suite.jobID, suite.instanceID).
+		Return(runtime, nil)
+	r, err := suite.task.GetRuntime(context.Background())
+	suite.NoError(err)
+	suite.Equal(runtime.GetRevision().GetVersion(), r.GetRevision().GetVersion())
+}
+
+// TestEvictIfTerminalNonTerminal tests that the runtime of a non-terminal
+// task is never evicted from the cache
+func (suite *taskTestSuite) TestEvictIfTerminalNonTerminal() {
+	suite.task.runtime = &pbtask.RuntimeInfo{
+		State:     pbtask.TaskState_RUNNING,
+		GoalState: pbtask.TaskState_RUNNING,
+		Revision:  &peloton.ChangeLog{Version: 1},
+	}
+	suite.False(suite.task.evictIfTerminal(time.Minute, time.Now()))
+	suite.NotNil(suite.task.GetCacheRuntime())
+
+	suite.task.runtime = nil
+	suite.False(suite.task.evictIfTerminal(time.Minute, time.Now()))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	"github.com/uber/peloton/pkg/jobmgr/podsvc"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
 	"github.com/uber/peloton/pkg/jobmgr/task/evictor"
 	"github.com/uber/peloton/pkg/jobmgr/task/placement"
@@ -56,6 +57,9 @@ type Config struct {
 	// Job service specific configuration
 	JobSvcCfg jobsvc.Config `yaml:"job_service"`
 
+	// Pod service specific configuration
+	PodSvcCfg podsvc.Config `yaml:"pod_service"`
+
 	// Watch API specific configuration
 	Watch watchsvc.Config `yaml:"watch"`
 
@@ -65,6 +69,12 @@ type Config struct {
 	// Period in sec for updating active cache
 	ActiveTaskUpdatePeriod time.Duration `yaml:"active_task_update_period"`
 
+	// TerminalTaskCacheTTL is the idle period after which the runtimes of
+	// terminal tasks are evicted from the job factory cache. Evicted
+	// runtimes are read from the DB again when needed. A zero value
+	// disables the eviction.
+	TerminalTaskCacheTTL time.Duration `yaml:"terminal_task_cache_ttl"`
+
 	// HostManagerAPIVersion is the API version that the Resource Manager
 	// should use to talk to Host Manager.
 	HostManagerAPIVersion api.Version `yaml:"hostmgr_api_version"`
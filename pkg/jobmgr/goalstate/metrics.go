@@ -49,6 +49,9 @@ type TaskMetrics struct {
 	RetryFailedLaunchTotal tally.Counter
 	RetryFailedTasksTotal  tally.Counter
 	RetryLostTasksTotal    tally.Counter
+
+	TaskPodEventsTrimmed  tally.Counter
+	TaskPodEventsTrimFail tally.Counter
 }
 
 // UpdateMetrics contains all counters to track
@@ -108,6 +111,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		RetryFailedLaunchTotal: taskScope.Counter("retry_system_failure_total"),
 		RetryFailedTasksTotal:  taskScope.Counter("retry_failed_total"),
 		RetryLostTasksTotal:    taskScope.Counter("retry_lost_total"),
+		TaskPodEventsTrimmed:   taskScope.Counter("pod_events_trimmed"),
+		TaskPodEventsTrimFail:  taskScope.Counter("pod_events_trim_fail"),
 	}
 
 	updateMetrics := &UpdateMetrics{
@@ -58,7 +58,8 @@ func TaskStart(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
-	if cachedConfig.GetSLA().GetMaximumRunningInstances() > 0 {
+	sla := cachedConfig.GetSLA()
+	if sla.GetMaximumRunningInstances() > 0 {
 		// Tasks are enqueued into goal state in INITIALiZED state either
 		// during recovery or due to task restart due to failure/task lost
 		// or due to launch/starting state timeouts. In all these cases,
@@ -135,5 +136,40 @@ func TaskStart(ctx context.Context, entity goalstate.Entity) error {
 			return _errTasksNotInCache
 		}
 	}
+	if err == nil && sla.GetMaxRunsToRetain() > 0 {
+		trimPodEvents(ctx, taskEnt, runtime, sla.GetMaxRunsToRetain())
+	}
 	return err
 }
+
+// trimPodEvents deletes the pod events of the runs of the task older than
+// the last maxRuns runs. Failures are only logged, since the pod events are
+// trimmed again when the next run of the task is started.
+func trimPodEvents(
+	ctx context.Context,
+	taskEnt *taskEntity,
+	runtime *task.RuntimeInfo,
+	maxRuns uint32) {
+	goalStateDriver := taskEnt.driver
+	runID, err := util.ParseRunID(runtime.GetMesosTaskId().GetValue())
+	if err != nil || runID <= uint64(maxRuns) {
+		return
+	}
+
+	if err := goalStateDriver.taskStore.DeletePodEvents(
+		ctx,
+		taskEnt.jobID.GetValue(),
+		taskEnt.instanceID,
+		0,
+		runID-uint64(maxRuns)+1,
+	); err != nil {
+		log.WithError(err).
+			WithField("job_id", taskEnt.jobID.GetValue()).
+			WithField("instance_id", taskEnt.instanceID).
+			WithField("run_id", runID).
+			Warn("failed to trim pod events")
+		goalStateDriver.mtx.taskMetrics.TaskPodEventsTrimFail.Inc(1)
+		return
+	}
+	goalStateDriver.mtx.taskMetrics.TaskPodEventsTrimmed.Inc(1)
+}
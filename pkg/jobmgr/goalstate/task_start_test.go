@@ -172,6 +172,78 @@ func (suite *TaskStartTestSuite) TestTaskStartStateless() {
 	suite.NoError(err)
 }
 
+// TestTaskStartTrimsPodEvents tests that the pod events of the runs older
+// than the retained runs are deleted when a new run of the task is started.
+func (suite *TaskStartTestSuite) TestTaskStartTrimsPodEvents() {
+	jobConfig := &job2.JobConfig{
+		RespoolID: &peloton.ResourcePoolID{
+			Value: "my-respool-id",
+		},
+		SLA: &job2.SlaConfig{
+			MaxRunsToRetain: 3,
+		},
+	}
+	mesosTaskID := fmt.Sprintf("%s-%d-%d", suite.jobID.GetValue(), suite.instanceID, 5)
+	taskInfo := &pbtask.TaskInfo{
+		InstanceId: suite.instanceID,
+		Config:     &pbtask.TaskConfig{},
+		Runtime: &pbtask.RuntimeInfo{
+			MesosTaskId: &mesos_v1.TaskID{Value: &mesosTaskID},
+		},
+	}
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(suite.cachedConfig, nil)
+
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(jobConfig.SLA).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetRespoolID().
+		Return(jobConfig.RespoolID)
+
+	suite.cachedConfig.EXPECT().
+		GetType().
+		Return(job2.JobType_SERVICE).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetPlacementStrategy().
+		Return(job2.PlacementStrategy_PLACEMENT_STRATEGY_INVALID)
+
+	suite.taskStore.EXPECT().
+		GetTaskByID(gomock.Any(), fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)).
+		Return(taskInfo, nil)
+
+	suite.resmgrClient.EXPECT().
+		EnqueueGangs(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Return(nil, nil, nil)
+
+	// Runs 3, 4 and 5 are retained.
+	suite.taskStore.EXPECT().
+		DeletePodEvents(
+			gomock.Any(),
+			suite.jobID.GetValue(),
+			suite.instanceID,
+			uint64(0),
+			uint64(3)).
+		Return(nil)
+
+	err := TaskStart(context.Background(), suite.taskEnt)
+	suite.NoError(err)
+}
+
 func (suite *TaskStartTestSuite) TestTaskStartWithSlaMaxRunningInstances() {
 	jobConfig := &job2.JobConfig{
 		InstanceCount: 2,
@@ -427,6 +427,7 @@ func convertCacheJobConfigToJobSpec(config jobmgrcommon.JobConfig) *stateless.Jo
 			Preemptible:                 config.GetSLA().GetPreemptible(),
 			Revocable:                   config.GetSLA().GetRevocable(),
 			MaximumUnavailableInstances: config.GetSLA().GetMaximumUnavailableInstances(),
+			MaxRunsToRetain:             config.GetSLA().GetMaxRunsToRetain(),
 		}
 	}
 	result.Revision = &v1alphapeloton.Revision{
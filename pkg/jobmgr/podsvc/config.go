@@ -0,0 +1,23 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podsvc
+
+// Config for pod service
+type Config struct {
+	// Maximum size in bytes of the events returned by GetPodEvents. The
+	// most recent events which fit in the limit are returned when the
+	// events of a pod exceed it. A value of 0 means there is no limit.
+	MaxPodEventsResponseBytes int `yaml:"max_pod_events_response_bytes"`
+}
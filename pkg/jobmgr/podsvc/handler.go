@@ -16,6 +16,7 @@ package podsvc
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -25,12 +26,14 @@ import (
 	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	"github.com/uber/peloton/pkg/common/util/podname"
 	yarpcutil "github.com/uber/peloton/pkg/common/util/yarpc"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
@@ -42,14 +45,20 @@ import (
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
 const (
 	_frameworkName = "Peloton"
+
+	// _restartPodsPollInterval is the interval at which the state of the
+	// restarted pods is checked by RestartPods.
+	_restartPodsPollInterval = time.Second
 )
 
 var _errPodNotInCache = yarpcerrors.InternalErrorf("pod not present in cache, please retry action")
@@ -66,11 +75,14 @@ type serviceHandler struct {
 	logManager         logmanager.LogManager
 	mesosAgentWorkDir  string
 	hostMgrClient      hostsvc.InternalHostServiceYARPCClient
+	config             Config
+	metrics            *Metrics
 }
 
 // InitV1AlphaPodServiceHandler initializes the Pod Service Handler
 func InitV1AlphaPodServiceHandler(
 	d *yarpc.Dispatcher,
+	parent tally.Scope,
 	jobStore storage.JobStore,
 	podStore storage.TaskStore,
 	frameworkInfoStore storage.FrameworkInfoStore,
@@ -81,6 +93,7 @@ func InitV1AlphaPodServiceHandler(
 	logManager logmanager.LogManager,
 	mesosAgentWorkDir string,
 	hostMgrClient hostsvc.InternalHostServiceYARPCClient,
+	config Config,
 ) {
 	handler := &serviceHandler{
 		jobStore:           jobStore,
@@ -94,6 +107,8 @@ func InitV1AlphaPodServiceHandler(
 		logManager:         logManager,
 		mesosAgentWorkDir:  mesosAgentWorkDir,
 		hostMgrClient:      hostMgrClient,
+		config:             config,
+		metrics:            NewMetrics(parent.SubScope("jobmgr").SubScope("pod")),
 	}
 	d.Register(svc.BuildPodServiceYARPCProcedures(handler))
 }
@@ -124,7 +139,7 @@ func (h *serviceHandler) StartPod(
 			yarpcerrors.UnavailableErrorf("PodSVC.StartPod is not supported on non-leader")
 	}
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
@@ -296,11 +311,16 @@ func (h *serviceHandler) StopPod(
 			yarpcerrors.UnavailableErrorf("PodSVC.StopPod is not supported on non-leader")
 	}
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
 
+	if req.GetKillGracePeriodSeconds() < 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"kill grace period cannot be negative")
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 
 	runtimeInfo, err := h.podStore.GetTaskRuntime(
@@ -324,6 +344,10 @@ func (h *serviceHandler) StopPod(
 		},
 		jobmgrcommon.DesiredHostField: "",
 	}
+	if gracePeriod := req.GetKillGracePeriodSeconds(); gracePeriod > 0 {
+		runtimeDiff[instanceID][jobmgrcommon.DesiredKillGracePeriodSecondsField] =
+			uint32(gracePeriod)
+	}
 	_, instancesToRetry, err := cachedJob.PatchTasks(ctx, runtimeDiff, false)
 
 	// We should enqueue the tasks even if PatchTasks fail,
@@ -368,13 +392,113 @@ func (h *serviceHandler) RestartPod(
 			yarpcerrors.UnavailableErrorf("PodSVC.RestartPod is not supported on non-leader")
 	}
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, yarpcerrors.InvalidArgumentErrorf("invalid pod name")
 	}
 
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
+	newPodID, err := h.restartPod(
+		ctx, cachedJob, instanceID, req.GetCheckSla())
+	if newPodID == nil {
+		return nil, err
+	}
+	return &svc.RestartPodResponse{}, err
+}
+
+// RestartPods restarts the pods of a range of instances of a job, the
+// given number of pods at a time. The next pods are restarted once the
+// restarted pods are running.
+func (h *serviceHandler) RestartPods(
+	ctx context.Context,
+	req *svc.RestartPodsRequest,
+) (resp *svc.RestartPodsResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("PodSVC.RestartPods failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("response", resp).
+			WithField("headers", headers).
+			Info("PodSVC.RestartPods succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("PodSVC.RestartPods is not supported on non-leader")
+	}
+
+	from := req.GetRange().GetFrom()
+	to := req.GetRange().GetTo()
+	if from >= to {
+		return nil, yarpcerrors.InvalidArgumentErrorf("empty instance range")
+	}
+
+	concurrency := req.GetConcurrency()
+	if concurrency == 0 {
+		concurrency = 1
+	}
+
+	cachedJob := h.jobFactory.AddJob(
+		&v0peloton.JobID{Value: req.GetJobId().GetValue()})
+
+	for start := from; start < to; start += concurrency {
+		if start > from && req.GetWaitSeconds() > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, yarpcerrors.DeadlineExceededErrorf(
+					"pods restarted up to instance %d", start)
+			case <-time.After(time.Duration(req.GetWaitSeconds()) * time.Second):
+			}
+		}
+
+		var instanceIDs []uint32
+		for i := start; i < to && i < start+concurrency; i++ {
+			instanceIDs = append(instanceIDs, i)
+		}
 
+		results, err := util.RunInParallelWithResults(
+			req.GetJobId().GetValue(),
+			instanceIDs,
+			func(instanceID uint32) error {
+				newPodID, err := h.restartPod(
+					ctx, cachedJob, instanceID, req.GetCheckSla())
+				if err != nil {
+					return err
+				}
+				return h.waitForPodRunning(ctx, cachedJob, instanceID, newPodID)
+			})
+		if err != nil {
+			// return the error of the failed pod, which is more
+			// specific than the aggregated error
+			for _, result := range results {
+				if result.Err != nil {
+					return nil, result.Err
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return &svc.RestartPodsResponse{}, nil
+}
+
+// restartPod restarts the pod of the instance and returns the ID of the
+// new run of the pod. The ID is returned along with the error if patching
+// the runtime of the pod fails, since the restart may have been persisted.
+func (h *serviceHandler) restartPod(
+	ctx context.Context,
+	cachedJob cached.Job,
+	instanceID uint32,
+	checkSLA bool,
+) (*mesos.TaskID, error) {
 	newPodID, err := h.getPodIDForRestart(ctx,
 		cachedJob,
 		instanceID)
@@ -387,7 +511,7 @@ func (h *serviceHandler) RestartPod(
 		jobmgrcommon.DesiredMesosTaskIDField: newPodID,
 	}
 
-	if req.GetCheckSla() {
+	if checkSLA {
 		runtimeDiff[instanceID][jobmgrcommon.TerminationStatusField] = &pbtask.TerminationStatus{
 			Reason: pbtask.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART,
 		}
@@ -403,7 +527,7 @@ func (h *serviceHandler) RestartPod(
 	// because some tasks may get updated successfully in db.
 	// We can let goal state engine to decide whether or not to restart.
 	h.goalStateDriver.EnqueueTask(
-		&v0peloton.JobID{Value: jobID},
+		cachedJob.ID(),
 		instanceID,
 		time.Now(),
 	)
@@ -413,11 +537,41 @@ func (h *serviceHandler) RestartPod(
 	}
 
 	// the restart would violate SLA
-	if req.GetCheckSla() && len(instancesSucceeded) == 0 {
+	if checkSLA && len(instancesSucceeded) == 0 {
 		return nil, yarpcerrors.AbortedErrorf("pod restart would violate SLA")
 	}
 
-	return &svc.RestartPodResponse{}, err
+	return newPodID, err
+}
+
+// waitForPodRunning waits until the given run of the pod of the instance
+// is running.
+func (h *serviceHandler) waitForPodRunning(
+	ctx context.Context,
+	cachedJob cached.Job,
+	instanceID uint32,
+	podID *mesos.TaskID,
+) error {
+	for {
+		cachedTask := cachedJob.GetTask(instanceID)
+		if cachedTask != nil {
+			runtime, err := cachedTask.GetRuntime(ctx)
+			if err != nil {
+				return err
+			}
+			if runtime.GetMesosTaskId().GetValue() == podID.GetValue() &&
+				runtime.GetState() == pbtask.TaskState_RUNNING {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return yarpcerrors.DeadlineExceededErrorf(
+				"pod %s is not running", podID.GetValue())
+		case <-time.After(_restartPodsPollInterval):
+		}
+	}
 }
 
 func (h *serviceHandler) GetPod(
@@ -441,7 +595,7 @@ func (h *serviceHandler) GetPod(
 			Debug("PodSVC.GetPod succeeded")
 	}()
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
@@ -519,12 +673,12 @@ func (h *serviceHandler) GetPodEvents(
 			WithField("headers", headers).
 			Debug("PodSVC.GetPodEvents succeeded")
 	}()
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
 
-	podEvents, err := h.podStore.GetPodEvents(
+	podEvents, err := h.getPodEvents(
 		ctx,
 		jobID,
 		instanceID,
@@ -533,9 +687,315 @@ func (h *serviceHandler) GetPodEvents(
 		return nil, errors.Wrap(err, "failed to get pod events from store")
 	}
 
-	return &svc.GetPodEventsResponse{
+	resp = &svc.GetPodEventsResponse{
 		Events: podEvents,
-	}, nil
+	}
+
+	if req.GetAllRuns() {
+		resp.Runs, resp.BrokenChain, err = h.getPodRuns(
+			ctx,
+			jobID,
+			instanceID,
+			podEvents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	truncatePodEvents(resp, h.config.MaxPodEventsResponseBytes)
+
+	// pod events are stored with the most recent event first,
+	// and runs are collected starting from the most recent one
+	if req.GetOrder() == query.OrderBy_ORDER_BY_ASC {
+		reversePodEvents(resp.Events)
+		for i, j := 0, len(resp.Runs)-1; i < j; i, j = i+1, j-1 {
+			resp.Runs[i], resp.Runs[j] = resp.Runs[j], resp.Runs[i]
+		}
+		for _, run := range resp.Runs {
+			reversePodEvents(run.Events)
+		}
+	}
+
+	return resp, nil
+}
+
+// getPodEvents returns the events of a run of the pod from the store.
+// A pod which never ran has no events, which some store implementations
+// report as not found rather than as an empty list of events.
+func (h *serviceHandler) getPodEvents(
+	ctx context.Context,
+	jobID string,
+	instanceID uint32,
+	podID string,
+) ([]*pbpod.PodEvent, error) {
+	podEvents, err := h.podStore.GetPodEvents(ctx, jobID, instanceID, podID)
+	if err != nil && yarpcerrors.IsNotFound(errors.Cause(err)) {
+		return nil, nil
+	}
+	return podEvents, err
+}
+
+// getPodRuns groups the events of a pod by run, starting from the run of
+// the given events and following the previous pod ID of each run. The
+// runs are returned with the most recent one first, along with whether
+// the chain of runs is broken because the events of a previous run are
+// missing.
+func (h *serviceHandler) getPodRuns(
+	ctx context.Context,
+	jobID string,
+	instanceID uint32,
+	podEvents []*pbpod.PodEvent,
+) ([]*svc.GetPodEventsResponse_PodRun, bool, error) {
+	var runs []*svc.GetPodEventsResponse_PodRun
+	visited := make(map[string]bool)
+
+	for len(podEvents) != 0 {
+		podID := podEvents[0].GetPodId()
+		prevPodID := podEvents[0].GetPrevPodId()
+		visited[podID.GetValue()] = true
+
+		run := &svc.GetPodEventsResponse_PodRun{
+			PodId:  podID,
+			Events: make([]*pbpod.PodEvent, len(podEvents)),
+		}
+		copy(run.Events, podEvents)
+		if len(prevPodID.GetValue()) != 0 {
+			run.PrevPodId = prevPodID
+		}
+		runs = append(runs, run)
+
+		// the chain ends at the first run, and at a run pointing back
+		// to one already visited
+		if len(prevPodID.GetValue()) == 0 || visited[prevPodID.GetValue()] {
+			return runs, false, nil
+		}
+
+		var err error
+		podEvents, err = h.getPodEvents(
+			ctx,
+			jobID,
+			instanceID,
+			prevPodID.GetValue())
+		if err != nil {
+			return nil, false,
+				errors.Wrap(err, "failed to get pod events of previous run")
+		}
+
+		if len(podEvents) == 0 {
+			log.WithFields(log.Fields{
+				"job_id":      jobID,
+				"instance_id": instanceID,
+				"pod_id":      podID.GetValue(),
+				"prev_pod_id": prevPodID.GetValue(),
+			}).Info("pod events of previous run not found")
+			return runs, true, nil
+		}
+	}
+
+	return runs, false, nil
+}
+
+// truncatePodEvents truncates the events of a GetPodEvents response,
+// whose events and runs are sorted with the most recent first, to the
+// most recent ones which fit in maxBytes. A maxBytes of 0 means there
+// is no limit.
+func truncatePodEvents(resp *svc.GetPodEventsResponse, maxBytes int) {
+	if maxBytes <= 0 || proto.Size(resp) <= maxBytes {
+		return
+	}
+	resp.Truncated = true
+
+	budget := maxBytes
+	resp.Events, budget = keepRecentPodEvents(resp.Events, budget)
+	for i, run := range resp.Runs {
+		numEvents := len(run.Events)
+		run.Events, budget = keepRecentPodEvents(run.Events, budget)
+		if len(run.Events) == 0 {
+			resp.Runs = resp.Runs[:i]
+			return
+		}
+		if len(run.Events) < numEvents {
+			// the older runs are dropped to not leave a gap in the runs
+			resp.Runs = resp.Runs[:i+1]
+			return
+		}
+	}
+}
+
+// keepRecentPodEvents returns the leading pod events whose total size
+// fits in the budget, along with the budget left.
+func keepRecentPodEvents(
+	podEvents []*pbpod.PodEvent,
+	budget int,
+) ([]*pbpod.PodEvent, int) {
+	for i, podEvent := range podEvents {
+		size := proto.Size(podEvent)
+		if size > budget {
+			return podEvents[:i], budget
+		}
+		budget -= size
+	}
+	return podEvents, budget
+}
+
+// reversePodEvents reverses the order of the pod events in place.
+func reversePodEvents(podEvents []*pbpod.PodEvent) {
+	for i, j := 0, len(podEvents)-1; i < j; i, j = i+1, j-1 {
+		podEvents[i], podEvents[j] = podEvents[j], podEvents[i]
+	}
+}
+
+func (h *serviceHandler) GetPodTerminationInfo(
+	ctx context.Context,
+	req *svc.GetPodTerminationInfoRequest,
+) (resp *svc.GetPodTerminationInfoResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("PodSVC.GetPodTerminationInfo failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("response", resp).
+			WithField("headers", headers).
+			Debug("PodSVC.GetPodTerminationInfo succeeded")
+	}()
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
+	if err != nil {
+		return nil, err
+	}
+
+	podEvents, err := h.podStore.GetPodEvents(ctx, jobID, instanceID, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pod events from store")
+	}
+	event := lastTerminalPodEvent(podEvents)
+
+	// The latest run of the pod may not have terminated yet, in which case
+	// the previous run has terminated most recently.
+	if event == nil && len(podEvents) > 0 {
+		prevPodID := podEvents[len(podEvents)-1].GetPrevPodId().GetValue()
+		if len(prevPodID) > 0 {
+			podEvents, err = h.podStore.GetPodEvents(
+				ctx, jobID, instanceID, prevPodID)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get pod events from store")
+			}
+			event = lastTerminalPodEvent(podEvents)
+		}
+	}
+
+	if event == nil {
+		return nil, yarpcerrors.NotFoundErrorf("pod has never terminated")
+	}
+
+	resp = &svc.GetPodTerminationInfoResponse{
+		PodId:       event.GetPodId(),
+		ActualState: event.GetActualState(),
+		Reason:      event.GetReason(),
+		Message:     event.GetMessage(),
+		AgentId:     event.GetAgentId(),
+		Hostname:    event.GetHostname(),
+		Timestamp:   event.GetTimestamp(),
+	}
+
+	// The exit code is not part of the pod events, it is only available
+	// in the runtime of the latest run of the pod.
+	runtime, err := h.podStore.GetTaskRuntime(
+		ctx, &v0peloton.JobID{Value: jobID}, instanceID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pod runtime from store")
+	}
+	if runtime.GetMesosTaskId().GetValue() == event.GetPodId().GetValue() {
+		resp.ExitCode = runtime.GetTerminationStatus().GetExitCode()
+	}
+
+	return resp, nil
+}
+
+func (h *serviceHandler) GetPodRestartStats(
+	ctx context.Context,
+	req *svc.GetPodRestartStatsRequest,
+) (resp *svc.GetPodRestartStatsResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("PodSVC.GetPodRestartStats failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("response", resp).
+			WithField("headers", headers).
+			Debug("PodSVC.GetPodRestartStats succeeded")
+	}()
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if req.GetWindowSeconds() > 0 {
+		since = time.Now().Add(
+			-time.Duration(req.GetWindowSeconds()) * time.Second)
+	}
+
+	resp = &svc.GetPodRestartStatsResponse{}
+	visited := make(map[string]bool)
+	podEvents, err := h.getPodEvents(ctx, jobID, instanceID, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pod events from store")
+	}
+
+	// Each terminated run of the pod is a restart. The runs are walked
+	// from the most recent one, until a run whose last event is older
+	// than the window.
+	for len(podEvents) != 0 {
+		latest, err := time.Parse(time.RFC3339, podEvents[0].GetTimestamp())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse pod event time")
+		}
+		if latest.Before(since) {
+			break
+		}
+
+		if event := lastTerminalPodEvent(podEvents); event != nil {
+			terminated, err := time.Parse(time.RFC3339, event.GetTimestamp())
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse pod event time")
+			}
+			if !terminated.Before(since) {
+				resp.RestartCount++
+				if len(resp.LastRestartTime) == 0 {
+					resp.LastRestartTime = event.GetTimestamp()
+				}
+			}
+		}
+
+		podID := podEvents[0].GetPodId().GetValue()
+		prevPodID := podEvents[0].GetPrevPodId().GetValue()
+		visited[podID] = true
+		if len(prevPodID) == 0 || visited[prevPodID] {
+			break
+		}
+
+		podEvents, err = h.getPodEvents(ctx, jobID, instanceID, prevPodID)
+		if err != nil {
+			return nil, errors.Wrap(err,
+				"failed to get pod events of previous run")
+		}
+	}
+
+	return resp, nil
 }
 
 func (h *serviceHandler) BrowsePodSandbox(
@@ -559,7 +1019,7 @@ func (h *serviceHandler) BrowsePodSandbox(
 			Debug("PodSVC.BrowsePodSandbox succeeded")
 	}()
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
@@ -627,6 +1087,102 @@ func (h *serviceHandler) BrowsePodSandbox(
 	return resp, nil
 }
 
+func (h *serviceHandler) ListPods(
+	ctx context.Context,
+	req *svc.ListPodsRequest,
+) (resp *svc.ListPodsResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("PodSVC.ListPods failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			WithField("num_of_results", len(resp.GetPods())).
+			Debug("PodSVC.ListPods succeeded")
+	}()
+
+	jobID := req.GetJobId().GetValue()
+	if len(jobID) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("job id must be set")
+	}
+
+	runtimes, err := h.getJobTaskRuntimes(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[pbpod.PodState]bool)
+	for _, state := range req.GetStates() {
+		states[state] = true
+	}
+
+	instanceIDs := make([]uint32, 0, len(runtimes))
+	for instanceID := range runtimes {
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	sort.Slice(instanceIDs, func(i, j int) bool {
+		return instanceIDs[i] < instanceIDs[j]
+	})
+
+	resp = &svc.ListPodsResponse{}
+	for _, instanceID := range instanceIDs {
+		status := api.ConvertTaskRuntimeToPodStatus(runtimes[instanceID])
+		if len(states) != 0 && !states[status.GetState()] {
+			continue
+		}
+		resp.Pods = append(resp.Pods, &pbpod.PodSummary{
+			PodName: podname.FormatPodName(jobID, instanceID),
+			Status:  status,
+		})
+	}
+	return resp, nil
+}
+
+// getJobTaskRuntimes returns the runtimes of all the tasks of a job,
+// keyed by instance ID. The runtimes are read from cache if the tasks
+// of the job are cached, and from the store otherwise.
+func (h *serviceHandler) getJobTaskRuntimes(
+	ctx context.Context,
+	jobID string,
+) (map[uint32]*pbtask.RuntimeInfo, error) {
+	pelotonJobID := &v0peloton.JobID{Value: jobID}
+
+	if cachedJob := h.jobFactory.GetJob(pelotonJobID); cachedJob != nil {
+		if cachedTasks := cachedJob.GetAllTasks(); len(cachedTasks) != 0 {
+			runtimes := make(map[uint32]*pbtask.RuntimeInfo, len(cachedTasks))
+			for instanceID, cachedTask := range cachedTasks {
+				runtime, err := cachedTask.GetRuntime(ctx)
+				if err != nil {
+					return nil,
+						errors.Wrap(err, "fail to get task runtime")
+				}
+				runtimes[instanceID] = runtime
+			}
+			return runtimes, nil
+		}
+	}
+
+	runtimes, err := h.podStore.GetTaskRuntimesForJobByRange(
+		ctx,
+		pelotonJobID,
+		nil,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get task runtimes")
+	}
+	if len(runtimes) == 0 {
+		return nil, yarpcerrors.NotFoundErrorf("job not found")
+	}
+	return runtimes, nil
+}
+
 func (h *serviceHandler) RefreshPod(
 	ctx context.Context,
 	req *svc.RefreshPodRequest,
@@ -653,7 +1209,7 @@ func (h *serviceHandler) RefreshPod(
 			yarpcerrors.UnavailableErrorf("PodSVC.RefreshPod is not supported on non-leader")
 	}
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
@@ -666,6 +1222,13 @@ func (h *serviceHandler) RefreshPod(
 	}
 
 	cachedJob := h.jobFactory.AddJob(pelotonJobID)
+	if cachedTask := cachedJob.GetTask(instanceID); cachedTask != nil {
+		h.reportCacheDrift(
+			cachedTask.GetCacheRuntime(),
+			taskInfo[instanceID].GetRuntime(),
+		)
+	}
+
 	if err := cachedJob.ReplaceTasks(taskInfo, true); err != nil {
 		return nil, errors.Wrap(err, "fail to replace task runtime")
 	}
@@ -677,6 +1240,28 @@ func (h *serviceHandler) RefreshPod(
 	return &svc.RefreshPodResponse{}, nil
 }
 
+// reportCacheDrift increments the cache drift counters for the fields
+// which differ between the cached runtime and the runtime in the store.
+// Nothing is reported if the runtime is not present in the cache.
+func (h *serviceHandler) reportCacheDrift(
+	cacheRuntime *pbtask.RuntimeInfo,
+	storeRuntime *pbtask.RuntimeInfo,
+) {
+	if cacheRuntime == nil || storeRuntime == nil {
+		return
+	}
+
+	if cacheRuntime.GetState() != storeRuntime.GetState() {
+		h.metrics.PodCacheDriftState.Inc(1)
+	}
+	if cacheRuntime.GetGoalState() != storeRuntime.GetGoalState() {
+		h.metrics.PodCacheDriftGoalState.Inc(1)
+	}
+	if cacheRuntime.GetConfigVersion() != storeRuntime.GetConfigVersion() {
+		h.metrics.PodCacheDriftConfigVersion.Inc(1)
+	}
+}
+
 func (h *serviceHandler) GetPodCache(
 	ctx context.Context,
 	req *svc.GetPodCacheRequest,
@@ -698,39 +1283,152 @@ func (h *serviceHandler) GetPodCache(
 			Debug("PodSVC.GetPodCache succeeded")
 	}()
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
 	if err != nil {
 		return nil, err
 	}
 
+	runtime, labels, err := h.getCachedPod(ctx, jobID, instanceID)
+	if yarpcerrors.IsNotFound(err) && req.GetFallbackToStore() {
+		// The cache may be cold, e.g. right after a leader change,
+		// read the runtime from the store without populating the cache.
+		runtime, err = h.podStore.GetTaskRuntime(
+			ctx, &v0peloton.JobID{Value: jobID}, instanceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.GetPodCacheResponse{
+		Status:        api.ConvertTaskRuntimeToPodStatus(runtime),
+		Labels:        api.ConvertLabels(labels),
+		ConfigDrifted: isConfigDrifted(runtime),
+	}, nil
+}
+
+// isConfigDrifted returns true if the config version of the task differs
+// from its desired config version. It returns false if either of the
+// versions is not set, as the drift of the task is unknown.
+func isConfigDrifted(runtime *pbtask.RuntimeInfo) bool {
+	if runtime.GetConfigVersion() == 0 ||
+		runtime.GetDesiredConfigVersion() == 0 {
+		return false
+	}
+	return runtime.GetConfigVersion() != runtime.GetDesiredConfigVersion()
+}
+
+func (h *serviceHandler) GetPodsCache(
+	ctx context.Context,
+	req *svc.GetPodsCacheRequest,
+) (resp *svc.GetPodsCacheResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("PodSVC.GetPodsCache failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			Debug("PodSVC.GetPodsCache succeeded")
+	}()
+
+	podNames := req.GetPodNames()
+	if len(req.GetJobId().GetValue()) != 0 {
+		podNames, err = h.getCachedJobPodNames(ctx, req.GetJobId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+	} else if len(podNames) == 0 {
+		return nil,
+			yarpcerrors.InvalidArgumentErrorf("job id or pod names must be set")
+	}
+
+	resp = &svc.GetPodsCacheResponse{}
+	for _, podName := range podNames {
+		jobID, instanceID, err := podname.ParsePodName(podName)
+		if err != nil {
+			return nil, err
+		}
+
+		podCache := &svc.GetPodsCacheResponse_PodCache{PodName: podName}
+		runtime, labels, err := h.getCachedPod(ctx, jobID, instanceID)
+		switch {
+		case yarpcerrors.IsNotFound(err):
+			podCache.NotCached = true
+		case err != nil:
+			return nil, err
+		default:
+			podCache.Status = api.ConvertTaskRuntimeToPodStatus(runtime)
+			podCache.Labels = api.ConvertLabels(labels)
+		}
+		resp.Pods = append(resp.Pods, podCache)
+	}
+
+	return resp, nil
+}
+
+// getCachedJobPodNames returns the names of all the pods of a job
+// present in cache, based on the instance count of the job.
+func (h *serviceHandler) getCachedJobPodNames(
+	ctx context.Context,
+	jobID string,
+) ([]*v1alphapeloton.PodName, error) {
 	cachedJob := h.jobFactory.GetJob(&v0peloton.JobID{Value: jobID})
 	if cachedJob == nil {
 		return nil,
 			yarpcerrors.NotFoundErrorf("job not found in cache")
 	}
 
+	jobConfig, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		return nil,
+			errors.Wrap(err, "fail to get job config")
+	}
+
+	podNames := make([]*v1alphapeloton.PodName, 0, jobConfig.GetInstanceCount())
+	for i := uint32(0); i < jobConfig.GetInstanceCount(); i++ {
+		podNames = append(podNames, podname.FormatPodName(jobID, i))
+	}
+	return podNames, nil
+}
+
+// getCachedPod returns the runtime and labels of a pod from cache.
+// NotFound error is returned if the pod is not present in cache.
+func (h *serviceHandler) getCachedPod(
+	ctx context.Context,
+	jobID string,
+	instanceID uint32,
+) (*pbtask.RuntimeInfo, []*v0peloton.Label, error) {
+	cachedJob := h.jobFactory.GetJob(&v0peloton.JobID{Value: jobID})
+	if cachedJob == nil {
+		return nil, nil,
+			yarpcerrors.NotFoundErrorf("job not found in cache")
+	}
+
 	cachedTask := cachedJob.GetTask(instanceID)
 	if cachedTask == nil {
-		return nil,
+		return nil, nil,
 			yarpcerrors.NotFoundErrorf("task not found in cache")
 	}
 
 	runtime, err := cachedTask.GetRuntime(ctx)
 	if err != nil {
-		return nil,
+		return nil, nil,
 			errors.Wrap(err, "fail to get task runtime")
 	}
 
 	labels, err := cachedTask.GetLabels(ctx)
 	if err != nil {
-		return nil,
+		return nil, nil,
 			errors.Wrap(err, "fail to get task labels")
 	}
 
-	return &svc.GetPodCacheResponse{
-		Status: api.ConvertTaskRuntimeToPodStatus(runtime),
-		Labels: api.ConvertLabels(labels),
-	}, nil
+	return runtime, labels, nil
 }
 
 func (h *serviceHandler) DeletePodEvents(
@@ -754,15 +1452,35 @@ func (h *serviceHandler) DeletePodEvents(
 			Info("PodSVC.DeletePodEvents succeeded")
 	}()
 
-	jobID, instanceID, err := util.ParseTaskID(req.GetPodName().GetValue())
+	jobID, instanceID, err := podname.ParsePodName(req.GetPodName())
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, runID, err := podname.ParsePodID(req.GetPodId())
 	if err != nil {
 		return nil, err
 	}
 
-	runID, err := util.ParseRunID(req.GetPodId().GetValue())
+	// Never delete the events of the current run of the pod,
+	// which are needed to monitor the pod.
+	activeRunID, err := h.getActiveRunID(ctx, jobID, instanceID)
 	if err != nil {
 		return nil, err
 	}
+	if activeRunID != 0 && runID >= activeRunID {
+		return &svc.DeletePodEventsResponse{}, nil
+	}
+
+	// Only count the run if it has events to delete.
+	events, err := h.podStore.GetPodEvents(
+		ctx, jobID, instanceID, req.GetPodId().GetValue())
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return &svc.DeletePodEventsResponse{}, nil
+	}
 
 	if err = h.podStore.DeletePodEvents(
 		ctx,
@@ -774,7 +1492,89 @@ func (h *serviceHandler) DeletePodEvents(
 		return nil, err
 	}
 
-	return &svc.DeletePodEventsResponse{}, nil
+	return &svc.DeletePodEventsResponse{DeletedRuns: 1}, nil
+}
+
+// getActiveRunID returns the run ID of the current run of the pod,
+// or 0 if the pod is not found.
+func (h *serviceHandler) getActiveRunID(
+	ctx context.Context,
+	jobID string,
+	instanceID uint32,
+) (uint64, error) {
+	runtime, err := h.podStore.GetTaskRuntime(
+		ctx, &v0peloton.JobID{Value: jobID}, instanceID)
+	if yarpcerrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "fail to get task runtime")
+	}
+
+	runID, err := util.ParseRunID(runtime.GetMesosTaskId().GetValue())
+	if err != nil {
+		return 0, errors.Wrap(err, "fail to parse the run ID of the pod")
+	}
+	return runID, nil
+}
+
+// DeleteJobPodEvents deletes the events of all the pods of a job,
+// except the events of the current run of each pod.
+func (h *serviceHandler) DeleteJobPodEvents(
+	ctx context.Context,
+	req *svc.DeleteJobPodEventsRequest,
+) (resp *svc.DeleteJobPodEventsResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("PodSVC.DeleteJobPodEvents failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			Info("PodSVC.DeleteJobPodEvents succeeded")
+	}()
+
+	jobID := req.GetJobId().GetValue()
+	if len(jobID) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("job id must be set")
+	}
+
+	runtimes, err := h.getJobTaskRuntimes(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never delete the events of the current run of the pods,
+	// which are needed to monitor the pods.
+	instanceCount := uint32(0)
+	activeRuns := make(map[uint32]uint64, len(runtimes))
+	for instanceID, runtime := range runtimes {
+		if instanceID >= instanceCount {
+			instanceCount = instanceID + 1
+		}
+		runID, err := util.ParseRunID(runtime.GetMesosTaskId().GetValue())
+		if err != nil {
+			return nil, errors.Wrapf(err,
+				"fail to parse the run ID of pod %d", instanceID)
+		}
+		activeRuns[instanceID] = runID
+	}
+
+	if err := h.podStore.DeleteJobPodEvents(
+		ctx,
+		jobID,
+		instanceCount,
+		activeRuns,
+	); err != nil {
+		return nil, err
+	}
+	return &svc.DeleteJobPodEventsResponse{}, nil
 }
 
 func (h *serviceHandler) getHostInfo(
@@ -956,9 +1756,7 @@ func (h *serviceHandler) getPodInfoForAllPodRuns(
 				jobID,
 				instanceID,
 			)
-			spec.PodName = &v1alphapeloton.PodName{
-				Value: util.CreatePelotonTaskID(jobID, instanceID),
-			}
+			spec.PodName = podname.FormatPodName(jobID, instanceID)
 			podInfo.Spec = spec
 		}
 	}
@@ -970,3 +1768,16 @@ func (h *serviceHandler) getPodInfoForAllPodRuns(
 func NewTestServiceHandler() *serviceHandler {
 	return &serviceHandler{}
 }
+
+// lastTerminalPodEvent returns the most recent pod event in a terminal
+// state, or nil if there is none. The events are sorted with the most
+// recent event first.
+func lastTerminalPodEvent(events []*pbpod.PodEvent) *pbpod.PodEvent {
+	for _, event := range events {
+		state := pbpod.PodState(pbpod.PodState_value[event.GetActualState()])
+		if util.IsPelotonPodStateTerminal(state) {
+			return event
+		}
+	}
+	return nil
+}
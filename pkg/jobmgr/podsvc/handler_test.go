@@ -17,6 +17,7 @@ package podsvc
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,12 +29,14 @@ import (
 	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	hostmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
@@ -42,7 +45,9 @@ import (
 	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
@@ -73,6 +78,7 @@ type podHandlerTestSuite struct {
 	hostmgrClient       *hostmocks.MockInternalHostServiceYARPCClient
 	logmanager          *logmanagermocks.MockLogManager
 	mesosAgentWorkDir   string
+	testScope           tally.TestScope
 }
 
 func (suite *podHandlerTestSuite) SetupTest() {
@@ -89,6 +95,7 @@ func (suite *podHandlerTestSuite) SetupTest() {
 	suite.mesosAgentWorkDir = "test"
 	suite.mockedPodEventsOps = objectmocks.NewMockPodEventsOps(suite.ctrl)
 	suite.mockTaskConfigV2Ops = objectmocks.NewMockTaskConfigV2Ops(suite.ctrl)
+	suite.testScope = tally.NewTestScope("", map[string]string{})
 	suite.handler = &serviceHandler{
 		jobFactory:         suite.jobFactory,
 		candidate:          suite.candidate,
@@ -100,6 +107,7 @@ func (suite *podHandlerTestSuite) SetupTest() {
 		hostMgrClient:      suite.hostmgrClient,
 		logManager:         suite.logmanager,
 		mesosAgentWorkDir:  suite.mesosAgentWorkDir,
+		metrics:            NewMetrics(suite.testScope),
 	}
 }
 
@@ -120,9 +128,11 @@ func (suite *podHandlerTestSuite) TestGetPodCacheSuccess() {
 	suite.cachedTask.EXPECT().
 		GetRuntime(gomock.Any()).
 		Return(&pbtask.RuntimeInfo{
-			State:     pbtask.TaskState_RUNNING,
-			GoalState: pbtask.TaskState_KILLED,
-			Healthy:   pbtask.HealthState_HEALTHY,
+			State:                pbtask.TaskState_RUNNING,
+			GoalState:            pbtask.TaskState_KILLED,
+			Healthy:              pbtask.HealthState_HEALTHY,
+			ConfigVersion:        1,
+			DesiredConfigVersion: 2,
 		}, nil)
 
 	suite.cachedTask.EXPECT().
@@ -138,6 +148,246 @@ func (suite *podHandlerTestSuite) TestGetPodCacheSuccess() {
 	suite.Equal(resp.GetStatus().GetState(), pod.PodState_POD_STATE_RUNNING)
 	suite.Equal(resp.GetStatus().GetDesiredState(), pod.PodState_POD_STATE_KILLED)
 	suite.Equal(resp.GetStatus().GetContainersStatus()[0].GetHealthy().GetState(), pod.HealthState_HEALTH_STATE_HEALTHY)
+	suite.True(resp.GetConfigDrifted())
+}
+
+// TestIsConfigDrifted tests computing the config drift of a task runtime
+func (suite *podHandlerTestSuite) TestIsConfigDrifted() {
+	testCases := []struct {
+		configVersion        uint64
+		desiredConfigVersion uint64
+		drifted              bool
+	}{
+		{1, 2, true},
+		{2, 2, false},
+		{0, 2, false},
+		{2, 0, false},
+		{0, 0, false},
+	}
+
+	for _, tt := range testCases {
+		suite.Equal(tt.drifted, isConfigDrifted(&pbtask.RuntimeInfo{
+			ConfigVersion:        tt.configVersion,
+			DesiredConfigVersion: tt.desiredConfigVersion,
+		}))
+	}
+}
+
+// TestGetPodsCacheJob tests getting the cache of all the pods of a job
+// when all of them are present in cache
+func (suite *podHandlerTestSuite) TestGetPodsCacheJob() {
+	instanceCount := uint32(3)
+
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(suite.cachedJob).
+		Times(int(instanceCount) + 1)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{
+			InstanceCount: instanceCount,
+		}, nil)
+
+	for i := uint32(0); i < instanceCount; i++ {
+		suite.cachedJob.EXPECT().
+			GetTask(i).
+			Return(suite.cachedTask)
+	}
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbtask.RuntimeInfo{
+			State: pbtask.TaskState_RUNNING,
+		}, nil).
+		Times(int(instanceCount))
+
+	suite.cachedTask.EXPECT().
+		GetLabels(gomock.Any()).
+		Return(nil, nil).
+		Times(int(instanceCount))
+
+	resp, err := suite.handler.GetPodsCache(context.Background(),
+		&svc.GetPodsCacheRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.NoError(err)
+	suite.Len(resp.GetPods(), int(instanceCount))
+	for i, p := range resp.GetPods() {
+		suite.Equal(
+			util.CreatePelotonTaskID(testJobID, uint32(i)),
+			p.GetPodName().GetValue())
+		suite.False(p.GetNotCached())
+		suite.Equal(pod.PodState_POD_STATE_RUNNING, p.GetStatus().GetState())
+	}
+}
+
+// TestGetPodsCachePartiallyCached tests getting the cache of a list of pods
+// when some of them are not present in cache
+func (suite *podHandlerTestSuite) TestGetPodsCachePartiallyCached() {
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(suite.cachedJob).
+		Times(2)
+
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(0)).
+		Return(suite.cachedTask)
+
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(1)).
+		Return(nil)
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbtask.RuntimeInfo{
+			State: pbtask.TaskState_RUNNING,
+		}, nil)
+
+	suite.cachedTask.EXPECT().
+		GetLabels(gomock.Any()).
+		Return(nil, nil)
+
+	resp, err := suite.handler.GetPodsCache(context.Background(),
+		&svc.GetPodsCacheRequest{
+			PodNames: []*v1alphapeloton.PodName{
+				{Value: util.CreatePelotonTaskID(testJobID, 0)},
+				{Value: util.CreatePelotonTaskID(testJobID, 1)},
+			},
+		})
+	suite.NoError(err)
+	suite.Len(resp.GetPods(), 2)
+	suite.False(resp.GetPods()[0].GetNotCached())
+	suite.Equal(pod.PodState_POD_STATE_RUNNING,
+		resp.GetPods()[0].GetStatus().GetState())
+	suite.True(resp.GetPods()[1].GetNotCached())
+	suite.Nil(resp.GetPods()[1].GetStatus())
+}
+
+// TestGetPodsCacheFailures tests the failure cases of getting
+// the cache of multiple pods
+func (suite *podHandlerTestSuite) TestGetPodsCacheFailures() {
+	// neither job id nor pod names are set
+	_, err := suite.handler.GetPodsCache(context.Background(),
+		&svc.GetPodsCacheRequest{})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// invalid pod name
+	_, err = suite.handler.GetPodsCache(context.Background(),
+		&svc.GetPodsCacheRequest{
+			PodNames: []*v1alphapeloton.PodName{{Value: "invalid-name"}},
+		})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// job not in cache
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+	_, err = suite.handler.GetPodsCache(context.Background(),
+		&svc.GetPodsCacheRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestListPodsByState tests listing the pods of a cached job filtered
+// by their state
+func (suite *podHandlerTestSuite) TestListPodsByState() {
+	states := []pbtask.TaskState{
+		pbtask.TaskState_RUNNING,
+		pbtask.TaskState_FAILED,
+		pbtask.TaskState_SUCCEEDED,
+		pbtask.TaskState_FAILED,
+	}
+	cachedTasks := make(map[uint32]cached.Task)
+	for i, state := range states {
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		cachedTask.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(&pbtask.RuntimeInfo{State: state}, nil).
+			Times(2)
+		cachedTasks[uint32(i)] = cachedTask
+	}
+
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(suite.cachedJob).
+		Times(2)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(cachedTasks).
+		Times(2)
+
+	resp, err := suite.handler.ListPods(context.Background(),
+		&svc.ListPodsRequest{
+			JobId:  &v1alphapeloton.JobID{Value: testJobID},
+			States: []pod.PodState{pod.PodState_POD_STATE_FAILED},
+		})
+	suite.NoError(err)
+	suite.Len(resp.GetPods(), 2)
+	for i, instanceID := range []uint32{1, 3} {
+		suite.Equal(
+			util.CreatePelotonTaskID(testJobID, instanceID),
+			resp.GetPods()[i].GetPodName().GetValue())
+		suite.Equal(pod.PodState_POD_STATE_FAILED,
+			resp.GetPods()[i].GetStatus().GetState())
+	}
+
+	// all the pods are listed without a state filter
+	resp, err = suite.handler.ListPods(context.Background(),
+		&svc.ListPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.NoError(err)
+	suite.Len(resp.GetPods(), len(states))
+}
+
+// TestListPodsFromStore tests listing the pods of a job which is not
+// in cache
+func (suite *podHandlerTestSuite) TestListPodsFromStore() {
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+	suite.podStore.EXPECT().
+		GetTaskRuntimesForJobByRange(
+			gomock.Any(), &peloton.JobID{Value: testJobID}, gomock.Any()).
+		Return(map[uint32]*pbtask.RuntimeInfo{
+			0: {State: pbtask.TaskState_FAILED},
+			1: {State: pbtask.TaskState_RUNNING},
+		}, nil)
+
+	resp, err := suite.handler.ListPods(context.Background(),
+		&svc.ListPodsRequest{
+			JobId:  &v1alphapeloton.JobID{Value: testJobID},
+			States: []pod.PodState{pod.PodState_POD_STATE_RUNNING},
+		})
+	suite.NoError(err)
+	suite.Len(resp.GetPods(), 1)
+	suite.Equal(
+		util.CreatePelotonTaskID(testJobID, 1),
+		resp.GetPods()[0].GetPodName().GetValue())
+}
+
+// TestListPodsFailures tests the failure cases of listing pods
+func (suite *podHandlerTestSuite) TestListPodsFailures() {
+	// job id is not set
+	_, err := suite.handler.ListPods(context.Background(),
+		&svc.ListPodsRequest{})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// job not found
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+	suite.podStore.EXPECT().
+		GetTaskRuntimesForJobByRange(
+			gomock.Any(), &peloton.JobID{Value: testJobID}, gomock.Any()).
+		Return(nil, nil)
+	_, err = suite.handler.ListPods(context.Background(),
+		&svc.ListPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.True(yarpcerrors.IsNotFound(err))
 }
 
 // TestGetPodCacheInvalidPodName test the case of getting cache
@@ -172,6 +422,60 @@ func (suite *podHandlerTestSuite) TestGetPodCacheNoJobCache() {
 	suite.True(yarpcerrors.IsNotFound(err))
 }
 
+// TestGetPodCacheFallbackToStore tests the case of getting cache
+// when the job is not in cache, and the runtime is read from the store
+func (suite *podHandlerTestSuite) TestGetPodCacheFallbackToStore() {
+	runtime := &pbtask.RuntimeInfo{
+		State:                pbtask.TaskState_RUNNING,
+		ConfigVersion:        1,
+		DesiredConfigVersion: 1,
+	}
+
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(runtime, nil)
+
+	resp, err := suite.handler.GetPodCache(context.Background(),
+		&svc.GetPodCacheRequest{
+			PodName:         &v1alphapeloton.PodName{Value: testPodName},
+			FallbackToStore: true,
+		})
+	suite.NoError(err)
+	suite.Equal(pod.PodState_POD_STATE_RUNNING, resp.GetStatus().GetState())
+	suite.Empty(resp.GetLabels())
+	suite.False(resp.GetConfigDrifted())
+}
+
+// TestGetPodCacheFallbackToStoreNotFound tests the case of getting cache
+// when the pod is neither in cache nor in the store
+func (suite *podHandlerTestSuite) TestGetPodCacheFallbackToStoreNotFound() {
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(nil, yarpcerrors.NotFoundErrorf("task runtime not found"))
+
+	resp, err := suite.handler.GetPodCache(context.Background(),
+		&svc.GetPodCacheRequest{
+			PodName:         &v1alphapeloton.PodName{Value: testPodName},
+			FallbackToStore: true,
+		})
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
 // TestGetPodCacheNoTaskCache tests the case of getting cache
 // when cachedTask fail to get runtime
 func (suite *podHandlerTestSuite) TestGetPodCacheNoTaskCache() {
@@ -272,6 +576,10 @@ func (suite *podHandlerTestSuite) TestRefreshPodSuccess() {
 			AddJob(pelotonJobID).
 			Return(suite.cachedJob),
 
+		suite.cachedJob.EXPECT().
+			GetTask(uint32(testInstanceID)).
+			Return(nil),
+
 		suite.cachedJob.EXPECT().
 			ReplaceTasks(gomock.Any(), true).
 			Return(nil),
@@ -301,6 +609,82 @@ func (suite *podHandlerTestSuite) TestRefreshPodSuccess() {
 	suite.NoError(err)
 }
 
+// TestRefreshPodCacheDrift tests that refreshing a pod whose cached
+// runtime differs from the runtime in the store reports the drift
+func (suite *podHandlerTestSuite) TestRefreshPodCacheDrift() {
+	taskInfos := map[uint32]*pbtask.TaskInfo{
+		testInstanceID: {
+			Runtime: &pbtask.RuntimeInfo{
+				State:         pbtask.TaskState_KILLED,
+				GoalState:     pbtask.TaskState_KILLED,
+				ConfigVersion: 1,
+			},
+		},
+	}
+	pelotonJobID := &peloton.JobID{Value: testJobID}
+
+	gomock.InOrder(
+		suite.candidate.EXPECT().
+			IsLeader().
+			Return(true),
+
+		suite.podStore.EXPECT().
+			GetTaskForJob(gomock.Any(), testJobID, uint32(testInstanceID)).
+			Return(taskInfos, nil),
+
+		suite.jobFactory.EXPECT().
+			AddJob(pelotonJobID).
+			Return(suite.cachedJob),
+
+		suite.cachedJob.EXPECT().
+			GetTask(uint32(testInstanceID)).
+			Return(suite.cachedTask),
+
+		suite.cachedTask.EXPECT().
+			GetCacheRuntime().
+			Return(&pbtask.RuntimeInfo{
+				State:         pbtask.TaskState_RUNNING,
+				GoalState:     pbtask.TaskState_KILLED,
+				ConfigVersion: 1,
+			}),
+
+		suite.cachedJob.EXPECT().
+			ReplaceTasks(taskInfos, true).
+			Return(nil),
+
+		suite.goalStateDriver.EXPECT().
+			EnqueueTask(pelotonJobID, uint32(testInstanceID), gomock.Any()).
+			Return(),
+
+		suite.cachedJob.EXPECT().
+			GetJobType().
+			Return(pbjob.JobType_SERVICE),
+
+		suite.goalStateDriver.EXPECT().
+			JobRuntimeDuration(pbjob.JobType_SERVICE).
+			Return(time.Second),
+
+		suite.goalStateDriver.EXPECT().
+			EnqueueJob(pelotonJobID, gomock.Any()).
+			Return(),
+	)
+
+	resp, err := suite.handler.RefreshPod(context.Background(),
+		&svc.RefreshPodRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.NotNil(resp)
+	suite.NoError(err)
+
+	counters := suite.testScope.Snapshot().Counters()
+	suite.Equal(int64(1),
+		counters["cache_drift_detected+field=state"].Value())
+	suite.Equal(int64(0),
+		counters["cache_drift_detected+field=goal_state"].Value())
+	suite.Equal(int64(0),
+		counters["cache_drift_detected+field=config_version"].Value())
+}
+
 // TestRefreshPodNonLeader tests calling refresh pod
 // on non-leader jobmgr
 func (suite *podHandlerTestSuite) TestRefreshPodNonLeader() {
@@ -379,6 +763,10 @@ func (suite *podHandlerTestSuite) TestRefreshPodFailToReplaceTasks() {
 			AddJob(pelotonJobID).
 			Return(suite.cachedJob),
 
+		suite.cachedJob.EXPECT().
+			GetTask(uint32(testInstanceID)).
+			Return(nil),
+
 		suite.cachedJob.EXPECT().
 			ReplaceTasks(gomock.Any(), true).
 			Return(yarpcerrors.InternalErrorf("test error")),
@@ -865,17 +1253,28 @@ func (suite *podHandlerTestSuite) TestStopPodSuccess() {
 	suite.NotNil(response)
 }
 
-// TestStopPodAlreadyStoppedPod tests calling stop pod
-// on an already stopped pod
-func (suite *podHandlerTestSuite) TestStopPodAlreadyStoppedPod() {
+// TestStopPodWithKillGracePeriod tests that the kill grace period
+// provided in the request is persisted into the task runtime
+func (suite *podHandlerTestSuite) TestStopPodWithKillGracePeriod() {
 	jobID := &peloton.JobID{Value: testJobID}
 	mesosTaskID := testPodID
 	taskRuntimeInfo := &pbtask.RuntimeInfo{
-		GoalState: pbtask.TaskState_KILLED,
 		MesosTaskId: &mesos.TaskID{
 			Value: &mesosTaskID,
 		},
 	}
+	runtimeDiff := make(map[uint32]jobmgrcommon.RuntimeDiff)
+	runtimeDiff[uint32(testInstanceID)] = jobmgrcommon.RuntimeDiff{
+		jobmgrcommon.GoalStateField:                     pbtask.TaskState_KILLED,
+		jobmgrcommon.MessageField:                       "Task stop API request",
+		jobmgrcommon.ReasonField:                        "",
+		jobmgrcommon.DesiredHostField:                   "",
+		jobmgrcommon.DesiredKillGracePeriodSecondsField: uint32(45),
+
+		jobmgrcommon.TerminationStatusField: &pbtask.TerminationStatus{
+			Reason: pbtask.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_ON_REQUEST,
+		},
+	}
 
 	suite.cachedJob.EXPECT().
 		ID().
@@ -894,12 +1293,20 @@ func (suite *podHandlerTestSuite) TestStopPodAlreadyStoppedPod() {
 		suite.podStore.EXPECT().
 			GetTaskRuntime(gomock.Any(), jobID, uint32(testInstanceID)).
 			Return(taskRuntimeInfo, nil),
+
+		suite.cachedJob.EXPECT().
+			PatchTasks(gomock.Any(), runtimeDiff, false).
+			Return(nil, nil, nil),
+
+		suite.goalStateDriver.EXPECT().
+			EnqueueTask(jobID, uint32(testInstanceID), gomock.Any()),
 	)
 
 	request := &svc.StopPodRequest{
 		PodName: &v1alphapeloton.PodName{
 			Value: testPodName,
 		},
+		KillGracePeriodSeconds: 45,
 	}
 
 	response, err := suite.handler.StopPod(context.Background(), request)
@@ -907,52 +1314,40 @@ func (suite *podHandlerTestSuite) TestStopPodAlreadyStoppedPod() {
 	suite.NotNil(response)
 }
 
-// TestStopPodNonLeader tests calling stop pod
-// on non-leader jobmgr
-func (suite *podHandlerTestSuite) TestStopPodNonLeader() {
-	suite.candidate.EXPECT().
-		IsLeader().
-		Return(false)
-
-	resp, err := suite.handler.StopPod(context.Background(),
-		&svc.StopPodRequest{
-			PodName: &v1alphapeloton.PodName{Value: testPodName},
-		})
-	suite.Nil(resp)
-	suite.Error(err)
-	suite.True(yarpcerrors.IsUnavailable(err))
-}
-
-// TestStopPodInvalidPodName tests the case of
-// stopping pod with invalid pod name
-func (suite *podHandlerTestSuite) TestStopPodInvalidPodName() {
+// TestStopPodNegativeKillGracePeriod tests the case of
+// stopping pod with a negative kill grace period
+func (suite *podHandlerTestSuite) TestStopPodNegativeKillGracePeriod() {
 	suite.candidate.EXPECT().
 		IsLeader().
 		Return(true)
 
 	resp, err := suite.handler.StopPod(context.Background(),
 		&svc.StopPodRequest{
-			PodName: &v1alphapeloton.PodName{Value: "invalid-name"},
+			PodName:                &v1alphapeloton.PodName{Value: testPodName},
+			KillGracePeriodSeconds: -1,
 		})
 	suite.Nil(resp)
 	suite.Error(err)
 	suite.True(yarpcerrors.IsInvalidArgument(err))
 }
 
-// TestStopPodGetTaskRuntimeFailure tests failure of
-// stopping pod due to GetTaskRuntime failure
-func (suite *podHandlerTestSuite) TestStopPodGetTaskRuntimeFailure() {
+// TestStopPodAlreadyStoppedPod tests calling stop pod
+// on an already stopped pod
+func (suite *podHandlerTestSuite) TestStopPodAlreadyStoppedPod() {
 	jobID := &peloton.JobID{Value: testJobID}
+	mesosTaskID := testPodID
+	taskRuntimeInfo := &pbtask.RuntimeInfo{
+		GoalState: pbtask.TaskState_KILLED,
+		MesosTaskId: &mesos.TaskID{
+			Value: &mesosTaskID,
+		},
+	}
+
 	suite.cachedJob.EXPECT().
 		ID().
 		Return(jobID).
 		AnyTimes()
 
-	suite.cachedTask.EXPECT().
-		ID().
-		Return(uint32(testInstanceID)).
-		AnyTimes()
-
 	gomock.InOrder(
 		suite.candidate.EXPECT().
 			IsLeader().
@@ -964,11 +1359,82 @@ func (suite *podHandlerTestSuite) TestStopPodGetTaskRuntimeFailure() {
 
 		suite.podStore.EXPECT().
 			GetTaskRuntime(gomock.Any(), jobID, uint32(testInstanceID)).
-			Return(nil, yarpcerrors.NotFoundErrorf("test error")),
+			Return(taskRuntimeInfo, nil),
 	)
 
-	resp, err := suite.handler.StopPod(context.Background(),
-		&svc.StopPodRequest{
+	request := &svc.StopPodRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+	}
+
+	response, err := suite.handler.StopPod(context.Background(), request)
+	suite.NoError(err)
+	suite.NotNil(response)
+}
+
+// TestStopPodNonLeader tests calling stop pod
+// on non-leader jobmgr
+func (suite *podHandlerTestSuite) TestStopPodNonLeader() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(false)
+
+	resp, err := suite.handler.StopPod(context.Background(),
+		&svc.StopPodRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.Nil(resp)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestStopPodInvalidPodName tests the case of
+// stopping pod with invalid pod name
+func (suite *podHandlerTestSuite) TestStopPodInvalidPodName() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(true)
+
+	resp, err := suite.handler.StopPod(context.Background(),
+		&svc.StopPodRequest{
+			PodName: &v1alphapeloton.PodName{Value: "invalid-name"},
+		})
+	suite.Nil(resp)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestStopPodGetTaskRuntimeFailure tests failure of
+// stopping pod due to GetTaskRuntime failure
+func (suite *podHandlerTestSuite) TestStopPodGetTaskRuntimeFailure() {
+	jobID := &peloton.JobID{Value: testJobID}
+	suite.cachedJob.EXPECT().
+		ID().
+		Return(jobID).
+		AnyTimes()
+
+	suite.cachedTask.EXPECT().
+		ID().
+		Return(uint32(testInstanceID)).
+		AnyTimes()
+
+	gomock.InOrder(
+		suite.candidate.EXPECT().
+			IsLeader().
+			Return(true),
+
+		suite.jobFactory.EXPECT().
+			AddJob(&peloton.JobID{Value: testJobID}).
+			Return(suite.cachedJob),
+
+		suite.podStore.EXPECT().
+			GetTaskRuntime(gomock.Any(), jobID, uint32(testInstanceID)).
+			Return(nil, yarpcerrors.NotFoundErrorf("test error")),
+	)
+
+	resp, err := suite.handler.StopPod(context.Background(),
+		&svc.StopPodRequest{
 			PodName: &v1alphapeloton.PodName{Value: testPodName},
 		})
 	suite.Nil(resp)
@@ -1325,6 +1791,91 @@ func (suite *podHandlerTestSuite) TestRestartPodPatchTasksFailure() {
 	suite.NotNil(response)
 }
 
+// TestRestartPodsSequential tests that the pods of an instance range are
+// restarted one at a time, each after the previous one is running.
+func (suite *podHandlerTestSuite) TestRestartPodsSequential() {
+	jobID := &peloton.JobID{Value: testJobID}
+
+	suite.cachedJob.EXPECT().
+		ID().
+		Return(jobID).
+		AnyTimes()
+
+	calls := []*gomock.Call{
+		suite.candidate.EXPECT().
+			IsLeader().
+			Return(true),
+
+		suite.jobFactory.EXPECT().
+			AddJob(jobID).
+			Return(suite.cachedJob),
+	}
+	for i := uint32(0); i < 3; i++ {
+		mesosTaskID := fmt.Sprintf("%s-%d-%d", testJobID, i, testRunID)
+		newPodID := util.CreateMesosTaskID(jobID, i, uint64(testRunID)+1)
+		runtimeDiff := map[uint32]jobmgrcommon.RuntimeDiff{
+			i: {
+				jobmgrcommon.DesiredMesosTaskIDField: newPodID,
+				jobmgrcommon.TerminationStatusField: &pbtask.TerminationStatus{
+					Reason: pbtask.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART,
+				},
+			},
+		}
+
+		calls = append(calls,
+			suite.podStore.EXPECT().
+				GetTaskRuntime(gomock.Any(), jobID, i).
+				Return(&pbtask.RuntimeInfo{
+					MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+				}, nil),
+
+			suite.cachedJob.EXPECT().
+				PatchTasks(gomock.Any(), runtimeDiff, false).
+				Return(nil, nil, nil),
+
+			suite.goalStateDriver.EXPECT().
+				EnqueueTask(jobID, i, gomock.Any()),
+
+			suite.cachedJob.EXPECT().
+				GetTask(i).
+				Return(suite.cachedTask),
+
+			suite.cachedTask.EXPECT().
+				GetRuntime(gomock.Any()).
+				Return(&pbtask.RuntimeInfo{
+					MesosTaskId: newPodID,
+					State:       pbtask.TaskState_RUNNING,
+				}, nil),
+		)
+	}
+	gomock.InOrder(calls...)
+
+	resp, err := suite.handler.RestartPods(context.Background(),
+		&svc.RestartPodsRequest{
+			JobId:       &v1alphapeloton.JobID{Value: testJobID},
+			Range:       &pod.InstanceIDRange{From: 0, To: 3},
+			Concurrency: 1,
+		})
+	suite.NoError(err)
+	suite.NotNil(resp)
+}
+
+// TestRestartPodsNonLeader tests that restarting pods fails on a
+// non-leader.
+func (suite *podHandlerTestSuite) TestRestartPodsNonLeader() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(false)
+
+	resp, err := suite.handler.RestartPods(context.Background(),
+		&svc.RestartPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+			Range: &pod.InstanceIDRange{From: 0, To: 3},
+		})
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
 // TestGetPodSuccess tests the success case of getting pod info
 func (suite *podHandlerTestSuite) TestGetPodSuccess() {
 	request := &svc.GetPodRequest{
@@ -1775,6 +2326,197 @@ func (suite *podHandlerTestSuite) TestGetPodEvents() {
 	suite.Equal(events, response.GetEvents())
 }
 
+// TestGetPodEventsOrder tests that pod events are returned in opposite
+// sequences for ascending and descending orders
+func (suite *podHandlerTestSuite) TestGetPodEventsOrder() {
+	var events []*pod.PodEvent
+	for i := 3; i > 0; i-- {
+		events = append(events, &pod.PodEvent{
+			PodId: &v1alphapeloton.PodID{
+				Value: fmt.Sprintf("%s-%d", testPodName, i),
+			},
+			Timestamp:   fmt.Sprintf("2019-01-03T22:14:5%dZ", i),
+			ActualState: pod.PodState_POD_STATE_RUNNING.String(),
+		})
+	}
+
+	getEvents := func(order query.OrderBy_Order) []*pod.PodEvent {
+		storeEvents := make([]*pod.PodEvent, len(events))
+		copy(storeEvents, events)
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+			Return(storeEvents, nil)
+		response, err := suite.handler.GetPodEvents(
+			context.Background(),
+			&svc.GetPodEventsRequest{
+				PodName: &v1alphapeloton.PodName{
+					Value: testPodName,
+				},
+				Order: order,
+			})
+		suite.NoError(err)
+		return response.GetEvents()
+	}
+
+	defaultEvents := getEvents(query.OrderBy_ORDER_BY_INVALID)
+	descEvents := getEvents(query.OrderBy_ORDER_BY_DESC)
+	ascEvents := getEvents(query.OrderBy_ORDER_BY_ASC)
+
+	suite.Equal(events, defaultEvents)
+	suite.Equal(events, descEvents)
+	suite.Len(ascEvents, len(descEvents))
+	for i := range ascEvents {
+		suite.Equal(descEvents[len(descEvents)-1-i], ascEvents[i])
+	}
+}
+
+// newRunPodEvents returns the events of a run of the test pod, with the
+// most recent event first as they are stored. A previous run of 0 means
+// the run is the first one.
+func newRunPodEvents(run int, prevRun int) []*pod.PodEvent {
+	var prevPodID *v1alphapeloton.PodID
+	if prevRun > 0 {
+		prevPodID = &v1alphapeloton.PodID{
+			Value: fmt.Sprintf("%s-%d", testPodName, prevRun),
+		}
+	}
+
+	var events []*pod.PodEvent
+	for _, state := range []pod.PodState{
+		pod.PodState_POD_STATE_FAILED,
+		pod.PodState_POD_STATE_RUNNING,
+		pod.PodState_POD_STATE_LAUNCHED,
+	} {
+		events = append(events, &pod.PodEvent{
+			PodId: &v1alphapeloton.PodID{
+				Value: fmt.Sprintf("%s-%d", testPodName, run),
+			},
+			PrevPodId:   prevPodID,
+			ActualState: state.String(),
+		})
+	}
+	return events
+}
+
+// TestGetPodEventsAllRuns tests that the events of all the runs of a
+// pod are grouped by run and linked in order
+func (suite *podHandlerTestSuite) TestGetPodEventsAllRuns() {
+	gomock.InOrder(
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+			Return(newRunPodEvents(3, 2), nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID),
+				fmt.Sprintf("%s-%d", testPodName, 2)).
+			Return(newRunPodEvents(2, 1), nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID),
+				fmt.Sprintf("%s-%d", testPodName, 1)).
+			Return(newRunPodEvents(1, 0), nil),
+	)
+
+	response, err := suite.handler.GetPodEvents(
+		context.Background(),
+		&svc.GetPodEventsRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+			Order:   query.OrderBy_ORDER_BY_ASC,
+			AllRuns: true,
+		})
+	suite.NoError(err)
+	suite.False(response.GetBrokenChain())
+
+	runs := response.GetRuns()
+	suite.Len(runs, 3)
+	for i, run := range runs {
+		suite.Equal(
+			fmt.Sprintf("%s-%d", testPodName, i+1),
+			run.GetPodId().GetValue())
+		if i == 0 {
+			suite.Nil(run.GetPrevPodId())
+		} else {
+			suite.Equal(runs[i-1].GetPodId(), run.GetPrevPodId())
+		}
+
+		suite.Len(run.GetEvents(), 3)
+		suite.Equal(pod.PodState_POD_STATE_LAUNCHED.String(),
+			run.GetEvents()[0].GetActualState())
+		suite.Equal(pod.PodState_POD_STATE_FAILED.String(),
+			run.GetEvents()[2].GetActualState())
+	}
+	suite.Equal(runs[2].GetEvents(), response.GetEvents())
+}
+
+// TestGetPodEventsAllRunsBrokenChain tests that the runs of a pod are
+// returned up to the first run whose previous run events are missing
+func (suite *podHandlerTestSuite) TestGetPodEventsAllRunsBrokenChain() {
+	podID := fmt.Sprintf("%s-%d", testPodName, 3)
+	gomock.InOrder(
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), podID).
+			Return(newRunPodEvents(3, 2), nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID),
+				fmt.Sprintf("%s-%d", testPodName, 2)).
+			Return(nil, nil),
+	)
+
+	response, err := suite.handler.GetPodEvents(
+		context.Background(),
+		&svc.GetPodEventsRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+			PodId:   &v1alphapeloton.PodID{Value: podID},
+			AllRuns: true,
+		})
+	suite.NoError(err)
+	suite.True(response.GetBrokenChain())
+	suite.Len(response.GetRuns(), 1)
+	suite.Equal(podID, response.GetRuns()[0].GetPodId().GetValue())
+	suite.Equal(response.GetEvents(), response.GetRuns()[0].GetEvents())
+}
+
+// TestGetPodEventsTruncated tests that the pod events exceeding the
+// maximal response size are truncated to the most recent ones
+func (suite *podHandlerTestSuite) TestGetPodEventsTruncated() {
+	var events []*pod.PodEvent
+	for i := 1000; i > 0; i-- {
+		events = append(events, &pod.PodEvent{
+			PodId: &v1alphapeloton.PodID{
+				Value: fmt.Sprintf("%s-%d", testPodName, i),
+			},
+			ActualState: pod.PodState_POD_STATE_FAILED.String(),
+			Message:     strings.Repeat("x", 1024),
+		})
+	}
+	eventSize := proto.Size(events[0])
+	suite.handler.config.MaxPodEventsResponseBytes = 10*eventSize + eventSize/2
+
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+		Return(events, nil)
+	response, err := suite.handler.GetPodEvents(
+		context.Background(),
+		&svc.GetPodEventsRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.NoError(err)
+	suite.True(response.GetTruncated())
+	suite.Equal(events[:10], response.GetEvents())
+
+	// events within the maximal response size are not truncated
+	suite.handler.config.MaxPodEventsResponseBytes = 100 * eventSize
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+		Return(events[:10], nil)
+	response, err = suite.handler.GetPodEvents(
+		context.Background(),
+		&svc.GetPodEventsRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.NoError(err)
+	suite.False(response.GetTruncated())
+	suite.Len(response.GetEvents(), 10)
+}
+
 // TestGetPodEventsPodNameParseError tests PodName parse error
 // while getting pod events for a given pod
 func (suite *podHandlerTestSuite) TestGetPodEventsPodNameParseError() {
@@ -1798,6 +2540,282 @@ func (suite *podHandlerTestSuite) TestGetPodEventsStoreError() {
 	suite.Error(err)
 }
 
+// TestGetPodEventsNeverRun tests getting the pod events of a valid pod
+// which never ran, which the store reports as not found
+func (suite *podHandlerTestSuite) TestGetPodEventsNeverRun() {
+	request := &svc.GetPodEventsRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+		AllRuns: true,
+	}
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+		Return(nil, yarpcerrors.NotFoundErrorf("pod events not found"))
+	response, err := suite.handler.GetPodEvents(context.Background(), request)
+	suite.NoError(err)
+	suite.Empty(response.GetEvents())
+	suite.Empty(response.GetRuns())
+	suite.False(response.GetBrokenChain())
+}
+
+// TestGetPodEventsInvalidPodName tests getting the pod events of an
+// invalid pod name, which fails unlike a pod which never ran
+func (suite *podHandlerTestSuite) TestGetPodEventsInvalidPodName() {
+	request := &svc.GetPodEventsRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: "invalid-name",
+		},
+	}
+	response, err := suite.handler.GetPodEvents(context.Background(), request)
+	suite.Nil(response)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestGetPodTerminationInfo tests getting the last termination of a pod
+// whose latest run is still running
+func (suite *podHandlerTestSuite) TestGetPodTerminationInfo() {
+	request := &svc.GetPodTerminationInfoRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+	}
+
+	mesosTaskID := testPodID
+	prevPodID := testPodName + "-1"
+	latestEvents := []*pod.PodEvent{
+		{
+			PodId:       &v1alphapeloton.PodID{Value: testPodID},
+			PrevPodId:   &v1alphapeloton.PodID{Value: prevPodID},
+			Timestamp:   "2019-01-03T22:15:58Z",
+			ActualState: pod.PodState_POD_STATE_RUNNING.String(),
+			Hostname:    "peloton-host-1",
+		},
+	}
+	prevEvents := []*pod.PodEvent{
+		{
+			PodId:       &v1alphapeloton.PodID{Value: prevPodID},
+			Timestamp:   "2019-01-03T22:14:58Z",
+			ActualState: pod.PodState_POD_STATE_FAILED.String(),
+			Reason:      mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String(),
+			Message:     "Command exited with status 1",
+			Hostname:    "peloton-host-0",
+		},
+		{
+			PodId:       &v1alphapeloton.PodID{Value: prevPodID},
+			Timestamp:   "2019-01-03T22:13:58Z",
+			ActualState: pod.PodState_POD_STATE_RUNNING.String(),
+			Hostname:    "peloton-host-0",
+		},
+	}
+
+	gomock.InOrder(
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+			Return(latestEvents, nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), prevPodID).
+			Return(prevEvents, nil),
+		suite.podStore.EXPECT().
+			GetTaskRuntime(
+				gomock.Any(),
+				&peloton.JobID{Value: testJobID},
+				uint32(testInstanceID)).
+			Return(&pbtask.RuntimeInfo{
+				MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+			}, nil),
+	)
+
+	response, err := suite.handler.GetPodTerminationInfo(
+		context.Background(), request)
+	suite.NoError(err)
+	suite.Equal(prevPodID, response.GetPodId().GetValue())
+	suite.Equal(pod.PodState_POD_STATE_FAILED.String(), response.GetActualState())
+	suite.Equal(prevEvents[0].GetReason(), response.GetReason())
+	suite.Equal(prevEvents[0].GetMessage(), response.GetMessage())
+	suite.Equal("peloton-host-0", response.GetHostname())
+	suite.Zero(response.GetExitCode())
+}
+
+// TestGetPodTerminationInfoExitCode tests that the exit code is
+// returned when the latest run of the pod has terminated
+func (suite *podHandlerTestSuite) TestGetPodTerminationInfoExitCode() {
+	request := &svc.GetPodTerminationInfoRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+	}
+
+	mesosTaskID := testPodID
+	events := []*pod.PodEvent{
+		{
+			PodId:       &v1alphapeloton.PodID{Value: testPodID},
+			Timestamp:   "2019-01-03T22:14:58Z",
+			ActualState: pod.PodState_POD_STATE_FAILED.String(),
+			Reason:      mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String(),
+			Message:     "Command exited with status 2",
+		},
+	}
+
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+		Return(events, nil)
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+			TerminationStatus: &pbtask.TerminationStatus{
+				ExitCode: 2,
+			},
+		}, nil)
+
+	response, err := suite.handler.GetPodTerminationInfo(
+		context.Background(), request)
+	suite.NoError(err)
+	suite.Equal(testPodID, response.GetPodId().GetValue())
+	suite.Equal(events[0].GetReason(), response.GetReason())
+	suite.Equal(uint32(2), response.GetExitCode())
+}
+
+// TestGetPodTerminationInfoNeverTerminated tests that NotFound is
+// returned for a pod which has never terminated
+func (suite *podHandlerTestSuite) TestGetPodTerminationInfoNeverTerminated() {
+	request := &svc.GetPodTerminationInfoRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+	}
+
+	events := []*pod.PodEvent{
+		{
+			PodId:       &v1alphapeloton.PodID{Value: testPodID},
+			Timestamp:   "2019-01-03T22:14:58Z",
+			ActualState: pod.PodState_POD_STATE_RUNNING.String(),
+		},
+	}
+
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+		Return(events, nil)
+
+	_, err := suite.handler.GetPodTerminationInfo(
+		context.Background(), request)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestGetPodRestartStats tests that the runs of the pod which terminated
+// in the window are counted as restarts
+func (suite *podHandlerTestSuite) TestGetPodRestartStats() {
+	request := &svc.GetPodRestartStatsRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+		WindowSeconds: uint32(time.Hour.Seconds()),
+	}
+
+	now := time.Now().UTC()
+	timestamp := func(ago time.Duration) string {
+		return now.Add(-ago).Format(time.RFC3339)
+	}
+	podEvent := func(
+		run int,
+		state pod.PodState,
+		ago time.Duration,
+	) *pod.PodEvent {
+		return &pod.PodEvent{
+			PodId: &v1alphapeloton.PodID{
+				Value: fmt.Sprintf("%s-%d", testPodName, run),
+			},
+			PrevPodId: &v1alphapeloton.PodID{
+				Value: fmt.Sprintf("%s-%d", testPodName, run-1),
+			},
+			Timestamp:   timestamp(ago),
+			ActualState: state.String(),
+		}
+	}
+
+	// The pod is running its fourth run, the second and third runs
+	// terminated in the window, the first one before it.
+	runs := map[int][]*pod.PodEvent{
+		4: {
+			podEvent(4, pod.PodState_POD_STATE_RUNNING, 9*time.Minute),
+		},
+		3: {
+			podEvent(3, pod.PodState_POD_STATE_FAILED, 10*time.Minute),
+			podEvent(3, pod.PodState_POD_STATE_RUNNING, 20*time.Minute),
+		},
+		2: {
+			podEvent(2, pod.PodState_POD_STATE_KILLED, 30*time.Minute),
+			podEvent(2, pod.PodState_POD_STATE_RUNNING, 2*time.Hour),
+		},
+		1: {
+			podEvent(1, pod.PodState_POD_STATE_FAILED, 3*time.Hour),
+			podEvent(1, pod.PodState_POD_STATE_RUNNING, 4*time.Hour),
+		},
+	}
+
+	gomock.InOrder(
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+			Return(runs[4], nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID),
+				testPodName+"-3").
+			Return(runs[3], nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID),
+				testPodName+"-2").
+			Return(runs[2], nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID),
+				testPodName+"-1").
+			Return(runs[1], nil),
+	)
+
+	response, err := suite.handler.GetPodRestartStats(
+		context.Background(), request)
+	suite.NoError(err)
+	suite.Equal(uint32(2), response.GetRestartCount())
+	suite.Equal(timestamp(10*time.Minute), response.GetLastRestartTime())
+}
+
+// TestGetPodRestartStatsNoRestart tests that a pod which never terminated
+// has no restarts
+func (suite *podHandlerTestSuite) TestGetPodRestartStatsNoRestart() {
+	request := &svc.GetPodRestartStatsRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+	}
+
+	prevPodID := testPodName + "-0"
+	events := []*pod.PodEvent{
+		{
+			PodId:       &v1alphapeloton.PodID{Value: testPodName + "-1"},
+			PrevPodId:   &v1alphapeloton.PodID{Value: prevPodID},
+			Timestamp:   "2019-01-03T22:14:58Z",
+			ActualState: pod.PodState_POD_STATE_RUNNING.String(),
+		},
+	}
+
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+		Return(events, nil)
+	suite.podStore.EXPECT().
+		GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), prevPodID).
+		Return(nil, yarpcerrors.NotFoundErrorf("pod events not found"))
+
+	response, err := suite.handler.GetPodRestartStats(
+		context.Background(), request)
+	suite.NoError(err)
+	suite.Zero(response.GetRestartCount())
+	suite.Empty(response.GetLastRestartTime())
+}
+
 // TestBrowsePodSandboxSuccess tests the success case of browsing pod sandbox
 func (suite *podHandlerTestSuite) TestBrowsePodSandboxSuccess() {
 	request := &svc.BrowsePodSandboxRequest{
@@ -2104,11 +3122,31 @@ func (suite *podHandlerTestSuite) TestBrowsePodSandboxGetMesosMasterHostPortFail
 
 // TestDeletePodEventsSuccess tests the success case of deleting pod events
 func (suite *podHandlerTestSuite) TestDeletePodEventsSuccess() {
+	mesosTaskID := testJobID + "-1-4"
 	request := &svc.DeletePodEventsRequest{
 		PodName: &v1alphapeloton.PodName{Value: testPodName},
 		PodId:   &v1alphapeloton.PodID{Value: testPodID},
 	}
 
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{
+				Value: &mesosTaskID,
+			},
+		}, nil)
+
+	suite.podStore.EXPECT().
+		GetPodEvents(
+			gomock.Any(),
+			testJobID,
+			uint32(testInstanceID),
+			testPodID,
+		).Return([]*pod.PodEvent{{}}, nil)
+
 	suite.podStore.EXPECT().
 		DeletePodEvents(
 			gomock.Any(),
@@ -2120,7 +3158,84 @@ func (suite *podHandlerTestSuite) TestDeletePodEventsSuccess() {
 
 	response, err := suite.handler.DeletePodEvents(context.Background(), request)
 	suite.NoError(err)
-	suite.NotNil(response)
+	suite.Equal(uint64(1), response.GetDeletedRuns())
+}
+
+// TestDeletePodEventsActiveRun tests that DeletePodEvents never deletes
+// the events of the current run of the pod
+func (suite *podHandlerTestSuite) TestDeletePodEventsActiveRun() {
+	mesosTaskID := testPodID
+	request := &svc.DeletePodEventsRequest{
+		PodName: &v1alphapeloton.PodName{Value: testPodName},
+		PodId:   &v1alphapeloton.PodID{Value: testPodID},
+	}
+
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+		}, nil)
+
+	response, err := suite.handler.DeletePodEvents(context.Background(), request)
+	suite.NoError(err)
+	suite.Equal(uint64(0), response.GetDeletedRuns())
+}
+
+// TestDeletePodEventsNoEvents tests that DeletePodEvents does not count
+// a run which has no events
+func (suite *podHandlerTestSuite) TestDeletePodEventsNoEvents() {
+	mesosTaskID := testJobID + "-1-4"
+	request := &svc.DeletePodEventsRequest{
+		PodName: &v1alphapeloton.PodName{Value: testPodName},
+		PodId:   &v1alphapeloton.PodID{Value: testPodID},
+	}
+
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+		}, nil)
+
+	suite.podStore.EXPECT().
+		GetPodEvents(
+			gomock.Any(),
+			testJobID,
+			uint32(testInstanceID),
+			testPodID,
+		).Return(nil, nil)
+
+	response, err := suite.handler.DeletePodEvents(context.Background(), request)
+	suite.NoError(err)
+	suite.Equal(uint64(0), response.GetDeletedRuns())
+}
+
+// TestDeletePodEventsFailureInvalidActiveRunID tests that DeletePodEvents
+// fails instead of deleting any events when the run ID of the current
+// run of the pod can not be parsed
+func (suite *podHandlerTestSuite) TestDeletePodEventsFailureInvalidActiveRunID() {
+	mesosTaskID := "invalid-mesos-task-id"
+	request := &svc.DeletePodEventsRequest{
+		PodName: &v1alphapeloton.PodName{Value: testPodName},
+		PodId:   &v1alphapeloton.PodID{Value: testPodID},
+	}
+
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+		}, nil)
+
+	_, err := suite.handler.DeletePodEvents(context.Background(), request)
+	suite.Error(err)
 }
 
 // TestDeletePodEventsFailureInvalidPodName tests
@@ -2158,11 +3273,31 @@ func (suite *podHandlerTestSuite) TestDeletePodEventsFailureInvalidPodID() {
 // TestDeletePodEventsStoreError tests
 // DeletePodEvents failure due to store error
 func (suite *podHandlerTestSuite) TestDeletePodEventsStoreError() {
+	mesosTaskID := testJobID + "-1-4"
 	request := &svc.DeletePodEventsRequest{
 		PodName: &v1alphapeloton.PodName{Value: testPodName},
 		PodId:   &v1alphapeloton.PodID{Value: testPodID},
 	}
 
+	suite.podStore.EXPECT().
+		GetTaskRuntime(
+			gomock.Any(),
+			&peloton.JobID{Value: testJobID},
+			uint32(testInstanceID)).
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{
+				Value: &mesosTaskID,
+			},
+		}, nil)
+
+	suite.podStore.EXPECT().
+		GetPodEvents(
+			gomock.Any(),
+			testJobID,
+			uint32(testInstanceID),
+			testPodID,
+		).Return([]*pod.PodEvent{{}}, nil)
+
 	suite.podStore.EXPECT().
 		DeletePodEvents(
 			gomock.Any(),
@@ -2176,6 +3311,80 @@ func (suite *podHandlerTestSuite) TestDeletePodEventsStoreError() {
 	suite.Error(err)
 }
 
+// TestDeleteJobPodEvents tests that the events of all the pods of a job
+// are deleted, except the events of the current run of each pod
+func (suite *podHandlerTestSuite) TestDeleteJobPodEvents() {
+	mesosTaskID0 := testJobID + "-0-2"
+	mesosTaskID2 := testJobID + "-2-5"
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+	suite.podStore.EXPECT().
+		GetTaskRuntimesForJobByRange(
+			gomock.Any(), &peloton.JobID{Value: testJobID}, gomock.Any()).
+		Return(map[uint32]*pbtask.RuntimeInfo{
+			0: {MesosTaskId: &mesos.TaskID{Value: &mesosTaskID0}},
+			2: {MesosTaskId: &mesos.TaskID{Value: &mesosTaskID2}},
+		}, nil)
+	suite.podStore.EXPECT().
+		DeleteJobPodEvents(
+			gomock.Any(),
+			testJobID,
+			uint32(3),
+			map[uint32]uint64{0: 2, 2: 5},
+		).Return(nil)
+
+	_, err := suite.handler.DeleteJobPodEvents(context.Background(),
+		&svc.DeleteJobPodEventsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.NoError(err)
+}
+
+// TestDeleteJobPodEventsFailures tests the failure cases of deleting
+// the events of the pods of a job
+func (suite *podHandlerTestSuite) TestDeleteJobPodEventsFailures() {
+	request := &svc.DeleteJobPodEventsRequest{
+		JobId: &v1alphapeloton.JobID{Value: testJobID},
+	}
+
+	// job id is not set
+	_, err := suite.handler.DeleteJobPodEvents(context.Background(),
+		&svc.DeleteJobPodEventsRequest{})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// the run ID of a pod can not be parsed
+	mesosTaskID := "invalid-mesos-task-id"
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+	suite.podStore.EXPECT().
+		GetTaskRuntimesForJobByRange(
+			gomock.Any(), &peloton.JobID{Value: testJobID}, gomock.Any()).
+		Return(map[uint32]*pbtask.RuntimeInfo{
+			0: {MesosTaskId: &mesos.TaskID{Value: &mesosTaskID}},
+		}, nil)
+	_, err = suite.handler.DeleteJobPodEvents(context.Background(), request)
+	suite.Error(err)
+
+	// store error
+	mesosTaskID = testJobID + "-0-1"
+	suite.jobFactory.EXPECT().
+		GetJob(&peloton.JobID{Value: testJobID}).
+		Return(nil)
+	suite.podStore.EXPECT().
+		GetTaskRuntimesForJobByRange(
+			gomock.Any(), &peloton.JobID{Value: testJobID}, gomock.Any()).
+		Return(map[uint32]*pbtask.RuntimeInfo{
+			0: {MesosTaskId: &mesos.TaskID{Value: &mesosTaskID}},
+		}, nil)
+	suite.podStore.EXPECT().
+		DeleteJobPodEvents(gomock.Any(), testJobID, uint32(1), gomock.Any()).
+		Return(yarpcerrors.InternalErrorf("test error"))
+	_, err = suite.handler.DeleteJobPodEvents(context.Background(), request)
+	suite.Error(err)
+}
+
 func TestPodServiceHandler(t *testing.T) {
 	suite.Run(t, new(podHandlerTestSuite))
 }
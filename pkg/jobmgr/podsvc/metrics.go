@@ -0,0 +1,44 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podsvc
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track
+// internal state of the pod service
+type Metrics struct {
+	// Counters of pods whose cached runtime differed from the runtime in
+	// the store on refresh, tagged by the field which differed.
+	PodCacheDriftState         tally.Counter
+	PodCacheDriftGoalState     tally.Counter
+	PodCacheDriftConfigVersion tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	driftCounter := func(field string) tally.Counter {
+		return scope.Tagged(map[string]string{"field": field}).
+			Counter("cache_drift_detected")
+	}
+
+	return &Metrics{
+		PodCacheDriftState:         driftCounter("state"),
+		PodCacheDriftGoalState:     driftCounter("goal_state"),
+		PodCacheDriftConfigVersion: driftCounter("config_version"),
+	}
+}
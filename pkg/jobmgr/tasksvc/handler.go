@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
@@ -61,6 +62,10 @@ const (
 
 var (
 	errEmptyFrameworkID = errors.New("framework id is empty")
+
+	// _waitForRestartBatch waits for the delay between two batches of a
+	// batched restart, or until the context is done.
+	_waitForRestartBatch = waitWithContext
 )
 
 // InitServiceHandler initializes the TaskManager
@@ -278,7 +283,8 @@ func (m *serviceHandler) GetPodEvents(
 }
 
 // DeletePodEvents, deletes the pod events for provided request, which is for
-// a jobID + instanceID + less than equal to runID.
+// a jobID + instanceID + less than equal to runID. The events of the current
+// run of the task are never deleted.
 // Response will be successful or error on unable to delete events for input.
 func (m *serviceHandler) DeletePodEvents(
 	ctx context.Context,
@@ -299,16 +305,37 @@ func (m *serviceHandler) DeletePodEvents(
 			Info("TaskManager.DeletePodEvents succeeded")
 	}()
 
+	fromRunID := uint64(1)
+	toRunID := body.GetRunId() + 1
+
+	// Never delete the events of the current run of the task,
+	// which are needed to monitor the task.
+	runtime, err := m.taskStore.GetTaskRuntime(
+		ctx, body.GetJobId(), body.GetInstanceId())
+	if err != nil && !yarpcerrors.IsNotFound(err) {
+		return nil, err
+	}
+	if activeRunID, err := util.ParseRunID(
+		runtime.GetMesosTaskId().GetValue()); err == nil &&
+		activeRunID < toRunID {
+		toRunID = activeRunID
+	}
+	if toRunID <= fromRunID {
+		return &task.DeletePodEventsResponse{}, nil
+	}
+
 	if err := m.taskStore.DeletePodEvents(
 		ctx,
 		body.GetJobId().GetValue(),
 		body.GetInstanceId(),
-		1,
-		body.GetRunId()+1,
+		fromRunID,
+		toRunID,
 	); err != nil {
 		return nil, err
 	}
-	return &task.DeletePodEventsResponse{}, nil
+	return &task.DeletePodEventsResponse{
+		DeletedRuns: toRunID - fromRunID,
+	}, nil
 }
 
 // List/Query API should not use cachedJob
@@ -954,6 +981,16 @@ func (m *serviceHandler) Restart(
 				"Task Restart API not supported on non-leader")
 	}
 
+	if req.GetBatchSize() > 0 {
+		cachedJob := m.jobFactory.AddJob(req.JobId)
+		if err := m.restartInBatches(ctx, cachedJob, req); err != nil {
+			m.metrics.TaskRestartFail.Inc(1)
+			return nil, err
+		}
+		m.metrics.TaskRestart.Inc(1)
+		return &task.RestartResponse{}, nil
+	}
+
 	ctx, cancelFunc := context.WithTimeout(
 		ctx,
 		_rpcTimeout,
@@ -980,6 +1017,95 @@ func (m *serviceHandler) Restart(
 	return &task.RestartResponse{}, nil
 }
 
+// restartInBatches restarts the instances of the request in batches of the
+// request batch size, ordered by instance id, and waits for the batch delay
+// between two batches. The instances of a batch are restarted in parallel.
+// Each batch is restarted within the rpc timeout, while the whole restart
+// is only bound by the context of the request.
+func (m *serviceHandler) restartInBatches(
+	ctx context.Context,
+	cachedJob cached.Job,
+	req *task.RestartRequest) error {
+	diffCtx, cancelFunc := context.WithTimeout(ctx, _rpcTimeout)
+	runtimeDiffs, err := m.getRuntimeDiffsForRestart(diffCtx,
+		cachedJob,
+		req.GetRanges())
+	cancelFunc()
+	if err != nil {
+		return err
+	}
+
+	instanceIDs := make([]uint32, 0, len(runtimeDiffs))
+	for instanceID := range runtimeDiffs {
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	sort.Slice(instanceIDs, func(i, j int) bool {
+		return instanceIDs[i] < instanceIDs[j]
+	})
+
+	batchSize := int(req.GetBatchSize())
+	delay := time.Duration(req.GetBatchDelaySeconds()) * time.Second
+	for start := 0; start < len(instanceIDs); start += batchSize {
+		if start > 0 {
+			if err := _waitForRestartBatch(ctx, delay); err != nil {
+				return errors.Wrap(err, "restart interrupted between batches")
+			}
+		}
+
+		end := start + batchSize
+		if end > len(instanceIDs) {
+			end = len(instanceIDs)
+		}
+		if err := m.restartBatch(
+			ctx, req.JobId, cachedJob, instanceIDs[start:end], runtimeDiffs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restartBatch restarts the given instances in parallel by applying their
+// runtime diffs, and enqueues them into the goal state engine.
+func (m *serviceHandler) restartBatch(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	cachedJob cached.Job,
+	instanceIDs []uint32,
+	runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, _rpcTimeout)
+	defer cancelFunc()
+
+	return util.RunInParallel(
+		jobID.GetValue(),
+		instanceIDs,
+		func(instanceID uint32) error {
+			if _, _, err := cachedJob.PatchTasks(
+				ctx,
+				map[uint32]jobmgrcommon.RuntimeDiff{
+					instanceID: runtimeDiffs[instanceID],
+				},
+				false,
+			); err != nil {
+				return err
+			}
+			m.goalStateDriver.EnqueueTask(jobID, instanceID, time.Now())
+			return nil
+		})
+}
+
+// waitWithContext waits for the given duration, or until the context is
+// done, in which case the error of the context is returned.
+func waitWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // getRuntimeDiffsForRestart returns runtimeDiffs to be applied to task to be
 // restarted. It updates the DesiredMesosTaskID field of task runtime.
 func (m *serviceHandler) getRuntimeDiffsForRestart(
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -1243,6 +1244,52 @@ func (suite *TaskHandlerTestSuite) TestStartTasksWithInvalidRanges() {
 		uint32(testInstanceCount))
 }
 
+// TestDeletePodEventsKeepsActiveRun tests that DeletePodEvents never deletes
+// the events of the current run of the task, even if the requested run is
+// newer
+func (suite *TaskHandlerTestSuite) TestDeletePodEventsKeepsActiveRun() {
+	suite.mockedTaskStore.EXPECT().
+		GetTaskRuntime(gomock.Any(), suite.testJobID, uint32(0)).
+		Return(&task.RuntimeInfo{
+			MesosTaskId: util.CreateMesosTaskID(suite.testJobID, 0, 3),
+		}, nil)
+	suite.mockedTaskStore.EXPECT().
+		DeletePodEvents(
+			gomock.Any(), suite.testJobID.GetValue(), uint32(0),
+			uint64(1), uint64(3)).
+		Return(nil)
+
+	resp, err := suite.handler.DeletePodEvents(
+		context.Background(),
+		&task.DeletePodEventsRequest{
+			JobId:      suite.testJobID,
+			InstanceId: 0,
+			RunId:      5,
+		})
+	suite.NoError(err)
+	suite.Equal(uint64(2), resp.GetDeletedRuns())
+}
+
+// TestDeletePodEventsNoRunBeforeActiveRun tests that DeletePodEvents
+// deletes nothing if the only run of the task is the current one
+func (suite *TaskHandlerTestSuite) TestDeletePodEventsNoRunBeforeActiveRun() {
+	suite.mockedTaskStore.EXPECT().
+		GetTaskRuntime(gomock.Any(), suite.testJobID, uint32(0)).
+		Return(&task.RuntimeInfo{
+			MesosTaskId: util.CreateMesosTaskID(suite.testJobID, 0, 1),
+		}, nil)
+
+	resp, err := suite.handler.DeletePodEvents(
+		context.Background(),
+		&task.DeletePodEventsRequest{
+			JobId:      suite.testJobID,
+			InstanceId: 0,
+			RunId:      1,
+		})
+	suite.NoError(err)
+	suite.Equal(uint64(0), resp.GetDeletedRuns())
+}
+
 // TestGetPodEventsWithRunID tests getting
 // pod events for a given run of a task
 func (suite *TaskHandlerTestSuite) TestGetPodEventsWithRunID() {
@@ -2432,3 +2479,107 @@ func (suite *TaskHandlerTestSuite) TestRestartAllTasks() {
 	suite.NoError(err)
 	suite.NotNil(resp)
 }
+
+// TestRestartInBatches tests restarting the tasks of a job in batches,
+// which are sequenced with the batch delay in between
+func (suite *TaskHandlerTestSuite) TestRestartInBatches() {
+	var taskInfos = make(map[uint32]*task.TaskInfo)
+	for i := uint32(0); i < testInstanceCount; i++ {
+		taskInfos[i] = suite.createTestTaskInfo(
+			task.TaskState_RUNNING, i)
+	}
+
+	var lock sync.Mutex
+	var waits []time.Duration
+	defer func(f func(context.Context, time.Duration) error) {
+		_waitForRestartBatch = f
+	}(_waitForRestartBatch)
+	_waitForRestartBatch = func(_ context.Context, d time.Duration) error {
+		lock.Lock()
+		defer lock.Unlock()
+		waits = append(waits, d)
+		return nil
+	}
+
+	suite.mockedCandidate.EXPECT().IsLeader().Return(true)
+	suite.mockedJobFactory.EXPECT().
+		AddJob(suite.testJobID).Return(suite.mockedCachedJob)
+	suite.mockedCachedJob.EXPECT().
+		ID().Return(suite.testJobID).AnyTimes()
+	suite.mockedTaskStore.EXPECT().
+		GetTasksForJob(gomock.Any(), suite.testJobID).Return(taskInfos, nil)
+	suite.mockedCachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Do(func(
+			ctx context.Context,
+			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+			_ bool,
+		) {
+			lock.Lock()
+			defer lock.Unlock()
+			suite.Len(runtimeDiffs, 1)
+			for instanceID := range runtimeDiffs {
+				// instances 0 and 1 are restarted before the delay,
+				// instances 2 and 3 after it
+				suite.Len(waits, int(instanceID/2))
+			}
+		}).
+		Return(nil, nil, nil).
+		Times(testInstanceCount)
+	suite.mockedGoalStateDrive.EXPECT().
+		EnqueueTask(suite.testJobID, gomock.Any(), gomock.Any()).
+		Times(testInstanceCount)
+
+	resp, err := suite.handler.Restart(
+		context.Background(),
+		&task.RestartRequest{
+			JobId:             suite.testJobID,
+			BatchSize:         2,
+			BatchDelaySeconds: 5,
+		},
+	)
+	suite.NoError(err)
+	suite.NotNil(resp)
+	suite.Equal([]time.Duration{5 * time.Second}, waits)
+}
+
+// TestRestartInBatchesPatchFailure tests that a batched restart stops at
+// the batch which failed to be restarted
+func (suite *TaskHandlerTestSuite) TestRestartInBatchesPatchFailure() {
+	var taskInfos = make(map[uint32]*task.TaskInfo)
+	for i := uint32(0); i < testInstanceCount; i++ {
+		taskInfos[i] = suite.createTestTaskInfo(
+			task.TaskState_RUNNING, i)
+	}
+
+	defer func(f func(context.Context, time.Duration) error) {
+		_waitForRestartBatch = f
+	}(_waitForRestartBatch)
+	_waitForRestartBatch = func(context.Context, time.Duration) error {
+		suite.Fail("the next batch should not be restarted")
+		return nil
+	}
+
+	suite.mockedCandidate.EXPECT().IsLeader().Return(true)
+	suite.mockedJobFactory.EXPECT().
+		AddJob(suite.testJobID).Return(suite.mockedCachedJob)
+	suite.mockedCachedJob.EXPECT().
+		ID().Return(suite.testJobID).AnyTimes()
+	suite.mockedTaskStore.EXPECT().
+		GetTasksForJob(gomock.Any(), suite.testJobID).Return(taskInfos, nil)
+	suite.mockedCachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Return(nil, nil, yarpcerrors.InternalErrorf("test error")).
+		Times(2)
+
+	resp, err := suite.handler.Restart(
+		context.Background(),
+		&task.RestartRequest{
+			JobId:             suite.testJobID,
+			BatchSize:         2,
+			BatchDelaySeconds: 5,
+		},
+	)
+	suite.Error(err)
+	suite.Nil(resp)
+}
@@ -530,6 +530,7 @@ func ConvertSLAConfigToSLASpec(slaConfig *job.SlaConfig) *stateless.SlaSpec {
 		Preemptible:                 slaConfig.GetPreemptible(),
 		Revocable:                   slaConfig.GetRevocable(),
 		MaximumUnavailableInstances: slaConfig.GetMaximumUnavailableInstances(),
+		MaxRunsToRetain:             slaConfig.GetMaxRunsToRetain(),
 	}
 }
 
@@ -540,6 +541,7 @@ func ConvertSLASpecToSLAConfig(slaSpec *stateless.SlaSpec) *job.SlaConfig {
 		Preemptible:                 slaSpec.GetPreemptible(),
 		Revocable:                   slaSpec.GetRevocable(),
 		MaximumUnavailableInstances: slaSpec.GetMaximumUnavailableInstances(),
+		MaxRunsToRetain:             slaSpec.GetMaxRunsToRetain(),
 	}
 }
 
@@ -0,0 +1,93 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbound
+
+import (
+	"context"
+
+	"go.uber.org/yarpc/api/transport"
+)
+
+// CorrelationIDHeader is the header carrying the id which ties together
+// the requests made on behalf of a single inbound request.
+const CorrelationIDHeader = "x-peloton-correlation-id"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation id,
+// which is sent as the CorrelationIDHeader of the outbound requests made
+// with the context.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation id carried by ctx, or
+// an empty string if there is none.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// CorrelationOutboundMiddleware is the outbound middleware which adds the
+// correlation id of the context to the request headers.
+type CorrelationOutboundMiddleware struct{}
+
+// Call adds the correlation id into request and invoke the underlying
+// outbound call
+func (m *CorrelationOutboundMiddleware) Call(
+	ctx context.Context,
+	request *transport.Request,
+	out transport.UnaryOutbound,
+) (*transport.Response, error) {
+	request.Headers = getHeadersWithCorrelationID(ctx, request.Headers)
+	return out.Call(ctx, request)
+}
+
+// CallOneway adds the correlation id into request and invoke the
+// underlying outbound call
+func (m *CorrelationOutboundMiddleware) CallOneway(
+	ctx context.Context,
+	request *transport.Request,
+	out transport.OnewayOutbound,
+) (transport.Ack, error) {
+	request.Headers = getHeadersWithCorrelationID(ctx, request.Headers)
+	return out.CallOneway(ctx, request)
+}
+
+// CallStream adds the correlation id into request and invoke the
+// underlying outbound call
+func (m *CorrelationOutboundMiddleware) CallStream(
+	ctx context.Context,
+	request *transport.StreamRequest,
+	out transport.StreamOutbound,
+) (*transport.ClientStream, error) {
+	request.Meta.Headers = getHeadersWithCorrelationID(ctx, request.Meta.Headers)
+	return out.CallStream(ctx, request)
+}
+
+func getHeadersWithCorrelationID(
+	ctx context.Context,
+	headers transport.Headers,
+) transport.Headers {
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		headers = headers.With(CorrelationIDHeader, id)
+	}
+	return headers
+}
+
+// NewCorrelationOutboundMiddleware returns CorrelationOutboundMiddleware
+func NewCorrelationOutboundMiddleware() *CorrelationOutboundMiddleware {
+	return &CorrelationOutboundMiddleware{}
+}
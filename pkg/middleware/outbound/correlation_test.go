@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbound
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+)
+
+// TestCorrelationCall tests that the correlation id of the context is
+// added to the outbound request headers.
+func TestCorrelationCall(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := NewCorrelationOutboundMiddleware()
+	out := transporttest.NewMockUnaryOutbound(ctrl)
+	out.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.Request) {
+			value, ok := request.Headers.Get(CorrelationIDHeader)
+			assert.True(t, ok)
+			assert.Equal(t, "id1", value)
+		}).Return(nil, nil)
+
+	ctx := WithCorrelationID(context.Background(), "id1")
+	_, err := m.Call(ctx, &transport.Request{}, out)
+	assert.NoError(t, err)
+}
+
+// TestCorrelationCallWithoutID tests that no header is added if the
+// context carries no correlation id.
+func TestCorrelationCallWithoutID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := NewCorrelationOutboundMiddleware()
+	out := transporttest.NewMockUnaryOutbound(ctrl)
+	out.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.Request) {
+			_, ok := request.Headers.Get(CorrelationIDHeader)
+			assert.False(t, ok)
+		}).Return(nil, nil)
+
+	_, err := m.Call(context.Background(), &transport.Request{}, out)
+	assert.NoError(t, err)
+}
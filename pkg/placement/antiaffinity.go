@@ -0,0 +1,62 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+)
+
+// hostTasks is implemented by the offers which know the tasks running on
+// their host.
+type hostTasks interface {
+	GetTasks() []*resmgr.Task
+}
+
+// runsLabeledTask returns true if any of the tasks has any of the labels.
+func runsLabeledTask(tasks []*resmgr.Task, labels map[string]string) bool {
+	for _, task := range tasks {
+		for _, label := range task.GetLabels().GetLabels() {
+			if value, ok := labels[label.GetKey()]; ok &&
+				value == label.GetValue() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skipAntiAffineHosts releases the offers of the hosts running a task of
+// a job that the tasks of the placement needs are anti-affine to, and
+// returns the other offers. Only the tasks of the task type of the engine
+// are known to run on a host, and offers which do not know the tasks
+// running on their host are never skipped.
+func (e *engine) skipAntiAffineHosts(
+	ctx context.Context,
+	needs plugins.PlacementNeeds,
+	offers []models.Offer) []models.Offer {
+	if len(needs.AntiAffinityJobLabels) == 0 {
+		return offers
+	}
+	return e.skipOffers(ctx, offers, func(offer models.Offer) bool {
+		host, ok := offer.(hostTasks)
+		return ok &&
+			runsLabeledTask(host.GetTasks(), needs.AntiAffinityJobLabels)
+	}, e.metrics.OfferAntiAffinity)
+}
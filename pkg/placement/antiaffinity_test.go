@@ -0,0 +1,47 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupLabeledTask creates a resource manager task with the label.
+func setupLabeledTask(key, value string) *resmgr.Task {
+	return &resmgr.Task{
+		Labels: &mesos_v1.Labels{
+			Labels: []*mesos_v1.Label{{Key: &key, Value: &value}},
+		},
+	}
+}
+
+// TestRunsLabeledTask tests matching the labels of the tasks running on
+// a host.
+func TestRunsLabeledTask(t *testing.T) {
+	labels := map[string]string{"service": "cache"}
+
+	assert.True(t, runsLabeledTask(
+		[]*resmgr.Task{setupLabeledTask("service", "cache")}, labels))
+	assert.False(t, runsLabeledTask(
+		[]*resmgr.Task{setupLabeledTask("service", "web")}, labels))
+	assert.False(t, runsLabeledTask(
+		[]*resmgr.Task{setupLabeledTask("team", "cache")}, labels))
+	assert.False(t, runsLabeledTask(nil, labels))
+}
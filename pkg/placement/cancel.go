@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// _placementCancelled is the placement failure reason of the tasks whose
+// placement was cancelled.
+const _placementCancelled = "placement cancelled"
+
+// placementGroup identifies the tasks of a job with the same constraint
+// shape. An empty shape stands for all tasks of the job.
+type placementGroup struct {
+	jobID string
+	shape string
+}
+
+// placementCancellations is the registry of the placement groups whose
+// pending placements are cancelled in the next placement round.
+type placementCancellations struct {
+	sync.Mutex
+	groups map[placementGroup]struct{}
+}
+
+// newPlacementCancellations creates an empty placementCancellations.
+func newPlacementCancellations() *placementCancellations {
+	return &placementCancellations{
+		groups: make(map[placementGroup]struct{}),
+	}
+}
+
+// cancel registers the placement group to be cancelled.
+func (c *placementCancellations) cancel(group placementGroup) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.groups[group] = struct{}{}
+}
+
+// take splits the assignments into the ones to be placed and the ones of
+// the cancelled placement groups, and clears the registry, so that the
+// tasks of the groups are placed again once they are dequeued later.
+func (c *placementCancellations) take(
+	assignments []models.Task) (allowed, cancelled []models.Task) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.groups) == 0 {
+		return assignments, nil
+	}
+
+	for _, assignment := range assignments {
+		jobID := assignment.GetResmgrTaskV0().GetJobId().GetValue()
+		shape := constraintShape(assignment.GetPlacementNeeds())
+		_, jobCancelled := c.groups[placementGroup{jobID: jobID}]
+		_, groupCancelled := c.groups[placementGroup{jobID: jobID, shape: shape}]
+		if jobCancelled || groupCancelled {
+			cancelled = append(cancelled, assignment)
+			continue
+		}
+		allowed = append(allowed, assignment)
+	}
+	c.groups = make(map[placementGroup]struct{})
+	return allowed, cancelled
+}
+
+// CancelPlacements cancels the pending placements of the tasks of the job
+// with the constraint shape, or of all tasks of the job if the shape is
+// empty. The tasks are returned unplaced in the next placement round
+// instead of being placed.
+func (e *engine) CancelPlacements(jobID string, shape string) {
+	log.WithFields(log.Fields{
+		"job_id": jobID,
+		"shape":  shape,
+	}).Info("Cancelling pending placements")
+	e.cancellations.cancel(placementGroup{jobID: jobID, shape: shape})
+}
+
+// returnCancelledAssignments returns the assignments whose placement was
+// cancelled back to the task service unplaced.
+func (e *engine) returnCancelledAssignments(
+	ctx context.Context,
+	cancelled []models.Task) {
+	for _, a := range cancelled {
+		a.SetPlacement(nil)
+		a.SetPlacementFailure(_placementCancelled)
+	}
+	e.taskService.SetPlacements(ctx, nil, cancelled)
+}
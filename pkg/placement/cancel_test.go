@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupJobAssignments(jobID string, n int) []models.Task {
+	var assignments []models.Task
+	for i := 0; i < n; i++ {
+		assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+		assignment.GetTask().GetTask().JobId = &peloton.JobID{Value: jobID}
+		assignments = append(assignments, assignment)
+	}
+	return assignments
+}
+
+// TestPlacementCancellations tests that the assignments of the cancelled
+// placement groups are split out once, and that the registry is cleared.
+func TestPlacementCancellations(t *testing.T) {
+	job1 := setupJobAssignments("job1", 2)
+	job2 := setupJobAssignments("job2", 2)
+	job3 := setupJobAssignments("job3", 2)
+	assignments := append(append(append([]models.Task{}, job1...), job2...), job3...)
+
+	c := newPlacementCancellations()
+	allowed, cancelled := c.take(assignments)
+	assert.Equal(t, assignments, allowed)
+	assert.Empty(t, cancelled)
+
+	c.cancel(placementGroup{jobID: "job1"})
+	c.cancel(placementGroup{
+		jobID: "job2",
+		shape: constraintShape(job2[0].GetPlacementNeeds()),
+	})
+	c.cancel(placementGroup{jobID: "job3", shape: "revocable"})
+
+	allowed, cancelled = c.take(assignments)
+	assert.Equal(t, job3, allowed)
+	assert.Equal(t, append(append([]models.Task{}, job1...), job2...), cancelled)
+
+	allowed, cancelled = c.take(assignments)
+	assert.Equal(t, assignments, allowed)
+	assert.Empty(t, cancelled)
+}
+
+// TestEnginePlaceCancelledGroup tests that the tasks of a cancelled group
+// are returned unplaced in the next round without acquiring offers.
+func TestEnginePlaceCancelledGroup(t *testing.T) {
+	ctrl, engine, _, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+
+	assignments := setupJobAssignments("job1", 2)
+
+	mockTaskService.EXPECT().
+		Dequeue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(assignments)
+
+	var returned []models.Task
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ []models.Task, failed []models.Task) {
+			returned = failed
+		}).
+		Return(tasks.SetPlacementsResult{})
+
+	engine.CancelPlacements("job1", "")
+	unfulfilled, _ := engine.Place(context.Background(), nil)
+	engine.pool.WaitUntilProcessed()
+
+	assert.Empty(t, unfulfilled)
+	assert.Equal(t, assignments, returned)
+	for _, assignment := range assignments {
+		assert.Nil(t, assignment.GetPlacement())
+		assert.Equal(t, _placementCancelled, assignment.GetPlacementFailure())
+	}
+}
@@ -20,12 +20,16 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common/api"
+	commonconfig "github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/hostmgr/mesos"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
 	"github.com/uber/peloton/pkg/storage/config"
+
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -33,6 +37,18 @@ const (
 	Batch = PlacementStrategy("batch")
 	// Mimir is the Mimir strategy
 	Mimir = PlacementStrategy("mimir")
+
+	// BinPackFeatureFlag is the feature flag which makes the placement
+	// engine bin pack tasks of all task types using the batch strategy.
+	// It takes precedence over the configured strategy.
+	BinPackFeatureFlag = "placement_binpack"
+
+	// GroupByConstraint groups the tasks with the same placement needs,
+	// including their constraint, together.
+	GroupByConstraint = TaskGrouping("constraint")
+	// GroupByJobAndConstraint groups the tasks of the same job with the
+	// same placement needs together.
+	GroupByJobAndConstraint = TaskGrouping("job_and_constraint")
 )
 
 // Config holds all configs to run a placement engine.
@@ -51,6 +67,16 @@ type Config struct {
 // engine should use.
 type PlacementStrategy string
 
+// TaskGrouping determines how the placement engine groups the tasks which
+// acquire host offers together.
+type TaskGrouping string
+
+// ByJob returns true if the tasks of different jobs are never grouped
+// together.
+func (g TaskGrouping) ByJob() bool {
+	return g == GroupByJobAndConstraint
+}
+
 // PlacementConfig is Placement engine specific config
 type PlacementConfig struct {
 	// HTTP port which hostmgr is listening on
@@ -66,8 +92,15 @@ type PlacementConfig struct {
 	TaskDequeueLimit int `yaml:"task_dequeue_limit"`
 
 	// TaskDequeueTimeOut is the timeout for the ready queue in resmgr
+	// in milliseconds.
 	TaskDequeueTimeOut int `yaml:"task_dequeue_timeout"`
 
+	// TaskDequeueRPCTimeout is the timeout of a call to resmgr to dequeue
+	// tasks. It is raised to TaskDequeueTimeOut plus a margin if it is
+	// shorter, so that the call is not cancelled while resmgr waits for
+	// tasks in the ready queue. Defaults to 10 seconds.
+	TaskDequeueRPCTimeout time.Duration `yaml:"task_dequeue_rpc_timeout"`
+
 	// OfferDequeueLimit is the max Number of HostOffers to dequeue in
 	// a request
 	OfferDequeueLimit int `yaml:"offer_dequeue_limit"`
@@ -111,6 +144,124 @@ type PlacementConfig struct {
 
 	// UseHostPool is the config switch to use host pool logic in placement engine
 	UseHostPool bool `yaml:"use_host_pool"`
+
+	// MaxTasksPerHostPerRound caps the number of tasks placed onto a
+	// single host in one placement round, even if the host has resources
+	// left, so that a burst of launches does not overwhelm one agent. The
+	// batch strategy packs the remaining tasks onto the next offers, and
+	// the placement engine counts the tasks of all the groups of the round
+	// on each host, retrying the capped tasks on other offers or in the
+	// next round. A value of 0 means there is no limit.
+	MaxTasksPerHostPerRound int `yaml:"max_tasks_per_host_per_round"`
+
+	// RespoolRateLimits caps the rate at which tasks of a resource pool,
+	// keyed by resource pool ID, are placed. Tasks exceeding the rate are
+	// deferred to the next placement round.
+	RespoolRateLimits map[string]RateLimitConfig `yaml:"respool_rate_limits"`
+
+	// RespoolFairness places the tasks of the different resource pools in
+	// turn within each placement group, so that the offers acquired in a
+	// round are shared across the resource pools, rather than taken by
+	// the pool whose tasks were dequeued first.
+	RespoolFairness bool `yaml:"respool_fairness"`
+
+	// FeatureFlags toggles features of the placement engine which are
+	// being rolled out gradually.
+	FeatureFlags commonconfig.FeatureFlags `yaml:"feature_flags"`
+
+	// DecisionLogSampleRate is the fraction, between 0 and 1, of placement
+	// decisions for which a structured decision record is emitted, by
+	// default as a log at Info level. A value of 0 disables decision
+	// logging.
+	DecisionLogSampleRate float64 `yaml:"decision_log_sample_rate"`
+
+	// MaxOfferAcquiresPerGroup is the maximal number of times offers are
+	// acquired for a single group of tasks with the same placement needs
+	// in a placement round. The tasks of a group which used up its share
+	// are placed in the next round, so that a group with many tasks does
+	// not starve the other groups of offers. A value of 0 means there is
+	// no limit.
+	MaxOfferAcquiresPerGroup int `yaml:"max_offer_acquires_per_group"`
+
+	// FailedHostPenaltyWindow is the duration for which the offers of a
+	// host are skipped after a placement on the host was rejected. A value
+	// of 0 means the offers of such hosts are not skipped.
+	FailedHostPenaltyWindow time.Duration `yaml:"failed_host_penalty_window"`
+
+	// OfferRetentionPeriod is the duration for which the offers left
+	// unused by a placement round are kept to place tasks with the same
+	// placement needs in the next rounds, instead of being released to the
+	// host manager. It must be shorter than the time for which the host
+	// manager holds offers for placement. A value of 0 means unused offers
+	// are released right away.
+	OfferRetentionPeriod time.Duration `yaml:"offer_retention_period"`
+
+	// TransientErrorRetries is the number of times a call to resource
+	// manager to dequeue tasks or set placements is retried within a
+	// placement round when it fails with a transient error. Calls failing
+	// with a permanent error are never retried.
+	TransientErrorRetries int `yaml:"transient_error_retries"`
+
+	// TransientErrorRetryInterval is the time to wait before retrying a
+	// call failed with a transient error.
+	TransientErrorRetryInterval time.Duration `yaml:"transient_error_retry_interval"`
+
+	// ShutdownDrainTimeout is the maximal time to wait for the in-flight
+	// placements to finish when the engine is stopped. The offers held by
+	// the placements still running after the timeout are returned to the
+	// host manager, and the placements are abandoned. A value of 0
+	// disables the timeout.
+	ShutdownDrainTimeout time.Duration `yaml:"shutdown_drain_timeout"`
+
+	// SoftConstraintRelaxAfter makes the soft constraint of the tasks
+	// required when acquiring host offers, until the tasks have been
+	// starved of offers for this duration. After that the offers are
+	// acquired with the hard constraint of the tasks only, and the soft
+	// constraint is only preferred. The hard constraint is never relaxed.
+	// A value of 0 means the soft constraint is never required.
+	SoftConstraintRelaxAfter time.Duration `yaml:"soft_constraint_relax_after"`
+
+	// TaskGrouping determines how the tasks are grouped to acquire host
+	// offers together, either by their placement needs only (constraint),
+	// or also by their job (job_and_constraint), which acquires offers for
+	// each job separately. It defaults to constraint.
+	TaskGrouping TaskGrouping `yaml:"task_grouping"`
+
+	// ResourceWeights are the weights of the resource dimensions with
+	// which the batch strategy scores how well the tasks fit the hosts.
+	// The tasks are packed onto the best fitting hosts first, so that a
+	// resource which is scarce on a host is not taken by tasks which
+	// mostly need the other resources. If no weight is set, the hosts are
+	// used in the order they were acquired.
+	ResourceWeights ResourceWeightsConfig `yaml:"resource_weights"`
+}
+
+// ResourceWeightsConfig is the config of the weights of the resource
+// dimensions when scoring how well a task fits a host.
+type ResourceWeightsConfig struct {
+	CPU    float64 `yaml:"cpu"`
+	Memory float64 `yaml:"memory"`
+	Disk   float64 `yaml:"disk"`
+	GPU    float64 `yaml:"gpu"`
+}
+
+// Resources returns the weights as scalar resources.
+func (c ResourceWeightsConfig) Resources() scalar.Resources {
+	return scalar.Resources{
+		CPU:  c.CPU,
+		Mem:  c.Memory,
+		Disk: c.Disk,
+		GPU:  c.GPU,
+	}
+}
+
+// RateLimitConfig is the token bucket config for rate limiting placements.
+type RateLimitConfig struct {
+	// Rate is the number of placements allowed per second.
+	Rate rate.Limit `yaml:"rate"`
+
+	// Burst is the maximal number of placements allowed at once.
+	Burst int `yaml:"burst"`
 }
 
 // MaxRoundsConfig is the config of the maximal number of successful rounds
@@ -0,0 +1,147 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"math/rand"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// DecisionRecord is the structured record of the placement of a task on
+// a host, used to audit placement decisions.
+type DecisionRecord struct {
+	// TaskID is the Peloton ID of the placed task.
+	TaskID string
+	// Hostname is the host chosen for the task.
+	Hostname string
+	// OfferID is the ID of the offer or lease the task was placed on.
+	OfferID string
+	// Offered is the resources offered by the host.
+	Offered scalar.Resources
+	// Requested is the resources requested by the task.
+	Requested scalar.Resources
+	// Constraint is the placement constraint of the task.
+	Constraint interface{}
+	// Strategy is the placement strategy which chose the host.
+	Strategy config.PlacementStrategy
+}
+
+// DecisionSink receives the sampled placement decision records.
+type DecisionSink interface {
+	Write(record *DecisionRecord)
+}
+
+// logDecisionSink is the default DecisionSink, which emits each record as
+// a structured log at Info level.
+type logDecisionSink struct{}
+
+func (logDecisionSink) Write(record *DecisionRecord) {
+	log.WithFields(log.Fields{
+		"task":       record.TaskID,
+		"hostname":   record.Hostname,
+		"offer_id":   record.OfferID,
+		"offered":    record.Offered,
+		"requested":  record.Requested,
+		"constraint": record.Constraint,
+		"strategy":   record.Strategy,
+	}).Info("placement decision")
+}
+
+// decisionLogger emits a structured record for a sampled fraction of the
+// placement decisions made by the engine.
+type decisionLogger struct {
+	sync.Mutex
+
+	rate     float64
+	random   *rand.Rand
+	strategy config.PlacementStrategy
+	sink     DecisionSink
+}
+
+// newDecisionLogger creates a decisionLogger which writes the given
+// fraction of placement decisions to the sink, sampled using the given
+// random source.
+func newDecisionLogger(
+	rate float64,
+	strategy config.PlacementStrategy,
+	source rand.Source,
+	sink DecisionSink) *decisionLogger {
+	return &decisionLogger{
+		rate:     rate,
+		random:   rand.New(source),
+		strategy: strategy,
+		sink:     sink,
+	}
+}
+
+// sample returns true if the next placement decision should be logged.
+func (d *decisionLogger) sample() bool {
+	if d.rate <= 0 {
+		return false
+	}
+	d.Lock()
+	defer d.Unlock()
+	return d.random.Float64() < d.rate
+}
+
+// log writes a placement decision record for each task placed on a sampled
+// subset of the hosts that the assigned tasks were placed on, and returns
+// the number of placement decisions logged.
+func (d *decisionLogger) log(assigned []models.Task) int {
+	if d.rate <= 0 {
+		return 0
+	}
+
+	offers := make(map[string]models.Offer)
+	tasksByOffer := make(map[string][]models.Task)
+	for _, task := range assigned {
+		offer := task.GetPlacement()
+		if offer == nil {
+			continue
+		}
+		offers[offer.ID()] = offer
+		tasksByOffer[offer.ID()] = append(tasksByOffer[offer.ID()], task)
+	}
+
+	logged := 0
+	for id, tasks := range tasksByOffer {
+		if !d.sample() {
+			continue
+		}
+
+		offer := offers[id]
+		offered, _ := offer.GetAvailableResources()
+		for _, task := range tasks {
+			needs := task.GetPlacementNeeds()
+			d.sink.Write(&DecisionRecord{
+				TaskID:     task.PelotonID(),
+				Hostname:   offer.Hostname(),
+				OfferID:    id,
+				Offered:    offered,
+				Requested:  needs.Resources,
+				Constraint: needs.Constraint,
+				Strategy:   d.strategy,
+			})
+		}
+		logged++
+	}
+	return logged
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDecisionSink is a DecisionSink keeping the records written to it.
+type fakeDecisionSink struct {
+	records []*DecisionRecord
+}
+
+func (f *fakeDecisionSink) Write(record *DecisionRecord) {
+	f.records = append(f.records, record)
+}
+
+func setupPlacedAssignments(n int) []models.Task {
+	var assigned []models.Task
+	for i := 0; i < n; i++ {
+		assignment := testutil.SetupAssignment(time.Now(), 1)
+		assignment.SetPlacement(testutil.SetupHostOffers())
+		assigned = append(assigned, assignment)
+	}
+	return assigned
+}
+
+// TestDecisionLoggerSampleRate tests that roughly the configured fraction
+// of placement decisions are logged.
+func TestDecisionLoggerSampleRate(t *testing.T) {
+	placements := 1000
+	assigned := setupPlacedAssignments(placements)
+
+	logger := newDecisionLogger(0.1, config.Batch, rand.NewSource(42), logDecisionSink{})
+	logged := logger.log(assigned)
+	assert.InDelta(t, placements/10, logged, float64(placements)/20)
+
+	logger = newDecisionLogger(1, config.Batch, rand.NewSource(42), logDecisionSink{})
+	assert.Equal(t, placements, logger.log(assigned))
+}
+
+// TestDecisionLoggerDisabled tests that no placement decision is logged
+// when the sample rate is not set.
+func TestDecisionLoggerDisabled(t *testing.T) {
+	logger := newDecisionLogger(0, config.Batch, rand.NewSource(42), logDecisionSink{})
+	assert.Equal(t, 0, logger.log(setupPlacedAssignments(10)))
+}
+
+// TestDecisionLoggerGroupsTasksByHost tests that tasks placed on the same
+// host are reported as a single placement decision.
+func TestDecisionLoggerGroupsTasksByHost(t *testing.T) {
+	host := testutil.SetupHostOffers()
+	var assigned []models.Task
+	for i := 0; i < 3; i++ {
+		assignment := testutil.SetupAssignment(time.Now(), 1)
+		assignment.SetPlacement(host)
+		assigned = append(assigned, assignment)
+	}
+
+	logger := newDecisionLogger(1, config.Batch, rand.NewSource(42), logDecisionSink{})
+	assert.Equal(t, 1, logger.log(assigned))
+}
+
+// TestEngineWritesDecisionRecord tests that the engine writes a decision
+// record of a successful placement to the configured sink.
+func TestEngineWritesDecisionRecord(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, mockStrategy, _ := setupEngine(
+		t,
+		func(placementConfig *config.PlacementConfig) {
+			placementConfig.DecisionLogSampleRate = 1
+		})
+	defer ctrl.Finish()
+	sink := &fakeDecisionSink{}
+	WithDecisionSink(sink)(engine)
+
+	offer := setupNamedHostOffers("host1")
+	assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	needs := assignment.GetPlacementNeeds()
+
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]models.Offer{offer}, _testReason, nil)
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), gomock.Any()).
+		AnyTimes()
+	mockStrategy.EXPECT().
+		GetTaskPlacements(gomock.Any(), []plugins.Host{offer}).
+		Return(map[int]int{0: 0})
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tasks.SetPlacementsResult{})
+
+	unfulfilled := engine.placeAssignmentGroup(
+		context.Background(), needs, []models.Task{assignment})
+	assert.Empty(t, unfulfilled)
+
+	offered, _ := offer.GetAvailableResources()
+	assert.Equal(t, []*DecisionRecord{{
+		TaskID:     assignment.PelotonID(),
+		Hostname:   "host1",
+		OfferID:    offer.ID(),
+		Offered:    offered,
+		Requested:  needs.Resources,
+		Constraint: needs.Constraint,
+		Strategy:   config.Batch,
+	}}, sink.records)
+}
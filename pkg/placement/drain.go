@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// inFlightPlacements tracks the assignment groups being placed and the
+// offers they hold, so that the engine can wait for them to finish when
+// it is stopped, and return the offers of those which do not finish in
+// time.
+type inFlightPlacements struct {
+	sync.Mutex
+
+	nextID int
+	done   map[int]chan struct{}
+	offers map[int][]models.Offer
+}
+
+// newInFlightPlacements creates an empty inFlightPlacements.
+func newInFlightPlacements() *inFlightPlacements {
+	return &inFlightPlacements{
+		done:   make(map[int]chan struct{}),
+		offers: make(map[int][]models.Offer),
+	}
+}
+
+// start registers a placement and returns its ID.
+func (p *inFlightPlacements) start() int {
+	p.Lock()
+	defer p.Unlock()
+	id := p.nextID
+	p.nextID++
+	p.done[id] = make(chan struct{})
+	return id
+}
+
+// hold records the offers currently held by the placement.
+func (p *inFlightPlacements) hold(id int, offers []models.Offer) {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.done[id]; !ok {
+		// The placement has been abandoned.
+		return
+	}
+	if len(offers) == 0 {
+		delete(p.offers, id)
+		return
+	}
+	p.offers[id] = offers
+}
+
+// finish unregisters the placement.
+func (p *inFlightPlacements) finish(id int) {
+	p.Lock()
+	defer p.Unlock()
+	if done, ok := p.done[id]; ok {
+		close(done)
+		delete(p.done, id)
+	}
+	delete(p.offers, id)
+}
+
+// wait waits up to the timeout for the registered placements to finish,
+// and returns false if some of them did not.
+func (p *inFlightPlacements) wait(timeout time.Duration) bool {
+	p.Lock()
+	var pending []chan struct{}
+	for _, done := range p.done {
+		pending = append(pending, done)
+	}
+	p.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, done := range pending {
+		select {
+		case <-done:
+		case <-timer.C:
+			return false
+		}
+	}
+	return true
+}
+
+// abandon unregisters all the placements, and returns the number of
+// placements and the offers they held.
+func (p *inFlightPlacements) abandon() (int, []models.Offer) {
+	p.Lock()
+	defer p.Unlock()
+	var offers []models.Offer
+	for _, held := range p.offers {
+		offers = append(offers, held...)
+	}
+	abandoned := len(p.done)
+	p.done = make(map[int]chan struct{})
+	p.offers = make(map[int][]models.Offer)
+	return abandoned, offers
+}
+
+// _placementEngineStopped is the placement failure reason of the tasks
+// which were dequeued but not placed when the engine stopped.
+const _placementEngineStopped = "placement engine stopped"
+
+// unplacedAssignments holds the dequeued assignments which were not placed
+// when the engine daemon stopped.
+type unplacedAssignments struct {
+	sync.Mutex
+	assignments []models.Task
+}
+
+// set replaces the held assignments.
+func (u *unplacedAssignments) set(assignments []models.Task) {
+	u.Lock()
+	defer u.Unlock()
+	u.assignments = assignments
+}
+
+// take returns the held assignments and clears them.
+func (u *unplacedAssignments) take() []models.Task {
+	u.Lock()
+	defer u.Unlock()
+	assignments := u.assignments
+	u.assignments = nil
+	return assignments
+}
+
+// returnUnplacedAssignments returns the assignments which were dequeued
+// but not placed when the engine daemon stopped back to the resource
+// manager, so that they are placed again without waiting for their
+// placement to time out.
+func (e *engine) returnUnplacedAssignments() {
+	unplaced := e.unplaced.take()
+	if len(unplaced) == 0 {
+		return
+	}
+	for _, a := range unplaced {
+		a.SetPlacement(nil)
+		a.SetPlacementFailure(_placementEngineStopped)
+	}
+	log.WithField("tasks", len(unplaced)).
+		Info("returning unplaced tasks to the resource manager")
+	e.taskService.SetPlacements(context.Background(), nil, unplaced)
+}
+
+// drain waits up to the shutdown drain timeout for the engine daemon to
+// stop and the in-flight placements to finish. The placements which are
+// still running after the timeout are abandoned and their offers are
+// returned to the host manager.
+func (e *engine) drain(stopped <-chan struct{}) {
+	timeout := e.config.ShutdownDrainTimeout
+	deadline := time.Now().Add(timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var drained bool
+	select {
+	case <-stopped:
+		drained = e.inFlight.wait(time.Until(deadline))
+	case <-timer.C:
+		drained = false
+	}
+
+	if drained {
+		return
+	}
+	abandoned, held := e.inFlight.abandon()
+	log.WithFields(log.Fields{
+		"timeout":     timeout.String(),
+		"abandoned":   abandoned,
+		"held_offers": len(held),
+	}).Warn("placements did not finish within the shutdown drain timeout, abandoning them")
+	if len(held) > 0 {
+		e.offerService.Release(context.Background(), held)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInFlightPlacements tests that finished placements are not waited
+// for, and that abandoned placements return the offers they held.
+func TestInFlightPlacements(t *testing.T) {
+	inFlight := newInFlightPlacements()
+	offer := testutil.SetupHostOffers()
+
+	finished := inFlight.start()
+	inFlight.hold(finished, []models.Offer{testutil.SetupHostOffers()})
+	inFlight.finish(finished)
+	assert.True(t, inFlight.wait(time.Millisecond))
+
+	running := inFlight.start()
+	inFlight.hold(running, []models.Offer{offer})
+	assert.False(t, inFlight.wait(time.Millisecond))
+
+	abandoned, offers := inFlight.abandon()
+	assert.Equal(t, 1, abandoned)
+	assert.Equal(t, []models.Offer{offer}, offers)
+	assert.True(t, inFlight.wait(time.Millisecond))
+
+	// Offers held after the placement was abandoned are not tracked.
+	inFlight.hold(running, []models.Offer{offer})
+	inFlight.finish(running)
+	_, offers = inFlight.abandon()
+	assert.Empty(t, offers)
+}
+
+// TestEngineStopDrainTimeout tests that stopping the engine returns after
+// the shutdown drain timeout when a placement does not finish in time, and
+// that the offers held by the placement are returned.
+func TestEngineStopDrainTimeout(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, mockStrategy, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.config.ShutdownDrainTimeout = 100 * time.Millisecond
+
+	used := testutil.SetupHostOffers()
+	unused := testutil.SetupHostOffers()
+	needs := plugins.PlacementNeeds{}
+
+	placing := make(chan struct{})
+	unblock := make(chan struct{})
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), needs).
+		Return([]models.Offer{used, unused}, _testReason, nil)
+	mockStrategy.EXPECT().
+		GetTaskPlacements(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ []plugins.Task, _ []plugins.Host) map[int]int {
+			close(placing)
+			<-unblock
+			return map[int]int{0: 0}
+		})
+
+	// The offers held by the placement are returned on stop, and the
+	// abandoned placement may still release its unused offer.
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), []models.Offer{used, unused})
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), []models.Offer{unused}).
+		AnyTimes()
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tasks.SetPlacementsResult{}).
+		AnyTimes()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+		engine.placeAssignmentGroup(
+			context.Background(), needs, []models.Task{assignment})
+	}()
+	<-placing
+
+	start := time.Now()
+	engine.Stop()
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= engine.config.ShutdownDrainTimeout)
+	assert.True(t, elapsed < time.Second)
+
+	close(unblock)
+	<-done
+}
+
+// TestEngineStopReleasesRetainedOffers tests that stopping the engine
+// without a shutdown drain timeout releases the retained offers.
+func TestEngineStopReleasesRetainedOffers(t *testing.T) {
+	ctrl, engine, mockOfferService, _, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.config.ShutdownDrainTimeout = 0
+	engine.offerRetainer = newOfferRetainer(time.Minute)
+
+	offer := testutil.SetupHostOffers()
+	engine.offerRetainer.retain(
+		plugins.PlacementNeeds{},
+		[]models.Offer{offer},
+		time.Now())
+
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), []models.Offer{offer})
+
+	engine.Stop()
+	assert.Empty(t, engine.offerRetainer.retained)
+
+	// The offers are released only once.
+	engine.Stop()
+}
+
+// TestEngineStopReturnsUnplacedAssignments tests that stopping the engine
+// returns the dequeued assignments which are not placed yet to the
+// resource manager.
+func TestEngineStopReturnsUnplacedAssignments(t *testing.T) {
+	ctrl, engine, _, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+
+	assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	unplaced := []models.Task{assignment}
+	engine.unplaced.set(unplaced)
+
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), nil, unplaced).
+		Return(tasks.SetPlacementsResult{})
+
+	engine.Stop()
+	assert.Equal(t, _placementEngineStopped, assignment.GetPlacementFailure())
+	assert.Empty(t, engine.unplaced.take())
+
+	// The assignments are returned only once.
+	engine.Stop()
+}
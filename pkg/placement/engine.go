@@ -16,6 +16,8 @@ package placement
 
 import (
 	"context"
+	"math/rand"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +25,9 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
 	"github.com/uber/peloton/pkg/common/async"
 	"github.com/uber/peloton/pkg/placement/config"
 	"github.com/uber/peloton/pkg/placement/hosts"
@@ -47,6 +52,28 @@ const (
 type Engine interface {
 	Start()
 	Stop()
+
+	// Simulate returns the placements of the tasks on the offers, without
+	// setting the placements.
+	Simulate(
+		tasks []*resmgr.Task,
+		offers []*hostsvc.HostOffer) []*resmgr.Placement
+
+	// CancelPlacements cancels the pending placements of the tasks of the
+	// job with the constraint shape, or of all tasks of the job if the
+	// shape is empty, in the next placement round.
+	CancelPlacements(jobID string, shape string)
+}
+
+// Option configures optional behavior of a placement engine.
+type Option func(*engine)
+
+// WithDecisionSink makes the engine write the sampled placement decision
+// records to the sink, instead of logging them.
+func WithDecisionSink(sink DecisionSink) Option {
+	return func(e *engine) {
+		e.decisionLogger.sink = sink
+	}
 }
 
 // New creates a new placement engine having one dedicated coordinator per task type.
@@ -57,7 +84,8 @@ func New(
 	taskService tasks.Service,
 	hostsService hosts.Service,
 	strategy plugins.Strategy,
-	pool *async.Pool) Engine {
+	pool *async.Pool,
+	opts ...Option) Engine {
 	scope := tally_metrics.NewMetrics(
 		parent.SubScope(strings.ToLower(cfg.TaskType.String())))
 
@@ -68,7 +96,8 @@ func New(
 		strategy,
 		pool,
 		scope,
-		hostsService)
+		hostsService,
+		opts...)
 
 	return engine
 }
@@ -81,7 +110,8 @@ func NewEngine(
 	strategy plugins.Strategy,
 	pool *async.Pool,
 	scope *tally_metrics.Metrics,
-	hostsService hosts.Service) Engine {
+	hostsService hosts.Service,
+	opts ...Option) Engine {
 	result := &engine{
 		config:       config,
 		offerService: offerService,
@@ -89,6 +119,27 @@ func NewEngine(
 		strategy:     strategy,
 		pool:         pool,
 		metrics:      scope,
+		decisionLogger: newDecisionLogger(
+			config.DecisionLogSampleRate,
+			config.Strategy,
+			rand.NewSource(time.Now().UnixNano()),
+			logDecisionSink{}),
+		throttler:      newRespoolThrottler(config.RespoolRateLimits),
+		unplacedLogger: newUnplacedLogger(_unplacedLogInterval),
+		penaltyBox:     newHostPenaltyBox(config.FailedHostPenaltyWindow),
+		offerRetainer:  newOfferRetainer(config.OfferRetentionPeriod),
+		inFlight:       newInFlightPlacements(),
+		cancellations:  newPlacementCancellations(),
+		unplaced:       &unplacedAssignments{},
+		relaxer:        newConstraintRelaxer(config.SoftConstraintRelaxAfter),
+		firstPlacement: newFirstPlacementTimer(scope.TimeToFirstPlacement),
+		hostTaskCap:    newHostTaskCap(config.MaxTasksPerHostPerRound),
+	}
+	if source, ok := offerService.(offers.DrainingHostsSource); ok {
+		result.drainingHosts = source
+	}
+	for _, opt := range opts {
+		opt(result)
 	}
 	result.daemon = async.NewDaemon("Placement Engine", result)
 	result.reserver = reserver.NewReserver(scope, config, hostsService, taskService)
@@ -104,6 +155,19 @@ type engine struct {
 	strategy     plugins.Strategy
 	daemon       async.Daemon
 	reserver     reserver.Reserver
+
+	decisionLogger *decisionLogger
+	throttler      *respoolThrottler
+	unplacedLogger *unplacedLogger
+	penaltyBox     *hostPenaltyBox
+	offerRetainer  *offerRetainer
+	inFlight       *inFlightPlacements
+	cancellations  *placementCancellations
+	unplaced       *unplacedAssignments
+	drainingHosts  offers.DrainingHostsSource
+	relaxer        *constraintRelaxer
+	firstPlacement *firstPlacementTimer
+	hostTaskCap    *hostTaskCap
 }
 
 func (e *engine) Start() {
@@ -128,6 +192,9 @@ func (e *engine) Run(ctx context.Context) error {
 			if !timer.Stop() {
 				<-timer.C
 			}
+			// Keep the dequeued assignments which are not placed yet, so
+			// that they are returned to the resource manager on stop.
+			e.unplaced.set(unfulfilledAssignment)
 			return ctx.Err()
 		case <-timer.C:
 		}
@@ -138,8 +205,24 @@ func (e *engine) Run(ctx context.Context) error {
 	}
 }
 
+// Stop stops the engine. If a shutdown drain timeout is configured, it
+// waits at most the timeout for the in-flight placements to finish.
+// The retained offers are released to the host manager, and the dequeued
+// assignments which are not placed yet are returned to the
+// resource manager, so that they can be placed by the new leader.
 func (e *engine) Stop() {
-	e.daemon.Stop()
+	if e.config.ShutdownDrainTimeout > 0 {
+		stopped := make(chan struct{})
+		go func() {
+			e.daemon.Stop()
+			close(stopped)
+		}()
+		e.drain(stopped)
+	} else {
+		e.daemon.Stop()
+	}
+	e.releaseRetainedOffers(context.Background())
+	e.returnUnplacedAssignments()
 	e.reserver.Stop()
 	e.metrics.Running.Update(0)
 }
@@ -155,6 +238,8 @@ func (e *engine) Place(
 ) ([]models.Task, time.Duration) {
 	log.Debug("Beginning placement cycle")
 
+	e.releaseExpiredOffers(ctx)
+
 	// Try and get some tasks/assignments
 	dequeLimit := e.config.TaskDequeueLimit - len(lastRoundAssignment)
 	assignments := e.taskService.Dequeue(
@@ -166,6 +251,8 @@ func (e *engine) Place(
 	if len(assignments)+len(lastRoundAssignment) == 0 {
 		return nil, _noTasksTimeoutPenalty
 	}
+	e.firstPlacement.start(time.Now())
+	e.hostTaskCap.reset()
 
 	// process host reservation assignments
 	err := e.reserver.ProcessHostReservation(
@@ -180,6 +267,12 @@ func (e *engine) Place(
 	// them in this round
 	assignments = append(assignments, lastRoundAssignment...)
 
+	// return the assignments of the cancelled placement groups unplaced
+	assignments, cancelled := e.cancellations.take(assignments)
+	if len(cancelled) > 0 {
+		e.returnCancelledAssignments(ctx, cancelled)
+	}
+
 	// process revocable assignments
 	unfulfilledAssignment := e.processAssignments(
 		ctx,
@@ -218,11 +311,25 @@ func (e *engine) processAssignments(
 			assignments = append(assignments, assignment)
 		}
 	}
+
+	unfulfilledAssignment := &concurrencySafeAssignmentSlice{}
+
+	// defer the assignments of throttled resource pools to the next round
+	assignments, throttled := e.throttler.throttle(time.Now(), assignments)
+	if len(throttled) > 0 {
+		e.metrics.TasksThrottled.Inc(int64(len(throttled)))
+		unfulfilledAssignment.append(throttled...)
+	}
+
 	if len(assignments) == 0 {
-		return nil
+		return unfulfilledAssignment.get()
+	}
+
+	// share the offers of each group across the resource pools
+	if e.config.RespoolFairness {
+		assignments = interleaveByRespool(assignments)
 	}
 
-	unfulfilledAssignment := &concurrencySafeAssignmentSlice{}
 	tasks := models.ToPluginTasks(assignments)
 	tasksByNeeds := e.strategy.GroupTasksByPlacementNeeds(tasks)
 	for i := range tasksByNeeds {
@@ -233,6 +340,7 @@ func (e *engine) processAssignments(
 		}
 		e.pool.Enqueue(async.JobFunc(func(context.Context) {
 			unfulfilled := e.placeAssignmentGroup(ctx, group.PlacementNeeds, batch)
+			e.throttler.refund(unplacedAssignments(batch, unfulfilled))
 			unfulfilledAssignment.append(unfulfilled...)
 		}))
 	}
@@ -252,7 +360,27 @@ func (e *engine) processAssignments(
 func (e *engine) placeAssignmentGroup(
 	ctx context.Context,
 	needs plugins.PlacementNeeds,
-	assignments []models.Task) []models.Task {
+	assignments []models.Task) (unfulfilled []models.Task) {
+	id := e.inFlight.start()
+	defer e.inFlight.finish(id)
+
+	// Offers held by the current round of placing the assignment group,
+	// which need to be released if the placement panics.
+	var offers []models.Offer
+	defer func() {
+		if r := recover(); r != nil {
+			unfulfilled = e.recoverPlacementPanic(ctx, r, needs, assignments, offers)
+		}
+	}()
+
+	// A single task fits on a single host, so acquire only one offer sized
+	// to the task instead of as many offers as the strategy asks for.
+	if len(assignments) == 1 {
+		needs.MaxHosts = 1
+	}
+
+	// Number of times offers have been acquired for the group in this round.
+	acquires := 0
 	for len(assignments) > 0 {
 		log.WithFields(log.Fields{
 			"needs":           needs,
@@ -261,26 +389,41 @@ func (e *engine) placeAssignmentGroup(
 		}).Debug("placing assignment group")
 
 		// Get hosts with available resources and tasks currently running.
-		offers, reason := e.offerService.Acquire(
-			ctx,
-			e.config.FetchOfferTasks,
-			e.config.TaskType,
-			needs)
+		var reason string
+		var err error
+		offers, reason, err = e.acquireOffers(ctx, needs)
 
 		existing := e.findUsedHosts(assignments)
 		now := time.Now()
-		for !e.pastDeadline(now, assignments) && len(offers)+len(existing) == 0 {
+		for err == nil &&
+			!e.pastDeadline(now, assignments) &&
+			len(offers)+len(existing) == 0 {
 			time.Sleep(_noOffersTimeoutPenalty)
-			offers, reason = e.offerService.Acquire(
-				ctx,
-				e.config.FetchOfferTasks,
-				e.config.TaskType,
-				needs)
+			offers, reason, err = e.acquireOffers(ctx, needs)
 			now = time.Now()
 		}
 
+		// Acquiring offers failed with a permanent error, so there is no
+		// point in waiting for offers until the deadline.
+		if err != nil {
+			log.WithFields(log.Fields{
+				"needs":       needs,
+				"assignments": assignments,
+			}).WithError(err).Warn("failed to place tasks due to permanent offer error")
+			for _, assignment := range assignments {
+				assignment.SetPlacement(nil)
+			}
+			if len(existing) > 0 {
+				e.offerService.Release(ctx, existing)
+			}
+			e.reportUnplaced(needs, assignments, reason)
+			e.returnStarvedAssignments(ctx, assignments, reason)
+			return nil
+		}
+
 		// Add any offers still assigned to any task so the offers will eventually be returned or used in a placement.
 		offers = append(offers, existing...)
+		e.inFlight.hold(id, offers)
 
 		// We were starved for offers
 		if len(offers) == 0 {
@@ -288,11 +431,24 @@ func (e *engine) placeAssignmentGroup(
 				"needs":       needs,
 				"assignments": assignments,
 			}).Debug("failed to place tasks due to offer starvation")
+			e.relaxer.starved(needs, time.Now())
+			e.reportUnplaced(needs, assignments, reason)
 			e.returnStarvedAssignments(ctx, assignments, reason)
 			return nil
 		}
 
 		e.metrics.OfferGet.Inc(1)
+		e.relaxer.fed(needs)
+
+		// The order of the offers only matters if there is a choice between
+		// them.
+		if len(offers) > 1 {
+			// Offer the hosts satisfying the soft constraint first.
+			offers = preferSoftConstraint(needs, offers)
+
+			// Offer the desired hosts of the tasks before any other.
+			offers = preferDesiredHosts(assignments, offers)
+		}
 
 		tasks := []plugins.Task{}
 		for _, a := range assignments {
@@ -307,6 +463,7 @@ func (e *engine) placeAssignmentGroup(
 		// Delegate to the placement strategy to get the placements for these
 		// tasks onto these offers.
 		placements := e.strategy.GetTaskPlacements(tasks, hosts)
+		placements = e.capTasksPerHost(placements, offers)
 		for assignmentIdx, hostIdx := range placements {
 			if hostIdx != -1 {
 				assignments[assignmentIdx].SetPlacement(offers[hostIdx])
@@ -339,8 +496,8 @@ func (e *engine) placeAssignmentGroup(
 			}).Info("Unassigned tasks even when more hosts available")
 		}
 
-		// We will retry the retryable tasks
-		assignments = retryable
+		e.decisionLogger.log(assigned)
+		e.reportUnplaced(needs, unassigned, _failedToPlaceTaskAfterTimeout)
 
 		log.WithFields(log.Fields{
 			"needs":      needs,
@@ -351,7 +508,13 @@ func (e *engine) placeAssignmentGroup(
 		}).Debug("Finshed one round placing assignment group")
 
 		// Set placements and return unused offers and failed tasks
-		e.cleanup(ctx, assigned, retryable, unassigned, offers)
+		rejected := e.cleanup(ctx, needs, assigned, retryable, unassigned, offers)
+		e.inFlight.hold(id, nil)
+
+		// We will retry the retryable tasks, along with the tasks whose
+		// placements were rejected by the resource manager.
+		retryable = append(retryable, rejected...)
+		assignments = retryable
 
 		if len(retryable) != 0 && e.shouldPlaceRetryableInNextRun(retryable) {
 			log.WithFields(log.Fields{
@@ -359,11 +522,47 @@ func (e *engine) placeAssignmentGroup(
 			}).Info("tasks are retried in the next run of placement")
 			return retryable
 		}
+
+		acquires++
+		if len(retryable) != 0 && e.exceedsOfferAcquires(acquires) {
+			log.WithFields(log.Fields{
+				"needs":     needs,
+				"retryable": retryable,
+				"acquires":  acquires,
+			}).Debug("assignment group used up its offer acquires in this run")
+			return retryable
+		}
 	}
 
 	return nil
 }
 
+// recoverPlacementPanic handles a panic raised while placing an assignment
+// group. It releases the offers held by the group, and returns the
+// assignments to be placed again in the next round.
+func (e *engine) recoverPlacementPanic(
+	ctx context.Context,
+	r interface{},
+	needs plugins.PlacementNeeds,
+	assignments []models.Task,
+	offers []models.Offer) []models.Task {
+	e.metrics.PlacementPanic.Inc(1)
+	log.WithFields(log.Fields{
+		"needs":       needs,
+		"assignments": assignments,
+		"panic":       r,
+		"stack":       string(debug.Stack()),
+	}).Error("recovered from panic while placing assignment group")
+
+	for _, assignment := range assignments {
+		assignment.SetPlacement(nil)
+	}
+	if len(offers) > 0 {
+		e.offerService.Release(ctx, offers)
+	}
+	return assignments
+}
+
 // returns if the retryable assignments should be retried in the run.
 // Otherwise they would continue to be processed in the processAssignments loop.
 func (e *engine) shouldPlaceRetryableInNextRun(retryable []models.Task) bool {
@@ -402,6 +601,26 @@ func (e *engine) shouldPlaceRetryableInNextRun(retryable []models.Task) bool {
 	return false
 }
 
+// exceedsOfferAcquires returns if an assignment group which acquired
+// offers the given number of times has used up its share of offers in
+// the current run, and should let other groups be placed first.
+func (e *engine) exceedsOfferAcquires(acquires int) bool {
+	return e.config.MaxOfferAcquiresPerGroup > 0 &&
+		acquires >= e.config.MaxOfferAcquiresPerGroup
+}
+
+// capTasksPerHost unassigns the placements onto hosts which already got
+// MaxTasksPerHostPerRound tasks in the current round, so they are retried
+// on other offers or in the next round.
+func (e *engine) capTasksPerHost(
+	placements map[int]int,
+	offers []models.Offer) map[int]int {
+	if capped := e.hostTaskCap.apply(placements, offers); capped > 0 {
+		e.metrics.TasksCappedPerHost.Inc(int64(capped))
+	}
+	return placements
+}
+
 // returns the starved assignments back to the task service
 func (e *engine) returnStarvedAssignments(
 	ctx context.Context,
@@ -535,26 +754,104 @@ func (e *engine) findUnusedHosts(
 	return unusedOffers
 }
 
+// cleanup sets the placements in the resource manager and releases, or
+// retains for the next round, the offers which are neither used by the
+// accepted nor the retryable assignments. It returns the assignments whose
+// placement was rejected by the resource manager, or dropped because their
+// host started draining, which need to be placed again.
 func (e *engine) cleanup(
 	ctx context.Context,
+	needs plugins.PlacementNeeds,
 	assigned, retryable,
 	unassigned []models.Task,
-	offers []models.Offer) {
+	offers []models.Offer) []models.Task {
+
+	// Drop the placements on the hosts which started draining since the
+	// offers were acquired.
+	assigned, draining := e.dropDrainingPlacements(ctx, assigned)
 
 	// Create the resource manager placements.
-	e.taskService.SetPlacements(
+	result := e.taskService.SetPlacements(
 		ctx,
 		assigned,
 		unassigned,
 	)
 
+	// Only the placements accepted by the resource manager keep their offers.
+	accepted := assigned
+	if len(result.Rejected) > 0 {
+		rejectedIDs := map[string]struct{}{}
+		for _, task := range result.Rejected {
+			rejectedIDs[task.PelotonID()] = struct{}{}
+		}
+		accepted = nil
+		for _, task := range assigned {
+			if _, ok := rejectedIDs[task.PelotonID()]; !ok {
+				accepted = append(accepted, task)
+			}
+		}
+	}
+
+	if len(accepted) > 0 {
+		e.firstPlacement.placed(time.Now())
+	}
+
 	// Find the unused offers.
-	unusedOffers := e.findUnusedHosts(assigned, retryable, offers)
+	unusedOffers := e.findUnusedHosts(accepted, retryable, offers)
 
+	// The offers of the draining hosts are released like the offers of
+	// the rejected placements.
+	retry := append(result.Rejected, draining...)
 	if len(unusedOffers) > 0 {
-		// Release the unused offers.
-		e.offerService.Release(ctx, unusedOffers)
+		// Release or retain the unused offers.
+		e.retainOrReleaseOffers(ctx, needs, unusedOffers, retry)
+	}
+
+	// The offers of the rejected placements were released above, and
+	// their hosts are skipped for a while.
+	now := time.Now()
+	for _, task := range result.Rejected {
+		e.penaltyBox.penalize(task.GetPlacement().Hostname(), now)
+		task.SetPlacement(nil)
 	}
+	for _, task := range draining {
+		task.SetPlacement(nil)
+	}
+	return retry
+}
+
+// skipOffers releases the offers for which skip returns true, counting
+// them in the counter, and returns the other offers.
+func (e *engine) skipOffers(
+	ctx context.Context,
+	offers []models.Offer,
+	skip func(models.Offer) bool,
+	counter tally.Counter) []models.Offer {
+	var allowed, skipped []models.Offer
+	for _, offer := range offers {
+		if skip(offer) {
+			skipped = append(skipped, offer)
+			continue
+		}
+		allowed = append(allowed, offer)
+	}
+
+	if len(skipped) > 0 {
+		counter.Inc(int64(len(skipped)))
+		e.offerService.Release(ctx, skipped)
+	}
+	return allowed
+}
+
+// skipPenalizedHosts releases the offers of the hosts on which placements
+// failed recently, and returns the other offers.
+func (e *engine) skipPenalizedHosts(
+	ctx context.Context,
+	offers []models.Offer) []models.Offer {
+	now := time.Now()
+	return e.skipOffers(ctx, offers, func(offer models.Offer) bool {
+		return e.penaltyBox.penalized(offer.Hostname(), now)
+	}, e.metrics.OfferPenalized)
 }
 
 func (e *engine) pastDeadline(now time.Time, assignments []models.Task) bool {
@@ -16,6 +16,7 @@ package placement
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -30,6 +31,7 @@ import (
 	"github.com/uber/peloton/pkg/placement/plugins/mimir"
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
 	"github.com/uber/peloton/pkg/placement/plugins/mocks"
+	"github.com/uber/peloton/pkg/placement/tasks"
 	tasks_mock "github.com/uber/peloton/pkg/placement/tasks/mocks"
 	"github.com/uber/peloton/pkg/placement/testutil"
 
@@ -141,7 +143,7 @@ func TestEngineTaskAffinityConstraintFailure(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return().
+		Return(tasks.SetPlacementsResult{}).
 		AnyTimes()
 
 	mockOfferService.EXPECT().
@@ -151,7 +153,7 @@ func TestEngineTaskAffinityConstraintFailure(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return(offers, _testReason)
+		Return(offers, _testReason, nil)
 
 	mockOfferService.EXPECT().Release(
 		gomock.Any(),
@@ -171,6 +173,50 @@ func TestEngineTaskAffinityConstraintFailure(t *testing.T) {
 	assert.Equal(t, int64(1), scope.Snapshot().Counters()["batch.placement.host_limit+result=fail"].Value())
 }
 
+// Tests that a panic while placing an assignment group is recovered, the
+// offers are released and the assignments are returned for the next round.
+func TestEnginePlaceAssignmentGroupRecoversPanic(t *testing.T) {
+	ctrl, engine, mockOfferService, _, mockStrategy, scope := setupEngine(t)
+	defer ctrl.Finish()
+
+	host := testutil.SetupHostOffers()
+	offers := []models.Offer{host}
+	assignment := testutil.SetupAssignment(time.Now().Add(1*time.Second), 1)
+	assignments := []models.Task{assignment}
+
+	mockOfferService.EXPECT().
+		Acquire(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Return(offers, _testReason, nil)
+
+	mockStrategy.EXPECT().
+		GetTaskPlacements(
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Do(func(_ []plugins.Task, _ []plugins.Host) {
+			assignment.SetPlacement(host)
+			panic("malformed offer")
+		})
+
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), offers).
+		Return()
+
+	needs := plugins.PlacementNeeds{}
+	unfulfilled := engine.placeAssignmentGroup(context.Background(), needs, assignments)
+	assert.Equal(t, assignments, unfulfilled)
+	assert.Nil(t, assignment.GetPlacement())
+	assert.Equal(
+		t,
+		int64(1),
+		scope.Snapshot().Counters()["batch.placement.panic+result=fail"].Value())
+}
+
 func TestEnginePlaceNoTasksToPlace(t *testing.T) {
 	ctrl, engine, _, mockTaskService, _, _ := setupEngine(t)
 	defer ctrl.Finish()
@@ -216,7 +262,7 @@ func TestEnginePlaceMultipleTasks(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any(),
-	).Return(hosts, _testReason).MinTimes(1)
+	).Return(hosts, _testReason, nil).MinTimes(1)
 	mockOfferService.EXPECT().Release(
 		gomock.Any(),
 		gomock.Any()).
@@ -234,7 +280,7 @@ func TestEnginePlaceMultipleTasks(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any()).
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	engine.strategy = batch.New(&config.PlacementConfig{})
 	engine.Place(context.Background(), nil)
@@ -296,7 +342,7 @@ func TestEnginePlaceInPlaceUpdateTasks(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any(),
-	).Return(hosts, _testReason).MinTimes(1)
+	).Return(hosts, _testReason, nil).MinTimes(1)
 
 	mockTaskService.EXPECT().
 		Dequeue(
@@ -310,7 +356,7 @@ func TestEnginePlaceInPlaceUpdateTasks(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any()).
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	engine.config.Concurrency = 1
 	placer := algorithms.NewPlacer(4, 300)
@@ -345,13 +391,13 @@ func TestEnginePlaceSubsetOfTasksDueToInsufficientResources(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 			gomock.Any(),
-		).Return(hosts, _testReason).Times(1),
+		).Return(hosts, _testReason, nil).Times(1),
 		mockOfferService.EXPECT().Acquire(
 			gomock.Any(),
 			gomock.Any(),
 			gomock.Any(),
 			gomock.Any(),
-		).Return(nil, _testReason).AnyTimes(),
+		).Return(nil, _testReason, nil).AnyTimes(),
 	)
 
 	mockTaskService.EXPECT().
@@ -366,7 +412,7 @@ func TestEnginePlaceSubsetOfTasksDueToInsufficientResources(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any()).
-		Return().AnyTimes()
+		Return(tasks.SetPlacementsResult{}).AnyTimes()
 
 	engine.strategy = batch.New(&config.PlacementConfig{})
 	engine.Place(context.Background(), nil)
@@ -399,7 +445,7 @@ func TestEnginePlaceNoHostsMakesTaskExceedDeadline(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return(nil, _testReason)
+		Return(nil, _testReason, nil)
 
 	mockTaskService.EXPECT().
 		SetPlacements(
@@ -407,12 +453,118 @@ func TestEnginePlaceNoHostsMakesTaskExceedDeadline(t *testing.T) {
 			nil,
 			gomock.Any(),
 		).Times(1).
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	needs := plugins.PlacementNeeds{}
 	engine.placeAssignmentGroup(context.Background(), needs, assignments)
 }
 
+// Test tasks are returned right away, without waiting for offers until
+// the deadline, when acquiring offers fails with a permanent error.
+func TestEnginePlacePermanentOfferError(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.config.MaxPlacementDuration = time.Minute
+	assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	assignments := []models.Task{assignment}
+
+	mockOfferService.EXPECT().
+		Acquire(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+		).Times(1).
+		Return(nil, _testReason, errors.New("invalid host filter"))
+
+	mockTaskService.EXPECT().
+		SetPlacements(
+			gomock.Any(),
+			nil,
+			assignments,
+		).Times(1).
+		Return(tasks.SetPlacementsResult{})
+
+	needs := plugins.PlacementNeeds{}
+	unfulfilled := engine.placeAssignmentGroup(
+		context.Background(), needs, assignments)
+	assert.Empty(t, unfulfilled)
+	assert.Equal(t, _testReason, assignment.GetPlacementFailure())
+}
+
+// Test a small assignment group acquires offers in the same round as a
+// large one, instead of waiting for the large group to be placed.
+func TestEnginePlaceMaxOfferAcquiresPerGroup(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, mockStrategy, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.config.MaxOfferAcquiresPerGroup = 1
+	engine.pool = async.NewPool(async.PoolOptions{MaxWorkers: 1}, nil)
+	engine.pool.Start()
+
+	deadline := time.Now().Add(time.Minute)
+	var assignments []models.Task
+	for i := 0; i < 5; i++ {
+		assignments = append(assignments, testutil.SetupAssignment(deadline, 100))
+	}
+	small := testutil.SetupAssignment(deadline, 1)
+	assignments = append(assignments, small)
+
+	largeNeeds := plugins.PlacementNeeds{MaxHosts: 5}
+	smallNeeds := plugins.PlacementNeeds{MaxHosts: 1}
+	mockStrategy.EXPECT().
+		GroupTasksByPlacementNeeds(gomock.Any()).
+		Return([]*plugins.TasksByPlacementNeeds{
+			{PlacementNeeds: largeNeeds, Tasks: []int{0, 1, 2, 3, 4}},
+			{PlacementNeeds: smallNeeds, Tasks: []int{5}},
+		})
+	mockStrategy.EXPECT().
+		ConcurrencySafe().
+		AnyTimes().
+		Return(false)
+
+	// Place every task on the first host, the tasks of the large group
+	// would then keep looking for a better host for many rounds.
+	mockStrategy.EXPECT().
+		GetTaskPlacements(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(toPlace []plugins.Task, _ []plugins.Host) map[int]int {
+			placements := map[int]int{}
+			for i := range toPlace {
+				placements[i] = 0
+			}
+			return placements
+		})
+
+	var acquired []plugins.PlacementNeeds
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(
+			_ context.Context,
+			_ bool,
+			_ resmgr.TaskType,
+			needs plugins.PlacementNeeds) ([]models.Offer, string, error) {
+			acquired = append(acquired, needs)
+			return []models.Offer{testutil.SetupHostOffers()}, _testReason, nil
+		})
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), gomock.Any()).
+		AnyTimes()
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(tasks.SetPlacementsResult{})
+
+	unfulfilled := engine.processAssignments(
+		context.Background(),
+		assignments,
+		func(models.Task) bool { return true })
+
+	assert.Equal(t, []plugins.PlacementNeeds{largeNeeds, smallNeeds}, acquired)
+	assert.Equal(t, 5, len(unfulfilled))
+	assert.NotContains(t, unfulfilled, small)
+}
+
 func TestEnginePlaceTaskExceedMaxRoundsAndGetsPlaced(t *testing.T) {
 	ctrl, engine, mockOfferService, mockTaskService, mockStrategy, _ := setupEngine(t)
 	defer ctrl.Finish()
@@ -444,7 +596,7 @@ func TestEnginePlaceTaskExceedMaxRoundsAndGetsPlaced(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	mockOfferService.EXPECT().
 		Acquire(
@@ -453,7 +605,7 @@ func TestEnginePlaceTaskExceedMaxRoundsAndGetsPlaced(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return(offers, _testReason)
+		Return(offers, _testReason, nil)
 
 	needs := plugins.PlacementNeeds{}
 	engine.placeAssignmentGroup(context.Background(), needs, assignments)
@@ -485,7 +637,7 @@ func TestEnginePlaceTaskExceedMaxPlacementDeadlineGetsPlaced(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	mockOfferService.EXPECT().
 		Acquire(
@@ -494,7 +646,7 @@ func TestEnginePlaceTaskExceedMaxPlacementDeadlineGetsPlaced(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).MinTimes(1).
-		Return(offers, _testReason)
+		Return(offers, _testReason, nil)
 
 	needs := plugins.PlacementNeeds{}
 	engine.placeAssignmentGroup(context.Background(), needs, assignments)
@@ -535,6 +687,7 @@ func TestEnginePlaceCallToStrategy(t *testing.T) {
 		Return(
 			hosts,
 			_testReason,
+			nil,
 		)
 
 	mockStrategy.EXPECT().
@@ -569,7 +722,7 @@ func TestEnginePlaceCallToStrategy(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).AnyTimes().
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	mockTaskService.EXPECT().
 		SetPlacements(
@@ -577,7 +730,7 @@ func TestEnginePlaceCallToStrategy(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).AnyTimes().
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	mockOfferService.EXPECT().
 		Release(
@@ -619,7 +772,7 @@ func TestEnginePlaceReservedTasks(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any(),
-	).Return(hosts, _testReason).MinTimes(1)
+	).Return(hosts, _testReason, nil).MinTimes(1)
 	mockOfferService.EXPECT().Release(
 		gomock.Any(),
 		gomock.Any()).
@@ -637,7 +790,7 @@ func TestEnginePlaceReservedTasks(t *testing.T) {
 		gomock.Any(),
 		gomock.Any(),
 		gomock.Any()).
-		Return()
+		Return(tasks.SetPlacementsResult{})
 
 	// Test assignments ready for host reservation
 	engine.strategy = batch.New(&config.PlacementConfig{})
@@ -660,6 +813,150 @@ func TestEnginePlaceReservedTasks(t *testing.T) {
 	assert.Equal(t, 0, failed)
 }
 
+// Tests that a group of a single task acquires a single offer sized to the
+// task and is placed on it.
+func TestEnginePlaceSingleTaskGroup(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+
+	engine.strategy = batch.New(&config.PlacementConfig{})
+	host := testutil.SetupHostOffers()
+	assignment := testutil.SetupAssignment(time.Now().Add(time.Second), 1)
+	assignments := []models.Task{assignment}
+
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(
+			_ context.Context,
+			_ bool,
+			_ resmgr.TaskType,
+			needs plugins.PlacementNeeds) ([]models.Offer, string, error) {
+			assert.Equal(t, uint32(1), needs.MaxHosts)
+			return []models.Offer{host}, _testReason, nil
+		})
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), gomock.Any()).
+		AnyTimes()
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(tasks.SetPlacementsResult{})
+
+	needs := plugins.PlacementNeeds{MaxHosts: 5}
+	unfulfilled := engine.placeAssignmentGroup(
+		context.Background(), needs, assignments)
+	assert.Empty(t, unfulfilled)
+	assert.Equal(t, host, assignment.GetPlacement())
+}
+
+// Tests that no more than MaxTasksPerHostPerRound tasks of a group are
+// placed on a host in a round, even if the host has capacity for more.
+func TestEnginePlaceMaxTasksPerHostPerRound(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, mockStrategy, scope := setupEngine(t)
+	defer ctrl.Finish()
+	engine.hostTaskCap = newHostTaskCap(3)
+	engine.config.MaxOfferAcquiresPerGroup = 1
+
+	host := testutil.SetupHostOffers()
+	deadline := time.Now().Add(time.Minute)
+	var assignments []models.Task
+	for i := 0; i < 10; i++ {
+		assignments = append(assignments, testutil.SetupAssignment(deadline, 1))
+	}
+
+	// The host fits all the tasks.
+	mockStrategy.EXPECT().
+		GetTaskPlacements(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(toPlace []plugins.Task, _ []plugins.Host) map[int]int {
+			placements := map[int]int{}
+			for i := range toPlace {
+				placements[i] = 0
+			}
+			return placements
+		})
+	mockStrategy.EXPECT().
+		ConcurrencySafe().
+		AnyTimes().
+		Return(true)
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]models.Offer{host}, _testReason, nil)
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), gomock.Any()).
+		AnyTimes()
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(tasks.SetPlacementsResult{})
+
+	unfulfilled := engine.placeAssignmentGroup(
+		context.Background(), plugins.PlacementNeeds{}, assignments)
+
+	placed := 0
+	for _, assignment := range assignments {
+		if assignment.GetPlacement() != nil {
+			assert.Equal(t, host, assignment.GetPlacement())
+			placed++
+		}
+	}
+	assert.Equal(t, 3, placed)
+	assert.Len(t, unfulfilled, 7)
+	assert.Equal(t, int64(7), scope.Snapshot().Counters()["batch.placement.host_task_cap+result=fail"].Value())
+}
+
+func TestEnginePlaceTimeToFirstPlacement(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, scope := setupEngine(t)
+	defer ctrl.Finish()
+	delay := 50 * time.Millisecond
+
+	deadline := time.Now().Add(time.Minute)
+	assignments := []models.Task{testutil.SetupAssignment(deadline, 1)}
+
+	// The offers are acquired after the delay, which delays the first
+	// placement of the round.
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			context.Context,
+			bool,
+			resmgr.TaskType,
+			plugins.PlacementNeeds) ([]models.Offer, string, error) {
+			time.Sleep(delay)
+			return []models.Offer{testutil.SetupHostOffers()}, _testReason, nil
+		})
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), gomock.Any()).
+		AnyTimes()
+	mockTaskService.EXPECT().
+		Dequeue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(assignments)
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tasks.SetPlacementsResult{})
+
+	engine.strategy = batch.New(&config.PlacementConfig{})
+	engine.Place(context.Background(), nil)
+	engine.pool.WaitUntilProcessed()
+	assert.NotNil(t, assignments[0].GetPlacement())
+
+	histogram := scope.Snapshot().
+		Histograms()["batch.placement.time_to_first_placement+type=timer"]
+	assert.NotNil(t, histogram)
+	var observed int64
+	for upper, count := range histogram.Durations() {
+		if count == 0 {
+			continue
+		}
+		observed += count
+		// The bucket of the observed value covers the delay.
+		assert.True(t, upper >= delay)
+		assert.True(t, upper <= 4*delay)
+	}
+	assert.Equal(t, int64(1), observed)
+}
+
 func TestEngineFindUsedOffers(t *testing.T) {
 	ctrl, engine, _, _, _, _ := setupEngine(t)
 	defer ctrl.Finish()
@@ -741,9 +1038,61 @@ func TestEngineCleanup(t *testing.T) {
 			gomock.Any(),
 			gomock.Any(),
 		).
-		Return()
+		Return(tasks.SetPlacementsResult{})
+
+	engine.cleanup(
+		context.Background(),
+		plugins.PlacementNeeds{},
+		assignments,
+		nil,
+		assignments,
+		hosts)
+}
+
+// TestEngineCleanupRejectedPlacements tests that only the offers of the
+// placements rejected by the resource manager are released, and only the
+// rejected assignments are returned to be placed again.
+func TestEngineCleanupRejectedPlacements(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.penaltyBox = newHostPenaltyBox(time.Minute)
+
+	deadline := time.Now().Add(30 * time.Second)
+	host1 := testutil.SetupHostOffers()
+	host2 := testutil.SetupHostOffers()
+	host2.GetOffer().Hostname = "rejected-host"
+	assignment1 := testutil.SetupAssignment(deadline, 1)
+	assignment1.SetPlacement(host1)
+	assignment2 := testutil.SetupAssignment(deadline, 1)
+	assignment2.SetPlacement(host2)
+	assigned := []models.Task{assignment1, assignment2}
+
+	mockTaskService.EXPECT().
+		SetPlacements(
+			gomock.Any(),
+			assigned,
+			gomock.Any(),
+		).
+		Return(tasks.SetPlacementsResult{
+			Rejected: []models.Task{assignment2},
+		})
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), []models.Offer{host2})
 
-	engine.cleanup(context.Background(), assignments, nil, assignments, hosts)
+	rejected := engine.cleanup(
+		context.Background(),
+		plugins.PlacementNeeds{},
+		assigned,
+		nil,
+		nil,
+		[]models.Offer{host1, host2})
+	assert.Equal(t, []models.Task{assignment2}, rejected)
+	assert.Nil(t, assignment2.GetPlacement())
+	assert.Equal(t, host1, assignment1.GetPlacement())
+
+	// The host of the rejected placement is penalized.
+	assert.False(t, engine.penaltyBox.penalized(host1.Hostname(), time.Now()))
+	assert.True(t, engine.penaltyBox.penalized(host2.Hostname(), time.Now()))
 }
 
 func TestEngineFindUnusedOffers(t *testing.T) {
@@ -781,3 +1130,107 @@ func TestEngineFindUnusedOffers(t *testing.T) {
 	assert.Equal(t, 1, len(unused))
 	assert.Equal(t, host2, unused[0])
 }
+
+// TestEngineSkipsOffers tests that the acquired offers which can not be
+// used for the placement needs are released and counted, and that the
+// other offers are returned.
+func TestEngineSkipsOffers(t *testing.T) {
+	tt := []struct {
+		name      string
+		needs     plugins.PlacementNeeds
+		penalized []string
+		allowed   []int
+		released  []int
+		counter   string
+	}{
+		{
+			name:    "no skipped offers",
+			allowed: []int{0, 1, 2},
+		},
+		{
+			name:     "host pool",
+			needs:    plugins.PlacementNeeds{HostPool: "gpu"},
+			allowed:  []int{0},
+			released: []int{1, 2},
+			counter:  "batch.offer.out_of_host_pool+",
+		},
+		{
+			name: "allowlist",
+			needs: plugins.PlacementNeeds{
+				AllowedHosts: []string{"host1", "host4"},
+			},
+			allowed:  []int{0},
+			released: []int{1, 2},
+			counter:  "batch.offer.host_not_allowed+",
+		},
+		{
+			name: "denylist",
+			needs: plugins.PlacementNeeds{
+				DeniedHosts: []string{"host2"},
+			},
+			allowed:  []int{0, 2},
+			released: []int{1},
+			counter:  "batch.offer.host_not_allowed+",
+		},
+		{
+			name: "anti-affinity",
+			needs: plugins.PlacementNeeds{
+				AntiAffinityJobLabels: map[string]string{"service": "cache"},
+			},
+			allowed:  []int{1, 2},
+			released: []int{0},
+			counter:  "batch.offer.anti_affinity+",
+		},
+		{
+			name:      "penalized host",
+			penalized: []string{"host3"},
+			allowed:   []int{0, 1},
+			released:  []int{2},
+			counter:   "batch.offer.penalized+",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl, engine, mockOfferService, _, _, scope := setupEngine(t)
+			defer ctrl.Finish()
+			engine.penaltyBox = newHostPenaltyBox(time.Minute)
+			for _, hostname := range test.penalized {
+				engine.penaltyBox.penalize(hostname, time.Now())
+			}
+
+			host1 := setupPoolHostOffers("host1", "gpu")
+			host1.Tasks = []*resmgr.Task{setupLabeledTask("service", "cache")}
+			acquired := []models.Offer{
+				host1,
+				setupPoolHostOffers("host2", "shared"),
+				setupNamedHostOffers("host3"),
+			}
+			var allowed, released []models.Offer
+			for _, i := range test.allowed {
+				allowed = append(allowed, acquired[i])
+			}
+			for _, i := range test.released {
+				released = append(released, acquired[i])
+			}
+
+			mockOfferService.EXPECT().
+				Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(acquired, _testReason, nil)
+			if len(released) > 0 {
+				mockOfferService.EXPECT().
+					Release(gomock.Any(), released)
+			}
+
+			offers, _, err := engine.acquireOffers(
+				context.Background(), test.needs)
+			assert.NoError(t, err)
+			assert.Equal(t, allowed, offers)
+			if test.counter != "" {
+				assert.Equal(t,
+					int64(len(released)),
+					scope.Snapshot().Counters()[test.counter].Value())
+			}
+		})
+	}
+}
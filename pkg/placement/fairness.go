@@ -0,0 +1,49 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// interleaveByRespool reorders the assignments so that the assignments of
+// the different resource pools take turns, while the assignments of each
+// pool keep their order. The strategies place the tasks of a placement
+// group in order, so the offers acquired for the group are shared across
+// the resource pools instead of taken by the pool dequeued first.
+func interleaveByRespool(assignments []models.Task) []models.Task {
+	var respools []string
+	byRespool := make(map[string][]models.Task)
+	for _, assignment := range assignments {
+		respoolID := assignment.GetResmgrTaskV0().GetRespoolID().GetValue()
+		if _, ok := byRespool[respoolID]; !ok {
+			respools = append(respools, respoolID)
+		}
+		byRespool[respoolID] = append(byRespool[respoolID], assignment)
+	}
+	if len(respools) < 2 {
+		return assignments
+	}
+
+	interleaved := make([]models.Task, 0, len(assignments))
+	for i := 0; len(interleaved) < len(assignments); i++ {
+		for _, respoolID := range respools {
+			if tasks := byRespool[respoolID]; i < len(tasks) {
+				interleaved = append(interleaved, tasks[i])
+			}
+		}
+	}
+	return interleaved
+}
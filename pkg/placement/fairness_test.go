@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins/batch"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInterleaveByRespool tests that the assignments of the resource pools
+// take turns, keeping their order within each pool.
+func TestInterleaveByRespool(t *testing.T) {
+	a := setupRespoolAssignments("respool-a", 3)
+	b := setupRespoolAssignments("respool-b", 1)
+	c := setupRespoolAssignments("respool-c", 2)
+
+	assignments := append(append(append([]models.Task{}, a...), b...), c...)
+	assert.Equal(t,
+		[]models.Task{a[0], b[0], c[0], a[1], c[1], a[2]},
+		interleaveByRespool(assignments))
+
+	// the assignments of a single pool are left as they are
+	assert.Equal(t, a, interleaveByRespool(a))
+}
+
+// TestEnginePlaceRespoolFairness tests that the offers of a round are
+// shared across the resource pools when fairness is enabled, rather than
+// taken by the pool whose tasks were dequeued first.
+func TestEnginePlaceRespoolFairness(t *testing.T) {
+	for _, fairness := range []bool{false, true} {
+		ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+		engine.config.RespoolFairness = fairness
+		engine.config.MaxTasksPerHostPerRound = 4
+		engine.config.MaxOfferAcquiresPerGroup = 1
+		engine.strategy = batch.New(engine.config)
+
+		deadline := time.Now().Add(time.Minute)
+		var assignments []models.Task
+		for _, respoolID := range []string{"respool-a", "respool-b"} {
+			for i := 0; i < 6; i++ {
+				assignment := testutil.SetupAssignment(deadline, 1)
+				task := assignment.GetTask().GetTask()
+				task.RespoolID = &peloton.ResourcePoolID{Value: respoolID}
+				task.Resource.CpuLimit = 1
+				task.Resource.GpuLimit = 1
+				task.NumPorts = 0
+				assignments = append(assignments, assignment)
+			}
+		}
+
+		// A single host is acquired, which takes 4 of the tasks.
+		mockTaskService.EXPECT().
+			Dequeue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(assignments)
+		mockOfferService.EXPECT().
+			Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]models.Offer{testutil.SetupHostOffers()}, _testReason, nil)
+		mockOfferService.EXPECT().
+			Release(gomock.Any(), gomock.Any()).
+			AnyTimes()
+		mockTaskService.EXPECT().
+			SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(tasks.SetPlacementsResult{})
+
+		engine.Place(context.Background(), nil)
+		engine.pool.WaitUntilProcessed()
+
+		placed := map[string]int{}
+		for _, assignment := range assignments {
+			if assignment.GetPlacement() != nil {
+				respoolID := assignment.GetResmgrTaskV0().GetRespoolID().GetValue()
+				placed[respoolID]++
+			}
+		}
+		if fairness {
+			assert.Equal(t, map[string]int{"respool-a": 2, "respool-b": 2}, placed)
+		} else {
+			assert.Equal(t, map[string]int{"respool-a": 4}, placed)
+		}
+		ctrl.Finish()
+	}
+}
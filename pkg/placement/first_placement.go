@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+// firstPlacementTimer measures the time from the start of a placement
+// round to the first placement of the round accepted by the resource
+// manager.
+type firstPlacementTimer struct {
+	sync.Mutex
+
+	histogram tally.Histogram
+	// roundStart is the start time of the current placement round.
+	roundStart time.Time
+	// recorded indicates if the first placement of the current round
+	// has already been recorded.
+	recorded bool
+}
+
+// newFirstPlacementTimer creates a firstPlacementTimer which records the
+// time to the first placement into the given histogram.
+func newFirstPlacementTimer(histogram tally.Histogram) *firstPlacementTimer {
+	return &firstPlacementTimer{
+		histogram: histogram,
+		recorded:  true,
+	}
+}
+
+// start starts a new placement round.
+func (t *firstPlacementTimer) start(now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	t.roundStart = now
+	t.recorded = false
+}
+
+// placed records the time to the first placement of the current round,
+// later placements of the round are ignored.
+func (t *firstPlacementTimer) placed(now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	if t.recorded {
+		return
+	}
+	t.recorded = true
+	t.histogram.RecordDuration(now.Sub(t.roundStart))
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+// TestFirstPlacementTimer tests that only the first placement of each
+// placement round is recorded.
+func TestFirstPlacementTimer(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	timer := newFirstPlacementTimer(scope.Histogram(
+		"first", tally.MustMakeLinearDurationBuckets(0, time.Second, 10)))
+	now := time.Now()
+
+	// Placements before the first round are not recorded.
+	timer.placed(now)
+
+	timer.start(now)
+	timer.placed(now.Add(2500 * time.Millisecond))
+	timer.placed(now.Add(5500 * time.Millisecond))
+
+	timer.start(now)
+	timer.placed(now.Add(500 * time.Millisecond))
+
+	durations := scope.Snapshot().Histograms()["first+"].Durations()
+	assert.Equal(t, int64(1), durations[3*time.Second])
+	assert.Equal(t, int64(0), durations[6*time.Second])
+	assert.Equal(t, int64(1), durations[time.Second])
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// hostTaskCap caps the number of tasks placed on a single host in a
+// placement round. The counts are shared by all the assignment groups
+// placed in the round, and cover the hosts acquired again within it.
+type hostTaskCap struct {
+	sync.Mutex
+
+	max int
+	// placed is the number of tasks placed on each host in the round.
+	placed map[string]int
+}
+
+// newHostTaskCap creates a hostTaskCap which places at most max tasks on
+// a host per round, a max of 0 means there is no limit.
+func newHostTaskCap(max int) *hostTaskCap {
+	return &hostTaskCap{
+		max:    max,
+		placed: make(map[string]int),
+	}
+}
+
+// reset starts a new placement round.
+func (c *hostTaskCap) reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.placed = make(map[string]int)
+}
+
+// apply unassigns the placements onto the hosts which already got the
+// maximal number of tasks in the round, and counts the kept placements.
+// It returns the number of unassigned placements.
+func (c *hostTaskCap) apply(
+	placements map[int]int,
+	offers []models.Offer) int {
+	if c.max <= 0 {
+		return 0
+	}
+
+	// Keep the placements of the tasks in their order, so the same tasks
+	// are capped regardless of the map iteration order.
+	assignmentIdxs := make([]int, 0, len(placements))
+	for assignmentIdx := range placements {
+		assignmentIdxs = append(assignmentIdxs, assignmentIdx)
+	}
+	sort.Ints(assignmentIdxs)
+
+	c.Lock()
+	defer c.Unlock()
+	capped := 0
+	for _, assignmentIdx := range assignmentIdxs {
+		hostIdx := placements[assignmentIdx]
+		if hostIdx == -1 {
+			continue
+		}
+		hostname := offers[hostIdx].Hostname()
+		if c.placed[hostname] >= c.max {
+			placements[assignmentIdx] = -1
+			capped++
+			continue
+		}
+		c.placed[hostname]++
+	}
+	return capped
+}
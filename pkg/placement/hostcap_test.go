@@ -0,0 +1,64 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHostTaskCap tests that the tasks placed on a host are counted across
+// the assignment groups of a round, including the host acquired again
+// under another offer, until the next round starts.
+func TestHostTaskCap(t *testing.T) {
+	hostCap := newHostTaskCap(3)
+
+	first := testutil.SetupHostOffers()
+	again := testutil.SetupHostOffers()
+	again.GetOffer().Hostname = first.Hostname()
+	other := testutil.SetupHostOffers()
+	other.GetOffer().Hostname = "other-host"
+
+	// The first group places 2 tasks on the host.
+	placements := map[int]int{0: 0, 1: 0}
+	assert.Equal(t, 0, hostCap.apply(placements, []models.Offer{first}))
+	assert.Equal(t, map[int]int{0: 0, 1: 0}, placements)
+
+	// The second group gets the host again, and only 1 more task fits.
+	placements = map[int]int{0: 0, 1: 0, 2: 1, 3: -1}
+	assert.Equal(t, 1, hostCap.apply(placements, []models.Offer{again, other}))
+	assert.Equal(t, map[int]int{0: 0, 1: -1, 2: 1, 3: -1}, placements)
+
+	// The host is not capped in the next round.
+	hostCap.reset()
+	placements = map[int]int{0: 0, 1: 0}
+	assert.Equal(t, 0, hostCap.apply(placements, []models.Offer{first}))
+	assert.Equal(t, map[int]int{0: 0, 1: 0}, placements)
+}
+
+// TestHostTaskCapDisabled tests that no placement is capped when the cap
+// is 0.
+func TestHostTaskCapDisabled(t *testing.T) {
+	hostCap := newHostTaskCap(0)
+	offer := testutil.SetupHostOffers()
+
+	placements := map[int]int{0: 0, 1: 0, 2: 0}
+	assert.Equal(t, 0, hostCap.apply(placements, []models.Offer{offer}))
+	assert.Equal(t, map[int]int{0: 0, 1: 0, 2: 0}, placements)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+)
+
+// hostAllowed returns true if the host is in the allowlist, or the
+// allowlist is empty, and the host is not in the denylist.
+func hostAllowed(hostname string, allowed, denied []string) bool {
+	for _, host := range denied {
+		if host == hostname {
+			return false
+		}
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, host := range allowed {
+		if host == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+// skipDisallowedHosts releases the offers of the hosts which are not in
+// the host allowlist, or are in the host denylist, of the placement needs,
+// and returns the other offers.
+func (e *engine) skipDisallowedHosts(
+	ctx context.Context,
+	needs plugins.PlacementNeeds,
+	offers []models.Offer) []models.Offer {
+	if len(needs.AllowedHosts) == 0 && len(needs.DeniedHosts) == 0 {
+		return offers
+	}
+	return e.skipOffers(ctx, offers, func(offer models.Offer) bool {
+		return !hostAllowed(
+			offer.Hostname(), needs.AllowedHosts, needs.DeniedHosts)
+	}, e.metrics.OfferHostNotAllowed)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/placement/models/v0"
+	"github.com/uber/peloton/pkg/placement/testutil/v0"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupNamedHostOffers creates a host offer of the host.
+func setupNamedHostOffers(hostname string) *models_v0.HostOffers {
+	hostOffer := v0_testutil.SetupHostOffer()
+	hostOffer.Hostname = hostname
+	return models_v0.NewHostOffers(hostOffer, []*resmgr.Task{}, time.Now())
+}
+
+// TestHostAllowed tests that a host is allowed only if it is in the
+// allowlist, or the allowlist is empty, and it is not in the denylist.
+func TestHostAllowed(t *testing.T) {
+	assert.True(t, hostAllowed("host1", nil, nil))
+	assert.True(t, hostAllowed("host1", []string{"host1"}, nil))
+	assert.False(t, hostAllowed("host2", []string{"host1"}, nil))
+	assert.False(t, hostAllowed("host1", nil, []string{"host1"}))
+	assert.True(t, hostAllowed("host2", nil, []string{"host1"}))
+	assert.False(t, hostAllowed("host1", []string{"host1"}, []string{"host1"}))
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
+)
+
+// inHostPool returns true if the host of the offer belongs to the host
+// pool, that is if it carries the host pool label of the pool.
+func inHostPool(offer models.Offer, pool string) bool {
+	group := offer.ToMimirGroup()
+	if group == nil || group.Labels == nil {
+		return false
+	}
+	return group.Labels.Count(labels.NewLabel(common.HostPoolKey, pool)) > 0
+}
+
+// skipOutOfPoolHosts releases the offers of the hosts outside the host
+// pool required by the placement needs, and returns the other offers.
+// The host manager only returns offers of the host pool, this guards
+// against placing tasks outside of their dedicated pool regardless.
+func (e *engine) skipOutOfPoolHosts(
+	ctx context.Context,
+	needs plugins.PlacementNeeds,
+	offers []models.Offer) []models.Offer {
+	if needs.HostPool == "" {
+		return offers
+	}
+	return e.skipOffers(ctx, offers, func(offer models.Offer) bool {
+		return !inHostPool(offer, needs.HostPool)
+	}, e.metrics.OfferOutOfHostPool)
+}
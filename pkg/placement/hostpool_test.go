@@ -0,0 +1,52 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/placement/models/v0"
+	"github.com/uber/peloton/pkg/placement/testutil"
+	"github.com/uber/peloton/pkg/placement/testutil/v0"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupPoolHostOffers creates a host offer of a host in the host pool.
+func setupPoolHostOffers(hostname, pool string) *models_v0.HostOffers {
+	hostOffer := v0_testutil.SetupHostOffer()
+	hostOffer.Hostname = hostname
+	hostOffer.Attributes = append(hostOffer.Attributes, &mesos_v1.Attribute{
+		Name: proto.String(common.HostPoolKey),
+		Type: mesos_v1.Value_TEXT.Enum(),
+		Text: &mesos_v1.Value_Text{Value: proto.String(pool)},
+	})
+	return models_v0.NewHostOffers(hostOffer, []*resmgr.Task{}, time.Now())
+}
+
+// TestInHostPool tests that an offer is in a host pool only if its host
+// carries the host pool label of the pool.
+func TestInHostPool(t *testing.T) {
+	offer := setupPoolHostOffers("host1", "gpu")
+	assert.True(t, inHostPool(offer, "gpu"))
+	assert.False(t, inHostPool(offer, "shared"))
+	assert.False(t, inHostPool(testutil.SetupHostOffers(), "gpu"))
+}
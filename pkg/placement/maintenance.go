@@ -0,0 +1,61 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// dropDrainingPlacements splits the assigned tasks into the ones placed on
+// hosts which are not draining, and the ones placed on hosts which started
+// draining for maintenance since their offers were acquired. The draining
+// hosts are read from the offer service, which skips their offers when
+// acquiring offers. No placement is dropped if the draining hosts are not
+// known.
+func (e *engine) dropDrainingPlacements(
+	ctx context.Context,
+	assigned []models.Task) (placed, draining []models.Task) {
+	if e.drainingHosts == nil || len(assigned) == 0 {
+		return assigned, nil
+	}
+
+	drainingHosts, err := e.drainingHosts.DrainingHosts(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch draining hosts")
+		return assigned, nil
+	}
+	if len(drainingHosts) == 0 {
+		return assigned, nil
+	}
+
+	for _, task := range assigned {
+		if _, ok := drainingHosts[task.GetPlacement().Hostname()]; ok {
+			draining = append(draining, task)
+			continue
+		}
+		placed = append(placed, task)
+	}
+
+	if len(draining) > 0 {
+		e.metrics.PlacementDraining.Inc(int64(len(draining)))
+		log.WithField("tasks", len(draining)).
+			Info("dropping placements on draining hosts")
+	}
+	return placed, draining
+}
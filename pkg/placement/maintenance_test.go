@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDrainingHosts is a DrainingHostsSource returning fixed hosts.
+type fakeDrainingHosts struct {
+	hosts map[string]struct{}
+	err   error
+}
+
+func (f *fakeDrainingHosts) DrainingHosts(
+	ctx context.Context) (map[string]struct{}, error) {
+	return f.hosts, f.err
+}
+
+// TestCleanupDropsDrainingPlacements tests that the placements on a host
+// which started draining after its offers were acquired are not set in the
+// resource manager, that the offers of the host are released, and that its
+// tasks are returned to be placed again.
+func TestCleanupDropsDrainingPlacements(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, scope := setupEngine(t)
+	defer ctrl.Finish()
+	engine.drainingHosts = &fakeDrainingHosts{
+		hosts: map[string]struct{}{"host2": {}},
+	}
+
+	offer1 := setupNamedHostOffers("host1")
+	offer2 := setupNamedHostOffers("host2")
+	placed := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	placed.SetPlacement(offer1)
+	draining := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	draining.SetPlacement(offer2)
+
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), []models.Task{placed}, gomock.Any()).
+		Return(tasks.SetPlacementsResult{})
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), []models.Offer{offer2})
+
+	retry := engine.cleanup(
+		context.Background(),
+		plugins.PlacementNeeds{},
+		[]models.Task{placed, draining},
+		nil,
+		nil,
+		[]models.Offer{offer1, offer2})
+	assert.Equal(t, []models.Task{draining}, retry)
+	assert.Nil(t, draining.GetPlacement())
+	assert.Equal(t, offer1, placed.GetPlacement())
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t,
+		int64(1),
+		counters["batch.placement.host_draining+result=fail"].Value())
+}
+
+// TestCleanupDrainingHostsUnknown tests that no placement is dropped if
+// the draining hosts cannot be fetched.
+func TestCleanupDrainingHostsUnknown(t *testing.T) {
+	ctrl, engine, _, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.drainingHosts = &fakeDrainingHosts{
+		err: errors.New("host manager unavailable"),
+	}
+
+	offer := setupNamedHostOffers("host1")
+	assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	assignment.SetPlacement(offer)
+
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), []models.Task{assignment}, gomock.Any()).
+		Return(tasks.SetPlacementsResult{})
+
+	retry := engine.cleanup(
+		context.Background(),
+		plugins.PlacementNeeds{},
+		[]models.Task{assignment},
+		nil,
+		nil,
+		[]models.Offer{offer})
+	assert.Empty(t, retry)
+	assert.Equal(t, offer, assignment.GetPlacement())
+}
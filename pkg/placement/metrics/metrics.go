@@ -15,9 +15,16 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/uber-go/tally"
 )
 
+// _timeToFirstPlacementBuckets are the buckets of the time to the first
+// placement of a placement round, from 1ms to about 33s.
+var _timeToFirstPlacementBuckets = tally.MustMakeExponentialDurationBuckets(
+	time.Millisecond, 2, 16)
+
 // Metrics contains all the metrics relevant to the scheduler
 type Metrics struct {
 	// Running indicates if the scheduler is currently running or not
@@ -52,6 +59,46 @@ type Metrics struct {
 	// an Offer and it failed
 	OfferGetFail tally.Counter
 
+	// OfferGetFailPermanent indicates the number of times the scheduler
+	// requested an Offer and it failed with a permanent error
+	OfferGetFailPermanent tally.Counter
+
+	// OfferGetUnavailable indicates the number of times the scheduler
+	// requested an Offer and host manager was unavailable
+	OfferGetUnavailable tally.Counter
+
+	// OfferDraining indicates the number of offers skipped because
+	// their hosts are being drained for maintenance.
+	OfferDraining tally.Counter
+
+	// OfferPenalized indicates the number of offers skipped because
+	// placements on their hosts failed recently.
+	OfferPenalized tally.Counter
+
+	// OfferOutOfHostPool indicates the number of offers skipped because
+	// their host is outside the host pool required by the tasks.
+	OfferOutOfHostPool tally.Counter
+
+	// OfferHostNotAllowed indicates the number of offers skipped because
+	// their host is not allowed, or is denied, for the tasks.
+	OfferHostNotAllowed tally.Counter
+
+	// OfferAntiAffinity indicates the number of offers skipped because
+	// their host runs a task of a job the tasks are anti-affine to.
+	OfferAntiAffinity tally.Counter
+
+	// OfferRetained indicates the number of unused offers kept for the
+	// next placement round instead of being released.
+	OfferRetained tally.Counter
+
+	// OfferRetainedReused indicates the number of retained offers used
+	// in a later placement round.
+	OfferRetainedReused tally.Counter
+
+	// OfferGetRelaxed indicates the number of times offers were acquired
+	// without the soft constraint of starved tasks.
+	OfferGetRelaxed tally.Counter
+
 	// Launcher metrics
 
 	// LaunchTask is the number of mesos tasks launched. This is a
@@ -76,12 +123,29 @@ type Metrics struct {
 	// SetPlacementFail counts the number of tasks failed to be placed
 	SetPlacementFail tally.Counter
 
+	// SetPlacementFailPermanent counts the number of times setting the
+	// placements failed with a permanent error
+	SetPlacementFailPermanent tally.Counter
+
+	// TaskDequeueFailPermanent counts the number of times dequeuing the
+	// tasks failed with a permanent error
+	TaskDequeueFailPermanent tally.Counter
+
+	// TaskDequeueUnavailable counts the number of times dequeuing the
+	// tasks failed because resource manager was unavailable
+	TaskDequeueUnavailable tally.Counter
+
 	// CreatePlacementDuration is the timer for create placement
 	CreatePlacementDuration tally.Timer
 
 	// SetPlacementDuration is the timer for set placement
 	SetPlacementDuration tally.Timer
 
+	// TimeToFirstPlacement is the histogram of the time from the start
+	// of a placement round to its first placement accepted by the
+	// resource manager.
+	TimeToFirstPlacement tally.Histogram
+
 	// Host Metrics
 
 	// HostGet indicates the number of times the scheduler requested
@@ -95,6 +159,28 @@ type Metrics struct {
 	// TaskAffinityFail indicates failure on host manager to return
 	// host with affinity constraint satisfied.
 	TaskAffinityFail tally.Counter
+
+	// TasksThrottled counts the number of tasks deferred to the next
+	// placement round because their resource pool was throttled.
+	TasksThrottled tally.Counter
+
+	// TasksCappedPerHost counts the number of task placements dropped
+	// because their host already got the maximal number of tasks in the
+	// placement round.
+	TasksCappedPerHost tally.Counter
+
+	// PlacementPanic counts the number of panics recovered while placing
+	// a group of tasks.
+	PlacementPanic tally.Counter
+
+	// PlacementDraining counts the number of placements dropped because
+	// their hosts started draining for maintenance after the offers were
+	// acquired.
+	PlacementDraining tally.Counter
+
+	// unplacedScope is the scope of the metrics of the tasks which could
+	// not be placed, tagged by the constraint shape of the tasks.
+	unplacedScope tally.Scope
 }
 
 // NewMetrics returns a new Metrics struct with all metrics initialized and
@@ -125,13 +211,29 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		TaskLaunchDispatches:     taskSuccessScope.Counter("launch_dispatch"),
 		TaskLaunchDispatchesFail: taskFailScope.Counter("launch_dispatch"),
 		TasksDequeued:            taskScope.Gauge("dequeued"),
+		TaskDequeueFailPermanent: taskFailScope.Counter("dequeue_permanent"),
+		TaskDequeueUnavailable:   taskFailScope.Counter("dequeue_unavailable"),
 
 		SetPlacementSuccess: placementSuccessScope.Counter("set"),
 		SetPlacementFail:    placementFailScope.Counter("set"),
 
+		SetPlacementFailPermanent: placementFailScope.Counter("set_permanent"),
+
 		OfferGet:     offerSuccessScope.Counter("get"),
 		OfferGetFail: offerFailScope.Counter("get"),
 
+		OfferGetFailPermanent: offerFailScope.Counter("get_permanent"),
+		OfferGetUnavailable:   offerFailScope.Counter("get_unavailable"),
+
+		OfferDraining:       offerScope.Counter("draining"),
+		OfferPenalized:      offerScope.Counter("penalized"),
+		OfferOutOfHostPool:  offerScope.Counter("out_of_host_pool"),
+		OfferHostNotAllowed: offerScope.Counter("host_not_allowed"),
+		OfferAntiAffinity:   offerScope.Counter("anti_affinity"),
+		OfferRetained:       offerScope.Counter("retained"),
+		OfferRetainedReused: offerScope.Counter("retained_reused"),
+		OfferGetRelaxed:     offerScope.Counter("get_relaxed"),
+
 		LaunchTask:            taskSuccessScope.Counter("launch"),
 		LaunchTaskFail:        taskFailScope.Counter("launch"),
 		LaunchOfferAccept:     offerSuccessScope.Counter("accept"),
@@ -139,10 +241,35 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		CreatePlacementDuration: placementTimeScope.Timer("create_duration"),
 		SetPlacementDuration:    placementTimeScope.Timer("set_duration"),
+		TimeToFirstPlacement: placementTimeScope.Histogram(
+			"time_to_first_placement",
+			_timeToFirstPlacementBuckets),
 
 		HostGet:     HostSuccessScope.Counter("get"),
 		HostGetFail: HostFailScope.Counter("get"),
 
 		TaskAffinityFail: placementFailScope.Counter("host_limit"),
+
+		TasksThrottled:     placementFailScope.Counter("respool_throttled"),
+		TasksCappedPerHost: placementFailScope.Counter("host_task_cap"),
+		PlacementPanic:     placementFailScope.Counter("panic"),
+
+		PlacementDraining: placementFailScope.Counter("host_draining"),
+
+		unplacedScope: taskScope,
 	}
 }
+
+// TasksUnplaced returns the counter of the tasks of the given constraint
+// shape which could not be placed.
+func (m *Metrics) TasksUnplaced(shape string) tally.Counter {
+	return m.unplacedScope.Tagged(map[string]string{"shape": shape}).
+		Counter("unplaced")
+}
+
+// TasksUnplacedCurrent returns the gauge of the number of tasks of the
+// given constraint shape left unplaced by the latest placement attempt.
+func (m *Metrics) TasksUnplacedCurrent(shape string) tally.Gauge {
+	return m.unplacedScope.Tagged(map[string]string{"shape": shape}).
+		Gauge("unplaced_current")
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClientBackingOff is returned instead of calling a client which is
+// backed off after being found unavailable.
+var ErrClientBackingOff = errors.New("client is unavailable, backing off")
+
+// ClientBackoff backs off the calls to a client, like host manager or
+// resource manager, while the client is unavailable. The delay between
+// calls grows exponentially with the number of failed calls up to a
+// maximum, and is reset once a call succeeds.
+type ClientBackoff struct {
+	sync.Mutex
+
+	initial time.Duration
+	max     time.Duration
+
+	// delay is the current delay, which is 0 while the client is available.
+	delay time.Duration
+	// next is the time before which the client is not called.
+	next time.Time
+}
+
+// NewClientBackoff creates a ClientBackoff whose delay starts at initial
+// and is at most max.
+func NewClientBackoff(initial, max time.Duration) *ClientBackoff {
+	return &ClientBackoff{
+		initial: initial,
+		max:     max,
+	}
+}
+
+// Call calls f unless the client is backed off at the given time, in which
+// case ErrClientBackingOff is returned. The backoff grows if f fails
+// because the client is unavailable, and is reset if f succeeds.
+func (b *ClientBackoff) Call(now time.Time, f func() error) error {
+	b.Lock()
+	backingOff := now.Before(b.next)
+	b.Unlock()
+	if backingOff {
+		return ErrClientBackingOff
+	}
+
+	err := f()
+
+	b.Lock()
+	defer b.Unlock()
+	switch {
+	case err == nil:
+		b.delay = 0
+		b.next = time.Time{}
+	case IsUnavailableError(err):
+		b.delay *= 2
+		if b.delay < b.initial {
+			b.delay = b.initial
+		}
+		if b.delay > b.max {
+			b.delay = b.max
+		}
+		b.next = now.Add(b.delay)
+	}
+	return err
+}
+
+// Delay returns the current delay, which is 0 if the client is available.
+func (b *ClientBackoff) Delay() time.Duration {
+	b.Lock()
+	defer b.Unlock()
+	return b.delay
+}
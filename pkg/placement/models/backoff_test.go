@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func TestClientBackoff(t *testing.T) {
+	backoff := NewClientBackoff(time.Second, 3*time.Second)
+	now := time.Now()
+	calls := 0
+	unavailable := func() error {
+		calls++
+		return yarpcerrors.UnavailableErrorf("resmgr unavailable")
+	}
+	succeed := func() error {
+		calls++
+		return nil
+	}
+
+	// The delay doubles with every unavailable call, up to the maximum.
+	for _, delay := range []time.Duration{
+		time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second,
+	} {
+		assert.Error(t, backoff.Call(now, unavailable))
+		assert.Equal(t, delay, backoff.Delay())
+
+		// The client is not called until the delay elapsed.
+		assert.Equal(t,
+			ErrClientBackingOff,
+			backoff.Call(now.Add(delay-time.Millisecond), unavailable))
+		now = now.Add(delay)
+	}
+	assert.Equal(t, 4, calls)
+
+	// Other errors neither grow nor reset the backoff.
+	assert.Error(t, backoff.Call(now, func() error {
+		return errors.New("invalid request")
+	}))
+	assert.Equal(t, 3*time.Second, backoff.Delay())
+
+	// A successful call resets the backoff.
+	assert.NoError(t, backoff.Call(now, succeed))
+	assert.Equal(t, time.Duration(0), backoff.Delay())
+	assert.NoError(t, backoff.Call(now, succeed))
+	assert.Error(t, backoff.Call(now, unavailable))
+	assert.Equal(t, time.Second, backoff.Delay())
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/pkg/common"
+
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// IsTransientError returns true if an error returned by a call to host
+// manager or resource manager is transient, so the call should be retried.
+// Any other error, e.g. an invalid request, is permanent and retrying the
+// call would fail the same way.
+func IsTransientError(err error) bool {
+	cause := errors.Cause(err)
+	if common.IsTransientError(cause) {
+		return true
+	}
+	if yarpcerrors.IsResourceExhausted(cause) {
+		return true
+	}
+	return cause == context.DeadlineExceeded
+}
+
+// IsUnavailableError returns true if an error returned by a call to host
+// manager or resource manager means the service could not be reached.
+func IsUnavailableError(err error) bool {
+	return yarpcerrors.IsUnavailable(errors.Cause(err))
+}
+
+// RetryTransientErrors calls f until it succeeds, fails with a permanent
+// error, or has been retried the given number of times on transient errors,
+// sleeping for interval between the calls.
+func RetryTransientErrors(
+	ctx context.Context,
+	retries int,
+	interval time.Duration,
+	f func() error) error {
+	err := f()
+	for i := 0; i < retries && err != nil && IsTransientError(err); i++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+		err = f()
+	}
+	return err
+}
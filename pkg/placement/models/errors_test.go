@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, IsTransientError(yarpcerrors.UnavailableErrorf("")))
+	assert.True(t, IsTransientError(yarpcerrors.DeadlineExceededErrorf("")))
+	assert.True(t, IsTransientError(yarpcerrors.ResourceExhaustedErrorf("")))
+	assert.True(t, IsTransientError(context.DeadlineExceeded))
+
+	assert.False(t, IsTransientError(yarpcerrors.InvalidArgumentErrorf("")))
+	assert.False(t, IsTransientError(yarpcerrors.InternalErrorf("")))
+	assert.False(t, IsTransientError(errors.New("invalid constraint")))
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Transient errors are retried until the call succeeds.
+	calls := 0
+	err := RetryTransientErrors(ctx, 3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return yarpcerrors.UnavailableErrorf("")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// Transient errors are retried at most the given number of times.
+	calls = 0
+	err = RetryTransientErrors(ctx, 2, time.Millisecond, func() error {
+		calls++
+		return yarpcerrors.UnavailableErrorf("")
+	})
+	assert.True(t, yarpcerrors.IsUnavailable(err))
+	assert.Equal(t, 3, calls)
+
+	// Permanent errors are not retried.
+	calls = 0
+	err = RetryTransientErrors(ctx, 2, time.Millisecond, func() error {
+		calls++
+		return yarpcerrors.InvalidArgumentErrorf("")
+	})
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+	assert.Equal(t, 1, calls)
+}
@@ -142,13 +142,26 @@ func (a *Assignment) PreferredHost() string {
 func (a *Assignment) GetPlacementNeeds() plugins.PlacementNeeds {
 	rmTask := a.GetTask().GetTask()
 	needs := plugins.PlacementNeeds{
-		Resources:  scalar.FromResourceConfig(rmTask.GetResource()),
+		Resources:  _resources.get(rmTask.GetResource()),
 		Ports:      uint64(rmTask.GetNumPorts()),
 		Revocable:  rmTask.Revocable,
 		FDs:        rmTask.GetResource().GetFdLimit(),
 		MaxHosts:   _defaultMaxHosts,
 		HostHints:  map[string]string{},
+		HostPool:   rmTask.GetHostPool(),
 		Constraint: rmTask.Constraint,
+
+		AllowedHosts: rmTask.GetAllowedHosts(),
+		DeniedHosts:  rmTask.GetDeniedHosts(),
+	}
+	if rmTask.GetSoftConstraint() != nil {
+		needs.SoftConstraint = rmTask.GetSoftConstraint()
+	}
+	if len(rmTask.GetAntiAffinityJobLabels()) > 0 {
+		needs.AntiAffinityJobLabels = map[string]string{}
+		for _, label := range rmTask.GetAntiAffinityJobLabels() {
+			needs.AntiAffinityJobLabels[label.GetKey()] = label.GetValue()
+		}
 	}
 	if a.PreferredHost() != "" {
 		needs.HostHints[a.PelotonID()] = a.PreferredHost()
@@ -174,7 +187,7 @@ func (a *Assignment) IsRevocable() bool {
 
 // getUsage returns the resource and port usage of this assignment.
 func (a *Assignment) getUsage() (res scalar.Resources, ports uint64) {
-	res = scalar.FromResourceConfig(a.Task.GetTask().GetResource())
+	res = _resources.get(a.Task.GetTask().GetResource())
 	ports = uint64(a.Task.GetTask().GetNumPorts())
 	return
 }
@@ -148,6 +148,29 @@ func TestAssignment(t *testing.T) {
 		require.Equal(t, float64(1), resLeft.Mem)
 	})
 
+	t.Run("fits named resources", func(t *testing.T) {
+		_, _, rmTask, _, _, a1 := setupAssignmentVariables()
+		rmTask.Resource.ScalarResources = map[string]float64{"fpgas": 1.0}
+
+		// host lacks the named resource
+		resLeft := scalar.Resources{
+			CPU: 2.0,
+			Mem: 2.0,
+		}
+		resLeft, _, fit := a1.Fits(resLeft, 20)
+		require.False(t, fit)
+		require.Equal(t, float64(2), resLeft.CPU)
+
+		resLeft.Named = map[string]float64{"fpgas": 1.0}
+		resLeft, _, fit = a1.Fits(resLeft, 20)
+		require.True(t, fit)
+		require.Equal(t, float64(1), resLeft.CPU)
+		require.Equal(t, float64(0), resLeft.Named["fpgas"])
+
+		_, _, fit = a1.Fits(resLeft, 20)
+		require.False(t, fit)
+	})
+
 	t.Run("past deadline", func(t *testing.T) {
 		_, _, _, _, task, assignment := setupAssignmentVariables()
 		now := time.Now()
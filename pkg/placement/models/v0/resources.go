@@ -0,0 +1,102 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models_v0
+
+import (
+	"sync"
+
+	peloton_api_v0_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+)
+
+const (
+	// _resourceCacheSize is the maximal number of resource configs whose
+	// scalar resources are cached.
+	_resourceCacheSize = 4096
+)
+
+// _resources caches the scalar resources of the resource configs of the
+// tasks, which are mostly identical across tasks, groups and rounds.
+var _resources = newResourceCache(_resourceCacheSize, scalar.FromResourceConfig)
+
+// resourceKey identifies a resource config by the values of its fields.
+type resourceKey struct {
+	cpu  float64
+	mem  float64
+	disk float64
+	gpu  float64
+	fds  uint32
+}
+
+// resourceCache is a bounded, concurrency safe cache of the scalar
+// resources computed from resource configs.
+type resourceCache struct {
+	sync.RWMutex
+
+	size    int
+	convert func(*peloton_api_v0_task.ResourceConfig) scalar.Resources
+	entries map[resourceKey]scalar.Resources
+}
+
+// newResourceCache creates a resourceCache of at most size entries, which
+// computes the scalar resources of a resource config with convert.
+func newResourceCache(
+	size int,
+	convert func(*peloton_api_v0_task.ResourceConfig) scalar.Resources,
+) *resourceCache {
+	return &resourceCache{
+		size:    size,
+		convert: convert,
+		entries: make(map[resourceKey]scalar.Resources),
+	}
+}
+
+// get returns the scalar resources of the resource config. Resource
+// configs with named scalar resources are not cached.
+func (c *resourceCache) get(
+	rc *peloton_api_v0_task.ResourceConfig) scalar.Resources {
+	if len(rc.GetScalarResources()) > 0 {
+		return c.convert(rc)
+	}
+
+	key := resourceKey{
+		cpu:  rc.GetCpuLimit(),
+		mem:  rc.GetMemLimitMb(),
+		disk: rc.GetDiskLimitMb(),
+		gpu:  rc.GetGpuLimit(),
+		fds:  rc.GetFdLimit(),
+	}
+
+	c.RLock()
+	res, ok := c.entries[key]
+	c.RUnlock()
+	if ok {
+		return res
+	}
+
+	res = c.convert(rc)
+
+	c.Lock()
+	defer c.Unlock()
+	if len(c.entries) >= c.size {
+		// Evict an arbitrary entry to stay within the size of the cache.
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = res
+	return res
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models_v0
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	peloton_api_v0_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+)
+
+// countingConvert returns a conversion of resource configs which counts
+// the number of conversions done.
+func countingConvert(
+	count *int64) func(*peloton_api_v0_task.ResourceConfig) scalar.Resources {
+	return func(rc *peloton_api_v0_task.ResourceConfig) scalar.Resources {
+		atomic.AddInt64(count, 1)
+		return scalar.FromResourceConfig(rc)
+	}
+}
+
+// TestResourceCacheConvertsOnce tests that the resources of identical
+// resource configs are converted once, across concurrent lookups.
+func TestResourceCacheConvertsOnce(t *testing.T) {
+	var count int64
+	cache := newResourceCache(10, countingConvert(&count))
+	rc := &peloton_api_v0_task.ResourceConfig{
+		CpuLimit:    2,
+		MemLimitMb:  1024,
+		DiskLimitMb: 2048,
+		GpuLimit:    1,
+		FdLimit:     100,
+	}
+
+	// Warm up the cache, so the concurrent lookups only read it.
+	expected := cache.get(rc)
+	assert.Equal(t, scalar.FromResourceConfig(rc), expected)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				// An identical config, which is a different message.
+				res := cache.get(&peloton_api_v0_task.ResourceConfig{
+					CpuLimit:    2,
+					MemLimitMb:  1024,
+					DiskLimitMb: 2048,
+					GpuLimit:    1,
+					FdLimit:     100,
+				})
+				assert.Equal(t, expected, res)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&count))
+
+	// A different config is converted again.
+	cache.get(&peloton_api_v0_task.ResourceConfig{CpuLimit: 4})
+	assert.Equal(t, int64(2), atomic.LoadInt64(&count))
+}
+
+// TestResourceCacheBounded tests that the cache does not grow beyond
+// its size.
+func TestResourceCacheBounded(t *testing.T) {
+	var count int64
+	cache := newResourceCache(2, countingConvert(&count))
+	for i := 0; i < 5; i++ {
+		rc := &peloton_api_v0_task.ResourceConfig{CpuLimit: float64(i)}
+		assert.Equal(t, scalar.FromResourceConfig(rc), cache.get(rc))
+		assert.True(t, len(cache.entries) <= 2)
+	}
+	assert.Equal(t, int64(5), count)
+}
+
+func BenchmarkResourceCacheGet(b *testing.B) {
+	cache := newResourceCache(_resourceCacheSize, scalar.FromResourceConfig)
+	rc := &peloton_api_v0_task.ResourceConfig{CpuLimit: 2, MemLimitMb: 1024}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.get(rc)
+	}
+}
@@ -25,13 +25,22 @@ import (
 
 // Service will manage offers used by any placement strategy.
 type Service interface {
-	// Acquire fetches a batch of offers from the host manager.
+	// Acquire fetches a batch of offers from the host manager. The
+	// returned error is only set if acquiring the offers failed with a
+	// permanent error, so retrying with the same needs would fail again.
 	Acquire(ctx context.Context,
 		fetchTasks bool,
 		taskType resmgr.TaskType,
 		needs plugins.PlacementNeeds,
-	) (offers []models.Offer, reason string)
+	) (offers []models.Offer, reason string, err error)
 
 	// Release returns the acquired offers back to host manager.
 	Release(ctx context.Context, offers []models.Offer)
 }
+
+// DrainingHostsSource is implemented by the offer services which track the
+// hosts being drained for maintenance.
+type DrainingHostsSource interface {
+	// DrainingHosts returns the set of hosts being drained for maintenance.
+	DrainingHosts(ctx context.Context) (map[string]struct{}, error)
+}
@@ -18,9 +18,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+	"go.uber.org/yarpc/yarpcerrors"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -41,6 +44,19 @@ const (
 	_noHostOffers              = "no offers from the cluster"
 	_failedToFetchTasksOnHosts = "failed to fetch tasks on hosts"
 	_timeout                   = 10 * time.Second
+
+	// _releaseBatchSize is the maximum number of host offers released
+	// in a single ReleaseHostOffers request.
+	_releaseBatchSize = 100
+
+	// _drainingHostsRefreshInterval is the interval at which the hosts in
+	// DRAINING state are fetched again from host manager.
+	_drainingHostsRefreshInterval = 10 * time.Second
+
+	// _unavailableBackoffInitial and _unavailableBackoffMax bound the delay
+	// between acquire calls while host manager is unavailable.
+	_unavailableBackoffInitial = 1 * time.Second
+	_unavailableBackoffMax     = 1 * time.Minute
 )
 
 // NewService will create a new offer service.
@@ -52,6 +68,9 @@ func NewService(
 		hostManager:     hostManager,
 		resourceManager: resourceManager,
 		metrics:         metrics,
+		acquireBackoff: models.NewClientBackoff(
+			_unavailableBackoffInitial,
+			_unavailableBackoffMax),
 	}
 }
 
@@ -59,6 +78,16 @@ type service struct {
 	hostManager     hostsvc.InternalHostServiceYARPCClient
 	resourceManager resmgrsvc.ResourceManagerServiceYARPCClient
 	metrics         *metrics.Metrics
+
+	// acquireBackoff backs off the calls to acquire offers while host
+	// manager is unavailable.
+	acquireBackoff *models.ClientBackoff
+
+	// drainingHosts caches the hosts in DRAINING state fetched from host
+	// manager, which is refreshed every _drainingHostsRefreshInterval.
+	sync.Mutex
+	drainingHosts        map[string]struct{}
+	drainingHostsUpdated time.Time
 }
 
 // Acquire fetches a batch of offers from the host manager.
@@ -66,10 +95,15 @@ func (s *service) Acquire(
 	ctx context.Context,
 	fetchTasks bool,
 	taskType resmgr.TaskType,
-	needs plugins.PlacementNeeds) (offers []models.Offer, reason string) {
+	needs plugins.PlacementNeeds) (offers []models.Offer, reason string, err error) {
 	filter := plugins_v0.PlacementNeedsToHostFilter(needs)
 	// Get list of host -> resources (aggregate of outstanding offers)
 	hostOffers, filterResults, err := s.fetchOffers(ctx, filter)
+	if err == models.ErrClientBackingOff {
+		log.WithField("delay", s.acquireBackoff.Delay().String()).
+			Debug("host manager is unavailable, skipping acquire")
+		return offers, _failedToAcquireHostOffers, nil
+	}
 	if err != nil {
 		log.WithFields(log.Fields{
 			"host_offers":    hostOffers,
@@ -79,7 +113,11 @@ func (s *service) Acquire(
 			"fetch_tasks":    fetchTasks,
 		}).WithError(err).Error(_failedToAcquireHostOffers)
 		s.metrics.OfferGetFail.Inc(1)
-		return offers, _failedToAcquireHostOffers
+		if !models.IsTransientError(err) {
+			s.metrics.OfferGetFailPermanent.Inc(1)
+			return offers, _failedToAcquireHostOffers, err
+		}
+		return offers, _failedToAcquireHostOffers, nil
 	}
 
 	filterRes, err := json.Marshal(filterResults)
@@ -93,11 +131,15 @@ func (s *service) Acquire(
 			"filter_results_json": string(filterRes),
 		}).Error(err.Error())
 		s.metrics.OfferGetFail.Inc(1)
-		return offers, err.Error()
+		return offers, err.Error(), nil
 	}
 
+	// Skip offers from hosts which are being drained for maintenance, so
+	// that the tasks are placed on other hosts in the next rounds.
+	hostOffers = s.filterDrainingHosts(ctx, hostOffers)
+
 	if len(hostOffers) == 0 {
-		return offers, _noHostOffers
+		return offers, _noHostOffers, nil
 	}
 
 	// Get tasks running on hosts from hostOffers
@@ -113,7 +155,7 @@ func (s *service) Acquire(
 				"fetch_tasks":    fetchTasks,
 			}).WithError(err).Error(_failedToFetchTasksOnHosts)
 			s.metrics.OfferGetFail.Inc(1)
-			return offers, _failedToFetchTasksOnHosts
+			return offers, _failedToFetchTasksOnHosts, nil
 		}
 
 		// Log tasks already running on Hosts whose offers are acquired.
@@ -138,10 +180,12 @@ func (s *service) Acquire(
 	s.metrics.OfferGet.Inc(1)
 
 	// Create placement offers from the host offers
-	return s.convertOffers(hostOffers, hostTasksMap, time.Now()), string(filterRes)
+	return s.convertOffers(hostOffers, hostTasksMap, time.Now()), string(filterRes), nil
 }
 
-// Release returns the acquired offers back to host manager.
+// Release returns the acquired offers back to host manager. Offers are
+// released in batches of at most _releaseBatchSize offers, to avoid sending
+// oversized requests to host manager.
 func (s *service) Release(
 	ctx context.Context,
 	hosts []models.Offer) {
@@ -159,18 +203,37 @@ func (s *service) Release(
 		})
 	}
 
+	var errs error
+	for start := 0; start < len(hostOffers); start += _releaseBatchSize {
+		end := start + _releaseBatchSize
+		if end > len(hostOffers) {
+			end = len(hostOffers)
+		}
+		errs = multierr.Append(errs, s.releaseBatch(ctx, hostOffers[start:end]))
+	}
+
+	if errs != nil {
+		log.WithFields(log.Fields{
+			"num_host_offers": len(hostOffers),
+			"batch_size":      _releaseBatchSize,
+		}).WithError(errs).Error("release host offers failed")
+	}
+}
+
+// releaseBatch releases a single batch of host offers to host manager.
+func (s *service) releaseBatch(
+	ctx context.Context,
+	hostOffers []*hostsvc.HostOffer) error {
 	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
 	defer cancelFunc()
 
-	// ToDo: buffer the hosts until we have a batch of a certain size and return that.
 	request := &hostsvc.ReleaseHostOffersRequest{
 		HostOffers: hostOffers,
 	}
 	response, err := s.hostManager.ReleaseHostOffers(ctx, request)
 
 	if err != nil {
-		log.WithField("error", err).Error("release host offers failed")
-		return
+		return err
 	}
 
 	if respErr := response.GetError(); respErr != nil {
@@ -180,12 +243,14 @@ func (s *service) Release(
 			"release_host_response_error": respErr,
 		}).Error("release host offers error")
 		// TODO: Differentiate known error types by metrics and logs.
-	} else {
-		log.WithFields(log.Fields{
-			"release_host_request":  request,
-			"release_host_response": response,
-		}).Debug("release host offers request returned")
+		return errors.New(respErr.String())
 	}
+
+	log.WithFields(log.Fields{
+		"release_host_request":  request,
+		"release_host_response": response,
+	}).Debug("release host offers request returned")
+	return nil
 }
 
 // fetchOffers returns the offers by each host and count of all offers from host manager.
@@ -198,8 +263,15 @@ func (s *service) fetchOffers(
 	offersRequest := &hostsvc.AcquireHostOffersRequest{
 		Filter: filter,
 	}
-	offersResponse, err := s.hostManager.AcquireHostOffers(ctx, offersRequest)
+	var offersResponse *hostsvc.AcquireHostOffersResponse
+	err := s.acquireBackoff.Call(time.Now(), func() (err error) {
+		offersResponse, err = s.hostManager.AcquireHostOffers(ctx, offersRequest)
+		return err
+	})
 	if err != nil {
+		if models.IsUnavailableError(err) {
+			s.metrics.OfferGetUnavailable.Inc(1)
+		}
 		return nil, nil, err
 	}
 
@@ -209,12 +281,94 @@ func (s *service) fetchOffers(
 	}).Debug("acquire host offers returned")
 
 	if respErr := offersResponse.GetError(); respErr != nil {
-		return nil, nil, errors.New(respErr.String())
+		// An invalid filter is rejected the same way on every call, while
+		// any other failure may succeed once retried.
+		if respErr.GetInvalidHostFilter() != nil {
+			return nil, nil, errors.New(respErr.String())
+		}
+		return nil, nil, yarpcerrors.UnavailableErrorf("%s", respErr.String())
 	}
 
 	return offersResponse.GetHostOffers(), offersResponse.GetFilterResultCounts(), nil
 }
 
+// filterDrainingHosts removes the offers of hosts in DRAINING state from
+// hostOffers and releases them back to host manager. The offers are returned
+// unfiltered if the draining hosts cannot be fetched.
+func (s *service) filterDrainingHosts(
+	ctx context.Context,
+	hostOffers []*hostsvc.HostOffer) []*hostsvc.HostOffer {
+	if len(hostOffers) == 0 {
+		return hostOffers
+	}
+
+	drainingHosts, err := s.fetchDrainingHosts(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch draining hosts")
+		return hostOffers
+	}
+	if len(drainingHosts) == 0 {
+		return hostOffers
+	}
+
+	var result, draining []*hostsvc.HostOffer
+	for _, hostOffer := range hostOffers {
+		if _, ok := drainingHosts[hostOffer.GetHostname()]; ok {
+			draining = append(draining, hostOffer)
+			continue
+		}
+		result = append(result, hostOffer)
+	}
+
+	if len(draining) > 0 {
+		s.metrics.OfferDraining.Inc(int64(len(draining)))
+		if err := s.releaseBatch(ctx, draining); err != nil {
+			log.WithError(err).
+				WithField("num_host_offers", len(draining)).
+				Error("release host offers of draining hosts failed")
+		}
+	}
+	return result
+}
+
+// DrainingHosts returns the set of hosts in DRAINING state, from the same
+// cache which is used to filter the acquired offers.
+func (s *service) DrainingHosts(
+	ctx context.Context) (map[string]struct{}, error) {
+	return s.fetchDrainingHosts(ctx)
+}
+
+// fetchDrainingHosts returns the set of hosts in DRAINING state from
+// host manager.
+func (s *service) fetchDrainingHosts(
+	ctx context.Context) (map[string]struct{}, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.drainingHosts != nil &&
+		time.Since(s.drainingHostsUpdated) < _drainingHostsRefreshInterval {
+		return s.drainingHosts, nil
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
+	defer cancelFunc()
+
+	response, err := s.hostManager.GetDrainingHosts(
+		ctx,
+		&hostsvc.GetDrainingHostsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	drainingHosts := make(map[string]struct{}, len(response.GetHostnames()))
+	for _, hostname := range response.GetHostnames() {
+		drainingHosts[hostname] = struct{}{}
+	}
+	s.drainingHosts = drainingHosts
+	s.drainingHostsUpdated = time.Now()
+	return drainingHosts, nil
+}
+
 // fetchTasks returns the tasks running on provided host from resource manager.
 func (s *service) fetchTasks(
 	ctx context.Context,
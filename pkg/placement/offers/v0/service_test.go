@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -38,6 +39,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 func TestOfferService_Dequeue(t *testing.T) {
@@ -58,8 +60,42 @@ func TestOfferService_Dequeue(t *testing.T) {
 			gomock.Any(),
 			&hostsvc.AcquireHostOffersRequest{Filter: filter}).
 		Return(nil, errors.New("acquire host offers failed"))
-	hosts, reason := service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	hosts, reason, err := service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 	assert.Equal(t, reason, _failedToAcquireHostOffers)
+	assert.Error(t, err)
+
+	// Acquire Host Offers API call failed with a transient error.
+	mockHostManager.EXPECT().
+		AcquireHostOffers(
+			gomock.Any(),
+			&hostsvc.AcquireHostOffersRequest{Filter: filter}).
+		Return(nil, yarpcerrors.UnavailableErrorf("hostmgr unavailable"))
+	hosts, reason, err = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	assert.Equal(t, reason, _failedToAcquireHostOffers)
+	assert.NoError(t, err)
+
+	// Host manager is not called again until the backoff elapsed.
+	hosts, reason, err = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	assert.Equal(t, reason, _failedToAcquireHostOffers)
+	assert.NoError(t, err)
+	service.(*service).acquireBackoff = models.NewClientBackoff(
+		_unavailableBackoffInitial,
+		_unavailableBackoffMax)
+
+	// Acquire Host Offers API response has invalid filter error
+	mockHostManager.EXPECT().
+		AcquireHostOffers(
+			gomock.Any(),
+			&hostsvc.AcquireHostOffersRequest{Filter: filter}).
+		Return(&hostsvc.AcquireHostOffersResponse{
+			Error: &hostsvc.AcquireHostOffersResponse_Error{
+				InvalidHostFilter: &hostsvc.InvalidHostFilter{
+					Message: "invalid host filter",
+				},
+			}}, nil)
+	hosts, reason, err = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	assert.Equal(t, reason, _failedToAcquireHostOffers)
+	assert.Error(t, err)
 
 	// Acquire Host Offers API response has error
 	mockHostManager.EXPECT().
@@ -72,8 +108,9 @@ func TestOfferService_Dequeue(t *testing.T) {
 					Message: "acquire host offers response err",
 				},
 			}}, nil)
-	hosts, reason = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	hosts, reason, err = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 	assert.Equal(t, reason, _failedToAcquireHostOffers)
+	assert.NoError(t, err)
 
 	// Acquire Host Offers does not return any offer
 	mockHostManager.EXPECT().
@@ -83,7 +120,7 @@ func TestOfferService_Dequeue(t *testing.T) {
 		Return(&hostsvc.AcquireHostOffersResponse{
 			HostOffers: nil,
 		}, nil)
-	hosts, reason = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	hosts, reason, _ = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 	assert.Equal(t, reason, _noHostOffers)
 
 	// Acquire Host Offers get tasks failure
@@ -114,10 +151,15 @@ func TestOfferService_Dequeue(t *testing.T) {
 					Filter: filter,
 				},
 			).Return(hostOffers, nil),
+		// Draining hosts are cached by the service, so they are fetched
+		// only once in this test.
+		mockHostManager.EXPECT().
+			GetDrainingHosts(gomock.Any(), &hostsvc.GetDrainingHostsRequest{}).
+			Return(&hostsvc.GetDrainingHostsResponse{}, nil),
 		mockResourceManager.EXPECT().GetTasksByHosts(gomock.Any(), tasksRequest).
 			Return(nil, errors.New("get tasks by host failed")),
 	)
-	hosts, reason = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	hosts, reason, _ = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 
 	// Acquire Host Offers successful call
 	gomock.InOrder(
@@ -149,12 +191,58 @@ func TestOfferService_Dequeue(t *testing.T) {
 				Error: nil,
 			}, nil),
 	)
-	hosts, reason = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+	hosts, reason, _ = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 	assert.Equal(t, string(filterResultStr), reason)
 	assert.Equal(t, 1, len(hosts))
 	assert.Equal(t, "hostname", hosts[0].Hostname())
 }
 
+// TestOfferService_AcquireSkipsDrainingHosts tests that offers from hosts
+// being drained are not used for placement and are released.
+func TestOfferService_AcquireSkipsDrainingHosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockResourceManager := resource_mocks.NewMockResourceManagerServiceYARPCClient(ctrl)
+	mockHostManager := host_mocks.NewMockInternalHostServiceYARPCClient(ctrl)
+	metrics := metrics.NewMetrics(tally.NoopScope)
+	service := NewService(mockHostManager, mockResourceManager, metrics)
+	ctx := context.Background()
+
+	drainingOffer := &hostsvc.HostOffer{
+		Id:       &peloton.HostOfferID{Value: "offer-draining"},
+		Hostname: "draining-host",
+	}
+	hostOffer := &hostsvc.HostOffer{
+		Id:       &peloton.HostOfferID{Value: "offer"},
+		Hostname: "hostname",
+	}
+
+	gomock.InOrder(
+		mockHostManager.EXPECT().
+			AcquireHostOffers(gomock.Any(), gomock.Any()).
+			Return(&hostsvc.AcquireHostOffersResponse{
+				HostOffers: []*hostsvc.HostOffer{drainingOffer, hostOffer},
+			}, nil),
+		mockHostManager.EXPECT().
+			GetDrainingHosts(gomock.Any(), &hostsvc.GetDrainingHostsRequest{}).
+			Return(&hostsvc.GetDrainingHostsResponse{
+				Hostnames: []string{"draining-host"},
+			}, nil),
+		mockHostManager.EXPECT().
+			ReleaseHostOffers(
+				gomock.Any(),
+				&hostsvc.ReleaseHostOffersRequest{
+					HostOffers: []*hostsvc.HostOffer{drainingOffer},
+				}).
+			Return(&hostsvc.ReleaseHostOffersResponse{}, nil),
+	)
+
+	hosts, _, _ := service.Acquire(
+		ctx, false, resmgr.TaskType_UNKNOWN, plugins.PlacementNeeds{})
+	assert.Equal(t, 1, len(hosts))
+	assert.Equal(t, "hostname", hosts[0].Hostname())
+}
+
 func TestOfferService_Return(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -224,3 +312,52 @@ func TestOfferService_Return(t *testing.T) {
 
 	service.Release(ctx, offers)
 }
+
+func TestOfferService_ReleaseInBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockResourceManager := resource_mocks.NewMockResourceManagerServiceYARPCClient(ctrl)
+	mockHostManager := host_mocks.NewMockInternalHostServiceYARPCClient(ctrl)
+	metrics := metrics.NewMetrics(tally.NoopScope)
+	service := NewService(mockHostManager, mockResourceManager, metrics)
+	ctx := context.Background()
+
+	numOffers := 2*_releaseBatchSize + 1
+	var offers []models.Offer
+	for i := 0; i < numOffers; i++ {
+		hostOffer := &hostsvc.HostOffer{
+			Id:       &peloton.HostOfferID{Value: fmt.Sprintf("pelotonid-%d", i)},
+			Hostname: fmt.Sprintf("hostname-%d", i),
+			AgentId:  &mesos.AgentID{Value: &[]string{"agentid"}[0]},
+		}
+		offers = append(offers, models_v0.NewHostOffers(hostOffer, nil, time.Now()))
+	}
+
+	// The first batch fails, and the remaining batches are still released.
+	var released []int
+	gomock.InOrder(
+		mockHostManager.EXPECT().
+			ReleaseHostOffers(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *hostsvc.ReleaseHostOffersRequest) {
+				released = append(released, len(req.GetHostOffers()))
+			}).
+			Return(nil, errors.New("release host offers api error")),
+		mockHostManager.EXPECT().
+			ReleaseHostOffers(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *hostsvc.ReleaseHostOffersRequest) {
+				released = append(released, len(req.GetHostOffers()))
+			}).
+			Return(&hostsvc.ReleaseHostOffersResponse{
+				Error: &hostsvc.ReleaseHostOffersResponse_Error{},
+			}, nil),
+		mockHostManager.EXPECT().
+			ReleaseHostOffers(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *hostsvc.ReleaseHostOffersRequest) {
+				released = append(released, len(req.GetHostOffers()))
+			}).
+			Return(&hostsvc.ReleaseHostOffersResponse{}, nil),
+	)
+
+	service.Release(ctx, offers)
+	assert.Equal(t, []int{_releaseBatchSize, _releaseBatchSize, 1}, released)
+}
@@ -68,7 +68,7 @@ func (s *service) Acquire(
 	fetchTasks bool,
 	taskType resmgr.TaskType,
 	needs plugins.PlacementNeeds,
-) ([]models.Offer, string) {
+) ([]models.Offer, string, error) {
 	filter := plugins_v1.PlacementNeedsToHostFilter(needs)
 	req := &hostsvc.AcquireHostsRequest{Filter: filter}
 
@@ -83,7 +83,12 @@ func (s *service) Acquire(
 			"fetch_tasks": fetchTasks,
 		}).WithError(err).Error(_failedToAcquireHosts)
 		s.metrics.OfferGetFail.Inc(1)
-		return nil, fmt.Sprintf("failed to acquire hosts: %s", err)
+		reason := fmt.Sprintf("failed to acquire hosts: %s", err)
+		if !models.IsTransientError(err) {
+			s.metrics.OfferGetFailPermanent.Inc(1)
+			return nil, reason, err
+		}
+		return nil, reason, nil
 	}
 
 	log.WithFields(log.Fields{
@@ -98,7 +103,7 @@ func (s *service) Acquire(
 			"task_type":   taskType,
 			"fetch_tasks": fetchTasks,
 		}).Error(_noHostsAcquired)
-		return nil, _noHostsAcquired
+		return nil, _noHostsAcquired, nil
 	}
 
 	// Ignore error. It literally will never happen.
@@ -121,7 +126,7 @@ func (s *service) Acquire(
 				"fetch_tasks":    fetchTasks,
 			}).WithError(err).Error(_failedToFetchTasksOnHosts)
 			s.metrics.OfferGetFail.Inc(1)
-			return nil, fmt.Sprintf("failed to fetch tasks on hosts: %v", err)
+			return nil, fmt.Sprintf("failed to fetch tasks on hosts: %v", err), nil
 		}
 	}
 
@@ -139,7 +144,7 @@ func (s *service) Acquire(
 		hostname := host.GetHostSummary().GetHostname()
 		offers[i] = models_v1.NewOffer(host, tasksLists[hostname])
 	}
-	return offers, string(jsonFilterRes)
+	return offers, string(jsonFilterRes), nil
 }
 
 // Release releases a set of leases from host manager so they can
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 
 	host "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
 	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
@@ -72,9 +73,21 @@ func TestOfferServiceAcquire(t *testing.T) {
 				gomock.Any(),
 				&hostsvc.AcquireHostsRequest{Filter: filter}).
 			Return(nil, errors.New("acquire hosts failed"))
-		hosts, reason := service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+		hosts, reason, err := service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 		require.True(t, strings.Contains(reason, _failedToAcquireHosts))
 		require.Len(t, hosts, 0)
+		require.Error(t, err)
+
+		// Acquire Host Offers API call failed with a transient error.
+		mockHostManager.EXPECT().
+			AcquireHosts(
+				gomock.Any(),
+				&hostsvc.AcquireHostsRequest{Filter: filter}).
+			Return(nil, yarpcerrors.UnavailableErrorf("hostmgr unavailable"))
+		hosts, reason, err = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+		require.True(t, strings.Contains(reason, _failedToAcquireHosts))
+		require.Len(t, hosts, 0)
+		require.NoError(t, err)
 
 		// Acquire Host Offers does not return any offer
 		mockHostManager.EXPECT().
@@ -84,9 +97,10 @@ func TestOfferServiceAcquire(t *testing.T) {
 			Return(&hostsvc.AcquireHostsResponse{
 				Hosts: nil,
 			}, nil)
-		hosts, reason = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+		hosts, reason, err = service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
 		require.Equal(t, reason, _noHostsAcquired)
 		require.Len(t, hosts, 0)
+		require.NoError(t, err)
 	})
 
 	t.Run("acquire success", func(t *testing.T) {
@@ -133,7 +147,8 @@ func TestOfferServiceAcquire(t *testing.T) {
 				&hostsvc.AcquireHostsRequest{Filter: filter}).
 			Return(hostOffers, nil)
 
-		hosts, reason := service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+		hosts, reason, err := service.Acquire(ctx, true, resmgr.TaskType_UNKNOWN, needs)
+		require.NoError(t, err)
 		assert.Equal(t, string(filterResultStr), reason)
 		require.Equal(t, 1, len(hosts))
 		assert.Equal(t, "hostname", hosts[0].Hostname())
@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sync"
+	"time"
+)
+
+// hostPenaltyBox keeps track of the hosts on which placements failed
+// recently, so that their offers are skipped until the penalty expires.
+type hostPenaltyBox struct {
+	sync.Mutex
+
+	window time.Duration
+	// expiry is the time at which the penalty of each host expires.
+	expiry map[string]time.Time
+}
+
+// newHostPenaltyBox creates a hostPenaltyBox which penalizes a host for
+// the given window after a failure, a window of 0 disables the penalties.
+func newHostPenaltyBox(window time.Duration) *hostPenaltyBox {
+	return &hostPenaltyBox{
+		window: window,
+		expiry: make(map[string]time.Time),
+	}
+}
+
+// penalize records a placement failure on the host.
+func (b *hostPenaltyBox) penalize(hostname string, now time.Time) {
+	if b.window <= 0 {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.expiry[hostname] = now.Add(b.window)
+}
+
+// penalized returns true if the host is still penalized. An expired
+// penalty is removed.
+func (b *hostPenaltyBox) penalized(hostname string, now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+	expiry, ok := b.expiry[hostname]
+	if !ok {
+		return false
+	}
+	if !now.Before(expiry) {
+		delete(b.expiry, hostname)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHostPenaltyBox tests that a recently failed host is penalized until
+// the penalty window elapses.
+func TestHostPenaltyBox(t *testing.T) {
+	box := newHostPenaltyBox(time.Minute)
+	now := time.Now()
+
+	assert.False(t, box.penalized("failed-host", now))
+
+	box.penalize("failed-host", now)
+	assert.True(t, box.penalized("failed-host", now.Add(time.Second)))
+	assert.False(t, box.penalized("healthy-host", now.Add(time.Second)))
+
+	assert.False(t, box.penalized("failed-host", now.Add(time.Minute)))
+	assert.Empty(t, box.expiry)
+}
+
+// TestHostPenaltyBoxDisabled tests that no host is penalized when the
+// penalty window is 0.
+func TestHostPenaltyBoxDisabled(t *testing.T) {
+	box := newHostPenaltyBox(0)
+	box.penalize("failed-host", time.Now())
+	assert.False(t, box.penalized("failed-host", time.Now()))
+}
@@ -15,6 +15,8 @@
 package batch
 
 import (
+	"sort"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/uber/peloton/pkg/placement/config"
@@ -26,8 +28,11 @@ func New(config *config.PlacementConfig) plugins.Strategy {
 	log.Info("Using batch placement strategy.")
 	return &batch{
 		config: &plugins.Config{
-			TaskType:    config.TaskType,
-			UseHostPool: config.UseHostPool,
+			TaskType:        config.TaskType,
+			UseHostPool:     config.UseHostPool,
+			MaxTasksPerHost: config.MaxTasksPerHostPerRound,
+			GroupByJob:      config.TaskGrouping.ByJob(),
+			ResourceWeights: config.ResourceWeights.Resources(),
 		},
 	}
 }
@@ -84,7 +89,8 @@ func (batch *batch) packTasksOnHost(
 ) map[int]int {
 	placements := map[int]int{}
 	totalAssignedCount := 0
-	for hostIdx, host := range hosts {
+	for _, hostIdx := range batch.orderHosts(unassigned[0], hosts) {
+		host := hosts[hostIdx]
 		log.WithFields(log.Fields{
 			"unassigned": unassigned,
 			"hosts":      hosts,
@@ -100,6 +106,36 @@ func (batch *batch) packTasksOnHost(
 	return placements
 }
 
+// orderHosts returns the indices of the hosts ordered by how well the task
+// fits them under the configured resource weights, the best fit first, so
+// that the task does not take the resources which are scarce on a host
+// while other hosts have plenty of them. The hosts keep their order if no
+// resource weight is configured.
+func (batch *batch) orderHosts(
+	task plugins.Task,
+	hosts []plugins.Host,
+) []int {
+	order := make([]int, len(hosts))
+	for i := range hosts {
+		order[i] = i
+	}
+	weights := batch.config.ResourceWeights
+	if weights.Empty() {
+		return order
+	}
+
+	need := task.GetPlacementNeeds().Resources
+	scores := make([]float64, len(hosts))
+	for i, host := range hosts {
+		available, _ := host.GetAvailableResources()
+		scores[i] = available.WeightedFit(need, weights)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] < scores[order[j]]
+	})
+	return order
+}
+
 // Assign exactly one task to a host, and return all tasks that
 // could not be assigned (in case there are fewer hosts than tasks).
 // Note that all task have identical resource and scheduling
@@ -130,10 +166,15 @@ func (batch *batch) spreadTasksOnHost(
 // NOTE: getTasksForHost stops at the first task that failed to fit on
 // the host.
 // TODO (pourchet): Is the above note a bug? Or is it deliberate?
+// At most MaxTasksPerHost tasks are fitted to the host if it is set.
 func (batch *batch) getTasksForHost(
 	host plugins.Host,
 	unassigned []plugins.Task,
 ) int {
+	if max := batch.config.MaxTasksPerHost; max > 0 && len(unassigned) > max {
+		unassigned = unassigned[:max]
+	}
+
 	resLeft, portsLeft := host.GetAvailableResources()
 	for i, task := range unassigned {
 		var ok bool
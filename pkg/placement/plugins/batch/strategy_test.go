@@ -21,6 +21,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
 	"github.com/uber/peloton/pkg/placement/config"
 	"github.com/uber/peloton/pkg/placement/models/v0"
 	"github.com/uber/peloton/pkg/placement/plugins"
@@ -76,6 +77,85 @@ func (suite *BatchStrategyTestSuite) TestBatchGetTaskPlacementsPackFreeHost() {
 	suite.Equal(0, placements[1])
 }
 
+// TestBatchGetTaskPlacementsMaxTasksPerHost tests that no more than the
+// configured number of tasks are packed onto a host with resources left,
+// and that the remaining tasks are packed onto the next host.
+func (suite *BatchStrategyTestSuite) TestBatchGetTaskPlacementsMaxTasksPerHost() {
+	assignments := make([]*models_v0.Assignment, 0)
+	for i := 0; i < 10; i++ {
+		a := testutil.SetupAssignment(time.Now().Add(10*time.Second), 1)
+		a.GetTask().GetTask().Resource.CpuLimit = 1
+		a.GetTask().GetTask().Resource.GpuLimit = 1
+		a.GetTask().GetTask().NumPorts = 0
+		assignments = append(assignments, a)
+	}
+	offers := []plugins.Host{
+		testutil.SetupHostOffers(),
+		testutil.SetupHostOffers(),
+	}
+
+	strategy := New(&config.PlacementConfig{MaxTasksPerHostPerRound: 4})
+	tasks := models_v0.AssignmentsToPluginsTasks(assignments)
+	placements := strategy.GetTaskPlacements(tasks, offers)
+
+	for i := 0; i < 4; i++ {
+		suite.Equal(0, placements[i])
+	}
+	for i := 4; i < 8; i++ {
+		suite.Equal(1, placements[i])
+	}
+	suite.Equal(-1, placements[8])
+	suite.Equal(-1, placements[9])
+}
+
+// weightedHost is a host with the given available resources.
+type weightedHost struct {
+	plugins.Host
+	available scalar.Resources
+}
+
+func (h weightedHost) GetAvailableResources() (scalar.Resources, uint64) {
+	return h.available, 0
+}
+
+// TestBatchGetTaskPlacementsResourceWeights tests that a task is packed onto
+// the host which it fits best under the configured resource weights.
+func (suite *BatchStrategyTestSuite) TestBatchGetTaskPlacementsResourceWeights() {
+	assignment := testutil.SetupAssignment(time.Now().Add(10*time.Second), 1)
+	resource := assignment.GetTask().GetTask().Resource
+	resource.CpuLimit = 2
+	resource.MemLimitMb = 1000
+	resource.DiskLimitMb = 0
+	resource.GpuLimit = 0
+	assignment.GetTask().GetTask().NumPorts = 0
+	tasks := models_v0.AssignmentsToPluginsTasks(
+		[]*models_v0.Assignment{assignment})
+
+	// The first host is tight on cpu, the second one on memory.
+	hosts := []plugins.Host{
+		weightedHost{available: scalar.Resources{CPU: 3, Mem: 4000}},
+		weightedHost{available: scalar.Resources{CPU: 10, Mem: 1500}},
+	}
+
+	// Without weights the hosts are used in order.
+	strategy := New(&config.PlacementConfig{})
+	suite.Equal(0, strategy.GetTaskPlacements(tasks, hosts)[0])
+
+	// With equal weights the task takes a smaller share of the second
+	// host overall.
+	strategy = New(&config.PlacementConfig{
+		ResourceWeights: config.ResourceWeightsConfig{CPU: 1, Memory: 1},
+	})
+	suite.Equal(1, strategy.GetTaskPlacements(tasks, hosts)[0])
+
+	// With memory weighted, the task leaves the scarce memory of the
+	// second host available.
+	strategy = New(&config.PlacementConfig{
+		ResourceWeights: config.ResourceWeightsConfig{CPU: 1, Memory: 4},
+	})
+	suite.Equal(0, strategy.GetTaskPlacements(tasks, hosts)[0])
+}
+
 func (suite *BatchStrategyTestSuite) TestBatchGetTaskPlacementsSpread() {
 	assignments := make([]*models_v0.Assignment, 0)
 	for i := 0; i < 5; i++ {
@@ -16,6 +16,7 @@ package plugins
 
 import (
 	"encoding/json"
+	"sort"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -41,16 +42,12 @@ func GroupByPlacementNeeds(tasks []Task, config *Config) []*TasksByPlacementNeed
 		// TODO: This is ok for now since this is the only place getting constraint
 		// from task placement needs; once host pool is enabled,
 		// host pool constraint upsert should be moved into task.GetPlacementNeeds().
-		if config.UseHostPool {
-			var poolID string
-			if config.TaskType == resmgr.TaskType_BATCH {
-				if task.GetResmgrTaskV0().GetPreemptible() {
-					poolID = common.SharedHostPoolID
-				} else {
-					poolID = common.BatchReservedHostPoolID
-				}
-			} else if config.TaskType == resmgr.TaskType_STATELESS {
-				poolID = common.StatelessHostPoolID
+		// A task requiring a dedicated host pool is always constrained to
+		// it, whether or not host pools are used for the task type.
+		if needs.HostPool != "" || config.UseHostPool {
+			poolID := needs.HostPool
+			if poolID == "" {
+				poolID = defaultHostPool(task, config)
 			}
 
 			hostPoolConstraint := &peloton_api_v0_task.Constraint{
@@ -77,6 +74,9 @@ func GroupByPlacementNeeds(tasks []Task, config *Config) []*TasksByPlacementNeed
 		}
 
 		key := needs.ToMapKey()
+		if config.GroupByJob {
+			key = task.GetResmgrTaskV0().GetJobId().GetValue() + "/" + key
+		}
 		if _, found := groupByPlacementNeeds[key]; !found {
 			groupByPlacementNeeds[key] = &TasksByPlacementNeeds{
 				PlacementNeeds: needs,
@@ -92,6 +92,21 @@ func GroupByPlacementNeeds(tasks []Task, config *Config) []*TasksByPlacementNeed
 	return result
 }
 
+// defaultHostPool returns the host pool of tasks of the configured task
+// type which do not require a dedicated host pool.
+func defaultHostPool(task Task, config *Config) string {
+	switch config.TaskType {
+	case resmgr.TaskType_BATCH:
+		if task.GetResmgrTaskV0().GetPreemptible() {
+			return common.SharedHostPoolID
+		}
+		return common.BatchReservedHostPoolID
+	case resmgr.TaskType_STATELESS:
+		return common.StatelessHostPoolID
+	}
+	return ""
+}
+
 // upsertConstraint combines given constraint with existing constraint in
 // placement needs as an new constraint, inserts and updates it into PlacementNeeds.
 // TODO: It assumes PlacementNeeds.Constraint is *peloton_api_v0_task.Constraint,
@@ -133,9 +148,58 @@ func (needs *PlacementNeeds) upsertConstraint(newConstraint *peloton_api_v0_task
 }
 
 // ToMapKey returns a stringified version of the placement needs.
-// It zeroes out HostHints first to make sure
+// HostHints are left out, and the constraint is serialized in a canonical
+// form, so that logically identical needs always map to the same key.
 func (needs PlacementNeeds) ToMapKey() string {
 	needs.HostHints = nil
+	if constraint, ok := needs.Constraint.(*peloton_api_v0_task.Constraint); ok {
+		needs.Constraint = canonicalConstraint(constraint)
+	}
 	content, _ := json.Marshal(needs)
 	return string(content)
 }
+
+// canonicalConstraint returns a copy of the given constraint in which
+// the sub-constraints of every AND and OR constraint are sorted by their
+// canonical serialization.
+func canonicalConstraint(
+	constraint *peloton_api_v0_task.Constraint,
+) *peloton_api_v0_task.Constraint {
+	if constraint == nil {
+		return nil
+	}
+
+	result := &peloton_api_v0_task.Constraint{
+		Type:            constraint.GetType(),
+		LabelConstraint: constraint.GetLabelConstraint(),
+	}
+	if and := constraint.GetAndConstraint(); and != nil {
+		result.AndConstraint = &peloton_api_v0_task.AndConstraint{
+			Constraints: sortConstraints(and.GetConstraints()),
+		}
+	}
+	if or := constraint.GetOrConstraint(); or != nil {
+		result.OrConstraint = &peloton_api_v0_task.OrConstraint{
+			Constraints: sortConstraints(or.GetConstraints()),
+		}
+	}
+	return result
+}
+
+// sortConstraints returns the canonical form of the given constraints
+// sorted by their serialization.
+func sortConstraints(
+	constraints []*peloton_api_v0_task.Constraint,
+) []*peloton_api_v0_task.Constraint {
+	result := make([]*peloton_api_v0_task.Constraint, len(constraints))
+	keys := make(map[*peloton_api_v0_task.Constraint]string, len(constraints))
+	for i, c := range constraints {
+		result[i] = canonicalConstraint(c)
+		content, _ := json.Marshal(result[i])
+		keys[result[i]] = string(content)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return keys[result[i]] < keys[result[j]]
+	})
+	return result
+}
@@ -106,6 +106,86 @@ func (suite *PluginsHelperTestSuite) TestGroupByPlacementNeeds() {
 	}
 }
 
+// TestGroupByPlacementNeedsByJob tests that tasks are grouped by their job
+// and placement needs if grouping by job is configured, and by their
+// placement needs only otherwise.
+func (suite *PluginsHelperTestSuite) TestGroupByPlacementNeedsByJob() {
+	constraint1 := getFakeLabelConstraint("key1", "value1")
+	constraint2 := getFakeLabelConstraint("key2", "value2")
+	tasks := []Task{
+		&fakeTask{jobID: "job1", constraint: constraint1},
+		&fakeTask{jobID: "job1", constraint: constraint1},
+		&fakeTask{jobID: "job1", constraint: constraint2},
+		&fakeTask{jobID: "job2", constraint: constraint1},
+		&fakeTask{jobID: "job2", constraint: constraint2},
+	}
+
+	testCases := map[string]struct {
+		config     *Config
+		partitions [][]int
+	}{
+		"by-constraint": {
+			config:     &Config{},
+			partitions: [][]int{{0, 1, 3}, {2, 4}},
+		},
+		"by-job-and-constraint": {
+			config:     &Config{GroupByJob: true},
+			partitions: [][]int{{0, 1}, {2}, {3}, {4}},
+		},
+	}
+
+	for tcName, tc := range testCases {
+		var partitions [][]int
+		for _, group := range GroupByPlacementNeeds(tasks, tc.config) {
+			partitions = append(partitions, group.Tasks)
+		}
+		suite.ElementsMatch(tc.partitions, partitions, "test case: %s", tcName)
+	}
+}
+
+// TestGroupByPlacementNeedsEquivalentConstraints tests that tasks with
+// logically identical constraints, whose sub-constraints are in different
+// orders, are grouped together.
+func (suite *PluginsHelperTestSuite) TestGroupByPlacementNeedsEquivalentConstraints() {
+	tasks := []Task{
+		&fakeTask{
+			constraint: &peloton_api_v0_task.Constraint{
+				Type: peloton_api_v0_task.Constraint_AND_CONSTRAINT,
+				AndConstraint: &peloton_api_v0_task.AndConstraint{
+					Constraints: []*peloton_api_v0_task.Constraint{
+						getFakeLabelConstraint("key1", "value1"),
+						getFakeOrConstraint("key2", "value2"),
+					},
+				},
+			},
+		},
+		&fakeTask{
+			constraint: &peloton_api_v0_task.Constraint{
+				AndConstraint: &peloton_api_v0_task.AndConstraint{
+					Constraints: []*peloton_api_v0_task.Constraint{
+						getFakeOrConstraint("key2", "value2"),
+						getFakeLabelConstraint("key1", "value1"),
+					},
+				},
+				Type: peloton_api_v0_task.Constraint_AND_CONSTRAINT,
+			},
+		},
+		&fakeTask{
+			constraint: getFakeLabelConstraint("key1", "value1"),
+		},
+	}
+
+	needs := GroupByPlacementNeeds(tasks, &Config{})
+	suite.Len(needs, 2)
+	for _, n := range needs {
+		if len(n.Tasks) == 2 {
+			suite.Equal([]int{0, 1}, n.Tasks)
+		} else {
+			suite.Equal([]int{2}, n.Tasks)
+		}
+	}
+}
+
 // TestUpsertConstraint tests upserting given task constraints into placement needs.
 func (suite *PluginsHelperTestSuite) TestUpsertConstraint() {
 	testCases := map[string]struct {
@@ -336,6 +416,7 @@ func (suite *PluginsHelperTestSuite) setupMockTasks() []Task {
 // since we can't use mock task due to circular dependency.
 type fakeTask struct {
 	constraint interface{}
+	jobID      string
 }
 
 // newFakeTask returns a new fakeTask.
@@ -393,7 +474,9 @@ func (t *fakeTask) GetPlacementNeeds() PlacementNeeds {
 
 // GetResmgrTaskV0 returns resmgr task of fakeTask.
 func (t *fakeTask) GetResmgrTaskV0() *resmgr.Task {
-	return &resmgr.Task{}
+	return &resmgr.Task{
+		JobId: &peloton_api_v0_peloton.JobID{Value: t.jobID},
+	}
 }
 
 func getFakeLabelConstraint(key, value string) *peloton_api_v0_task.Constraint {
@@ -157,6 +157,7 @@ func (mimir *mimir) GroupTasksByPlacementNeeds(
 	pluginsConfig := &plugins.Config{
 		TaskType:    mimir.config.TaskType,
 		UseHostPool: mimir.config.UseHostPool,
+		GroupByJob:  mimir.config.TaskGrouping.ByJob(),
 	}
 
 	tasksByNeeds := plugins.GroupByPlacementNeeds(tasks, pluginsConfig)
@@ -66,9 +66,26 @@ type PlacementNeeds struct {
 	// A map from task/pod ID to preferred hostname.
 	HostHints map[string]string
 
+	// The host pool that all hosts must belong to, if set.
+	HostPool string
+
+	// The names of the hosts that the tasks may be placed on, if set.
+	AllowedHosts []string
+
+	// The names of the hosts that the tasks must not be placed on.
+	DeniedHosts []string
+
+	// The labels, keyed by label key, of the jobs whose tasks must not
+	// run on the same host as the tasks.
+	AntiAffinityJobLabels map[string]string
+
 	// TODO: Constraint
 	Constraint interface{}
 
+	// The constraint which hosts are preferred to satisfy, hosts which do
+	// not satisfy it are used only if no other host is available.
+	SoftConstraint interface{}
+
 	// TODO: RankingHint
 	RankHint interface{}
 }
@@ -123,4 +140,17 @@ type Host interface {
 type Config struct {
 	TaskType    resmgr.TaskType
 	UseHostPool bool
+
+	// MaxTasksPerHost is the maximum number of tasks placed on a host in
+	// one round, 0 meaning no limit.
+	MaxTasksPerHost int
+
+	// GroupByJob groups the tasks of different jobs separately even if
+	// they have the same placement needs.
+	GroupByJob bool
+
+	// ResourceWeights are the weights of the resource dimensions when
+	// scoring how well the tasks fit the hosts, no weight meaning the
+	// hosts are not scored.
+	ResourceWeights scalar.Resources
 }
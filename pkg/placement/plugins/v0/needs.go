@@ -32,6 +32,8 @@ func PlacementNeedsToHostFilter(needs plugins.PlacementNeeds) *hostsvc.HostFilte
 			DiskLimitMb: needs.Resources.Disk,
 			GpuLimit:    needs.Resources.GPU,
 			FdLimit:     needs.FDs,
+
+			ScalarResources: needs.Resources.Named,
 		},
 		NumPorts:  uint32(needs.Ports),
 		Revocable: needs.Revocable,
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+)
+
+var _hostEvaluator = constraints.NewEvaluator(task.LabelConstraint_HOST)
+
+// preferSoftConstraint returns the offers ordered so that the offers of the
+// hosts satisfying the soft constraint of the placement needs come first,
+// which makes the placement strategy prefer them. The offers of the other
+// hosts are kept, so that tasks are still placed if no preferred host is
+// available.
+func preferSoftConstraint(
+	needs plugins.PlacementNeeds,
+	offers []models.Offer) []models.Offer {
+	constraint, ok := needs.SoftConstraint.(*task.Constraint)
+	if !ok || constraint == nil {
+		return offers
+	}
+
+	preferred := make([]models.Offer, 0, len(offers))
+	var others []models.Offer
+	for _, offer := range offers {
+		if satisfiesConstraint(offer, constraint) {
+			preferred = append(preferred, offer)
+			continue
+		}
+		others = append(others, offer)
+	}
+	return append(preferred, others...)
+}
+
+// preferDesiredHosts returns the offers ordered so that the offers of the
+// desired hosts of the assignments come first, keeping the relative order
+// of the offers otherwise. The offers of the other hosts are kept, so that
+// tasks whose desired host is not available are placed elsewhere once
+// their desired host placement deadline has passed.
+func preferDesiredHosts(
+	assignments []models.Task,
+	offers []models.Offer) []models.Offer {
+	desiredHosts := make(map[string]struct{})
+	for _, assignment := range assignments {
+		if host := assignment.PreferredHost(); host != "" {
+			desiredHosts[host] = struct{}{}
+		}
+	}
+	if len(desiredHosts) == 0 {
+		return offers
+	}
+
+	preferred := make([]models.Offer, 0, len(offers))
+	var others []models.Offer
+	for _, offer := range offers {
+		if _, ok := desiredHosts[offer.Hostname()]; ok {
+			preferred = append(preferred, offer)
+			continue
+		}
+		others = append(others, offer)
+	}
+	return append(preferred, others...)
+}
+
+// satisfiesConstraint returns true if the host of the offer satisfies the
+// host label constraints of the constraint.
+func satisfiesConstraint(offer models.Offer, constraint *task.Constraint) bool {
+	result, err := _hostEvaluator.Evaluate(constraint, hostLabelValues(offer))
+	if err != nil {
+		log.WithField("hostname", offer.Hostname()).
+			WithError(err).
+			Debug("failed to evaluate soft constraint")
+		return false
+	}
+	return result == constraints.EvaluateResultMatch ||
+		result == constraints.EvaluateResultNotApplicable
+}
+
+// hostLabelValues returns the label values of the host of the offer, which
+// are the labels of its mimir group. The mimir labels consist of the
+// dot-separated name of the host attribute, followed by its value.
+func hostLabelValues(offer models.Offer) constraints.LabelValues {
+	lv := make(constraints.LabelValues)
+	group := offer.ToMimirGroup()
+	if group == nil || group.Labels == nil {
+		return lv
+	}
+
+	for _, label := range group.Labels.Labels() {
+		names := label.Names()
+		if len(names) < 2 {
+			continue
+		}
+		key := strings.Join(names[:len(names)-1], ".")
+		value := names[len(names)-1]
+		if _, ok := lv[key]; !ok {
+			lv[key] = make(map[string]uint32)
+		}
+		lv[key][value]++
+	}
+	return lv
+}
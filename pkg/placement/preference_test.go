@@ -0,0 +1,200 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/models/v0"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/plugins/batch"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+	"github.com/uber/peloton/pkg/placement/testutil/v0"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupStorageHostOffers creates a host offer of a host with the storage
+// attribute.
+func setupStorageHostOffers(hostname, storage string) *models_v0.HostOffers {
+	hostOffer := v0_testutil.SetupHostOffer()
+	hostOffer.Hostname = hostname
+	hostOffer.Attributes = append(hostOffer.Attributes, &mesos_v1.Attribute{
+		Name: proto.String("storage"),
+		Type: mesos_v1.Value_TEXT.Enum(),
+		Text: &mesos_v1.Value_Text{Value: proto.String(storage)},
+	})
+	return models_v0.NewHostOffers(hostOffer, []*resmgr.Task{}, time.Now())
+}
+
+// ssdConstraint is a constraint satisfied by the hosts with SSD storage.
+var ssdConstraint = &task.Constraint{
+	Type: task.Constraint_LABEL_CONSTRAINT,
+	LabelConstraint: &task.LabelConstraint{
+		Kind:        task.LabelConstraint_HOST,
+		Condition:   task.LabelConstraint_CONDITION_EQUAL,
+		Label:       &peloton.Label{Key: "storage", Value: "ssd"},
+		Requirement: 1,
+	},
+}
+
+// TestPreferSoftConstraint tests that the offers of hosts satisfying the
+// soft constraint are ordered first, and that no offer is dropped.
+func TestPreferSoftConstraint(t *testing.T) {
+	hdd1 := setupStorageHostOffers("host1", "hdd")
+	ssd1 := setupStorageHostOffers("host2", "ssd")
+	hdd2 := setupStorageHostOffers("host3", "hdd")
+	ssd2 := setupStorageHostOffers("host4", "ssd")
+	offers := []models.Offer{hdd1, ssd1, hdd2, ssd2}
+
+	assert.Equal(t,
+		[]models.Offer{ssd1, ssd2, hdd1, hdd2},
+		preferSoftConstraint(
+			plugins.PlacementNeeds{SoftConstraint: ssdConstraint}, offers))
+	assert.Equal(t,
+		offers,
+		preferSoftConstraint(plugins.PlacementNeeds{}, offers))
+}
+
+// TestEnginePlacesOnPreferredHost tests that a task preferring SSD hosts is
+// placed on an SSD host if one is offered, and on another host otherwise.
+func TestEnginePlacesOnPreferredHost(t *testing.T) {
+	hdd := setupStorageHostOffers("host1", "hdd")
+	ssd := setupStorageHostOffers("host2", "ssd")
+	hddOnly := setupStorageHostOffers("host3", "hdd")
+
+	testCases := []struct {
+		name     string
+		offers   []models.Offer
+		expected models.Offer
+	}{
+		{"ssd host offered", []models.Offer{hdd, ssd}, ssd},
+		{"ssd host not offered", []models.Offer{hddOnly}, hddOnly},
+	}
+
+	for _, tc := range testCases {
+		ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+		engine.strategy = batch.New(&config.PlacementConfig{})
+
+		assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+		assignment.GetTask().GetTask().Constraint = nil
+		assignment.GetTask().GetTask().SoftConstraint = ssdConstraint
+		needs := assignment.GetPlacementNeeds()
+
+		mockOfferService.EXPECT().
+			Acquire(gomock.Any(), gomock.Any(), gomock.Any(), needs).
+			Return(tc.offers, _testReason, nil)
+		mockTaskService.EXPECT().
+			SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(tasks.SetPlacementsResult{})
+		mockOfferService.EXPECT().
+			Release(gomock.Any(), gomock.Any()).
+			AnyTimes()
+
+		unfulfilled := engine.placeAssignmentGroup(
+			context.Background(), needs, []models.Task{assignment})
+		assert.Empty(t, unfulfilled, tc.name)
+		assert.Equal(t, tc.expected, assignment.GetPlacement(), tc.name)
+		ctrl.Finish()
+	}
+}
+
+// TestPreferDesiredHosts tests that the offers of the desired hosts of the
+// assignments are ordered first, and that no offer is dropped.
+func TestPreferDesiredHosts(t *testing.T) {
+	host1 := setupStorageHostOffers("host1", "hdd")
+	host2 := setupStorageHostOffers("host2", "hdd")
+	host3 := setupStorageHostOffers("host3", "hdd")
+	offers := []models.Offer{host1, host2, host3}
+
+	assignment1 := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	assignment1.GetTask().GetTask().DesiredHost = "host3"
+	assignment2 := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+
+	assert.Equal(t,
+		[]models.Offer{host3, host1, host2},
+		preferDesiredHosts(
+			[]models.Task{assignment1, assignment2}, offers))
+	assert.Equal(t,
+		offers,
+		preferDesiredHosts([]models.Task{assignment2}, offers))
+}
+
+// TestEnginePlacesOnDesiredHost tests that a task with a desired host is
+// placed on that host if it is offered among other hosts, and on another
+// host once its desired host placement deadline has passed otherwise.
+func TestEnginePlacesOnDesiredHost(t *testing.T) {
+	other := setupStorageHostOffers("host1", "hdd")
+	prior := setupStorageHostOffers("host2", "hdd")
+	otherOnly := setupStorageHostOffers("host3", "hdd")
+
+	testCases := []struct {
+		name     string
+		deadline time.Time
+		offers   []models.Offer
+		expected models.Offer
+	}{
+		{
+			"desired host offered",
+			time.Now().Add(time.Minute),
+			[]models.Offer{other, prior},
+			prior,
+		},
+		{
+			"desired host not offered",
+			time.Now().Add(-time.Minute),
+			[]models.Offer{otherOnly},
+			otherOnly,
+		},
+	}
+
+	for _, tc := range testCases {
+		ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+		engine.strategy = batch.New(&config.PlacementConfig{})
+
+		assignment := testutil.SetupAssignment(tc.deadline, 1)
+		assignment.GetTask().GetTask().Constraint = nil
+		assignment.GetTask().GetTask().DesiredHost = "host2"
+		needs := assignment.GetPlacementNeeds()
+
+		mockOfferService.EXPECT().
+			Acquire(gomock.Any(), gomock.Any(), gomock.Any(), needs).
+			Return(tc.offers, _testReason, nil)
+		mockTaskService.EXPECT().
+			SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(tasks.SetPlacementsResult{})
+		mockOfferService.EXPECT().
+			Release(gomock.Any(), gomock.Any()).
+			AnyTimes()
+
+		unfulfilled := engine.placeAssignmentGroup(
+			context.Background(), needs, []models.Task{assignment})
+		assert.Empty(t, unfulfilled, tc.name)
+		assert.Equal(t, tc.expected, assignment.GetPlacement(), tc.name)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/placement/plugins"
+)
+
+// starvation is the period during which the tasks of placement needs
+// have been starved of offers.
+type starvation struct {
+	// since is the time at which the tasks were first starved.
+	since time.Time
+	// last is the time at which the tasks were last starved.
+	last time.Time
+}
+
+// constraintRelaxer requires the soft constraint of the tasks when
+// acquiring offers, until the tasks have been starved of offers for a
+// while, after which the offers are acquired with the hard constraint
+// of the tasks only.
+type constraintRelaxer struct {
+	sync.Mutex
+
+	after time.Duration
+	// starvations holds the starvation of each placement needs by its
+	// map key.
+	starvations map[string]starvation
+}
+
+// newConstraintRelaxer creates a constraintRelaxer which relaxes the soft
+// constraint of the tasks starved for the given duration, a duration of 0
+// means the soft constraint is never required.
+func newConstraintRelaxer(after time.Duration) *constraintRelaxer {
+	return &constraintRelaxer{
+		after:       after,
+		starvations: make(map[string]starvation),
+	}
+}
+
+// starved records that the tasks of the placement needs were starved of
+// offers. The needs which were not starved recently are forgotten.
+func (r *constraintRelaxer) starved(
+	needs plugins.PlacementNeeds,
+	now time.Time) {
+	if r.after <= 0 {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	for key, s := range r.starvations {
+		if now.Sub(s.last) > r.after {
+			delete(r.starvations, key)
+		}
+	}
+
+	key := needs.ToMapKey()
+	s, ok := r.starvations[key]
+	if !ok {
+		s.since = now
+	}
+	s.last = now
+	r.starvations[key] = s
+}
+
+// fed records that offers were acquired for the tasks of the placement
+// needs, which ends their starvation.
+func (r *constraintRelaxer) fed(needs plugins.PlacementNeeds) {
+	if r.after <= 0 {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	delete(r.starvations, needs.ToMapKey())
+}
+
+// acquireNeeds returns the placement needs to acquire offers with. The
+// soft constraint of the needs is added to their hard constraint, unless
+// the tasks have been starved of offers for long enough, in which case the
+// needs are returned as is and relaxed is true.
+func (r *constraintRelaxer) acquireNeeds(
+	needs plugins.PlacementNeeds,
+	now time.Time) (result plugins.PlacementNeeds, relaxed bool) {
+	if r.after <= 0 {
+		return needs, false
+	}
+	soft, ok := needs.SoftConstraint.(*task.Constraint)
+	if !ok || soft.GetType() == task.Constraint_UNKNOWN_CONSTRAINT {
+		return needs, false
+	}
+
+	r.Lock()
+	s, ok := r.starvations[needs.ToMapKey()]
+	r.Unlock()
+	if ok && now.Sub(s.since) >= r.after {
+		return needs, true
+	}
+
+	hard, _ := needs.Constraint.(*task.Constraint)
+	if hard.GetType() == task.Constraint_UNKNOWN_CONSTRAINT {
+		needs.Constraint = soft
+		return needs, false
+	}
+	needs.Constraint = &task.Constraint{
+		Type: task.Constraint_AND_CONSTRAINT,
+		AndConstraint: &task.AndConstraint{
+			Constraints: []*task.Constraint{hard, soft},
+		},
+	}
+	return needs, false
+}
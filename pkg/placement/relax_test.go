@@ -0,0 +1,127 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/placement/plugins"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hostLabelConstraint(key, value string) *task.Constraint {
+	return &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:        task.LabelConstraint_HOST,
+			Condition:   task.LabelConstraint_CONDITION_EQUAL,
+			Label:       &peloton.Label{Key: key, Value: value},
+			Requirement: 1,
+		},
+	}
+}
+
+// TestConstraintRelaxer tests that the soft constraint of the tasks is
+// required when acquiring offers until the tasks have been starved for
+// the configured duration, and that the hard constraint is kept.
+func TestConstraintRelaxer(t *testing.T) {
+	relaxer := newConstraintRelaxer(time.Minute)
+	now := time.Now()
+
+	hard := hostLabelConstraint("storage", "ssd")
+	soft := hostLabelConstraint("zone", "a")
+	needs := plugins.PlacementNeeds{
+		Constraint:     hard,
+		SoftConstraint: soft,
+	}
+	strict := &task.Constraint{
+		Type: task.Constraint_AND_CONSTRAINT,
+		AndConstraint: &task.AndConstraint{
+			Constraints: []*task.Constraint{hard, soft},
+		},
+	}
+
+	result, relaxed := relaxer.acquireNeeds(needs, now)
+	assert.False(t, relaxed)
+	assert.Equal(t, strict, result.Constraint)
+
+	// starved, but not for long enough
+	relaxer.starved(needs, now)
+	result, relaxed = relaxer.acquireNeeds(needs, now.Add(30*time.Second))
+	assert.False(t, relaxed)
+	assert.Equal(t, strict, result.Constraint)
+
+	relaxer.starved(needs, now.Add(30*time.Second))
+	result, relaxed = relaxer.acquireNeeds(needs, now.Add(time.Minute))
+	assert.True(t, relaxed)
+	assert.Equal(t, hard, result.Constraint)
+
+	// offers were acquired, the soft constraint is required again
+	relaxer.fed(needs)
+	result, relaxed = relaxer.acquireNeeds(needs, now.Add(2*time.Minute))
+	assert.False(t, relaxed)
+	assert.Equal(t, strict, result.Constraint)
+}
+
+// TestConstraintRelaxerNoSoftConstraint tests that the hard constraint of
+// the tasks without soft constraint is never relaxed.
+func TestConstraintRelaxerNoSoftConstraint(t *testing.T) {
+	relaxer := newConstraintRelaxer(time.Minute)
+	now := time.Now()
+
+	hard := hostLabelConstraint("storage", "ssd")
+	needs := plugins.PlacementNeeds{Constraint: hard}
+
+	relaxer.starved(needs, now)
+	result, relaxed := relaxer.acquireNeeds(needs, now.Add(time.Hour))
+	assert.False(t, relaxed)
+	assert.Equal(t, hard, result.Constraint)
+}
+
+// TestConstraintRelaxerDisabled tests that the soft constraint is never
+// required when the relaxation duration is 0.
+func TestConstraintRelaxerDisabled(t *testing.T) {
+	relaxer := newConstraintRelaxer(0)
+	needs := plugins.PlacementNeeds{
+		SoftConstraint: hostLabelConstraint("zone", "a"),
+	}
+
+	relaxer.starved(needs, time.Now())
+	assert.Empty(t, relaxer.starvations)
+
+	result, relaxed := relaxer.acquireNeeds(needs, time.Now())
+	assert.False(t, relaxed)
+	assert.Nil(t, result.Constraint)
+}
+
+// TestConstraintRelaxerForgetsStaleStarvations tests that the starvation
+// of the needs which were not starved recently is forgotten.
+func TestConstraintRelaxerForgetsStaleStarvations(t *testing.T) {
+	relaxer := newConstraintRelaxer(time.Minute)
+	now := time.Now()
+
+	stale := plugins.PlacementNeeds{SoftConstraint: hostLabelConstraint("zone", "a")}
+	recent := plugins.PlacementNeeds{SoftConstraint: hostLabelConstraint("zone", "b")}
+
+	relaxer.starved(stale, now)
+	relaxer.starved(recent, now.Add(2*time.Minute))
+	assert.Len(t, relaxer.starvations, 1)
+	assert.Contains(t, relaxer.starvations, recent.ToMapKey())
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+)
+
+// retainedOffers are unused offers acquired for some placement needs,
+// which are kept to place tasks with the same needs in the next round.
+type retainedOffers struct {
+	needs  plugins.PlacementNeeds
+	offers []models.Offer
+	// expiry is the time after which the offers are released.
+	expiry time.Time
+}
+
+// offerRetainer keeps the offers which were left unused at the end of a
+// placement round, so that they can be used in the next round instead
+// of being released to the host manager and acquired again.
+type offerRetainer struct {
+	sync.Mutex
+
+	period   time.Duration
+	retained []*retainedOffers
+}
+
+// newOfferRetainer creates an offerRetainer which keeps unused offers for
+// the given period, a period of 0 disables the retention.
+func newOfferRetainer(period time.Duration) *offerRetainer {
+	return &offerRetainer{
+		period: period,
+	}
+}
+
+// enabled returns true if unused offers are retained.
+func (r *offerRetainer) enabled() bool {
+	return r.period > 0
+}
+
+// retain keeps the unused offers acquired for the placement needs.
+func (r *offerRetainer) retain(
+	needs plugins.PlacementNeeds,
+	offers []models.Offer,
+	now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	r.retained = append(r.retained, &retainedOffers{
+		needs:  needs,
+		offers: offers,
+		expiry: now.Add(r.period),
+	})
+}
+
+// take removes and returns the unexpired offers retained for the
+// placement needs.
+func (r *offerRetainer) take(
+	needs plugins.PlacementNeeds,
+	now time.Time) []models.Offer {
+	r.Lock()
+	defer r.Unlock()
+
+	var offers []models.Offer
+	kept := r.retained[:0]
+	for _, retained := range r.retained {
+		if now.Before(retained.expiry) && sameNeeds(needs, retained.needs) {
+			offers = append(offers, retained.offers...)
+			continue
+		}
+		kept = append(kept, retained)
+	}
+	r.retained = kept
+	return offers
+}
+
+// expire removes and returns the offers whose retention period elapsed.
+func (r *offerRetainer) expire(now time.Time) []models.Offer {
+	r.Lock()
+	defer r.Unlock()
+
+	var offers []models.Offer
+	kept := r.retained[:0]
+	for _, retained := range r.retained {
+		if !now.Before(retained.expiry) {
+			offers = append(offers, retained.offers...)
+			continue
+		}
+		kept = append(kept, retained)
+	}
+	r.retained = kept
+	return offers
+}
+
+// clear removes and returns all the retained offers.
+func (r *offerRetainer) clear() []models.Offer {
+	r.Lock()
+	defer r.Unlock()
+
+	var offers []models.Offer
+	for _, retained := range r.retained {
+		offers = append(offers, retained.offers...)
+	}
+	r.retained = nil
+	return offers
+}
+
+// sameNeeds returns true if offers acquired for one of the placement needs
+// can be used for the other. The maximal number of hosts is ignored since
+// it only limits how many offers are acquired.
+func sameNeeds(a, b plugins.PlacementNeeds) bool {
+	a.MaxHosts = 0
+	b.MaxHosts = 0
+	return reflect.DeepEqual(a, b)
+}
+
+// acquireOffers returns the offers retained for the placement needs in
+// a previous round if there are any, and acquires offers from the offer
+// service otherwise. The offers of penalized hosts, of hosts outside the
+// required host pool, of hosts not allowed for the tasks, and of hosts
+// running a job the tasks are anti-affine to, are skipped. The tasks
+// running on the hosts are fetched if the tasks are anti-affine to a job.
+func (e *engine) acquireOffers(
+	ctx context.Context,
+	needs plugins.PlacementNeeds) ([]models.Offer, string, error) {
+	if retained := e.offerRetainer.take(needs, time.Now()); len(retained) > 0 {
+		e.metrics.OfferRetainedReused.Inc(int64(len(retained)))
+		return e.skipPenalizedHosts(ctx, retained), "", nil
+	}
+
+	acquireNeeds, relaxed := e.relaxer.acquireNeeds(needs, time.Now())
+	if relaxed {
+		e.metrics.OfferGetRelaxed.Inc(1)
+	}
+	offers, reason, err := e.offerService.Acquire(
+		ctx,
+		e.config.FetchOfferTasks || len(needs.AntiAffinityJobLabels) > 0,
+		e.config.TaskType,
+		acquireNeeds)
+	offers = e.skipOutOfPoolHosts(ctx, needs, offers)
+	offers = e.skipDisallowedHosts(ctx, needs, offers)
+	offers = e.skipAntiAffineHosts(ctx, needs, offers)
+	return e.skipPenalizedHosts(ctx, offers), reason, err
+}
+
+// retainOrReleaseOffers retains the unused offers for the next placement
+// of tasks with the same placement needs if offer retention is enabled,
+// and releases them otherwise. The offers of the hosts on which a
+// placement was rejected are always released.
+func (e *engine) retainOrReleaseOffers(
+	ctx context.Context,
+	needs plugins.PlacementNeeds,
+	offers []models.Offer,
+	rejected []models.Task) {
+	if !e.offerRetainer.enabled() {
+		e.offerService.Release(ctx, offers)
+		return
+	}
+
+	rejectedHosts := make(map[string]struct{})
+	for _, task := range rejected {
+		rejectedHosts[task.GetPlacement().Hostname()] = struct{}{}
+	}
+
+	var retain, release []models.Offer
+	for _, offer := range offers {
+		if _, ok := rejectedHosts[offer.Hostname()]; ok {
+			release = append(release, offer)
+			continue
+		}
+		retain = append(retain, offer)
+	}
+
+	if len(retain) > 0 {
+		e.metrics.OfferRetained.Inc(int64(len(retain)))
+		e.offerRetainer.retain(needs, retain, time.Now())
+	}
+	if len(release) > 0 {
+		e.offerService.Release(ctx, release)
+	}
+}
+
+// releaseExpiredOffers releases the retained offers whose retention
+// period elapsed.
+func (e *engine) releaseExpiredOffers(ctx context.Context) {
+	if expired := e.offerRetainer.expire(time.Now()); len(expired) > 0 {
+		e.offerService.Release(ctx, expired)
+	}
+}
+
+// releaseRetainedOffers releases all the retained offers, regardless of
+// their retention period.
+func (e *engine) releaseRetainedOffers(ctx context.Context) {
+	if retained := e.offerRetainer.clear(); len(retained) > 0 {
+		e.offerService.Release(ctx, retained)
+	}
+}
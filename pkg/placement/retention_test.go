@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOfferRetainer tests that retained offers are only taken for the
+// same placement needs before they expire.
+func TestOfferRetainer(t *testing.T) {
+	retainer := newOfferRetainer(time.Minute)
+	now := time.Now()
+
+	needs := plugins.PlacementNeeds{MaxHosts: 1}
+	revocable := plugins.PlacementNeeds{Revocable: true}
+	offer := testutil.SetupHostOffers()
+	revocableOffer := testutil.SetupHostOffers()
+	retainer.retain(needs, []models.Offer{offer}, now)
+	retainer.retain(revocable, []models.Offer{revocableOffer}, now)
+
+	// The maximal number of hosts does not matter.
+	assert.Equal(t,
+		[]models.Offer{offer},
+		retainer.take(plugins.PlacementNeeds{MaxHosts: 2}, now))
+	assert.Empty(t, retainer.take(needs, now))
+
+	// Expired offers are not taken, but returned to be released.
+	assert.Empty(t, retainer.take(revocable, now.Add(time.Minute)))
+	assert.Empty(t, retainer.expire(now.Add(time.Second)))
+	assert.Equal(t,
+		[]models.Offer{revocableOffer},
+		retainer.expire(now.Add(time.Minute)))
+	assert.Empty(t, retainer.retained)
+}
+
+// TestEngineReusesRetainedOffers tests that an offer left unused by a
+// placement round is used in the next round instead of being released.
+func TestEngineReusesRetainedOffers(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, mockStrategy, scope := setupEngine(t)
+	defer ctrl.Finish()
+	engine.offerRetainer = newOfferRetainer(time.Minute)
+
+	used := testutil.SetupHostOffers()
+	unused := testutil.SetupHostOffers()
+	needs := plugins.PlacementNeeds{}
+
+	// Only the first round acquires offers, and no offer is released.
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), needs).
+		Return([]models.Offer{used, unused}, _testReason, nil)
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tasks.SetPlacementsResult{}).
+		Times(2)
+
+	gomock.InOrder(
+		mockStrategy.EXPECT().
+			GetTaskPlacements(gomock.Any(), gomock.Any()).
+			Return(map[int]int{0: 0}),
+		mockStrategy.EXPECT().
+			GetTaskPlacements(gomock.Any(), []plugins.Host{unused}).
+			Return(map[int]int{0: 0}),
+	)
+
+	deadline := time.Now().Add(time.Minute)
+	first := testutil.SetupAssignment(deadline, 1)
+	unfulfilled := engine.placeAssignmentGroup(
+		context.Background(), needs, []models.Task{first})
+	assert.Empty(t, unfulfilled)
+	assert.Equal(t, used, first.GetPlacement())
+
+	second := testutil.SetupAssignment(deadline, 1)
+	unfulfilled = engine.placeAssignmentGroup(
+		context.Background(), needs, []models.Task{second})
+	assert.Empty(t, unfulfilled)
+	assert.Equal(t, unused, second.GetPlacement())
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["batch.offer.retained+"].Value())
+	assert.Equal(t, int64(1), counters["batch.offer.retained_reused+"].Value())
+}
+
+// TestEngineReleasesExpiredOffers tests that retained offers are released
+// once their retention period elapsed.
+func TestEngineReleasesExpiredOffers(t *testing.T) {
+	ctrl, engine, mockOfferService, _, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.offerRetainer = newOfferRetainer(time.Minute)
+
+	offer := testutil.SetupHostOffers()
+	engine.offerRetainer.retain(
+		plugins.PlacementNeeds{},
+		[]models.Offer{offer},
+		time.Now().Add(-time.Minute))
+
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), []models.Offer{offer})
+
+	engine.releaseExpiredOffers(context.Background())
+	assert.Empty(t, engine.offerRetainer.retained)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/models/v0"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/tasks"
+)
+
+// Simulate returns the placements the engine would make for the tasks on
+// the offers in a single placement round, without acquiring any offers
+// or setting any placements.
+// Like in a placement round, the tasks are grouped by their placement
+// needs and the offers used by a group are not available to the other
+// groups. The offers are not filtered by the constraints of the tasks
+// though, which is done by host manager when offers are acquired.
+func (e *engine) Simulate(
+	resTasks []*resmgr.Task,
+	hostOffers []*hostsvc.HostOffer) []*resmgr.Placement {
+	now := time.Now()
+	assignments := make([]models.Task, 0, len(resTasks))
+	for _, resTask := range resTasks {
+		gang := &resmgrsvc.Gang{Tasks: []*resmgr.Task{resTask}}
+		assignments = append(assignments, models_v0.NewAssignment(
+			models_v0.NewTask(gang, resTask, now, now, 1)))
+	}
+
+	available := make([]models.Offer, 0, len(hostOffers))
+	for _, hostOffer := range hostOffers {
+		available = append(available, models_v0.NewHostOffers(hostOffer, nil, now))
+	}
+
+	var placed []models.Task
+	groups := e.strategy.GroupTasksByPlacementNeeds(models.ToPluginTasks(assignments))
+	for _, group := range groups {
+		if len(available) == 0 {
+			break
+		}
+
+		// Host manager acquires at most MaxHosts offers for a group.
+		offers := available
+		if maxHosts := int(group.PlacementNeeds.MaxHosts); maxHosts > 0 && maxHosts < len(offers) {
+			offers = offers[:maxHosts]
+		}
+
+		batch := make([]models.Task, 0, len(group.Tasks))
+		for _, idx := range group.Tasks {
+			batch = append(batch, assignments[idx])
+		}
+		hosts := make([]plugins.Host, 0, len(offers))
+		for _, offer := range offers {
+			hosts = append(hosts, offer)
+		}
+
+		used := map[string]struct{}{}
+		placements := e.strategy.GetTaskPlacements(models.ToPluginTasks(batch), hosts)
+		for taskIdx, hostIdx := range placements {
+			if hostIdx == -1 {
+				continue
+			}
+			batch[taskIdx].SetPlacement(offers[hostIdx])
+			placed = append(placed, batch[taskIdx])
+			used[offers[hostIdx].ID()] = struct{}{}
+		}
+
+		// Remove the offers used by the group.
+		var unused []models.Offer
+		for _, offer := range available {
+			if _, ok := used[offer.ID()]; !ok {
+				unused = append(unused, offer)
+			}
+		}
+		available = unused
+	}
+
+	return tasks.NewPlacements(e.config, placed)
+}
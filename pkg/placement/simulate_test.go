@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/models/v0"
+	"github.com/uber/peloton/pkg/placement/plugins/batch"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil/v0"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEngineSimulate tests that the simulated placements match the
+// placements made by a placement round on the same tasks and offers.
+func TestEngineSimulate(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+	engine.strategy = batch.New(&config.PlacementConfig{})
+
+	// Each host offer fits a single task.
+	var resTasks []*resmgr.Task
+	for i := 0; i < 3; i++ {
+		resTasks = append(resTasks, v0_testutil.SetupRMTask())
+	}
+	hostOffers := []*hostsvc.HostOffer{
+		v0_testutil.SetupHostOffer(),
+		v0_testutil.SetupHostOffer(),
+	}
+
+	simulated := map[string]string{}
+	for _, placement := range engine.Simulate(resTasks, hostOffers) {
+		for _, task := range placement.GetTaskIDs() {
+			simulated[task.GetPelotonTaskID().GetValue()] =
+				placement.GetHostOfferID().GetValue()
+		}
+	}
+
+	// Place the same tasks on the same offers in a placement round, the
+	// deadline of the tasks has passed so that the round ends after the
+	// offers are acquired once.
+	now := time.Now()
+	var assignments []models.Task
+	for _, resTask := range resTasks {
+		gang := &resmgrsvc.Gang{Tasks: []*resmgr.Task{resTask}}
+		assignments = append(assignments, models_v0.NewAssignment(
+			models_v0.NewTask(gang, resTask, now, now, 1)))
+	}
+	var offers []models.Offer
+	for _, hostOffer := range hostOffers {
+		offers = append(offers, models_v0.NewHostOffers(hostOffer, nil, now))
+	}
+
+	placed := map[string]string{}
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(offers, _testReason, nil)
+	mockOfferService.EXPECT().
+		Release(gomock.Any(), gomock.Any()).
+		AnyTimes()
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(
+			_ context.Context,
+			assigned []models.Task,
+			_ []models.Task) tasks.SetPlacementsResult {
+			for _, placement := range tasks.NewPlacements(engine.config, assigned) {
+				for _, task := range placement.GetTaskIDs() {
+					placed[task.GetPelotonTaskID().GetValue()] =
+						placement.GetHostOfferID().GetValue()
+				}
+			}
+			return tasks.SetPlacementsResult{}
+		})
+
+	needs := engine.strategy.GroupTasksByPlacementNeeds(
+		models.ToPluginTasks(assignments))[0].PlacementNeeds
+	engine.placeAssignmentGroup(context.Background(), needs, assignments)
+
+	assert.Len(t, simulated, 2)
+	assert.Equal(t, placed, simulated)
+}
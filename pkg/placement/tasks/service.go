@@ -37,6 +37,15 @@ const (
 	_failedToEnqueueTasks  = "failed to enqueue tasks back to resource manager"
 	_failedToDequeueTasks  = "failed to dequeue tasks from resource manager"
 	_failedToSetPlacements = "failed to set placements"
+
+	// _dequeueTimeoutMargin is the minimal time a dequeue call is given
+	// on top of the time resmgr waits for tasks in the ready queue.
+	_dequeueTimeoutMargin = 1 * time.Second
+
+	// _unavailableBackoffInitial and _unavailableBackoffMax bound the delay
+	// between dequeue calls while resource manager is unavailable.
+	_unavailableBackoffInitial = 1 * time.Second
+	_unavailableBackoffMax     = 1 * time.Minute
 )
 
 // Service will manage gangs/tasks and placements used by any placement strategy.
@@ -49,7 +58,32 @@ type Service interface {
 		ctx context.Context,
 		successFullPlacements []models.Task,
 		failedAssignments []models.Task,
-	)
+	) SetPlacementsResult
+}
+
+// SetPlacementsResult is the outcome of setting placements in the service.
+type SetPlacementsResult struct {
+	// Rejected are the successfully placed tasks whose placement was
+	// rejected by the service, these tasks still hold their offers.
+	Rejected []models.Task
+}
+
+// RejectedOffers returns the offers of the rejected placements.
+func (r SetPlacementsResult) RejectedOffers() []models.Offer {
+	var offers []models.Offer
+	seen := map[string]struct{}{}
+	for _, task := range r.Rejected {
+		offer := task.GetPlacement()
+		if offer == nil {
+			continue
+		}
+		if _, ok := seen[offer.ID()]; ok {
+			continue
+		}
+		seen[offer.ID()] = struct{}{}
+		offers = append(offers, offer)
+	}
+	return offers
 }
 
 // NewService will create a new task service.
@@ -61,6 +95,9 @@ func NewService(
 		config:          cfg,
 		resourceManager: resourceManager,
 		metrics:         metrics,
+		dequeueBackoff: models.NewClientBackoff(
+			_unavailableBackoffInitial,
+			_unavailableBackoffMax),
 	}
 }
 
@@ -68,6 +105,10 @@ type service struct {
 	config          *config.PlacementConfig
 	resourceManager resmgrsvc.ResourceManagerServiceYARPCClient
 	metrics         *metrics.Metrics
+
+	// dequeueBackoff backs off the dequeue calls while resource manager
+	// is unavailable.
+	dequeueBackoff *models.ClientBackoff
 }
 
 // Dequeue fetches some tasks from the resource manager.
@@ -76,7 +117,7 @@ func (s *service) Dequeue(
 	taskType resmgr.TaskType,
 	batchSize int,
 	timeout int) []models.Task {
-	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
+	ctx, cancelFunc := context.WithTimeout(ctx, s.dequeueTimeout(timeout))
 	defer cancelFunc()
 
 	request := &resmgrsvc.DequeueGangsRequest{
@@ -85,8 +126,25 @@ func (s *service) Dequeue(
 		Timeout: uint32(timeout),
 	}
 
-	response, err := s.resourceManager.DequeueGangs(ctx, request)
+	var response *resmgrsvc.DequeueGangsResponse
+	err := s.dequeueBackoff.Call(time.Now(), func() error {
+		return s.retryTransientErrors(ctx, func() (err error) {
+			response, err = s.resourceManager.DequeueGangs(ctx, request)
+			return err
+		})
+	})
+	if err == models.ErrClientBackingOff {
+		log.WithField("delay", s.dequeueBackoff.Delay().String()).
+			Debug("resource manager is unavailable, skipping dequeue")
+		return nil
+	}
 	if err != nil {
+		if models.IsUnavailableError(err) {
+			s.metrics.TaskDequeueUnavailable.Inc(1)
+		}
+		if !models.IsTransientError(err) {
+			s.metrics.TaskDequeueFailPermanent.Inc(1)
+		}
 		log.WithFields(log.Fields{
 			"task_type":              taskType,
 			"batch_size":             batchSize,
@@ -147,12 +205,27 @@ func (s *service) Dequeue(
 	return assignments
 }
 
+// dequeueTimeout returns the timeout of a dequeue call for which the
+// resource manager waits up to the given milliseconds for tasks. It is
+// never shorter than the wait plus a margin.
+func (s *service) dequeueTimeout(timeout int) time.Duration {
+	rpcTimeout := s.config.TaskDequeueRPCTimeout
+	if rpcTimeout <= 0 {
+		rpcTimeout = _timeout
+	}
+	minTimeout := time.Duration(timeout)*time.Millisecond + _dequeueTimeoutMargin
+	if rpcTimeout < minTimeout {
+		rpcTimeout = minTimeout
+	}
+	return rpcTimeout
+}
+
 // SetPlacements sets placements in the resource manager.
 func (s *service) SetPlacements(
 	ctx context.Context,
 	successes []models.Task,
 	failures []models.Task,
-) {
+) (result SetPlacementsResult) {
 	if len(successes) == 0 && len(failures) == 0 {
 		log.Debug("No task to place")
 		return
@@ -186,8 +259,15 @@ func (s *service) SetPlacements(
 		Placements:       s.createPlacements(successes),
 		FailedPlacements: failedPlacements,
 	}
-	response, err := s.resourceManager.SetPlacements(ctx, request)
+	var response *resmgrsvc.SetPlacementsResponse
+	err := s.retryTransientErrors(ctx, func() (err error) {
+		response, err = s.resourceManager.SetPlacements(ctx, request)
+		return err
+	})
 	if err != nil {
+		if !models.IsTransientError(err) {
+			s.metrics.SetPlacementFailPermanent.Inc(1)
+		}
 		log.WithFields(log.Fields{
 			"num_placements":          len(successes),
 			"num_failed_placements":   len(failedPlacements),
@@ -203,6 +283,9 @@ func (s *service) SetPlacements(
 	if response.GetError().GetFailure() != nil {
 		s.metrics.SetPlacementFail.Inc(
 			int64(len(response.GetError().GetFailure().GetFailed())))
+		result.Rejected = rejectedTasks(
+			successes,
+			response.GetError().GetFailure().GetFailed())
 	}
 
 	if response.GetError() != nil {
@@ -225,10 +308,56 @@ func (s *service) SetPlacements(
 	setPlacementDuration := time.Since(setPlacementStart)
 	s.metrics.SetPlacementDuration.Record(setPlacementDuration)
 	s.metrics.SetPlacementSuccess.Inc(int64(len(successes)))
+	return
+}
+
+// rejectedTasks returns the placed tasks which belong to any of the
+// placements rejected by the resource manager.
+func rejectedTasks(
+	placed []models.Task,
+	failed []*resmgrsvc.SetPlacementsFailure_FailedPlacement,
+) []models.Task {
+	rejectedIDs := map[string]struct{}{}
+	for _, f := range failed {
+		for _, t := range f.GetPlacement().GetTaskIDs() {
+			rejectedIDs[t.GetPelotonTaskID().GetValue()] = struct{}{}
+		}
+	}
+
+	var rejected []models.Task
+	for _, task := range placed {
+		if _, ok := rejectedIDs[task.PelotonID()]; ok {
+			rejected = append(rejected, task)
+		}
+	}
+	return rejected
+}
+
+// retryTransientErrors retries the call to resource manager when it fails
+// with a transient error, up to the configured number of retries.
+func (s *service) retryTransientErrors(
+	ctx context.Context,
+	f func() error) error {
+	return models.RetryTransientErrors(
+		ctx,
+		s.config.TransientErrorRetries,
+		s.config.TransientErrorRetryInterval,
+		f)
 }
 
 func (s *service) createPlacements(assigned []models.Task) []*resmgr.Placement {
 	createPlacementStart := time.Now()
+	resPlacements := NewPlacements(s.config, assigned)
+	createPlacementDuration := time.Since(createPlacementStart)
+	s.metrics.CreatePlacementDuration.Record(createPlacementDuration)
+	return resPlacements
+}
+
+// NewPlacements creates the resource manager placements of the tasks
+// assigned to offers, tasks without an offer are skipped.
+func NewPlacements(
+	cfg *config.PlacementConfig,
+	assigned []models.Task) []*resmgr.Placement {
 	// For each offer find all tasks assigned to it.
 	offersByID := map[string]models.Offer{}
 	offersToTasks := map[string][]models.Task{}
@@ -253,15 +382,13 @@ func (s *service) createPlacements(assigned []models.Task) []*resmgr.Placement {
 		placement := &resmgr.Placement{
 			Hostname:    offer.Hostname(),
 			AgentId:     &mesos.AgentID{Value: &agentID},
-			Type:        s.config.TaskType,
+			Type:        cfg.TaskType,
 			TaskIDs:     getPlacementTasks(tasks),
 			Ports:       formatPorts(selectedPorts),
 			HostOfferID: &peloton.HostOfferID{Value: offer.ID()},
 		}
 		resPlacements = append(resPlacements, placement)
 	}
-	createPlacementDuration := time.Since(createPlacementStart)
-	s.metrics.CreatePlacementDuration.Record(createPlacementDuration)
 	return resPlacements
 }
 
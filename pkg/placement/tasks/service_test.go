@@ -23,6 +23,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 
 	"github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -62,6 +63,9 @@ func setupService(t *testing.T) (*service, *resource_mocks.MockResourceManagerSe
 		config:          config,
 		resourceManager: mockResourceManager,
 		metrics:         metrics,
+		dequeueBackoff: models.NewClientBackoff(
+			_unavailableBackoffInitial,
+			_unavailableBackoffMax),
 	}, mockResourceManager, ctrl
 }
 
@@ -150,6 +154,40 @@ func TestTaskService_Dequeue(t *testing.T) {
 	assert.Equal(t, 1, len(assignments))
 }
 
+// TestTaskService_DequeueTimeout tests that the deadline of a dequeue call
+// leaves resmgr enough time to wait for tasks for the requested timeout.
+func TestTaskService_DequeueTimeout(t *testing.T) {
+	service, mockResourceManager, ctrl := setupService(t)
+	defer ctrl.Finish()
+
+	// The default timeout is used for short waits.
+	assert.Equal(t, _timeout, service.dequeueTimeout(100))
+
+	// A configured timeout is raised if the wait does not fit in it.
+	service.config.TaskDequeueRPCTimeout = 5 * time.Second
+	assert.Equal(t, 5*time.Second, service.dequeueTimeout(1000))
+	assert.Equal(t,
+		20*time.Second+_dequeueTimeoutMargin,
+		service.dequeueTimeout(20000))
+
+	timeout := 30000
+	start := time.Now()
+	mockResourceManager.EXPECT().
+		DequeueGangs(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *resmgrsvc.DequeueGangsRequest) {
+			assert.Equal(t, uint32(timeout), request.GetTimeout())
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.False(t, deadline.Before(
+				start.Add(30*time.Second+_dequeueTimeoutMargin)))
+		}).
+		Return(&resmgrsvc.DequeueGangsResponse{}, nil)
+
+	assignments := service.Dequeue(
+		context.Background(), resmgr.TaskType_BATCH, 10, timeout)
+	assert.Empty(t, assignments)
+}
+
 func TestTaskService_SetPlacements(t *testing.T) {
 	service, mockResourceManager, ctrl := setupService(t)
 	defer ctrl.Finish()
@@ -238,6 +276,155 @@ func TestTaskService_SetPlacements(t *testing.T) {
 	service.SetPlacements(ctx, assignments, nil)
 }
 
+// TestTaskService_SetPlacementsRejected tests that the placements rejected
+// by the resource manager are reported back in the result.
+func TestTaskService_SetPlacementsRejected(t *testing.T) {
+	service, mockResourceManager, ctrl := setupService(t)
+	defer ctrl.Finish()
+
+	newAssignment := func(offerID, taskID string) models.Task {
+		return &models_v0.Assignment{
+			Offer: &models_v0.HostOffers{
+				Offer: &hostsvc.HostOffer{
+					Id:       &peloton.HostOfferID{Value: offerID},
+					Hostname: "hostname-" + offerID,
+					AgentId:  &mesos_v1.AgentID{Value: &[]string{"agentid"}[0]},
+				},
+			},
+			Task: &models_v0.TaskV0{
+				Task: &resmgr.Task{
+					Id:     &peloton.TaskID{Value: taskID},
+					TaskId: &mesos_v1.TaskID{Value: &[]string{taskID + "-1"}[0]},
+				},
+			},
+		}
+	}
+	accepted := newAssignment("offer-1", "task-1")
+	rejected := newAssignment("offer-2", "task-2")
+
+	mockResourceManager.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any()).
+		Return(
+			&resmgrsvc.SetPlacementsResponse{
+				Error: &resmgrsvc.SetPlacementsResponse_Error{
+					Failure: &resmgrsvc.SetPlacementsFailure{
+						Failed: []*resmgrsvc.SetPlacementsFailure_FailedPlacement{
+							{
+								Placement: service.createPlacements(
+									[]models.Task{rejected})[0],
+								Message: "task not in placing state",
+							},
+						},
+					},
+				},
+			},
+			nil,
+		)
+
+	result := service.SetPlacements(
+		context.Background(),
+		[]models.Task{accepted, rejected},
+		nil,
+	)
+	assert.Equal(t, []models.Task{rejected}, result.Rejected)
+	assert.Equal(t, []models.Offer{rejected.GetPlacement()}, result.RejectedOffers())
+}
+
+// TestTaskService_RetryTransientErrors tests that the calls to resource
+// manager are retried on transient errors, and not on permanent errors.
+func TestTaskService_RetryTransientErrors(t *testing.T) {
+	service, mockResourceManager, ctrl := setupService(t)
+	defer ctrl.Finish()
+	service.config.TransientErrorRetries = 2
+	service.config.TransientErrorRetryInterval = time.Millisecond
+	ctx := context.Background()
+
+	// Dequeue succeeds after a transient error is retried.
+	gomock.InOrder(
+		mockResourceManager.EXPECT().
+			DequeueGangs(gomock.Any(), gomock.Any()).
+			Return(nil, yarpcerrors.UnavailableErrorf("resmgr unavailable")),
+		mockResourceManager.EXPECT().
+			DequeueGangs(gomock.Any(), gomock.Any()).
+			Return(&resmgrsvc.DequeueGangsResponse{
+				Gangs: []*resmgrsvc.Gang{
+					{Tasks: []*resmgr.Task{{Name: "task"}}},
+				},
+			}, nil),
+	)
+	assignments := service.Dequeue(ctx, resmgr.TaskType_UNKNOWN, 10, 100)
+	assert.Equal(t, 1, len(assignments))
+
+	// Dequeue is not retried on a permanent error.
+	mockResourceManager.EXPECT().
+		DequeueGangs(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("invalid task type")).
+		Times(1)
+	assignments = service.Dequeue(ctx, resmgr.TaskType_UNKNOWN, 10, 100)
+	assert.Nil(t, assignments)
+
+	assignment := testutil.SetupAssignment(time.Now().Add(time.Minute), 1)
+	assignment.SetPlacement(testutil.SetupHostOffers())
+	placed := []models.Task{assignment}
+
+	// Set placements gives up once the retries are exhausted.
+	mockResourceManager.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("resmgr unavailable")).
+		Times(3)
+	service.SetPlacements(ctx, placed, nil)
+
+	// Set placements is not retried on a permanent error.
+	mockResourceManager.EXPECT().
+		SetPlacements(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("invalid placement")).
+		Times(1)
+	service.SetPlacements(ctx, placed, nil)
+}
+
+// TestTaskService_DequeueUnavailableBackoff tests that dequeue calls are
+// backed off while resource manager is unavailable, and that the backoff
+// is reset once resource manager is available again.
+func TestTaskService_DequeueUnavailableBackoff(t *testing.T) {
+	service, mockResourceManager, ctrl := setupService(t)
+	defer ctrl.Finish()
+	service.dequeueBackoff = models.NewClientBackoff(
+		10*time.Millisecond,
+		40*time.Millisecond)
+	ctx := context.Background()
+
+	// Resource manager is unavailable for several attempts.
+	mockResourceManager.EXPECT().
+		DequeueGangs(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("resmgr unavailable")).
+		Times(4)
+	for _, delay := range []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		40 * time.Millisecond,
+	} {
+		assert.Nil(t, service.Dequeue(ctx, resmgr.TaskType_UNKNOWN, 10, 100))
+		assert.Equal(t, delay, service.dequeueBackoff.Delay())
+
+		// Resource manager is not called while backed off.
+		assert.Nil(t, service.Dequeue(ctx, resmgr.TaskType_UNKNOWN, 10, 100))
+		time.Sleep(delay)
+	}
+
+	// Resource manager recovers.
+	mockResourceManager.EXPECT().
+		DequeueGangs(gomock.Any(), gomock.Any()).
+		Return(&resmgrsvc.DequeueGangsResponse{
+			Gangs: []*resmgrsvc.Gang{
+				{Tasks: []*resmgr.Task{{Name: "task"}}},
+			},
+		}, nil)
+	assignments := service.Dequeue(ctx, resmgr.TaskType_UNKNOWN, 10, 100)
+	assert.Equal(t, 1, len(assignments))
+	assert.Equal(t, time.Duration(0), service.dequeueBackoff.Delay())
+}
+
 // TestCreatePlacement tests that we can turn assignments into resmgr placement objects
 // properly.
 func TestCreatePlacement(t *testing.T) {
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// respoolThrottler caps the rate at which tasks of a resource pool are
+// placed, so that a pool being drained or rebalanced does not thrash.
+type respoolThrottler struct {
+	sync.Mutex
+
+	limiters map[string]*rate.Limiter
+	// refunds is the number of tokens given back by the tasks of each
+	// resource pool which were not placed. They are used before taking
+	// tokens from the limiter of the resource pool.
+	refunds map[string]int
+}
+
+// newRespoolThrottler creates a respoolThrottler from the per resource pool
+// rate limit configs.
+func newRespoolThrottler(
+	limits map[string]config.RateLimitConfig) *respoolThrottler {
+	limiters := make(map[string]*rate.Limiter, len(limits))
+	for respoolID, limit := range limits {
+		limiters[respoolID] = rate.NewLimiter(limit.Rate, limit.Burst)
+	}
+	return &respoolThrottler{
+		limiters: limiters,
+		refunds:  make(map[string]int),
+	}
+}
+
+// throttle splits the assignments into the ones which can be placed now
+// and the ones which exceed the placement rate of their resource pool and
+// need to be deferred to the next round.
+func (t *respoolThrottler) throttle(
+	now time.Time,
+	assignments []models.Task) (allowed, deferred []models.Task) {
+	if len(t.limiters) == 0 {
+		return assignments, nil
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	for _, assignment := range assignments {
+		respoolID := assignment.GetResmgrTaskV0().GetRespoolID().GetValue()
+		limiter, ok := t.limiters[respoolID]
+		if !ok {
+			allowed = append(allowed, assignment)
+			continue
+		}
+		if t.refunds[respoolID] > 0 {
+			t.refunds[respoolID]--
+			allowed = append(allowed, assignment)
+			continue
+		}
+		if limiter.AllowN(now, 1) {
+			allowed = append(allowed, assignment)
+			continue
+		}
+		deferred = append(deferred, assignment)
+	}
+	return allowed, deferred
+}
+
+// refund gives back the tokens taken by the assignments which were allowed
+// but not placed, so that only placed tasks count towards the placement
+// rate of their resource pool. The refunds of a resource pool are capped
+// at its burst.
+func (t *respoolThrottler) refund(assignments []models.Task) {
+	if len(t.limiters) == 0 {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	for _, assignment := range assignments {
+		respoolID := assignment.GetResmgrTaskV0().GetRespoolID().GetValue()
+		limiter, ok := t.limiters[respoolID]
+		if !ok {
+			continue
+		}
+		if t.refunds[respoolID] < limiter.Burst() {
+			t.refunds[respoolID]++
+		}
+	}
+}
+
+// unplacedAssignments returns the assignments of the group which were not
+// placed: the unfulfilled ones, which are retried in the next round, and
+// the ones returned to the resource manager without a placement.
+func unplacedAssignments(
+	group []models.Task,
+	unfulfilled []models.Task) []models.Task {
+	retried := make(map[string]struct{}, len(unfulfilled))
+	for _, assignment := range unfulfilled {
+		retried[assignment.PelotonID()] = struct{}{}
+	}
+
+	unplaced := append([]models.Task{}, unfulfilled...)
+	for _, assignment := range group {
+		if _, ok := retried[assignment.PelotonID()]; ok {
+			continue
+		}
+		if assignment.GetPlacement() == nil {
+			unplaced = append(unplaced, assignment)
+		}
+	}
+	return unplaced
+}
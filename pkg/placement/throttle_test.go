@@ -0,0 +1,134 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRespoolAssignments(respoolID string, n int) []models.Task {
+	var assignments []models.Task
+	for i := 0; i < n; i++ {
+		assignment := testutil.SetupAssignment(time.Now(), 1)
+		assignment.GetTask().GetTask().RespoolID = &peloton.ResourcePoolID{
+			Value: respoolID,
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments
+}
+
+// TestRespoolThrottlerRate tests that placements into a throttled resource
+// pool do not exceed the configured rate within a window.
+func TestRespoolThrottlerRate(t *testing.T) {
+	throttler := newRespoolThrottler(map[string]config.RateLimitConfig{
+		"respool1": {Rate: 2, Burst: 2},
+	})
+
+	now := time.Now()
+	allowedCount := 0
+	// try to place 5 tasks every 100ms for one second
+	for i := 0; i < 10; i++ {
+		allowed, deferred := throttler.throttle(
+			now.Add(time.Duration(i)*100*time.Millisecond),
+			setupRespoolAssignments("respool1", 5))
+		assert.Len(t, deferred, 5-len(allowed))
+		allowedCount += len(allowed)
+	}
+	// burst of 2 and 2 more tasks over one second
+	assert.True(t, allowedCount <= 4)
+	assert.True(t, allowedCount >= 3)
+}
+
+// TestRespoolThrottlerUnthrottledRespool tests that tasks of resource pools
+// without a rate limit are never deferred.
+func TestRespoolThrottlerUnthrottledRespool(t *testing.T) {
+	throttler := newRespoolThrottler(map[string]config.RateLimitConfig{
+		"respool1": {Rate: 1, Burst: 1},
+	})
+
+	assignments := append(
+		setupRespoolAssignments("respool1", 3),
+		setupRespoolAssignments("respool2", 3)...)
+	allowed, deferred := throttler.throttle(time.Now(), assignments)
+	assert.Len(t, allowed, 4)
+	assert.Len(t, deferred, 2)
+	for _, assignment := range deferred {
+		assert.Equal(t,
+			"respool1",
+			assignment.GetResmgrTaskV0().GetRespoolID().GetValue())
+	}
+
+	throttler = newRespoolThrottler(nil)
+	allowed, deferred = throttler.throttle(time.Now(), assignments)
+	assert.Len(t, allowed, len(assignments))
+	assert.Empty(t, deferred)
+}
+
+// TestRespoolThrottlerRefund tests that the tokens refunded by the tasks
+// which were not placed let other tasks of the resource pool be placed,
+// up to the burst of the resource pool.
+func TestRespoolThrottlerRefund(t *testing.T) {
+	throttler := newRespoolThrottler(map[string]config.RateLimitConfig{
+		"respool1": {Rate: 0.001, Burst: 2},
+	})
+
+	now := time.Now()
+	allowed, deferred := throttler.throttle(
+		now, setupRespoolAssignments("respool1", 3))
+	assert.Len(t, allowed, 2)
+	assert.Len(t, deferred, 1)
+
+	// no tokens are left without a refund
+	allowed, _ = throttler.throttle(
+		now, setupRespoolAssignments("respool1", 1))
+	assert.Empty(t, allowed)
+
+	// the refunds are capped at the burst of the resource pool
+	throttler.refund(append(
+		setupRespoolAssignments("respool1", 3),
+		setupRespoolAssignments("respool2", 1)...))
+	allowed, deferred = throttler.throttle(
+		now, setupRespoolAssignments("respool1", 3))
+	assert.Len(t, allowed, 2)
+	assert.Len(t, deferred, 1)
+}
+
+// TestUnplacedAssignments tests that the unfulfilled assignments and the
+// assignments without a placement are unplaced.
+func TestUnplacedAssignments(t *testing.T) {
+	placed := testutil.SetupAssignment(time.Now(), 1)
+	placed.SetPlacement(testutil.SetupHostOffers())
+	retried := testutil.SetupAssignment(time.Now(), 1)
+	retried.SetPlacement(testutil.SetupHostOffers())
+	returned := testutil.SetupAssignment(time.Now(), 1)
+
+	assert.Equal(t,
+		[]models.Task{retried, returned},
+		unplacedAssignments(
+			[]models.Task{placed, retried, returned},
+			[]models.Task{retried}))
+	assert.Empty(t,
+		unplacedAssignments([]models.Task{placed}, nil))
+}
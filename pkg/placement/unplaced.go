@@ -0,0 +1,121 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+)
+
+const (
+	// _unplacedLogInterval is the minimal interval between two logs of the
+	// tasks of the same constraint shape which could not be placed.
+	_unplacedLogInterval = 1 * time.Minute
+)
+
+// unplacedLogger logs the tasks which could not be placed at most once
+// per interval for each constraint shape, so that a capacity crunch does
+// not flood the logs.
+type unplacedLogger struct {
+	sync.Mutex
+
+	interval time.Duration
+	logged   map[string]time.Time
+}
+
+// newUnplacedLogger creates an unplacedLogger which logs the tasks of
+// a constraint shape at most once per the given interval.
+func newUnplacedLogger(interval time.Duration) *unplacedLogger {
+	return &unplacedLogger{
+		interval: interval,
+		logged:   make(map[string]time.Time),
+	}
+}
+
+// allow returns true if the unplaced tasks of the given shape should be
+// logged now, and records the time of the log.
+func (l *unplacedLogger) allow(shape string, now time.Time) bool {
+	l.Lock()
+	defer l.Unlock()
+	if last, ok := l.logged[shape]; ok && now.Sub(last) < l.interval {
+		return false
+	}
+	l.logged[shape] = now
+	return true
+}
+
+// reportUnplaced records the tasks with the given placement needs which
+// could not be placed in the metrics, and logs them unless the tasks of
+// the same constraint shape were logged recently.
+func (e *engine) reportUnplaced(
+	needs plugins.PlacementNeeds,
+	unplaced []models.Task,
+	reason string) {
+	if len(unplaced) == 0 {
+		return
+	}
+
+	shape := constraintShape(needs)
+	e.metrics.TasksUnplaced(shape).Inc(int64(len(unplaced)))
+	e.metrics.TasksUnplacedCurrent(shape).Update(float64(len(unplaced)))
+
+	if !e.unplacedLogger.allow(shape, time.Now()) {
+		return
+	}
+	log.WithFields(log.Fields{
+		"shape":        shape,
+		"needs":        needs,
+		"reason":       reason,
+		"num_unplaced": len(unplaced),
+		"log_interval": e.unplacedLogger.interval,
+	}).Warn("Could not place tasks due to insufficient offers")
+}
+
+// constraintShape returns a coarse description of the placement needs,
+// which is used to tag the metrics of the unplaced tasks without
+// creating a metric for every resource configuration.
+func constraintShape(needs plugins.PlacementNeeds) string {
+	parts := []string{"non_revocable"}
+	if needs.Revocable {
+		parts[0] = "revocable"
+	}
+	if needs.Resources.GetGPU() > 0 {
+		parts = append(parts, "gpu")
+	}
+	if hasConstraint(needs.Constraint) {
+		parts = append(parts, "constrained")
+	}
+	if len(needs.HostHints) > 0 {
+		parts = append(parts, "host_hints")
+	}
+	return strings.Join(parts, "_")
+}
+
+// hasConstraint returns true if the constraint of placement needs is set,
+// the constraint can be a typed nil pointer.
+func hasConstraint(constraint interface{}) bool {
+	if constraint == nil {
+		return false
+	}
+	v := reflect.ValueOf(constraint)
+	return v.Kind() != reflect.Ptr || !v.IsNil()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/tasks"
+	"github.com/uber/peloton/pkg/placement/testutil"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEngineReportUnplacedTasks tests that the tasks left unplaced by a
+// failed placement round are counted by their constraint shape.
+func TestEngineReportUnplacedTasks(t *testing.T) {
+	ctrl, engine, mockOfferService, mockTaskService, _, scope := setupEngine(t)
+	defer ctrl.Finish()
+	engine.config.MaxPlacementDuration = time.Millisecond
+
+	deadline := time.Now().Add(time.Millisecond)
+	var assignments []models.Task
+	for i := 0; i < 3; i++ {
+		assignments = append(assignments, testutil.SetupAssignment(deadline, 1))
+	}
+
+	mockOfferService.EXPECT().
+		Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		MinTimes(1).
+		Return(nil, _testReason, nil)
+	mockTaskService.EXPECT().
+		SetPlacements(gomock.Any(), nil, assignments).
+		Return(tasks.SetPlacementsResult{})
+
+	needs := assignments[0].GetPlacementNeeds()
+	engine.placeAssignmentGroup(context.Background(), needs, assignments)
+
+	shape := constraintShape(needs)
+	assert.Equal(t, "non_revocable_gpu_constrained", shape)
+	key := "batch.task.unplaced+shape=" + shape
+	assert.Equal(t, int64(3), scope.Snapshot().Counters()[key].Value())
+	key = "batch.task.unplaced_current+shape=" + shape
+	assert.Equal(t, float64(3), scope.Snapshot().Gauges()[key].Value())
+}
+
+// TestUnplacedLoggerAllow tests that the unplaced tasks of a constraint
+// shape are logged at most once per interval.
+func TestUnplacedLoggerAllow(t *testing.T) {
+	logger := newUnplacedLogger(time.Minute)
+	now := time.Now()
+
+	assert.True(t, logger.allow("revocable", now))
+	assert.False(t, logger.allow("revocable", now.Add(time.Second)))
+	assert.True(t, logger.allow("non_revocable", now.Add(time.Second)))
+	assert.True(t, logger.allow("revocable", now.Add(time.Minute)))
+}
+
+// TestConstraintShape tests the constraint shape of placement needs.
+func TestConstraintShape(t *testing.T) {
+	assert.Equal(t, "non_revocable", constraintShape(plugins.PlacementNeeds{}))
+	assert.Equal(t, "revocable_gpu_host_hints", constraintShape(
+		plugins.PlacementNeeds{
+			Revocable: true,
+			Resources: scalar.Resources{GPU: 1},
+			HostHints: map[string]string{"task": "host"},
+		}))
+
+	var constraint *struct{}
+	assert.Equal(t, "non_revocable", constraintShape(
+		plugins.PlacementNeeds{Constraint: constraint}))
+	assert.Equal(t, "non_revocable_constrained", constraintShape(
+		plugins.PlacementNeeds{Constraint: &struct{}{}}))
+}
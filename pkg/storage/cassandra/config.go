@@ -49,4 +49,7 @@ type Config struct {
 	MaxUpdatesPerJob int `yaml:"max_updates_job"`
 	// Replication controls the replication config of the keyspace
 	Replication *Replication `yaml:"replication"`
+	// PodSpecReadRepair controls whether the pod spec of the task configs
+	// stored without one is synthesized and persisted when it is read
+	PodSpecReadRepair bool `yaml:"pod_spec_read_repair"`
 }
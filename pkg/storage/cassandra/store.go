@@ -28,6 +28,7 @@ import (
 	"strings"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/query"
@@ -43,6 +44,7 @@ import (
 	"github.com/uber/peloton/pkg/common"
 	apiconvertor "github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/backoff"
+	"github.com/uber/peloton/pkg/common/concurrency"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/cassandra/api"
@@ -104,6 +106,10 @@ const (
 	// to read if not provided for jobID + instanceID
 	_defaultPodEventsLimit = 100
 
+	// _podEventsDeleteBatchSize is the number of instances whose pod
+	// events are deleted concurrently.
+	_podEventsDeleteBatchSize = 50
+
 	// Default context timeout for the method to cleanup old
 	// job updates from the storage
 	_jobUpdatesCleanupTimeout = 120 * time.Second
@@ -158,7 +164,8 @@ func ToOrmConfig(c *Config) *ormcassandra.Config {
 			CQLVersion:         c.CassandraConn.CQLVersion,
 			MaxGoRoutines:      c.CassandraConn.MaxGoRoutines,
 		},
-		StoreName: c.StoreName,
+		StoreName:         c.StoreName,
+		PodSpecReadRepair: c.PodSpecReadRepair,
 	}
 }
 
@@ -961,11 +968,17 @@ func (s *Store) GetPodEvents(
 		podEvent.DesiredState = apiconvertor.ConvertTaskStateToPodState(
 			task.TaskState(task.TaskState_value[value["goal_state"].(string)])).String()
 
-		podEvent.Healthy = pod.HealthState(
-			task.HealthState_value[value["healthy"].(string)]).String()
-
 		podEvent.Message = value["message"].(string)
 		podEvent.Reason = value["reason"].(string)
+
+		// healthy is not set for events recorded before
+		// the health of the pod was tracked
+		if healthy, ok := value["healthy"].(string); ok && healthy != "" {
+			podEvent.Healthy = pod.HealthState(
+				task.HealthState_value[healthy]).String()
+			podEvent.Health = newPodEventHealthStatus(
+				healthy, podEvent.Reason, podEvent.Message)
+		}
 		podEvent.AgentId = value["agent_id"].(string)
 		podEvent.Hostname = value["hostname"].(string)
 
@@ -976,6 +989,23 @@ func (s *Store) GetPodEvents(
 	return podEvents, nil
 }
 
+// newPodEventHealthStatus returns the health status of a pod event. The
+// message of the event is the output of the health check if the event
+// was recorded due to a health check status update.
+func newPodEventHealthStatus(
+	healthy string,
+	reason string,
+	message string,
+) *pod.HealthStatus {
+	status := &pod.HealthStatus{
+		State: pod.HealthState(task.HealthState_value[healthy]),
+	}
+	if reason == mesos.TaskStatus_REASON_TASK_HEALTH_CHECK_STATUS_UPDATED.String() {
+		status.Output = message
+	}
+	return status
+}
+
 // DeletePodEvents deletes the pod events for provided JobID,
 // InstanceID and RunID in the range [fromRunID-toRunID)
 func (s *Store) DeletePodEvents(
@@ -1588,23 +1618,13 @@ func (s *Store) DeleteTaskRuntime(
 	return nil
 }
 
-// 1) Pod Events table has partition key job_id + instance_id,
-// so pod events need to be deleted per instance.
-// 2) Fetch instance count from job config, and delete pod events
-// incrementally for each Instance.
-// 3) There maybe a scenario, were instance count is shrunk, in order to delete
-// pod events for shrunk instances, first read pod event for shrunk instances,
-// if exist then delete. If result is zero, that means we have reached
-// maximum instance count ever for that job.
-// 4) Performance optimization for deleting shrunk instances,
-// read pod events for every - instance_id % 100 = 0
-// If pod event exist then continue to delete pod events for next 100 instances
-// If pod event not exist means pod events are deleted for all shrunk instances
+// deletePodEventsOnDeleteJob deletes the pod events of all the instances
+// of a job being deleted, except the events of the active run of each
+// instance. The instance count is read from the job config, and the active
+// runs from the task runtimes, which are deleted after the pod events.
 func (s *Store) deletePodEventsOnDeleteJob(
 	ctx context.Context,
 	jobID string) error {
-	queryBuilder := s.DataStore.NewQuery()
-	instanceCount := uint32(0)
 	jobConfig, _, err := s.jobConfigOps.GetCurrentVersion(
 		ctx,
 		&peloton.JobID{Value: jobID},
@@ -1617,32 +1637,103 @@ func (s *Store) deletePodEventsOnDeleteJob(
 		return err
 	}
 
-	for {
-		// 1) read pod events to identify shrunk instances
-		// 2) read pod events if instance_id (shrunk instances) % 100 = 0
-		if instanceCount > jobConfig.InstanceCount &&
-			instanceCount%_defaultPodEventsLimit == 0 {
-			events, err := s.GetPodEvents(
-				ctx,
-				jobID,
-				instanceCount)
+	activeRuns, err := s.getActiveRunIDs(ctx, jobID)
+	if err != nil {
+		s.metrics.JobMetrics.JobDeleteFail.Inc(1)
+		return err
+	}
+
+	if err := s.DeleteJobPodEvents(
+		ctx, jobID, jobConfig.GetInstanceCount(), activeRuns); err != nil {
+		s.metrics.JobMetrics.JobDeleteFail.Inc(1)
+		return err
+	}
+	return nil
+}
+
+// getActiveRunIDs returns the run ID of the active run of each instance
+// of a job which has been run, read from the task runtimes.
+func (s *Store) getActiveRunIDs(
+	ctx context.Context,
+	jobID string) (map[uint32]uint64, error) {
+	tasks, err := s.GetTasksForJob(ctx, &peloton.JobID{Value: jobID})
+	if err != nil {
+		return nil, err
+	}
+
+	activeRuns := make(map[uint32]uint64, len(tasks))
+	for instanceID, taskInfo := range tasks {
+		mesosTaskID := taskInfo.GetRuntime().GetMesosTaskId().GetValue()
+		if mesosTaskID == "" {
+			// the instance has never been run
+			continue
+		}
+		runID, err := util.ParseRunID(mesosTaskID)
+		if err != nil {
+			return nil, errors.Wrapf(err,
+				"fail to parse the active run ID of instance %d", instanceID)
+		}
+		activeRuns[instanceID] = runID
+	}
+	return activeRuns, nil
+}
+
+// DeleteJobPodEvents deletes the pod events of all the instances of a job,
+// except the events of the active runs, which maps instance IDs to the run
+// ID of their active run. All the events of the instances without an
+// active run are deleted.
+// 1) Pod Events table has partition key job_id + instance_id,
+// so pod events are deleted per instance, concurrently for
+// _podEventsDeleteBatchSize instances at a time.
+// 2) There maybe a scenario, were instance count is shrunk. Past the
+// instance count, the pod events of the first instance of each batch are
+// read, and if there are none, the maximum instance count ever for the job
+// has been reached.
+func (s *Store) DeleteJobPodEvents(
+	ctx context.Context,
+	jobID string,
+	instanceCount uint32,
+	activeRuns map[uint32]uint64) error {
+	queryBuilder := s.DataStore.NewQuery()
+	deleteInstance := func(
+		ctx context.Context,
+		input interface{}) (interface{}, error) {
+		return nil, s.applyStatement(ctx, input.(api.Statement), jobID)
+	}
+
+	for from := uint32(0); ; from += _podEventsDeleteBatchSize {
+		if from >= instanceCount {
+			events, err := s.GetPodEvents(ctx, jobID, from)
 			if err != nil {
-				s.metrics.JobMetrics.JobDeleteFail.Inc(1)
+				s.metrics.TaskMetrics.PodEventsDeleteFail.Inc(1)
 				return err
 			}
 			if len(events) == 0 {
 				break
 			}
 		}
-		stmt := queryBuilder.Delete(podEventsTable).
-			Where(qb.Eq{"job_id": jobID}).
-			Where(qb.Eq{"instance_id": instanceCount})
 
-		if err := s.applyStatement(ctx, stmt, jobID); err != nil {
-			s.metrics.JobMetrics.JobDeleteFail.Inc(1)
+		var stmts []interface{}
+		for instanceID := from; instanceID < from+_podEventsDeleteBatchSize; instanceID++ {
+			stmt := queryBuilder.Delete(podEventsTable).
+				Where(qb.Eq{"job_id": jobID}).
+				Where(qb.Eq{"instance_id": instanceID})
+			if runID := activeRuns[instanceID]; runID > 0 {
+				stmt = stmt.Where("run_id < ?", runID)
+			}
+			stmts = append(stmts, stmt)
+		}
+
+		if _, err := concurrency.Map(
+			ctx,
+			concurrency.MapperFunc(deleteInstance),
+			stmts,
+			_podEventsDeleteBatchSize,
+		); err != nil {
+			s.metrics.TaskMetrics.PodEventsDeleteFail.Inc(1)
 			return err
 		}
-		instanceCount++
+		s.metrics.TaskMetrics.PodEventsDeleteSucess.Inc(1)
 	}
 	return nil
 }
@@ -32,11 +32,13 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v0/update"
 	"github.com/uber/peloton/.gen/peloton/api/v0/volume"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
 	"github.com/uber/peloton/pkg/common/taskconfig"
+	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	qb "github.com/uber/peloton/pkg/storage/querybuilder"
@@ -3053,6 +3055,10 @@ func (suite *CassandraStoreTestSuite) TestGetPodEvent() {
 		"7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-2")
 	suite.Equal(len(podEvents), 1)
 	suite.NoError(err)
+	suite.Equal(
+		pod.HealthState_HEALTH_STATE_HEALTHY,
+		podEvents[0].GetHealth().GetState())
+	suite.Empty(podEvents[0].GetHealth().GetOutput())
 
 	mesosTaskID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-3"
 	prevMesosTaskID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-2"
@@ -3062,10 +3068,10 @@ func (suite *CassandraStoreTestSuite) TestGetPodEvent() {
 		CompletionTime: time.Now().String(),
 		State:          task.TaskState_RUNNING,
 		GoalState:      task.TaskState_SUCCEEDED,
-		Healthy:        task.HealthState_HEALTHY,
+		Healthy:        task.HealthState_UNHEALTHY,
 		Host:           "mesos-slave-01",
-		Message:        "",
-		Reason:         "",
+		Message:        "health check failed",
+		Reason:         mesos.TaskStatus_REASON_TASK_HEALTH_CHECK_STATUS_UPDATED.String(),
 		MesosTaskId: &mesos.TaskID{
 			Value: &mesosTaskID,
 		},
@@ -3094,6 +3100,13 @@ func (suite *CassandraStoreTestSuite) TestGetPodEvent() {
 		"7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-3")
 	suite.Equal(len(podEvents), 1)
 	suite.NoError(err)
+	suite.Equal(
+		pod.HealthState_HEALTH_STATE_UNHEALTHY.String(),
+		podEvents[0].GetHealthy())
+	suite.Equal(
+		pod.HealthState_HEALTH_STATE_UNHEALTHY,
+		podEvents[0].GetHealth().GetState())
+	suite.Equal("health check failed", podEvents[0].GetHealth().GetOutput())
 
 	err = store.DeletePodEvents(context.Background(), jobID.GetValue(), 0, 2, 3)
 	suite.NoError(err)
@@ -3106,6 +3119,120 @@ func (suite *CassandraStoreTestSuite) TestGetPodEvent() {
 	suite.NoError(err)
 }
 
+// TestDeleteJobPodEvents tests that the pod events of all instances of a
+// job are deleted, except the events of the active runs.
+func (suite *CassandraStoreTestSuite) TestDeleteJobPodEvents() {
+	ctx := context.Background()
+	jobID := &peloton.JobID{Value: uuid.New()}
+
+	// instance 3 was removed by shrinking the job to 3 instances
+	for instanceID := uint32(0); instanceID < 4; instanceID++ {
+		for runID := uint64(1); runID <= 2; runID++ {
+			suite.NoError(store.addPodEvent(ctx, jobID, instanceID,
+				&task.RuntimeInfo{
+					State:       task.TaskState_RUNNING,
+					GoalState:   task.TaskState_SUCCEEDED,
+					MesosTaskId: util.CreateMesosTaskID(jobID, instanceID, runID),
+					PrevMesosTaskId: util.CreateMesosTaskID(
+						jobID, instanceID, runID-1),
+					DesiredMesosTaskId: util.CreateMesosTaskID(
+						jobID, instanceID, runID),
+				}))
+		}
+	}
+
+	activeRuns := map[uint32]uint64{1: 2}
+	suite.NoError(store.DeleteJobPodEvents(ctx, jobID.GetValue(), 3, activeRuns))
+	// deleting again is a no-op
+	suite.NoError(store.DeleteJobPodEvents(ctx, jobID.GetValue(), 3, activeRuns))
+
+	for instanceID := uint32(0); instanceID < 4; instanceID++ {
+		for runID := uint64(1); runID <= 2; runID++ {
+			podEvents, err := store.GetPodEvents(
+				ctx,
+				jobID.GetValue(),
+				instanceID,
+				util.CreateMesosTaskID(jobID, instanceID, runID).GetValue())
+			suite.NoError(err)
+			if activeRuns[instanceID] == runID {
+				suite.Len(podEvents, 1)
+				continue
+			}
+			suite.Empty(podEvents)
+		}
+	}
+}
+
+// TestDeletePodEventsOnDeleteJob tests that deleting a job keeps the pod
+// events of the active run of each instance, read from the task runtimes.
+func (suite *CassandraStoreTestSuite) TestDeletePodEventsOnDeleteJob() {
+	ctx := context.Background()
+	jobID := &peloton.JobID{Value: uuid.New()}
+	jobConfig := buildJobConfig()
+	jobConfig.InstanceCount = 2
+	suite.NoError(suite.createJob(
+		ctx, jobID, jobConfig, &models.ConfigAddOn{}, "uber"))
+
+	for instanceID := uint32(0); instanceID < 2; instanceID++ {
+		for runID := uint64(1); runID <= 2; runID++ {
+			suite.NoError(store.addPodEvent(ctx, jobID, instanceID,
+				&task.RuntimeInfo{
+					State:       task.TaskState_RUNNING,
+					GoalState:   task.TaskState_SUCCEEDED,
+					MesosTaskId: util.CreateMesosTaskID(jobID, instanceID, runID),
+					PrevMesosTaskId: util.CreateMesosTaskID(
+						jobID, instanceID, runID-1),
+					DesiredMesosTaskId: util.CreateMesosTaskID(
+						jobID, instanceID, runID),
+				}))
+		}
+	}
+
+	// only instance 0 is running, in its second run
+	taskInfo := createTaskInfo(jobConfig, jobID, 0)
+	taskInfo.Runtime.MesosTaskId = util.CreateMesosTaskID(jobID, 0, 2)
+	suite.NoError(store.CreateTaskRuntime(
+		ctx, jobID, 0, taskInfo.Runtime, "test", jobConfig.GetType()))
+
+	suite.NoError(store.deletePodEventsOnDeleteJob(ctx, jobID.GetValue()))
+
+	for instanceID := uint32(0); instanceID < 2; instanceID++ {
+		for runID := uint64(1); runID <= 2; runID++ {
+			podEvents, err := store.GetPodEvents(
+				ctx,
+				jobID.GetValue(),
+				instanceID,
+				util.CreateMesosTaskID(jobID, instanceID, runID).GetValue())
+			suite.NoError(err)
+			if instanceID == 0 && runID == 2 {
+				suite.Len(podEvents, 1)
+				continue
+			}
+			suite.Empty(podEvents)
+		}
+	}
+
+	suite.NoError(store.DeleteJob(ctx, jobID.GetValue()))
+}
+
+// TestNewPodEventHealthStatus tests that the health check output is only
+// set for events recorded due to a health check status update
+func TestNewPodEventHealthStatus(t *testing.T) {
+	status := newPodEventHealthStatus(
+		task.HealthState_HEALTHY.String(),
+		mesos.TaskStatus_REASON_TASK_HEALTH_CHECK_STATUS_UPDATED.String(),
+		"health check passed")
+	assert.Equal(t, pod.HealthState_HEALTH_STATE_HEALTHY, status.GetState())
+	assert.Equal(t, "health check passed", status.GetOutput())
+
+	status = newPodEventHealthStatus(
+		task.HealthState_HEALTHY.String(),
+		mesos.TaskStatus_REASON_TASK_KILLED_DURING_LAUNCH.String(),
+		"task killed")
+	assert.Equal(t, pod.HealthState_HEALTH_STATE_HEALTHY, status.GetState())
+	assert.Empty(t, status.GetOutput())
+}
+
 func TestLess(t *testing.T) {
 	// testing sort by state
 	stateOrder := query.OrderBy{
@@ -38,13 +38,14 @@ const (
 
 const (
 	// operation tags for metrics
-	create  = "create"
-	cas     = "cas"
-	get     = "get"
-	getAll  = "get_all"
-	getIter = "get_iter"
-	update  = "update"
-	del     = "delete"
+	create      = "create"
+	createBatch = "create_batch"
+	cas         = "cas"
+	get         = "get"
+	getAll      = "get_all"
+	getIter     = "get_iter"
+	update      = "update"
+	del         = "delete"
 
 	// default limit for select statements.
 	_defaultQueryLimit = 1
@@ -322,6 +323,46 @@ func (c *cassandraConnector) create(
 	return nil
 }
 
+// CreateBatch creates new rows in DB in a single logged batch.
+func (c *cassandraConnector) CreateBatch(
+	ctx context.Context,
+	e *base.Definition,
+	rows [][]base.Column,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	batch := c.Session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for _, row := range rows {
+		// split row into a list of names and values to compose query stmt
+		// using names and use values in the batch query call, so the order
+		// needs to be maintained.
+		colNames, colValues := splitColumnNameValue(row)
+
+		// Prepare insert statement
+		stmt, err := InsertStmt(
+			Table(e.Name),
+			Columns(colNames),
+			Values(colValues),
+			IfNotExist(!useCasWrite),
+		)
+		if err != nil {
+			return err
+		}
+		batch.Query(stmt, colValues...)
+	}
+
+	if err := c.Session.ExecuteBatch(batch); err != nil {
+		sendCounters(c.executeFailScope, e.Name, createBatch, err)
+		return err
+	}
+
+	sendLatency(c.scope, e.Name, createBatch, time.Duration(batch.Latency()))
+	sendCounters(c.executeSuccessScope, e.Name, createBatch, nil)
+	return nil
+}
+
 // buildSelectQuery builds a select query using base object and key columns.
 // If limit is non-zero, it will be enforced in the select query.
 // If limit is 0, the select query will fetch all rows that match.
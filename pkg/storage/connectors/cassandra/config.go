@@ -46,4 +46,7 @@ type Config struct {
 	CassandraConn *CassandraConn `yaml:"connection"`
 	StoreName     string         `yaml:"store_name"`
 	Migrations    string         `yaml:"migrations"`
+	// PodSpecReadRepair controls whether the pod spec of the task configs
+	// stored without one is synthesized and persisted when it is read
+	PodSpecReadRepair bool `yaml:"pod_spec_read_repair"`
 }
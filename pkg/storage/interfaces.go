@@ -103,6 +103,8 @@ type TaskStore interface {
 	DeleteTaskRuntime(ctx context.Context, id *peloton.JobID, instanceID uint32) error
 	// DeletePodEvents deletes the pod events for provided JobID, InstanceID and RunID in the range [fromRunID-toRunID)
 	DeletePodEvents(ctx context.Context, jobID string, instanceID uint32, fromRunID uint64, toRunID uint64) error
+	// DeleteJobPodEvents deletes the pod events of all instances of a job, except the events of the given active run of each instance
+	DeleteJobPodEvents(ctx context.Context, jobID string, instanceCount uint32, activeRuns map[uint32]uint64) error
 	// GetPodEvents returns pod events for a Job + Instance + PodID (optional), events are sorted descending timestamp order
 	GetPodEvents(ctx context.Context, jobID string, instanceID uint32, podID ...string) ([]*pod.PodEvent, error)
 }
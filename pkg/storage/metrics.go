@@ -304,17 +304,31 @@ type OrmTaskMetrics struct {
 	PodEventsGet     tally.Counter
 	PodEventsGetFail tally.Counter
 
-	TaskConfigV2Create     tally.Counter
-	TaskConfigV2CreateFail tally.Counter
+	TaskConfigV2Create         tally.Counter
+	TaskConfigV2CreateFail     tally.Counter
+	TaskConfigV2CreateDuration tally.Timer
 
 	TaskConfigV2Get     tally.Counter
 	TaskConfigV2GetFail tally.Counter
 
+	TaskConfigV2Copy     tally.Counter
+	TaskConfigV2CopyFail tally.Counter
+
+	TaskConfigV2CreateBatch         tally.Counter
+	TaskConfigV2CreateBatchFail     tally.Counter
+	TaskConfigV2CreateBatchDuration tally.Timer
+
 	TaskConfigLegacyGet     tally.Counter
 	TaskConfigLegacyGetFail tally.Counter
 
-	PodSpecGet     tally.Counter
-	PodSpecGetFail tally.Counter
+	PodSpecGet         tally.Counter
+	PodSpecGetFail     tally.Counter
+	PodSpecGetDuration tally.Timer
+
+	// PodSpecGetNil counts the reads of task configs stored without a
+	// pod spec, and PodSpecGetNotFound the reads of missing task configs.
+	PodSpecGetNil      tally.Counter
+	PodSpecGetNotFound tally.Counter
 }
 
 // OrmHostInfoMetrics tracks counters for host info related table
@@ -720,17 +734,28 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		PodEventsGet:     podEventsSuccessScope.Counter("get"),
 		PodEventsGetFail: podEventsFailScope.Counter("get"),
 
-		TaskConfigV2Create:     taskConfigV2SuccessScope.Counter("create"),
-		TaskConfigV2CreateFail: taskConfigV2FailScope.Counter("create"),
+		TaskConfigV2Create:         taskConfigV2SuccessScope.Counter("create"),
+		TaskConfigV2CreateFail:     taskConfigV2FailScope.Counter("create"),
+		TaskConfigV2CreateDuration: taskConfigV2Scope.Timer("create_duration"),
 
 		TaskConfigV2Get:     taskConfigV2SuccessScope.Counter("get"),
 		TaskConfigV2GetFail: taskConfigV2FailScope.Counter("get"),
 
+		TaskConfigV2Copy:     taskConfigV2SuccessScope.Counter("copy"),
+		TaskConfigV2CopyFail: taskConfigV2FailScope.Counter("copy"),
+
+		TaskConfigV2CreateBatch:         taskConfigV2SuccessScope.Counter("create_batch"),
+		TaskConfigV2CreateBatchFail:     taskConfigV2FailScope.Counter("create_batch"),
+		TaskConfigV2CreateBatchDuration: taskConfigV2Scope.Timer("create_batch_duration"),
+
 		TaskConfigLegacyGet:     taskConfigV2SuccessScope.Counter("get_legacy"),
 		TaskConfigLegacyGetFail: taskConfigV2FailScope.Counter("get_legacy"),
 
-		PodSpecGet:     podSpecSuccessScope.Counter("get"),
-		PodSpecGetFail: podSpecFailScope.Counter("get"),
+		PodSpecGet:         podSpecSuccessScope.Counter("get"),
+		PodSpecGetFail:     podSpecFailScope.Counter("get"),
+		PodSpecGetDuration: podSpecScope.Timer("get_pod_spec_duration"),
+		PodSpecGetNil:      podSpecScope.Counter("get_nil_pod_spec"),
+		PodSpecGetNotFound: podSpecScope.Counter("get_pod_spec_not_found"),
 	}
 
 	ormHostInfoMetrics := &OrmHostInfoMetrics{
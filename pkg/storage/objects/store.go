@@ -40,6 +40,10 @@ var Objs []base.Object
 type Store struct {
 	oClient orm.Client
 	metrics *pelotonstore.Metrics
+
+	// taskConfigV2Options are applied to all the TaskConfigV2Ops
+	// constructed for the store
+	taskConfigV2Options []TaskConfigV2Option
 }
 
 // NewCassandraStore creates a new Cassandra storage client
@@ -57,10 +61,15 @@ func NewCassandraStore(
 	if err != nil {
 		return nil, err
 	}
-	return &Store{
+	store := &Store{
 		oClient: oclient,
 		metrics: pelotonstore.NewMetrics(scope),
-	}, nil
+	}
+	if config.PodSpecReadRepair {
+		store.taskConfigV2Options = append(
+			store.taskConfigV2Options, WithPodSpecReadRepair())
+	}
+	return store, nil
 }
 
 // GenerateTestCassandraConfig generates a test config for local C* client
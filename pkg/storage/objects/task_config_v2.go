@@ -16,6 +16,7 @@ package objects
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -24,6 +25,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/storage/objects/base"
 
 	"github.com/gogo/protobuf/proto"
@@ -57,6 +59,9 @@ type TaskConfigV2Object struct {
 	Spec []byte `column:"name=spec"`
 	// APIVersion of the task config
 	APIVersion string `column:"name=api_version"`
+	// IsOverride is true if the task config only overrides some fields
+	// of the default task config
+	IsOverride bool `column:"name=is_override"`
 }
 
 // transform will convert all the value from DB into the corresponding type
@@ -70,7 +75,7 @@ func (o *TaskConfigV2Object) transform(row map[string]interface{}) {
 	o.CreationTime = row["creation_time"].(time.Time)
 	o.Spec = row["spec"].([]byte)
 	o.APIVersion = row["api_version"].(string)
-
+	o.IsOverride, _ = row["is_override"].(bool)
 }
 
 // TaskConfigObject corresponds to a row in task_config table. This is a legacy
@@ -105,8 +110,22 @@ const (
 	specColumn        = "spec"
 	configColumn      = "config"
 	configAddOnColumn = "config_addon"
+	overrideColumn    = "is_override"
 )
 
+// _createBatchSize is the number of instances whose task configs are
+// created in a single batch by CreateBatch, which keeps a batch within the
+// maximum batch size of the data store.
+const _createBatchSize = 50
+
+// InstanceConfig is the task config, config addon and pod spec of an
+// instance created by TaskConfigV2Ops.CreateBatch. The pod spec may be nil.
+type InstanceConfig struct {
+	TaskConfig  *pbtask.TaskConfig
+	ConfigAddOn *models.ConfigAddOn
+	PodSpec     *pbpod.PodSpec
+}
+
 // TaskConfigV2Ops provides methods for manipulating task_config_v2 table.
 type TaskConfigV2Ops interface {
 	// Create creates task config with version number for a task
@@ -120,6 +139,27 @@ type TaskConfigV2Ops interface {
 		version uint64,
 	) error
 
+	// CreateBatch creates the task configs with version number for the
+	// given instances in batches of bounded size
+	CreateBatch(
+		ctx context.Context,
+		id *peloton.JobID,
+		configs map[uint32]*InstanceConfig,
+		version uint64,
+	) error
+
+	// CreateInstanceOverride creates a task config with version number for
+	// a task, which only overrides the fields of the default task config
+	// and pod spec set in the given config and spec
+	CreateInstanceOverride(
+		ctx context.Context,
+		id *peloton.JobID,
+		instanceID uint32,
+		taskConfig *pbtask.TaskConfig,
+		podSpec *pbpod.PodSpec,
+		version uint64,
+	) error
+
 	// GetPodSpec returns the pod spec of a task config
 	GetPodSpec(
 		ctx context.Context,
@@ -135,6 +175,27 @@ type TaskConfigV2Ops interface {
 		instanceID uint32,
 		version uint64,
 	) (*pbtask.TaskConfig, *models.ConfigAddOn, error)
+
+	// GetConfig returns the task specific config, config addon and
+	// pod spec of a task config, read together as they were created
+	GetConfig(
+		ctx context.Context,
+		id *peloton.JobID,
+		instanceID uint32,
+		version uint64,
+	) (*pbtask.TaskConfig, *models.ConfigAddOn, *pbpod.PodSpec, error)
+
+	// CopyConfigVersion copies the stored task configs of the given
+	// instances, along with the default task config, from one config
+	// version to another. Instances which do not have a task config at
+	// the source version are skipped.
+	CopyConfigVersion(
+		ctx context.Context,
+		id *peloton.JobID,
+		fromVersion uint64,
+		toVersion uint64,
+		instances []uint32,
+	) error
 }
 
 // ensure that default implementation (taskConfigV2Object) satisfies the interface
@@ -143,11 +204,40 @@ var _ TaskConfigV2Ops = (*taskConfigV2Object)(nil)
 // taskConfigV2Object implements TaskConfigV2Ops using a particular Store
 type taskConfigV2Object struct {
 	store *Store
+
+	// podSpecReadRepair is true if the pod spec of a task config stored
+	// without one is synthesized from its task config on read
+	podSpecReadRepair bool
+}
+
+// TaskConfigV2Option configures the TaskConfigV2Ops constructed by
+// NewTaskConfigV2Ops.
+type TaskConfigV2Option func(*taskConfigV2Object)
+
+// WithPodSpecReadRepair enables the read-repair of the task configs which
+// are stored without a pod spec. GetPodSpec synthesizes the pod spec of
+// such a task config from its task config, and persists it so that
+// subsequent reads are consistent.
+func WithPodSpecReadRepair() TaskConfigV2Option {
+	return func(d *taskConfigV2Object) {
+		d.podSpecReadRepair = true
+	}
 }
 
 // NewTaskConfigV2Ops constructs a TaskConfigV2Ops object for provided Store.
-func NewTaskConfigV2Ops(s *Store) TaskConfigV2Ops {
-	return &taskConfigV2Object{store: s}
+// The options configured for the Store are applied before the given ones.
+func NewTaskConfigV2Ops(
+	s *Store,
+	opts ...TaskConfigV2Option,
+) TaskConfigV2Ops {
+	d := &taskConfigV2Object{store: s}
+	for _, opt := range s.taskConfigV2Options {
+		opt(d)
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // Create creates task config with version number for a task
@@ -160,7 +250,10 @@ func (d *taskConfigV2Object) Create(
 	podSpec *pbpod.PodSpec,
 	version uint64,
 ) (err error) {
+	callStart := time.Now()
 	defer func() {
+		d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateDuration.Record(
+			time.Since(callStart))
 		if err != nil {
 			d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateFail.Inc(1)
 		} else {
@@ -168,15 +261,97 @@ func (d *taskConfigV2Object) Create(
 		}
 	}()
 
+	obj, err := newConfigObject(
+		id, instanceID, taskConfig, configAddOn, podSpec, version)
+	if err != nil {
+		return err
+	}
+
+	return d.store.oClient.Create(ctx, obj)
+}
+
+// CreateBatch creates the task configs with version number for the given
+// instances in batches of at most _createBatchSize instances, so that the
+// task configs of a job are created in a few round-trips. The task configs
+// of a batch are created together, but a batch failing does not undo the
+// batches created before it. Every instance is its own partition, so a
+// batch is a multi-partition batch which may exceed the batch size limit
+// of the data store for large task configs, in which case creating them
+// concurrently with Create is preferable.
+func (d *taskConfigV2Object) CreateBatch(
+	ctx context.Context,
+	id *peloton.JobID,
+	configs map[uint32]*InstanceConfig,
+	version uint64,
+) (err error) {
+	callStart := time.Now()
+	defer func() {
+		d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateBatchDuration.Record(
+			time.Since(callStart))
+		if err != nil {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateBatchFail.Inc(1)
+		} else {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateBatch.Inc(1)
+		}
+	}()
+
+	instanceIDs := make([]uint32, 0, len(configs))
+	for instanceID := range configs {
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	sort.Slice(instanceIDs, func(i, j int) bool {
+		return instanceIDs[i] < instanceIDs[j]
+	})
+
+	for from := 0; from < len(instanceIDs); from += _createBatchSize {
+		to := from + _createBatchSize
+		if to > len(instanceIDs) {
+			to = len(instanceIDs)
+		}
+
+		objs := make([]base.Object, 0, to-from)
+		for _, instanceID := range instanceIDs[from:to] {
+			config := configs[instanceID]
+			obj, err := newConfigObject(
+				id,
+				int64(instanceID),
+				config.TaskConfig,
+				config.ConfigAddOn,
+				config.PodSpec,
+				version,
+			)
+			if err != nil {
+				return err
+			}
+			objs = append(objs, obj)
+		}
+
+		if err := d.store.oClient.CreateBatch(ctx, objs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newConfigObject builds the task_config_v2 row of a task config. A task
+// config without pod spec is stored with the V0 api version.
+func newConfigObject(
+	id *peloton.JobID,
+	instanceID int64,
+	taskConfig *pbtask.TaskConfig,
+	configAddOn *models.ConfigAddOn,
+	podSpec *pbpod.PodSpec,
+	version uint64,
+) (*TaskConfigV2Object, error) {
 	configBuffer, err := proto.Marshal(taskConfig)
 	if err != nil {
-		return errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
+		return nil, errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
 			"fail to unmarshal task config")
 	}
 
 	addOnBuffer, err := proto.Marshal(configAddOn)
 	if err != nil {
-		return errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
+		return nil, errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
 			"fail to unmarshal config addon")
 	}
 
@@ -185,13 +360,13 @@ func (d *taskConfigV2Object) Create(
 	if podSpec != nil {
 		specBuffer, err = proto.Marshal(podSpec)
 		if err != nil {
-			return errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
+			return nil, errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
 				"fail to unmarshal pod spec")
 		}
 		apiVersion = api.V1
 	}
 
-	obj := &TaskConfigV2Object{
+	return &TaskConfigV2Object{
 		JobID:        id.GetValue(),
 		Version:      version,
 		InstanceID:   instanceID,
@@ -200,6 +375,64 @@ func (d *taskConfigV2Object) Create(
 		CreationTime: time.Now(),
 		Spec:         specBuffer,
 		APIVersion:   apiVersion.String(),
+	}, nil
+}
+
+// CreateInstanceOverride creates a task config with version number for
+// a task, which only overrides the fields of the default task config and
+// pod spec set in the given config and spec. The config addon of the
+// default task config is used for the task.
+func (d *taskConfigV2Object) CreateInstanceOverride(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID uint32,
+	taskConfig *pbtask.TaskConfig,
+	podSpec *pbpod.PodSpec,
+	version uint64,
+) (err error) {
+	callStart := time.Now()
+	defer func() {
+		d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateDuration.Record(
+			time.Since(callStart))
+		if err != nil {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2CreateFail.Inc(1)
+		} else {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2Create.Inc(1)
+		}
+	}()
+
+	if taskConfig == nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"task config override is not set")
+	}
+
+	configBuffer, err := proto.Marshal(taskConfig)
+	if err != nil {
+		return errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
+			"fail to unmarshal task config")
+	}
+
+	var specBuffer []byte
+	apiVersion := api.V0
+	if podSpec != nil {
+		specBuffer, err = proto.Marshal(podSpec)
+		if err != nil {
+			return errors.Wrap(yarpcerrors.InvalidArgumentErrorf(err.Error()),
+				"fail to unmarshal pod spec")
+		}
+		apiVersion = api.V1
+	}
+
+	obj := &TaskConfigV2Object{
+		JobID:        id.GetValue(),
+		Version:      version,
+		InstanceID:   int64(instanceID),
+		Config:       configBuffer,
+		ConfigAddOn:  []byte{},
+		CreationTime: time.Now(),
+		Spec:         specBuffer,
+		APIVersion:   apiVersion.String(),
+		IsOverride:   true,
 	}
 
 	return d.store.oClient.Create(ctx, obj)
@@ -212,7 +445,10 @@ func (d *taskConfigV2Object) GetPodSpec(
 	instanceID uint32,
 	version uint64,
 ) (result *pbpod.PodSpec, err error) {
+	callStart := time.Now()
 	defer func() {
+		d.store.metrics.OrmTaskMetrics.PodSpecGetDuration.Record(
+			time.Since(callStart))
 		if err != nil {
 			d.store.metrics.OrmTaskMetrics.PodSpecGetFail.Inc(1)
 		} else {
@@ -226,10 +462,20 @@ func (d *taskConfigV2Object) GetPodSpec(
 		Version:    version,
 	}
 
-	row, err := d.store.oClient.Get(ctx, obj, specColumn)
+	row, err := d.store.oClient.Get(ctx, obj, specColumn, overrideColumn)
 	if err != nil {
 		return nil, err
 	}
+
+	// per-instance spec which only overrides the default spec
+	var override *pbpod.PodSpec
+	if isOverride(row) {
+		if override, err = unmarshalPodSpec(row); err != nil {
+			return nil, err
+		}
+		row = nil
+	}
+
 	if len(row) == 0 {
 		// per-instance spec not found, return default spec in this case.
 		obj.InstanceID = common.DefaultTaskConfigID
@@ -240,21 +486,72 @@ func (d *taskConfigV2Object) GetPodSpec(
 	}
 
 	if len(row) == 0 {
+		d.store.metrics.OrmTaskMetrics.PodSpecGetNotFound.Inc(1)
 		return nil, yarpcerrors.NotFoundErrorf("pod spec " +
 			"not found")
 	}
-	obj.Spec = row["spec"].([]byte)
-	// no spec set, return nil
-	if len(obj.Spec) == 0 {
-		return nil, nil
+	podSpec, err := unmarshalPodSpec(row)
+	if err != nil {
+		return nil, err
+	}
+	if podSpec == nil {
+		d.store.metrics.OrmTaskMetrics.PodSpecGetNil.Inc(1)
 	}
 
-	podSpec := &pbpod.PodSpec{}
-	if err := proto.Unmarshal(obj.Spec, podSpec); err != nil {
+	// an instance override only holds part of the task config, so the
+	// pod spec is repaired only for complete task configs
+	if podSpec == nil && override == nil && d.podSpecReadRepair {
+		if podSpec, err = d.repairPodSpec(
+			ctx, id, obj.InstanceID, version); err != nil {
+			return nil, err
+		}
+	}
+
+	return taskconfig.MergePodSpec(podSpec, override), nil
+}
+
+// repairPodSpec synthesizes the pod spec of a task config stored without
+// one from its task config, and writes it back to task_config_v2 table.
+// It returns the stored pod spec if the task config already has one, and
+// nil if the task config is not found.
+func (d *taskConfigV2Object) repairPodSpec(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID int64,
+	version uint64,
+) (*pbpod.PodSpec, error) {
+	obj, err := d.getConfigObject(ctx, id, instanceID, version)
+	if err != nil || obj == nil || obj.IsOverride {
+		return nil, err
+	}
+
+	taskConfig, _, podSpec, err := unmarshalConfigObject(obj)
+	if err != nil || podSpec != nil {
+		return podSpec, err
+	}
+
+	// the pod spec is synthesized the same way as the spec of a job
+	// created with v0 API, without the pod name
+	podSpec = api.ConvertTaskConfigToPodSpec(taskConfig, "", 0)
+	specBuffer, err := proto.Marshal(podSpec)
+	if err != nil {
 		return nil, errors.Wrap(yarpcerrors.InternalErrorf(err.Error()),
-			"Failed to unmarshal pod spec")
+			"fail to marshal pod spec")
 	}
 
+	obj.Spec = specBuffer
+	obj.APIVersion = api.V1.String()
+	if err := d.store.oClient.Update(
+		ctx, obj, "Spec", "APIVersion"); err != nil {
+		// Do not fail read path because write path failed.
+		log.WithError(err).
+			WithFields(log.Fields{
+				"job_id":      id.GetValue(),
+				"instance_id": instanceID,
+				"version":     version,
+			}).
+			Info("pod spec read repair failed")
+	}
 	return podSpec, nil
 }
 
@@ -287,6 +584,217 @@ func (d *taskConfigV2Object) GetTaskConfig(
 	return taskConfig, configAddOn, err
 }
 
+// GetConfig returns the task specific config, config addon and pod spec
+// of a task config
+func (d *taskConfigV2Object) GetConfig(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID uint32,
+	version uint64,
+) (
+	taskConfig *pbtask.TaskConfig,
+	configAddOn *models.ConfigAddOn,
+	podSpec *pbpod.PodSpec,
+	err error,
+) {
+	defer func() {
+		if err != nil {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2GetFail.Inc(1)
+		} else {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2Get.Inc(1)
+		}
+	}()
+
+	obj, err := d.getConfigObject(ctx, id, int64(instanceID), version)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// instance config which only overrides the default config
+	var override *TaskConfigV2Object
+	if obj != nil && obj.IsOverride {
+		override, obj = obj, nil
+	}
+
+	// no instance config, use default config
+	if obj == nil {
+		obj, err = d.getConfigObject(
+			ctx, id, common.DefaultTaskConfigID, version)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// config not present in task_config_v2, read config from legacy
+	// task_config table, which does not have pod spec
+	if obj == nil {
+		taskConfig, configAddOn, err = d.GetTaskConfig(
+			ctx, id, instanceID, version)
+		return taskConfig, configAddOn, nil, err
+	}
+
+	taskConfig, configAddOn, podSpec, err = unmarshalConfigObject(obj)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if override != nil {
+		overrideConfig, _, overrideSpec, err := unmarshalConfigObject(override)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		taskConfig = taskconfig.Merge(taskConfig, overrideConfig)
+		podSpec = taskconfig.MergePodSpec(podSpec, overrideSpec)
+	}
+
+	return taskConfig, configAddOn, podSpec, nil
+}
+
+// CopyConfigVersion copies the stored task configs of the given instances
+// from one config version to another. The default task config is always
+// copied, since instances without their own config and instance overrides
+// read it at the same version. The config, config addon and pod spec are
+// copied as stored, without unmarshalling them. Instances which do not
+// have a task config at the source version are skipped.
+func (d *taskConfigV2Object) CopyConfigVersion(
+	ctx context.Context,
+	id *peloton.JobID,
+	fromVersion uint64,
+	toVersion uint64,
+	instances []uint32,
+) (err error) {
+	defer func() {
+		if err != nil {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2CopyFail.Inc(1)
+		} else {
+			d.store.metrics.OrmTaskMetrics.TaskConfigV2Copy.Inc(1)
+		}
+	}()
+
+	instanceIDs := []int64{common.DefaultTaskConfigID}
+	for _, instanceID := range instances {
+		instanceIDs = append(instanceIDs, int64(instanceID))
+	}
+
+	for _, instanceID := range instanceIDs {
+		obj, err := d.getConfigObject(ctx, id, instanceID, fromVersion)
+		if err != nil {
+			return err
+		}
+
+		// instance has no config at the source version
+		if obj == nil {
+			continue
+		}
+
+		apiVersion := api.V0
+		if len(obj.Spec) != 0 {
+			apiVersion = api.V1
+		}
+
+		obj.Version = toVersion
+		obj.CreationTime = time.Now()
+		obj.APIVersion = apiVersion.String()
+		if err := d.store.oClient.Create(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalConfigObject unmarshals config, config addon and pod spec of
+// a task config read from task_config_v2 table. The pod spec is nil if it
+// is not set.
+func unmarshalConfigObject(obj *TaskConfigV2Object) (
+	*pbtask.TaskConfig,
+	*models.ConfigAddOn,
+	*pbpod.PodSpec,
+	error,
+) {
+	taskConfig := &pbtask.TaskConfig{}
+	if err := proto.Unmarshal(obj.Config, taskConfig); err != nil {
+		return nil, nil, nil, errors.Wrap(yarpcerrors.InternalErrorf(err.Error()),
+			"Failed to unmarshal task config")
+	}
+
+	configAddOn := &models.ConfigAddOn{}
+	if err := proto.Unmarshal(obj.ConfigAddOn, configAddOn); err != nil {
+		return nil, nil, nil, errors.Wrap(yarpcerrors.InternalErrorf(err.Error()),
+			"Failed to unmarshal config addOn")
+	}
+
+	// no spec set, return nil pod spec
+	if len(obj.Spec) == 0 {
+		return taskConfig, configAddOn, nil, nil
+	}
+
+	podSpec := &pbpod.PodSpec{}
+	if err := proto.Unmarshal(obj.Spec, podSpec); err != nil {
+		return nil, nil, nil, errors.Wrap(yarpcerrors.InternalErrorf(err.Error()),
+			"Failed to unmarshal pod spec")
+	}
+
+	return taskConfig, configAddOn, podSpec, nil
+}
+
+// unmarshalPodSpec unmarshals the pod spec of a row read from
+// task_config_v2 table. It returns nil if no spec is set.
+func unmarshalPodSpec(row map[string]interface{}) (*pbpod.PodSpec, error) {
+	spec, _ := row["spec"].([]byte)
+	if len(spec) == 0 {
+		return nil, nil
+	}
+
+	podSpec := &pbpod.PodSpec{}
+	if err := proto.Unmarshal(spec, podSpec); err != nil {
+		return nil, errors.Wrap(yarpcerrors.InternalErrorf(err.Error()),
+			"Failed to unmarshal pod spec")
+	}
+	return podSpec, nil
+}
+
+// isOverride returns true if a row read from task_config_v2 table only
+// overrides the default task config.
+func isOverride(row map[string]interface{}) bool {
+	override, _ := row["is_override"].(bool)
+	return override
+}
+
+// getConfigObject reads config, config addon and pod spec of a task config
+// from task_config_v2 table. It returns nil if the task config is not found.
+func (d *taskConfigV2Object) getConfigObject(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID int64,
+	version uint64,
+) (*TaskConfigV2Object, error) {
+	obj := &TaskConfigV2Object{
+		JobID:      id.GetValue(),
+		InstanceID: instanceID,
+		Version:    version,
+	}
+
+	row, err := d.store.oClient.Get(
+		ctx, obj, configColumn, configAddOnColumn, specColumn, overrideColumn)
+	if err != nil {
+		if yarpcerrors.IsNotFound(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(row) == 0 || row["config"] == nil {
+		return nil, nil
+	}
+
+	obj.Config = row["config"].([]byte)
+	obj.ConfigAddOn = row["config_addon"].([]byte)
+	if spec, ok := row["spec"].([]byte); ok {
+		obj.Spec = spec
+	}
+	obj.IsOverride = isOverride(row)
+	return obj, nil
+}
+
 // getTaskConfig returns config of specific version,
 // different from GetTaskConfig it does not which version
 // number is default config version and which is instance
@@ -304,7 +812,7 @@ func (d *taskConfigV2Object) getTaskConfig(
 	}
 
 	row, err := d.store.oClient.Get(
-		ctx, obj, configColumn, configAddOnColumn)
+		ctx, obj, configColumn, configAddOnColumn, overrideColumn)
 	if err != nil {
 		if yarpcerrors.IsNotFound(errors.Cause(err)) {
 			return d.handleLegacyConfig(ctx, id, instanceID, version)
@@ -329,6 +837,17 @@ func (d *taskConfigV2Object) getTaskConfig(
 			"Failed to unmarshal task config")
 	}
 
+	// the config only overrides the default config, which provides
+	// the config addon
+	if isOverride(row) {
+		defaultConfig, configAddOn, err := d.getTaskConfig(
+			ctx, id, common.DefaultTaskConfigID, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		return taskconfig.Merge(defaultConfig, taskConfig), configAddOn, nil
+	}
+
 	configAddOn := &models.ConfigAddOn{}
 	if err := proto.Unmarshal(obj.ConfigAddOn, configAddOn); err != nil {
 		return nil, nil, errors.Wrap(yarpcerrors.InternalErrorf(err.Error()),
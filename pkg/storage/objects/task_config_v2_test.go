@@ -16,6 +16,7 @@ package objects
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -25,10 +26,12 @@ import (
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/api"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 )
 
 type TaskConfigV2ObjectTestSuite struct {
@@ -140,6 +143,78 @@ func (s *TaskConfigV2ObjectTestSuite) TestCreateGetPodSpec() {
 
 }
 
+// TestGetPodSpecReadRepair tests that a task config stored without pod
+// spec is repaired to a pod spec synthesized from its task config on read.
+func (s *TaskConfigV2ObjectTestSuite) TestGetPodSpecReadRepair() {
+	var configVersion uint64 = 1
+	var instance0 int64 = 0
+
+	db := NewTaskConfigV2Ops(testStore)
+	repairDB := NewTaskConfigV2Ops(testStore, WithPodSpecReadRepair())
+	ctx := context.Background()
+
+	taskConfig := &pbtask.TaskConfig{
+		Name: "test-task",
+		Resource: &pbtask.ResourceConfig{
+			CpuLimit:    0.8,
+			MemLimitMb:  800,
+			DiskLimitMb: 1500,
+		},
+	}
+
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		instance0,
+		taskConfig,
+		&models.ConfigAddOn{},
+		nil,
+		configVersion,
+	))
+
+	// the read-repair is off by default
+	spec, err := db.GetPodSpec(ctx, s.jobID, uint32(instance0), configVersion)
+	s.NoError(err)
+	s.Nil(spec)
+
+	expectedSpec := api.ConvertTaskConfigToPodSpec(taskConfig, "", 0)
+	spec, err = repairDB.GetPodSpec(
+		ctx, s.jobID, uint32(instance0), configVersion)
+	s.NoError(err)
+	s.Equal(expectedSpec, spec)
+
+	// the synthesized spec is persisted, and repairing again is a no-op
+	spec, err = db.GetPodSpec(ctx, s.jobID, uint32(instance0), configVersion)
+	s.NoError(err)
+	s.Equal(expectedSpec, spec)
+
+	spec, err = repairDB.GetPodSpec(
+		ctx, s.jobID, uint32(instance0), configVersion)
+	s.NoError(err)
+	s.Equal(expectedSpec, spec)
+
+	config, _, spec, err := db.GetConfig(
+		ctx, s.jobID, uint32(instance0), configVersion)
+	s.NoError(err)
+	s.Equal(taskConfig, config)
+	s.Equal(expectedSpec, spec)
+}
+
+// TestNewTaskConfigV2OpsStoreOptions tests that the options configured
+// for the store are applied to the constructed TaskConfigV2Ops.
+func (s *TaskConfigV2ObjectTestSuite) TestNewTaskConfigV2OpsStoreOptions() {
+	db := NewTaskConfigV2Ops(testStore)
+	s.False(db.(*taskConfigV2Object).podSpecReadRepair)
+
+	repairStore := &Store{
+		oClient:             testStore.oClient,
+		metrics:             testStore.metrics,
+		taskConfigV2Options: []TaskConfigV2Option{WithPodSpecReadRepair()},
+	}
+	db = NewTaskConfigV2Ops(repairStore)
+	s.True(db.(*taskConfigV2Object).podSpecReadRepair)
+}
+
 func (s *TaskConfigV2ObjectTestSuite) TestCreateGetTaskConfig() {
 	var configVersion uint64 = 1
 	var instance0 int64 = 0
@@ -209,6 +284,194 @@ func (s *TaskConfigV2ObjectTestSuite) TestCreateGetTaskConfig() {
 	s.Equal(addOn, configAddOn)
 }
 
+// TestCreateGetConfig tests that task config, config addon and pod spec
+// are read together consistently with what was created.
+func (s *TaskConfigV2ObjectTestSuite) TestCreateGetConfig() {
+	var configVersion uint64 = 1
+	var instance0 int64 = 0
+	var instance1 int64 = 1
+
+	db := NewTaskConfigV2Ops(testStore)
+	ctx := context.Background()
+
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: []*peloton.Label{{Key: "k1", Value: "v1"}},
+	}
+
+	defaultConfig := &pbtask.TaskConfig{
+		Name: "default",
+		Resource: &pbtask.ResourceConfig{
+			CpuLimit:    0.8,
+			MemLimitMb:  800,
+			DiskLimitMb: 1500,
+		},
+	}
+	defaultSpec := &pbpod.PodSpec{
+		PodName:    &v1alphapeloton.PodName{Value: "default-pod"},
+		Containers: []*pbpod.ContainerSpec{{}},
+	}
+
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		common.DefaultTaskConfigID,
+		defaultConfig,
+		configAddOn,
+		defaultSpec,
+		configVersion,
+	))
+
+	instance0Config := &pbtask.TaskConfig{
+		Name: "instance0",
+		Resource: &pbtask.ResourceConfig{
+			CpuLimit:    1.0,
+			MemLimitMb:  80,
+			DiskLimitMb: 150,
+		},
+	}
+	instance0Spec := &pbpod.PodSpec{
+		PodName:    &v1alphapeloton.PodName{Value: "instance0-pod"},
+		Containers: []*pbpod.ContainerSpec{{}},
+	}
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		instance0,
+		instance0Config,
+		configAddOn,
+		instance0Spec,
+		configVersion,
+	))
+
+	// instance1 is created with a nil pod spec
+	instance1Config := &pbtask.TaskConfig{Name: "instance1"}
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		instance1,
+		instance1Config,
+		configAddOn,
+		nil,
+		configVersion,
+	))
+
+	// instance0 should have specific config and spec
+	config, addOn, spec, err := db.GetConfig(
+		ctx, s.jobID, uint32(instance0), configVersion)
+	s.NoError(err)
+	s.Equal(instance0Config, config)
+	s.Equal(configAddOn, addOn)
+	s.Equal(instance0Spec, spec)
+
+	// instance1 should have specific config and no spec
+	config, addOn, spec, err = db.GetConfig(
+		ctx, s.jobID, uint32(instance1), configVersion)
+	s.NoError(err)
+	s.Equal(instance1Config, config)
+	s.Equal(configAddOn, addOn)
+	s.Nil(spec)
+
+	// instance2 should have default config and spec
+	config, addOn, spec, err = db.GetConfig(
+		ctx, s.jobID, uint32(2), configVersion)
+	s.NoError(err)
+	s.Equal(defaultConfig, config)
+	s.Equal(configAddOn, addOn)
+	s.Equal(defaultSpec, spec)
+}
+
+// TestCreateInstanceOverride tests that an instance override is layered on
+// the default config and spec, and only for the overridden instance.
+func (s *TaskConfigV2ObjectTestSuite) TestCreateInstanceOverride() {
+	var configVersion uint64 = 1
+	var instance0 uint32 = 0
+	var instance1 uint32 = 1
+
+	db := NewTaskConfigV2Ops(testStore)
+	ctx := context.Background()
+
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: []*peloton.Label{{Key: "k1", Value: "v1"}},
+	}
+
+	defaultConfig := &pbtask.TaskConfig{
+		Name: "default",
+		Resource: &pbtask.ResourceConfig{
+			CpuLimit:    0.8,
+			MemLimitMb:  800,
+			DiskLimitMb: 1500,
+		},
+	}
+	defaultSpec := &pbpod.PodSpec{
+		PodName:    &v1alphapeloton.PodName{Value: "default-pod"},
+		Containers: []*pbpod.ContainerSpec{{Name: "default"}},
+	}
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		common.DefaultTaskConfigID,
+		defaultConfig,
+		configAddOn,
+		defaultSpec,
+		configVersion,
+	))
+
+	// instance0 is a canary which only overrides the name and containers
+	s.NoError(db.CreateInstanceOverride(
+		ctx,
+		s.jobID,
+		instance0,
+		&pbtask.TaskConfig{Name: "canary"},
+		&pbpod.PodSpec{
+			Containers: []*pbpod.ContainerSpec{{Name: "canary"}},
+		},
+		configVersion,
+	))
+
+	expectedConfig := &pbtask.TaskConfig{
+		Name:     "canary",
+		Resource: defaultConfig.GetResource(),
+	}
+	expectedSpec := &pbpod.PodSpec{
+		PodName:    defaultSpec.GetPodName(),
+		Containers: []*pbpod.ContainerSpec{{Name: "canary"}},
+	}
+
+	// instance0 should have the override layered on the default
+	config, addOn, err := db.GetTaskConfig(
+		ctx, s.jobID, instance0, configVersion)
+	s.NoError(err)
+	s.Equal(expectedConfig, config)
+	s.Equal(configAddOn, addOn)
+
+	spec, err := db.GetPodSpec(ctx, s.jobID, instance0, configVersion)
+	s.NoError(err)
+	s.Equal(expectedSpec, spec)
+
+	config, addOn, spec, err = db.GetConfig(
+		ctx, s.jobID, instance0, configVersion)
+	s.NoError(err)
+	s.Equal(expectedConfig, config)
+	s.Equal(configAddOn, addOn)
+	s.Equal(expectedSpec, spec)
+
+	// instance1 should have default config and spec
+	config, addOn, spec, err = db.GetConfig(
+		ctx, s.jobID, instance1, configVersion)
+	s.NoError(err)
+	s.Equal(defaultConfig, config)
+	s.Equal(configAddOn, addOn)
+	s.Equal(defaultSpec, spec)
+
+	spec, err = db.GetPodSpec(ctx, s.jobID, instance1, configVersion)
+	s.NoError(err)
+	s.Equal(defaultSpec, spec)
+
+	// an override without task config is rejected
+	s.Error(db.CreateInstanceOverride(
+		ctx, s.jobID, instance1, nil, nil, configVersion))
+}
+
 // TestGetTaskConfigLegacy tests a case where config is present in task_config
 // and not in task_config_v2.
 func (s *TaskConfigV2ObjectTestSuite) TestGetTaskConfigLegacy() {
@@ -257,3 +520,181 @@ func (s *TaskConfigV2ObjectTestSuite) TestGetTaskConfigLegacy() {
 	s.Equal(config, taskConfig)
 	s.Equal(addOn, configAddOn)
 }
+
+// TestCopyConfigVersion tests copying task configs to a new config version
+func (s *TaskConfigV2ObjectTestSuite) TestCopyConfigVersion() {
+	var fromVersion uint64 = 1
+	var toVersion uint64 = 2
+
+	db := NewTaskConfigV2Ops(testStore)
+	ctx := context.Background()
+
+	defaultSpec := &pbpod.PodSpec{
+		PodName:    &v1alphapeloton.PodName{Value: "default-pod"},
+		Containers: []*pbpod.ContainerSpec{{}},
+	}
+	instanceSpec := &pbpod.PodSpec{
+		PodName:    &v1alphapeloton.PodName{Value: "instance-pod"},
+		Containers: []*pbpod.ContainerSpec{{}},
+	}
+
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		common.DefaultTaskConfigID,
+		&pbtask.TaskConfig{Name: "default-task"},
+		&models.ConfigAddOn{},
+		defaultSpec,
+		fromVersion,
+	))
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		0,
+		&pbtask.TaskConfig{Name: "instance-task"},
+		&models.ConfigAddOn{},
+		instanceSpec,
+		fromVersion,
+	))
+
+	// instance 1 has no config at the source version and is skipped
+	s.NoError(db.CopyConfigVersion(
+		ctx, s.jobID, fromVersion, toVersion, []uint32{0, 1}))
+
+	spec, err := db.GetPodSpec(ctx, s.jobID, 0, toVersion)
+	s.NoError(err)
+	s.Equal(instanceSpec, spec)
+
+	taskConfig, _, err := db.GetTaskConfig(ctx, s.jobID, 0, toVersion)
+	s.NoError(err)
+	s.Equal("instance-task", taskConfig.GetName())
+
+	// instance 1 reads the copied default config
+	spec, err = db.GetPodSpec(ctx, s.jobID, 1, toVersion)
+	s.NoError(err)
+	s.Equal(defaultSpec, spec)
+
+	obj, err := db.(*taskConfigV2Object).getConfigObject(
+		ctx, s.jobID, 1, toVersion)
+	s.NoError(err)
+	s.Nil(obj)
+}
+
+// TestCreateBatch tests creating the task configs of more instances than
+// fit in a single batch, some of them without pod spec
+func (s *TaskConfigV2ObjectTestSuite) TestCreateBatch() {
+	var configVersion uint64 = 1
+
+	db := NewTaskConfigV2Ops(testStore)
+	ctx := context.Background()
+
+	configs := map[uint32]*InstanceConfig{}
+	for i := uint32(0); i < 2*_createBatchSize+5; i++ {
+		config := &InstanceConfig{
+			TaskConfig: &pbtask.TaskConfig{
+				Name: fmt.Sprintf("task-%d", i),
+			},
+			ConfigAddOn: &models.ConfigAddOn{},
+		}
+		// odd instances have no pod spec
+		if i%2 == 0 {
+			config.PodSpec = &pbpod.PodSpec{
+				PodName: &v1alphapeloton.PodName{
+					Value: fmt.Sprintf("pod-%d", i),
+				},
+				Containers: []*pbpod.ContainerSpec{{}},
+			}
+		}
+		configs[i] = config
+	}
+
+	s.NoError(db.CreateBatch(ctx, s.jobID, configs, configVersion))
+
+	for i, config := range configs {
+		taskConfig, _, podSpec, err := db.GetConfig(
+			ctx, s.jobID, i, configVersion)
+		s.NoError(err)
+		s.Equal(config.TaskConfig, taskConfig)
+		if config.PodSpec == nil {
+			s.Nil(podSpec)
+		} else {
+			s.Equal(config.PodSpec, podSpec)
+		}
+
+		obj, err := db.(*taskConfigV2Object).getConfigObject(
+			ctx, s.jobID, int64(i), configVersion)
+		s.NoError(err)
+		if config.PodSpec == nil {
+			s.Equal(api.V0.String(), obj.APIVersion)
+		} else {
+			s.Equal(api.V1.String(), obj.APIVersion)
+		}
+	}
+
+	// an empty batch creates nothing
+	s.NoError(db.CreateBatch(ctx, s.jobID, nil, configVersion+1))
+}
+
+// TestCreateGetPodSpecMetrics tests that creating a task config and
+// getting its pod spec record their latency and result
+func (s *TaskConfigV2ObjectTestSuite) TestCreateGetPodSpecMetrics() {
+	var configVersion uint64 = 1
+
+	scope := tally.NewTestScope("", map[string]string{})
+	store, err := NewCassandraStore(GenerateTestCassandraConfig(), scope)
+	s.NoError(err)
+	db := NewTaskConfigV2Ops(store)
+	ctx := context.Background()
+
+	podSpec := &pbpod.PodSpec{
+		PodName:    &v1alphapeloton.PodName{Value: "test-pod"},
+		Containers: []*pbpod.ContainerSpec{{}},
+	}
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		common.DefaultTaskConfigID,
+		&pbtask.TaskConfig{Name: "test-task"},
+		&models.ConfigAddOn{},
+		podSpec,
+		configVersion,
+	))
+
+	_, err = db.GetPodSpec(ctx, s.jobID, 0, configVersion)
+	s.NoError(err)
+
+	// get from a non-existent job
+	_, err = db.GetPodSpec(
+		ctx, &peloton.JobID{Value: uuid.New()}, 0, configVersion)
+	s.Error(err)
+
+	// get a task config stored without a pod spec
+	s.NoError(db.Create(
+		ctx,
+		s.jobID,
+		common.DefaultTaskConfigID,
+		&pbtask.TaskConfig{Name: "test-task"},
+		&models.ConfigAddOn{},
+		nil,
+		configVersion+1,
+	))
+	podSpec, err = db.GetPodSpec(ctx, s.jobID, 0, configVersion+1)
+	s.NoError(err)
+	s.Nil(podSpec)
+
+	snapshot := scope.Snapshot()
+	counters := snapshot.Counters()
+	timers := snapshot.Timers()
+	s.Equal(int64(2),
+		counters["orm.task_config_v2.create+result=success"].Value())
+	s.Len(timers["orm.task_config_v2.create_duration+"].Values(), 2)
+	s.Equal(int64(2),
+		counters["orm.task_config_v2.get+result=success"].Value())
+	s.Equal(int64(1),
+		counters["orm.task_config_v2.get+result=fail"].Value())
+	s.Len(timers["orm.task_config_v2.get_pod_spec_duration+"].Values(), 3)
+	s.Equal(int64(1),
+		counters["orm.task_config_v2.get_pod_spec_not_found+"].Value())
+	s.Equal(int64(1),
+		counters["orm.task_config_v2.get_nil_pod_spec+"].Value())
+}
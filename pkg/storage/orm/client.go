@@ -30,6 +30,9 @@ type Client interface {
 	CreateIfNotExists(ctx context.Context, e base.Object) error
 	// Create creates the storage object in the database
 	Create(ctx context.Context, e base.Object) error
+	// CreateBatch creates the storage objects of the same type in the
+	// database in a single batch
+	CreateBatch(ctx context.Context, es []base.Object) error
 	// Get gets the storage object from the database
 	Get(ctx context.Context, e base.Object, fieldsToRead ...string) (
 		map[string]interface{}, error)
@@ -109,6 +112,34 @@ func (c *client) Create(ctx context.Context, e base.Object) error {
 	return c.connector.Create(ctx, &table.Definition, table.GetRowFromObject(e))
 }
 
+// CreateBatch creates the storage objects, which must all be of the same
+// type, in the database in a single batch.
+func (c *client) CreateBatch(ctx context.Context, es []base.Object) error {
+	if len(es) == 0 {
+		return nil
+	}
+
+	// lookup if a table exists for these objects, return error if not found
+	table, err := c.getTable(es[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]base.Column, 0, len(es))
+	for _, e := range es {
+		if reflect.TypeOf(e) != reflect.TypeOf(es[0]) {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"batch mixes objects of types %q and %q",
+				reflect.TypeOf(es[0]).Elem().Name(),
+				reflect.TypeOf(e).Elem().Name())
+		}
+		rows = append(rows, table.GetRowFromObject(e))
+	}
+
+	// Tell the connector to create all the rows in a single batch
+	return c.connector.CreateBatch(ctx, &table.Definition, rows)
+}
+
 // Get fetches an base by primary key, The base provided must contain
 // values for all components of its primary key for the operation to succeed.
 func (c *client) Get(
@@ -151,6 +151,40 @@ func (suite *ORMTestSuite) TestClientCreate() {
 	suite.Error(err)
 }
 
+// TestClientCreateBatch tests client batch create operation on valid and
+// invalid entities
+func (suite *ORMTestSuite) TestClientCreateBatch() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	conn.EXPECT().CreateBatch(suite.ctx, gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition, rows [][]base.Column) {
+			suite.Len(rows, 2)
+			for _, row := range rows {
+				suite.ensureRowsEqual(row, testRow)
+			}
+		}).Return(nil)
+
+	client, err := orm.NewClient(conn, &ValidObject{})
+	suite.NoError(err)
+
+	err = client.CreateBatch(
+		suite.ctx, []base.Object{testValidObject, testValidObject})
+	suite.NoError(err)
+
+	// an empty batch is not sent to the connector
+	err = client.CreateBatch(suite.ctx, nil)
+	suite.NoError(err)
+
+	err = client.CreateBatch(suite.ctx, []base.Object{&InvalidObject1{}})
+	suite.Error(err)
+
+	// a batch must not mix objects of different types
+	err = client.CreateBatch(
+		suite.ctx, []base.Object{testValidObject, &InvalidObject1{}})
+	suite.Error(err)
+}
+
 // TestClientGet tests client get operation on valid and invalid entities
 func (suite *ORMTestSuite) TestClientGet() {
 	defer suite.ctrl.Finish()
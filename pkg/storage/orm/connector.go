@@ -33,6 +33,14 @@ type Connector interface {
 	// Create creates a row in the DB for the base object
 	Create(ctx context.Context, e *base.Definition, values []base.Column) error
 
+	// CreateBatch creates rows in the DB for the base object in a single
+	// batch, so that either all or none of the rows are eventually written
+	CreateBatch(
+		ctx context.Context,
+		e *base.Definition,
+		rows [][]base.Column,
+	) error
+
 	// Get fetches a row by primary key of base object
 	Get(
 		ctx context.Context,